@@ -3,14 +3,21 @@ package main
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"gafroshka-main/internal/analytics"
+	"gafroshka-main/internal/analytics/analyticspb"
 	"gafroshka-main/internal/kafka"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/gorilla/mux"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
 
 	_ "github.com/lib/pq"
 )
@@ -20,6 +27,9 @@ const (
 	KafkaBrokers = "kafka:9092"
 	KafkaTopic   = "user-events"
 	KafkaGroupID = "analytics-group"
+
+	defaultGRPCAddr = ":9082"
+	shutdownTimeout = 15 * time.Second
 )
 
 func main() {
@@ -54,25 +64,67 @@ func main() {
 		logger.Errorf("DB ping failed: %v", err)
 	}
 
+	// Остановка по SIGINT/SIGTERM: consumer дообрабатывает прочитанное и фиксирует смещения
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// Init Kafka Consumer
-	consumer := kafka.NewConsumer(KafkaBrokers, KafkaTopic, KafkaGroupID, logger)
+	consumer := kafka.NewConsumer(KafkaBrokers, KafkaTopic, KafkaGroupID, c.CfgConsumer, logger)
 	defer consumer.Close()
 
 	// Init analytics repository и service через интерфейсы
 	repo := analytics.NewRepository(db, logger)
-	service := analytics.NewService(repo, logger)
+	service := analytics.NewService(repo, logger, c.CfgPreferences, c.CfgCoPurchase, c.CfgEvents)
+
+	// Партиции сырого лога событий: создаем до чтения событий, чтобы они не копились в events_default,
+	// дальше создаем заранее и удаляем по сроку хранения
+	if err := service.MaintainEvents(ctx); err != nil {
+		logger.Errorf("Failed to maintain event partitions: %v", err)
+	}
+	go service.RunEventsMaintenance(ctx)
 
-	// Start event processor
+	// Start event processor: c.CfgConsumer.Workers обработчиков, события пользователя - по порядку
+	consumed := make(chan struct{})
 	go func() {
-		consumer.Consume(context.Background(), func(ctx context.Context, event kafka.Event) error {
+		defer close(consumed)
+		consumer.Consume(ctx, func(ctx context.Context, event kafka.Event) error {
 			return service.ProcessEvent(ctx, event)
 		})
 	}()
 
+	// Init gRPC server, работает рядом с HTTP на том же сервисе
+	grpcAddr := c.GRPCAddr
+	if grpcAddr == "" {
+		grpcAddr = defaultGRPCAddr
+	}
+	lis, err := net.Listen("tcp", grpcAddr)
+	if err != nil {
+		logger.Fatalf("Failed to listen gRPC on %s: %v", grpcAddr, err)
+	}
+	grpcServer := grpc.NewServer()
+	analyticspb.RegisterAnalyticsServiceServer(grpcServer, analytics.NewGRPCServer(service, logger))
+	defer grpcServer.GracefulStop()
+
+	go func() {
+		logger.Infof("Starting analytics gRPC server on %s", grpcAddr)
+		if err := grpcServer.Serve(lis); err != nil {
+			logger.Fatalf("Failed to start gRPC server: %v", err)
+		}
+	}()
+
 	// Init HTTP server
 	handler := analytics.NewHandler(service, logger)
 	r := mux.NewRouter()
 	r.HandleFunc("/user/{user_id}/preferences", handler.GetUserPreferences).Methods("GET")
+	r.HandleFunc("/user/{user_id}/weights", handler.GetCategoryWeights).Methods("GET")
+	r.HandleFunc("/item/{id}/also-bought", handler.GetAlsoBought).Methods("GET")
+	r.HandleFunc("/trends/categories", handler.GetCategoryTrends).Methods("GET")
+	r.HandleFunc("/categories/{category}/stats", handler.GetCategoryStats).Methods("GET")
+	r.HandleFunc("/announcement/{id}/stats", handler.GetAnnouncementStats).Methods("GET")
+	r.HandleFunc("/seller/{id}/stats", handler.GetSellerStats).Methods("GET")
+	r.HandleFunc("/funnels", handler.GetFunnel).Methods("GET")
+	r.HandleFunc("/cohorts", handler.GetCohorts).Methods("GET")
+	r.HandleFunc("/experiments/{key}/results", handler.GetExperimentResults).Methods("GET")
 
 	srv := &http.Server{
 		Addr:         ":8082",
@@ -82,8 +134,21 @@ func main() {
 		IdleTimeout:  60 * time.Second,
 	}
 
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			logger.Errorf("Failed to shutdown server: %v", err)
+		}
+	}()
+
 	logger.Info("Starting analytics service on :8082")
-	if err := srv.ListenAndServe(); err != nil {
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		logger.Fatalf("Failed to start server: %v", err)
 	}
+
+	// смещения фиксируются до закрытия consumer в defer
+	<-consumed
+	logger.Info("Analytics service stopped")
 }
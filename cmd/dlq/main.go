@@ -0,0 +1,243 @@
+// dlq - просмотр и повторная отправка сообщений из топика недоставленных событий.
+//
+//	go run ./cmd/dlq inspect -brokers localhost:9093
+//	go run ./cmd/dlq replay -brokers localhost:9093 -limit 100
+//
+// inspect печатает сообщения DLQ в JSON построчно и не сдвигает смещения,
+// replay возвращает их в исходный топик и фиксирует смещение группы replay
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"gafroshka-main/internal/kafka"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	kgo "github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultBrokers = "localhost:9093"
+	defaultTopic   = "user-events-dlq"
+	defaultTarget  = "user-events"
+	defaultGroupID = "dlq-replay"
+	defaultIdle    = 5 * time.Second
+)
+
+type options struct {
+	brokers []string
+	topic   string
+	target  string
+	groupID string
+	limit   int
+	idle    time.Duration
+}
+
+// deadLetterView - строка вывода inspect
+type deadLetterView struct {
+	Partition int             `json:"partition"`
+	Offset    int64           `json:"offset"`
+	Key       string          `json:"key,omitempty"`
+	Error     string          `json:"error"`
+	Attempts  int             `json:"attempts"`
+	Source    string          `json:"source"`
+	FailedAt  time.Time       `json:"failed_at"`
+	Replays   int             `json:"replays,omitempty"`
+	Value     json.RawMessage `json:"value"`
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	command := os.Args[1]
+
+	fs := flag.NewFlagSet(command, flag.ExitOnError)
+	brokers := fs.String("brokers", defaultBrokers, "адреса брокеров Kafka через запятую")
+	topic := fs.String("topic", defaultTopic, "топик DLQ")
+	target := fs.String("to", "", "куда отправлять при replay, по умолчанию - исходный топик сообщения")
+	groupID := fs.String("group", defaultGroupID, "группа, в которой replay фиксирует смещения DLQ")
+	limit := fs.Int("limit", 0, "сколько сообщений обработать, 0 - все")
+	idle := fs.Duration("idle", defaultIdle, "завершиться, если новых сообщений нет дольше idle")
+	_ = fs.Parse(os.Args[2:])
+
+	opts := options{
+		brokers: strings.Split(*brokers, ","),
+		topic:   *topic,
+		target:  *target,
+		groupID: *groupID,
+		limit:   *limit,
+		idle:    *idle,
+	}
+
+	zapLogger, err := zap.NewDevelopment()
+	if err != nil {
+		panic(err)
+	}
+	logger := zapLogger.Sugar()
+	defer func() { _ = zapLogger.Sync() }()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	switch command {
+	case "inspect":
+		err = inspect(ctx, opts)
+	case "replay":
+		var n int
+		n, err = replay(ctx, opts, logger)
+		logger.Infof("Replayed %d messages", n)
+	default:
+		usage()
+	}
+	if err != nil {
+		logger.Fatalf("%s failed: %v", command, err)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: dlq inspect|replay [-brokers host:port] [-topic name] [-to topic] [-group id] [-limit n] [-idle duration]")
+	os.Exit(2)
+}
+
+// inspect читает все партиции DLQ с начала без группы, поэтому смещения replay не меняются
+func inspect(ctx context.Context, opts options) error {
+	conn, err := kgo.DialContext(ctx, "tcp", opts.brokers[0])
+	if err != nil {
+		return fmt.Errorf("failed to dial kafka: %w", err)
+	}
+	partitions, err := conn.ReadPartitions(opts.topic)
+	_ = conn.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read partitions of %s: %w", opts.topic, err)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	printed := 0
+	for _, p := range partitions {
+		reader := kgo.NewReader(kgo.ReaderConfig{
+			Brokers:   opts.brokers,
+			Topic:     opts.topic,
+			Partition: p.ID,
+			MaxBytes:  10e6, // 10MB
+		})
+
+		for opts.limit == 0 || printed < opts.limit {
+			msg, err := fetch(ctx, reader, opts.idle)
+			if err != nil {
+				_ = reader.Close()
+				return err
+			}
+			if msg == nil {
+				break
+			}
+
+			if err := enc.Encode(view(*msg)); err != nil {
+				_ = reader.Close()
+				return err
+			}
+			printed++
+		}
+		_ = reader.Close()
+	}
+
+	return nil
+}
+
+// replay отправляет сообщения DLQ в исходный топик. Смещение фиксируется после записи,
+// так что прерванный replay продолжится с того же места, а не потеряет сообщения
+func replay(ctx context.Context, opts options, logger *zap.SugaredLogger) (int, error) {
+	reader := kgo.NewReader(kgo.ReaderConfig{
+		Brokers:  opts.brokers,
+		Topic:    opts.topic,
+		GroupID:  opts.groupID,
+		MaxBytes: 10e6, // 10MB
+	})
+	defer reader.Close()
+
+	writer := &kgo.Writer{
+		Addr: kgo.TCP(opts.brokers...),
+		// тот же ключ - та же партиция, что у продюсера, порядок событий пользователя сохраняется
+		Balancer: &kgo.Hash{},
+	}
+	defer writer.Close()
+
+	replayed := 0
+	for opts.limit == 0 || replayed < opts.limit {
+		msg, err := fetch(ctx, reader, opts.idle)
+		if err != nil {
+			return replayed, err
+		}
+		if msg == nil {
+			return replayed, nil
+		}
+
+		out := kafka.ReplayMessage(*msg)
+		out.Topic = replayTarget(opts.target, kafka.ParseDeadLetter(*msg))
+		if err := writer.WriteMessages(ctx, out); err != nil {
+			return replayed, fmt.Errorf("failed to write message %d/%d to %s: %w", msg.Partition, msg.Offset, out.Topic, err)
+		}
+		if err := reader.CommitMessages(ctx, *msg); err != nil {
+			return replayed, fmt.Errorf("failed to commit message %d/%d: %w", msg.Partition, msg.Offset, err)
+		}
+
+		logger.Infow("Replayed message", "partition", msg.Partition, "offset", msg.Offset, "to", out.Topic)
+		replayed++
+	}
+
+	return replayed, nil
+}
+
+// fetch ждет следующее сообщение не дольше idle, nil - сообщений больше нет
+func fetch(ctx context.Context, reader *kgo.Reader, idle time.Duration) (*kgo.Message, error) {
+	fetchCtx, cancel := context.WithTimeout(ctx, idle)
+	defer cancel()
+
+	msg, err := reader.FetchMessage(fetchCtx)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch message: %w", err)
+	}
+
+	return &msg, nil
+}
+
+// replayTarget - топик из флага, иначе исходный топик сообщения
+func replayTarget(target string, dl kafka.DeadLetter) string {
+	if target != "" {
+		return target
+	}
+	if dl.Topic != "" {
+		return dl.Topic
+	}
+	return defaultTarget
+}
+
+func view(msg kgo.Message) deadLetterView {
+	dl := kafka.ParseDeadLetter(msg)
+	v := deadLetterView{
+		Partition: msg.Partition,
+		Offset:    msg.Offset,
+		Key:       string(msg.Key),
+		Error:     dl.Error,
+		Attempts:  dl.Attempts,
+		Source:    fmt.Sprintf("%s/%d/%d", dl.Topic, dl.Partition, dl.Offset),
+		FailedAt:  dl.FailedAt,
+		Replays:   dl.Replays,
+		Value:     msg.Value,
+	}
+	// битое тело из DLQ печатаем строкой, иначе Encode упадет
+	if !json.Valid(msg.Value) {
+		v.Value, _ = json.Marshal(string(msg.Value))
+	}
+	return v
+}
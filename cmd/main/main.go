@@ -4,19 +4,38 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	analyticsclient "gafroshka-main/internal/analytics_client"
 	"gafroshka-main/internal/announcement"
+	announcementimage "gafroshka-main/internal/announcement_image"
 
 	annfb "gafroshka-main/internal/announcment_feedback"
 	"gafroshka-main/internal/app"
+	"gafroshka-main/internal/blobstore"
+	"gafroshka-main/internal/category"
 	elastic "gafroshka-main/internal/elastic_search"
 	"gafroshka-main/internal/etl"
+	"gafroshka-main/internal/experiment"
+	"gafroshka-main/internal/favorite"
+	"gafroshka-main/internal/follow"
 	userAnnHandlers "gafroshka-main/internal/handlers/announcement"
 	handlersAnnFeedback "gafroshka-main/internal/handlers/announcement_feedback"
+	handlersAnnImage "gafroshka-main/internal/handlers/announcement_image"
+	handlersCategory "gafroshka-main/internal/handlers/category"
+	handlersExperiment "gafroshka-main/internal/handlers/experiment"
+	handlersFavorite "gafroshka-main/internal/handlers/favorite"
+	handlersFollow "gafroshka-main/internal/handlers/follow"
+	handlersNotification "gafroshka-main/internal/handlers/notification"
+	handlersPriceWatch "gafroshka-main/internal/handlers/pricewatch"
 	handlersCart "gafroshka-main/internal/handlers/shopping_cart"
+	handlersStorefront "gafroshka-main/internal/handlers/storefront"
 	handlersUser "gafroshka-main/internal/handlers/user"
 	handlersUserFeedback "gafroshka-main/internal/handlers/user_feedback"
+	"gafroshka-main/internal/inventory"
 	"gafroshka-main/internal/kafka"
-	"gafroshka-main/internal/middleware"
+	"gafroshka-main/internal/lifecycle"
+	"gafroshka-main/internal/notification"
+	"gafroshka-main/internal/pricewatch"
+	recentlyviewed "gafroshka-main/internal/recently_viewed"
 	"gafroshka-main/internal/session"
 	cart "gafroshka-main/internal/shopping_cart"
 	"gafroshka-main/internal/user"
@@ -27,7 +46,6 @@ import (
 	"github.com/elastic/go-elasticsearch/v8"
 	"github.com/go-redis/redis/v8"
 
-	"github.com/gorilla/mux"
 	"go.uber.org/zap"
 
 	_ "github.com/lib/pq"
@@ -120,65 +138,112 @@ func main() {
 
 	go pipeline.Run(context.Background())
 
+	// init хранилища фотографий
+	imageStore, err := blobstore.NewLocalStore(c.CfgImages.Dir, c.CfgImages.BaseURL, logger)
+	if err != nil {
+		logger.Fatalf("failed to init image store: %v", err)
+	}
+	imageProcessor := announcementimage.NewProcessor(c.CfgImages.MaxSize, announcementimage.DefaultThumbnailSizes)
+
 	// init repository
 	userRepository := user.NewUserDBRepository(db, logger)
-	announcementRepository := announcement.NewAnnouncementDBRepository(db, logger, elasticService)
+	categoryRepository := category.NewCategoryDBRepository(db, logger)
+	imageRepository := announcementimage.NewImageDBRepository(db, logger)
+	announcementRepository := announcement.NewAnnouncementDBRepository(
+		db, logger, elasticService, categoryRepository, imageRepository, c.CfgLifecycle.Lifetime,
+	)
 	sessionRepository := session.NewSessionRepository(redisClient, logger, c.Secret, c.SessionDuration)
 	userFeedbackRepository := userFeedback.NewUserFeedbackRepository(db, logger)
 	annFeedbackRepository := annfb.NewFeedbackDBRepository(db, logger)
 	shoppingCartRepository := cart.NewShoppingCartRepository(db, logger)
+	inventoryRepository := inventory.NewInventoryDBRepository(db, logger)
+	notificationRepository := notification.NewNotificationDBRepository(db, logger)
+	favoriteRepository := favorite.NewFavoriteDBRepository(db, logger)
+	followRepository := follow.NewFollowDBRepository(db, logger)
+	recentlyViewedRepository := recentlyviewed.NewRecentlyViewedRedisRepository(
+		redisClient, logger, c.CfgRecentlyViewed.MaxItems, c.CfgRecentlyViewed.TTL,
+	)
+
+	// init and start планировщика публикации и снятия объявлений
+	scheduler := lifecycle.NewScheduler(
+		lifecycle.NewLifecycleDBRepository(db, logger),
+		elasticService,
+		notificationRepository,
+		logger,
+		c.CfgLifecycle.Interval,
+		c.CfgLifecycle.ExpiryNotice,
+	)
+
+	go scheduler.Run(context.Background())
+
+	// init and start рассылки уведомлений о снижении цены
+	priceWatchRepository := pricewatch.NewPriceWatchDBRepository(db, logger)
+	priceNotifier := pricewatch.NewNotifier(priceWatchRepository, notificationRepository, logger, c.PriceWatchInterval)
+
+	go priceNotifier.Run(context.Background())
 
 	// init Kafka Producer для отправки событий
 	kafkaProducer := kafka.NewProducer([]string{KafkaBrokers}, KafkaTopic, logger)
 	defer kafkaProducer.Close()
 
-	// init router
-	r := mux.NewRouter()
+	// init клиента сервиса аналитики
+	analyticsClient, err := analyticsclient.New(c.CfgAnalytics, redisClient, logger)
+	if err != nil {
+		logger.Fatalf("invalid analytics client config: %v", err)
+	}
+	defer analyticsClient.Close()
+
+	// init распределения пользователей по A/B экспериментам
+	experimentAssigner, err := experiment.NewAssigner(c.Experiments)
+	if err != nil {
+		logger.Fatalf("invalid experiments config: %v", err)
+	}
 
 	// init handlers
-	userHandlers := handlersUser.NewUserHandler(logger, userRepository, sessionRepository)
+	userHandlers := handlersUser.NewUserHandler(
+		logger, userRepository, sessionRepository, kafkaProducer, recentlyViewedRepository,
+	)
 	userFeedbackHandlers := handlersUserFeedback.NewUserFeedbackHandler(logger, userFeedbackRepository)
 	annFeedbackHandlers := handlersAnnFeedback.NewAnnouncementFeedbackHandler(logger, annFeedbackRepository)
-	annHandlers := userAnnHandlers.NewAnnouncementHandler(logger, announcementRepository, kafkaProducer)
+	annHandlers := userAnnHandlers.NewAnnouncementHandler(
+		logger, announcementRepository, kafkaProducer, recentlyViewedRepository, analyticsClient,
+	)
+	categoryHandlers := handlersCategory.NewCategoryHandler(logger, categoryRepository)
+	notificationHandlers := handlersNotification.NewNotificationHandler(logger, notificationRepository)
+	favoriteHandlers := handlersFavorite.NewFavoriteHandler(logger, favoriteRepository, announcementRepository, kafkaProducer)
+	followHandlers := handlersFollow.NewFollowHandler(logger, followRepository, userRepository, announcementRepository)
+	priceWatchHandlers := handlersPriceWatch.NewPriceWatchHandler(logger, priceWatchRepository, announcementRepository)
+	storefrontHandlers := handlersStorefront.NewStorefrontHandler(logger, userRepository, announcementRepository)
+	experimentHandlers := handlersExperiment.NewExperimentHandler(logger)
+	imageHandlers := handlersAnnImage.NewImageHandler(
+		logger, imageRepository, announcementRepository, imageStore, imageProcessor, c.CfgImages.MaxPerAnnouncement,
+	)
 	// Передаём kafkaProducer в ShoppingCartHandler
-	shoppingCartHandlers := handlersCart.NewShoppingCartHandler(logger, shoppingCartRepository, announcementRepository, userRepository, kafkaProducer)
-
-	// Ручки требующие авторизации
-	authRouter := r.PathPrefix("/api").Subrouter()
-	authRouter.Use(middleware.Auth(sessionRepository))
-
-	authRouter.HandleFunc("/announcement/feedback", annFeedbackHandlers.Create).Methods("POST")
-	authRouter.HandleFunc("/announcement/feedback/{id}", annFeedbackHandlers.Delete).Methods("DELETE")
-	authRouter.HandleFunc("/announcement/feedback/{id}", annFeedbackHandlers.Update).Methods("PATCH")
-
-	authRouter.HandleFunc("/user/{id}", userHandlers.ChangeProfile).Methods("PUT")
-	authRouter.HandleFunc("/user/{id}/balance/topup", userHandlers.TopUpBalance).Methods("POST")
-
-	authRouter.HandleFunc("/user/feedback", userFeedbackHandlers.Create).Methods("POST")
-	authRouter.HandleFunc("/user/feedback/{id}", userFeedbackHandlers.Update).Methods("PUT")
-	authRouter.HandleFunc("/user/feedback/{id}", userFeedbackHandlers.Delete).Methods("DELETE")
-
-	authRouter.HandleFunc("/announcement", annHandlers.Create).Methods("POST")
-
-	authRouter.HandleFunc("/cart/{userID}/item/{annID}", shoppingCartHandlers.AddToShoppingCart).Methods("POST") //
-	authRouter.HandleFunc("/cart/{userID}/item/{annID}", shoppingCartHandlers.DeleteFromShoppingCart).Methods("DELETE")
-	authRouter.HandleFunc("/cart/{userID}", shoppingCartHandlers.GetCart).Methods("GET")
-	authRouter.HandleFunc("/cart/{userID}/purchase", shoppingCartHandlers.PurchaseFromCart).Methods("POST") //
-
-	// Ручки НЕ требующие авторизации
-	noAuthRouter := r.PathPrefix("/api").Subrouter()
-
-	noAuthRouter.HandleFunc("/user/{id}", userHandlers.Info).Methods("GET")
-	noAuthRouter.HandleFunc("/user/register", userHandlers.Register).Methods("POST")
-	noAuthRouter.HandleFunc("/user/login", userHandlers.Login).Methods("POST")
-	noAuthRouter.HandleFunc("/user/{id}/balance", userHandlers.GetBalance).Methods("GET")
-
-	noAuthRouter.HandleFunc("/user/feedback/user/{id}", userFeedbackHandlers.GetByUserID).Methods("GET")
-	noAuthRouter.HandleFunc("/announcement/feedback/announcement/{id}", annFeedbackHandlers.GetByAnnouncementID).Methods("GET") //
+	shoppingCartHandlers := handlersCart.NewShoppingCartHandler(
+		logger, shoppingCartRepository, announcementRepository, inventoryRepository, kafkaProducer, c.ReservationTTL,
+	)
 
-	noAuthRouter.HandleFunc("/announcements/top", annHandlers.GetTopN).Methods("POST")            //
-	noAuthRouter.HandleFunc("/announcement/{id}/{user_id}", annHandlers.GetByID).Methods("GET")   //
-	noAuthRouter.HandleFunc("/announcements/search/{user_id}", annHandlers.Search).Methods("GET") //
+	// init router
+	r := newRouter(handlers{
+		user:         userHandlers,
+		userFeedback: userFeedbackHandlers,
+		annFeedback:  annFeedbackHandlers,
+		announcement: annHandlers,
+		category:     categoryHandlers,
+		notification: notificationHandlers,
+		favorite:     favoriteHandlers,
+		follow:       followHandlers,
+		priceWatch:   priceWatchHandlers,
+		storefront:   storefrontHandlers,
+		experiment:   experimentHandlers,
+		image:        imageHandlers,
+		shoppingCart: shoppingCartHandlers,
+	}, sessionRepository, experimentAssigner)
+
+	// Фотографии объявлений отдаются статикой из локального хранилища
+	r.PathPrefix(c.CfgImages.BaseURL + "/").Handler(
+		http.StripPrefix(c.CfgImages.BaseURL+"/", imageStore.Handler()),
+	)
 
 	logger.Infow("starting server",
 		"type", "START",
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"gafroshka-main/internal/experiment"
+	userAnnHandlers "gafroshka-main/internal/handlers/announcement"
+	handlersAnnFeedback "gafroshka-main/internal/handlers/announcement_feedback"
+	handlersAnnImage "gafroshka-main/internal/handlers/announcement_image"
+	handlersCategory "gafroshka-main/internal/handlers/category"
+	handlersExperiment "gafroshka-main/internal/handlers/experiment"
+	handlersFavorite "gafroshka-main/internal/handlers/favorite"
+	handlersFollow "gafroshka-main/internal/handlers/follow"
+	handlersNotification "gafroshka-main/internal/handlers/notification"
+	handlersPriceWatch "gafroshka-main/internal/handlers/pricewatch"
+	handlersCart "gafroshka-main/internal/handlers/shopping_cart"
+	handlersStorefront "gafroshka-main/internal/handlers/storefront"
+	handlersUser "gafroshka-main/internal/handlers/user"
+	handlersUserFeedback "gafroshka-main/internal/handlers/user_feedback"
+	"gafroshka-main/internal/middleware"
+	"gafroshka-main/internal/session"
+
+	"github.com/gorilla/mux"
+)
+
+// handlers - обработчики всех ручек API
+type handlers struct {
+	user         *handlersUser.UserHandler
+	userFeedback *handlersUserFeedback.UserFeedbackHandler
+	annFeedback  *handlersAnnFeedback.AnnouncementFeedbackHandler
+	announcement *userAnnHandlers.AnnouncementHandler
+	category     *handlersCategory.CategoryHandler
+	notification *handlersNotification.NotificationHandler
+	favorite     *handlersFavorite.FavoriteHandler
+	follow       *handlersFollow.FollowHandler
+	priceWatch   *handlersPriceWatch.PriceWatchHandler
+	storefront   *handlersStorefront.StorefrontHandler
+	experiment   *handlersExperiment.ExperimentHandler
+	image        *handlersAnnImage.ImageHandler
+	shoppingCart *handlersCart.ShoppingCartHandler
+}
+
+// newRouter регистрирует ручки API вместе с цепочкой middleware
+func newRouter(h handlers, sessions session.SessionRepo, assigner *experiment.Assigner) *mux.Router {
+	r := mux.NewRouter()
+
+	// Ручки требующие авторизации
+	authRouter := r.PathPrefix("/api").Subrouter()
+	authRouter.Use(middleware.Auth(sessions))
+	authRouter.Use(middleware.Visitor)
+	authRouter.Use(middleware.Experiments(assigner))
+
+	authRouter.HandleFunc("/experiments", h.experiment.Assignments).Methods("GET")
+
+	authRouter.HandleFunc("/announcement/feedback", h.annFeedback.Create).Methods("POST")
+	authRouter.HandleFunc("/announcement/feedback/{id}", h.annFeedback.Delete).Methods("DELETE")
+	authRouter.HandleFunc("/announcement/feedback/{id}", h.annFeedback.Update).Methods("PATCH")
+
+	authRouter.HandleFunc("/user/{id}", h.user.ChangeProfile).Methods("PUT")
+	authRouter.HandleFunc("/user/{id}/balance/topup", h.user.TopUpBalance).Methods("POST")
+	authRouter.HandleFunc("/user/{id}/recently-viewed", h.announcement.RecentlyViewed).Methods("GET")
+
+	authRouter.HandleFunc("/user/feedback", h.userFeedback.Create).Methods("POST")
+	authRouter.HandleFunc("/user/feedback/{id}", h.userFeedback.Update).Methods("PUT")
+	authRouter.HandleFunc("/user/feedback/{id}", h.userFeedback.Delete).Methods("DELETE")
+
+	authRouter.HandleFunc("/announcement", h.announcement.Create).Methods("POST")
+	authRouter.HandleFunc("/announcement/{id}/attributes", h.announcement.UpdateAttributes).Methods("PUT")
+	authRouter.HandleFunc("/announcement/{id}", h.announcement.Update).Methods("PATCH")
+	authRouter.HandleFunc("/announcement/{id}/renew", h.announcement.Renew).Methods("POST")
+	authRouter.HandleFunc("/announcement/{id}/publish", h.announcement.Publish).Methods("POST")
+	authRouter.HandleFunc("/announcement/{id}/images", h.image.Upload).Methods("POST")
+	authRouter.HandleFunc("/announcement/{id}/images/order", h.image.Reorder).Methods("PUT")
+	authRouter.HandleFunc("/announcement/{id}/images/{imageID}", h.image.Delete).Methods("DELETE")
+
+	authRouter.HandleFunc("/announcement/{id}/price-watch", h.priceWatch.Watch).Methods("PUT")
+	authRouter.HandleFunc("/announcement/{id}/price-watch", h.priceWatch.Unwatch).Methods("DELETE")
+
+	authRouter.HandleFunc("/favorites", h.favorite.List).Methods("GET")
+	authRouter.HandleFunc("/favorites/{id}", h.favorite.Add).Methods("POST")
+	authRouter.HandleFunc("/favorites/{id}", h.favorite.Remove).Methods("DELETE")
+
+	authRouter.HandleFunc("/user/{id}/follow", h.follow.Follow).Methods("POST")
+	authRouter.HandleFunc("/user/{id}/follow", h.follow.Unfollow).Methods("DELETE")
+	authRouter.HandleFunc("/feed", h.follow.Feed).Methods("GET")
+
+	authRouter.HandleFunc("/notifications", h.notification.List).Methods("GET")
+	authRouter.HandleFunc("/notifications/read", h.notification.MarkRead).Methods("POST")
+
+	authRouter.HandleFunc("/cart/{userID}/item/{annID}", h.shoppingCart.AddToShoppingCart).Methods("POST") //
+	authRouter.HandleFunc("/cart/{userID}/item/{annID}", h.shoppingCart.DeleteFromShoppingCart).Methods("DELETE")
+	authRouter.HandleFunc("/cart/{userID}", h.shoppingCart.GetCart).Methods("GET")
+	authRouter.HandleFunc("/cart/{userID}/reserve", h.shoppingCart.ReserveCart).Methods("POST")
+	authRouter.HandleFunc("/cart/{userID}/purchase", h.shoppingCart.PurchaseFromCart).Methods("POST") //
+
+	// Ручки НЕ требующие авторизации. Сессия, если она есть, все равно кладется в контекст:
+	// по ней продавец видит свои черновики, а эксперименты распределяют пользователя
+	noAuthRouter := r.PathPrefix("/api").Subrouter()
+	noAuthRouter.Use(middleware.OptionalAuth(sessions))
+	noAuthRouter.Use(middleware.Visitor)
+	noAuthRouter.Use(middleware.Experiments(assigner))
+
+	noAuthRouter.HandleFunc("/user/{id}", h.user.Info).Methods("GET")
+	noAuthRouter.HandleFunc("/user/register", h.user.Register).Methods("POST")
+	noAuthRouter.HandleFunc("/user/login", h.user.Login).Methods("POST")
+	noAuthRouter.HandleFunc("/user/{id}/balance", h.user.GetBalance).Methods("GET")
+	noAuthRouter.HandleFunc("/user/{id}/storefront", h.storefront.Storefront).Methods("GET")
+	noAuthRouter.HandleFunc("/user/{id}/announcements", h.storefront.Announcements).Methods("GET")
+
+	noAuthRouter.HandleFunc("/user/feedback/user/{id}", h.userFeedback.GetByUserID).Methods("GET")
+	noAuthRouter.HandleFunc("/announcement/feedback/announcement/{id}", h.annFeedback.GetByAnnouncementID).Methods("GET") //
+
+	noAuthRouter.HandleFunc("/announcement/{id}/price-history", h.priceWatch.History).Methods("GET")
+	noAuthRouter.HandleFunc("/announcement/{id}/similar", h.announcement.Similar).Methods("GET")
+	noAuthRouter.HandleFunc("/announcements/top", h.announcement.GetTopN).Methods("POST") //
+	// просмотры и поиск учитываются за пользователем сессии или анонимным посетителем,
+	// user_id в пути оставлен для старых клиентов и не учитывается
+	noAuthRouter.HandleFunc("/announcement/{id}", h.announcement.GetByID).Methods("GET")
+	noAuthRouter.HandleFunc("/announcement/{id}/{user_id}", h.announcement.GetByID).Methods("GET") //
+	noAuthRouter.HandleFunc("/announcements/search", h.announcement.Search).Methods("GET")
+	noAuthRouter.HandleFunc("/announcements/search/{user_id}", h.announcement.Search).Methods("GET") //
+	noAuthRouter.HandleFunc("/announcements/facets", h.announcement.Facets).Methods("GET")
+	noAuthRouter.HandleFunc("/announcements/compare", h.announcement.Compare).Methods("POST")
+
+	noAuthRouter.HandleFunc("/categories", h.category.GetTree).Methods("GET")
+	noAuthRouter.HandleFunc("/categories/{id}/attributes", h.category.GetAttributes).Methods("GET")
+
+	return r
+}
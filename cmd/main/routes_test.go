@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"gafroshka-main/internal/announcement"
+	"gafroshka-main/internal/experiment"
+	userAnnHandlers "gafroshka-main/internal/handlers/announcement"
+	"gafroshka-main/internal/kafka"
+	"gafroshka-main/internal/mocks"
+	"gafroshka-main/internal/session"
+	"gafroshka-main/internal/visitor"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// recordingProducer запоминает отправленные события
+type recordingProducer struct {
+	events []kafka.Event
+}
+
+func (p *recordingProducer) SendEvent(_ context.Context, event kafka.Event) error {
+	p.events = append(p.events, event)
+	return nil
+}
+
+func (p *recordingProducer) Close() error { return nil }
+
+type routerEnv struct {
+	router   *mux.Router
+	sessions *session.SessionRepository
+	annRepo  *mocks.MockAnnouncementRepo
+	viewed   *mocks.MockRecentlyViewedRepo
+	producer *recordingProducer
+}
+
+// newRouterEnv собирает настоящий роутер с настоящими сессиями поверх miniredis
+func newRouterEnv(t *testing.T) *routerEnv {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	logger := zaptest.NewLogger(t).Sugar()
+
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	sessions := session.NewSessionRepository(rdb, logger, "secret", time.Hour)
+
+	assigner, err := experiment.NewAssigner(nil)
+	require.NoError(t, err)
+
+	env := &routerEnv{
+		sessions: sessions,
+		annRepo:  mocks.NewMockAnnouncementRepo(ctrl),
+		viewed:   mocks.NewMockRecentlyViewedRepo(ctrl),
+		producer: &recordingProducer{},
+	}
+	annHandlers := userAnnHandlers.NewAnnouncementHandler(logger, env.annRepo, env.producer, env.viewed, nil)
+	env.router = newRouter(handlers{announcement: annHandlers}, sessions, assigner)
+
+	return env
+}
+
+// login создает сессию пользователя и возвращает ее токен
+func (env *routerEnv) login(t *testing.T, userID string) string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	_, err := env.sessions.CreateSession(context.Background(), w, userID, userID+"@example.com")
+	require.NoError(t, err)
+
+	var resp struct {
+		Token string `json:"token"`
+	}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	return resp.Token
+}
+
+func TestRouter_GetByID_DraftVisibleToSeller(t *testing.T) {
+	env := newRouterEnv(t)
+	token := env.login(t, "seller-1")
+
+	env.annRepo.EXPECT().GetByID("ann-1").
+		Return(&announcement.Announcement{ID: "ann-1", UserSellerID: "seller-1", IsDraft: true}, nil)
+	env.viewed.EXPECT().Add(gomock.Any(), "seller-1", "ann-1").Return(nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/announcement/ann-1/seller-1", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rr := httptest.NewRecorder()
+	env.router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestRouter_GetByID_DraftHiddenFromOthers(t *testing.T) {
+	env := newRouterEnv(t)
+	token := env.login(t, "buyer-1")
+
+	draft := &announcement.Announcement{ID: "ann-1", UserSellerID: "seller-1", IsDraft: true}
+	env.annRepo.EXPECT().GetByID("ann-1").Return(draft, nil).Times(3)
+
+	for name, auth := range map[string]string{
+		"anonymous":     "",
+		"other user":    "Bearer " + token,
+		"invalid token": "Bearer broken",
+	} {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/announcement/ann-1/buyer-1", nil)
+			if auth != "" {
+				req.Header.Set("Authorization", auth)
+			}
+			rr := httptest.NewRecorder()
+			env.router.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusNotFound, rr.Code)
+		})
+	}
+}
+
+func TestRouter_GetByID_Anonymous_SavesVisitorView(t *testing.T) {
+	env := newRouterEnv(t)
+	visitorID := uuid.New().String()
+
+	env.annRepo.EXPECT().GetByID("ann-1").Return(&announcement.Announcement{ID: "ann-1", Category: 2}, nil)
+	env.viewed.EXPECT().Add(gomock.Any(), visitor.Key(visitorID), "ann-1").Return(nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/announcement/ann-1", nil)
+	req.AddCookie(&http.Cookie{Name: visitor.CookieName, Value: visitorID})
+	rr := httptest.NewRecorder()
+	env.router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestRouter_GetByID_WithoutUserID_UsesSession(t *testing.T) {
+	env := newRouterEnv(t)
+	token := env.login(t, "user-1")
+
+	env.annRepo.EXPECT().GetByID("ann-1").Return(&announcement.Announcement{ID: "ann-1", Category: 2}, nil)
+	env.viewed.EXPECT().Add(gomock.Any(), "user-1", "ann-1").Return(nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/announcement/ann-1", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rr := httptest.NewRecorder()
+	env.router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	require.Len(t, env.producer.events, 1)
+	assert.Equal(t, "user-1", env.producer.events[0].UserID)
+}
+
+func TestRouter_Search_Anonymous_SendsEvent(t *testing.T) {
+	env := newRouterEnv(t)
+
+	env.annRepo.EXPECT().Search(gomock.Any()).Return([]announcement.Announcement{{ID: "ann-1", Category: 4}}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/announcements/search?q=bike", nil)
+	rr := httptest.NewRecorder()
+	env.router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	// анонимный поиск учитывается за посетителем, id которого добавит продюсер
+	require.Len(t, env.producer.events, 1)
+	assert.Equal(t, kafka.EventTypeSearch, env.producer.events[0].Type)
+	assert.Empty(t, env.producer.events[0].UserID)
+	assert.Equal(t, []int{4}, env.producer.events[0].Categories)
+}
+
+func TestRouter_GetByID_PathUserIDIgnored(t *testing.T) {
+	env := newRouterEnv(t)
+	token := env.login(t, "user-1")
+	visitorID := uuid.New().String()
+
+	env.annRepo.EXPECT().GetByID("ann-1").Return(&announcement.Announcement{ID: "ann-1", Category: 2}, nil).Times(2)
+	// просмотр пишется за пользователем сессии, а без нее - за посетителем, но не за victim
+	env.viewed.EXPECT().Add(gomock.Any(), "user-1", "ann-1").Return(nil)
+	env.viewed.EXPECT().Add(gomock.Any(), visitor.Key(visitorID), "ann-1").Return(nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/announcement/ann-1/victim", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rr := httptest.NewRecorder()
+	env.router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/announcement/ann-1/victim", nil)
+	req.AddCookie(&http.Cookie{Name: visitor.CookieName, Value: visitorID})
+	rr = httptest.NewRecorder()
+	env.router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	require.Len(t, env.producer.events, 2)
+	assert.Equal(t, "user-1", env.producer.events[0].UserID)
+	assert.Empty(t, env.producer.events[1].UserID)
+}
+
+func TestRouter_Search_PathUserIDIgnored(t *testing.T) {
+	env := newRouterEnv(t)
+
+	env.annRepo.EXPECT().Search(gomock.Any()).Return([]announcement.Announcement{{ID: "ann-1", Category: 4}}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/announcements/search/victim?q=bike", nil)
+	rr := httptest.NewRecorder()
+	env.router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	require.Len(t, env.producer.events, 1)
+	assert.Empty(t, env.producer.events[0].UserID)
+}
@@ -1,9 +1,98 @@
+-- Вес категории на момент updated_at, при чтении и обновлении затухает экспоненциально
 CREATE TABLE IF NOT EXISTS user_preferences (
     user_id VARCHAR(64) NOT NULL,
     category INTEGER NOT NULL,
-    weight INTEGER NOT NULL DEFAULT 0,
+    weight DOUBLE PRECISION NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
     PRIMARY KEY (user_id, category)
 );
 
 -- Optionally, add an index for faster lookups
-CREATE INDEX IF NOT EXISTS idx_user_preferences_user ON user_preferences(user_id);
\ No newline at end of file
+CREATE INDEX IF NOT EXISTS idx_user_preferences_user ON user_preferences(user_id);
+
+-- Совместные покупки: сколько раз объявления купили вместе (support)
+-- и та же величина с экспоненциальным затуханием по времени (score на момент updated_at)
+-- Каждая пара хранится в обе стороны, чтобы читать по announcement_id одним индексом
+CREATE TABLE IF NOT EXISTS co_purchase (
+    announcement_id VARCHAR(64) NOT NULL,
+    related_id VARCHAR(64) NOT NULL,
+    support INTEGER NOT NULL DEFAULT 0,
+    score DOUBLE PRECISION NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (announcement_id, related_id)
+);
+
+-- Счетчики событий по категориям с разбивкой по типу, наполняются консьюмером.
+-- Почасовые нужны для коротких окон трендов, дневные - для длинных
+CREATE TABLE IF NOT EXISTS category_events_hourly (
+    bucket TIMESTAMP NOT NULL,
+    category INTEGER NOT NULL,
+    event_type VARCHAR(32) NOT NULL,
+    count BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (bucket, category, event_type)
+);
+
+CREATE INDEX IF NOT EXISTS idx_category_events_hourly_category ON category_events_hourly(category, bucket);
+
+CREATE TABLE IF NOT EXISTS category_events_daily (
+    bucket DATE NOT NULL,
+    category INTEGER NOT NULL,
+    event_type VARCHAR(32) NOT NULL,
+    count BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (bucket, category, event_type)
+);
+
+CREATE INDEX IF NOT EXISTS idx_category_events_daily_category ON category_events_daily(category, bucket);
+
+-- Продавец объявления, запоминается по событиям, в которых он указан.
+-- Нужен, чтобы сводить статистику продавца и по событиям без seller_id (покупка, удаление из корзины)
+CREATE TABLE IF NOT EXISTS announcement_seller (
+    announcement_id VARCHAR(64) PRIMARY KEY,
+    seller_id VARCHAR(64) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_announcement_seller_seller ON announcement_seller(seller_id);
+
+-- Дневные счетчики воронки объявления
+CREATE TABLE IF NOT EXISTS announcement_stats_daily (
+    bucket DATE NOT NULL,
+    announcement_id VARCHAR(64) NOT NULL,
+    views BIGINT NOT NULL DEFAULT 0,
+    cart_adds BIGINT NOT NULL DEFAULT 0,
+    cart_removes BIGINT NOT NULL DEFAULT 0,
+    purchases BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (announcement_id, bucket)
+);
+
+-- Обработанные события: id события записывается в одной транзакции со всеми его счетчиками,
+-- поэтому повторная доставка, повтор после ошибки или возврат из DLQ не учитываются дважды.
+-- Хранятся столько же, сколько сырой лог
+CREATE TABLE IF NOT EXISTS processed_events (
+    event_id VARCHAR(128) PRIMARY KEY,
+    processed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_processed_events_processed_at ON processed_events(processed_at);
+
+-- Сырые события для воронок и когорт. Партиции по дням создает и удаляет
+-- по сроку хранения сервис аналитики, default принимает события вне созданных партиций.
+-- При создании партиции дня его события переносятся из default, старые события из default удаляются по сроку хранения
+CREATE TABLE IF NOT EXISTS events (
+    user_id VARCHAR(64) NOT NULL DEFAULT '',
+    -- анонимный посетитель; при входе его события получают user_id
+    visitor_id VARCHAR(64) NOT NULL DEFAULT '',
+    event_type VARCHAR(32) NOT NULL,
+    announcement_id VARCHAR(64) NOT NULL DEFAULT '',
+    seller_id VARCHAR(64) NOT NULL DEFAULT '',
+    categories INTEGER[] NOT NULL DEFAULT '{}',
+    announcement_ids VARCHAR(64)[] NOT NULL DEFAULT '{}',
+    -- варианты пользователя в A/B экспериментах: {"ключ эксперимента": "вариант"}
+    experiments JSONB NOT NULL DEFAULT '{}',
+    occurred_at TIMESTAMP NOT NULL
+) PARTITION BY RANGE (occurred_at);
+
+CREATE TABLE IF NOT EXISTS events_default PARTITION OF events DEFAULT;
+
+CREATE INDEX IF NOT EXISTS idx_events_occurred_at ON events(occurred_at);
+CREATE INDEX IF NOT EXISTS idx_events_user ON events(user_id, occurred_at);
+CREATE INDEX IF NOT EXISTS idx_events_anonymous_visitor ON events(visitor_id) WHERE user_id = '';
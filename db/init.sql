@@ -24,19 +24,120 @@ CREATE TABLE user_feedback (
     rating SMALLINT NOT NULL
 );
 
+CREATE TABLE category (
+    id SERIAL PRIMARY KEY,
+    parent_id INTEGER REFERENCES category(id) ON DELETE RESTRICT,
+    slug VARCHAR(50) NOT NULL UNIQUE,
+    name_ru VARCHAR(100) NOT NULL,
+    name_en VARCHAR(100) NOT NULL
+);
+
 CREATE TABLE announcement (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
     name VARCHAR(100) NOT NULL,
     description TEXT,
     user_seller_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
     price DECIMAL NOT NULL CHECK (price >= 0),
-    category INTEGER,
+    category INTEGER REFERENCES category(id),
     discount SMALLINT DEFAULT 0 NOT NULL CHECK (discount BETWEEN 0 AND 100),
+    quantity INTEGER DEFAULT 1 NOT NULL CHECK (quantity >= 0),
     is_active BOOLEAN DEFAULT TRUE NOT NULL,
     rating FLOAT DEFAULT 0.0,
     rating_count INTEGER DEFAULT 0,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    searching BOOLEAN DEFAULT FALSE NOT NULL
+    searching BOOLEAN DEFAULT FALSE NOT NULL,
+    -- published = FALSE у отложенных объявлений, их активирует планировщик в publish_at
+    published BOOLEAN DEFAULT TRUE NOT NULL,
+    publish_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP + INTERVAL '30 days' NOT NULL,
+    expiry_notified BOOLEAN DEFAULT FALSE NOT NULL,
+    -- черновик не опубликован и не активен, category у него может быть не заполнена
+    is_draft BOOLEAN DEFAULT FALSE NOT NULL,
+    -- favorites_count - сколько пользователей добавили объявление в избранное
+    favorites_count INTEGER DEFAULT 0 NOT NULL,
+    -- updated_at - время публикации или последнего изменения, по нему строится лента подписок
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+-- Схема атрибутов категории, атрибуты родителя наследуются дочерними категориями
+CREATE TABLE category_attribute (
+    id SERIAL PRIMARY KEY,
+    category_id INTEGER NOT NULL REFERENCES category(id) ON DELETE CASCADE,
+    code VARCHAR(50) NOT NULL,
+    name_ru VARCHAR(100) NOT NULL,
+    name_en VARCHAR(100) NOT NULL,
+    type VARCHAR(10) NOT NULL CHECK (type IN ('string', 'number', 'bool', 'enum')),
+    allowed_values TEXT[],
+    required BOOLEAN DEFAULT FALSE NOT NULL,
+    UNIQUE (category_id, code)
+);
+
+CREATE TABLE announcement_attribute (
+    announcement_id UUID NOT NULL REFERENCES announcement(id) ON DELETE CASCADE,
+    attribute_id INTEGER NOT NULL REFERENCES category_attribute(id) ON DELETE CASCADE,
+    value VARCHAR(255) NOT NULL,
+    PRIMARY KEY (announcement_id, attribute_id)
+);
+
+CREATE TABLE announcement_image (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    announcement_id UUID NOT NULL REFERENCES announcement(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    url TEXT NOT NULL,
+    thumbnails JSONB NOT NULL DEFAULT '{}',
+    storage_keys TEXT[] NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+-- История цены объявления: строка на каждое изменение цены или скидки
+-- processed = TRUE, когда подписчики уже уведомлены о снижении цены
+CREATE TABLE price_history (
+    id BIGSERIAL PRIMARY KEY,
+    announcement_id UUID NOT NULL REFERENCES announcement(id) ON DELETE CASCADE,
+    price DECIMAL NOT NULL,
+    discount SMALLINT NOT NULL,
+    effective_price DECIMAL NOT NULL,
+    changed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    processed BOOLEAN DEFAULT FALSE NOT NULL
+);
+
+-- price_history_id - изменение цены, о котором уведомление о снижении цены.
+-- Одно изменение уведомляет пользователя не больше одного раза, даже если рассылку повторили
+CREATE TABLE notification (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(30) NOT NULL,
+    announcement_id UUID REFERENCES announcement(id) ON DELETE CASCADE,
+    price_history_id BIGINT REFERENCES price_history(id) ON DELETE CASCADE,
+    message TEXT NOT NULL,
+    is_read BOOLEAN DEFAULT FALSE NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    UNIQUE (user_id, price_history_id)
+);
+
+CREATE TABLE favorite (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    announcement_id UUID NOT NULL REFERENCES announcement(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, announcement_id)
+);
+
+-- Подписка покупателя на продавца, новые объявления продавца попадают в ленту /api/feed
+CREATE TABLE follow (
+    follower_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    seller_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    PRIMARY KEY (follower_id, seller_id),
+    CHECK (follower_id <> seller_id)
+);
+
+-- Подписка на снижение цены: уведомить, когда цена со скидкой опустится ниже threshold
+CREATE TABLE price_watch (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    announcement_id UUID NOT NULL REFERENCES announcement(id) ON DELETE CASCADE,
+    threshold DECIMAL NOT NULL CHECK (threshold > 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, announcement_id)
 );
 
 CREATE TABLE announcement_feedback (
@@ -50,15 +151,41 @@ CREATE TABLE announcement_feedback (
 CREATE TABLE shopping_cart (
     user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
     announcement_id UUID NOT NULL REFERENCES announcement(id) ON DELETE CASCADE,
+    quantity INTEGER DEFAULT 1 NOT NULL CHECK (quantity > 0),
+    PRIMARY KEY (user_id, announcement_id)
+);
+
+-- Временный резерв товара на время оформления покупки
+-- Просроченные резервы не учитываются и удаляются при следующем резервировании
+CREATE TABLE stock_reservation (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    announcement_id UUID NOT NULL REFERENCES announcement(id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    expires_at TIMESTAMP NOT NULL,
     PRIMARY KEY (user_id, announcement_id)
 );
 
 
 CREATE INDEX idx_user_feedback_recipient ON user_feedback(user_recipient_id);
 CREATE INDEX idx_announcement_seller ON announcement(user_seller_id);
+CREATE INDEX idx_announcement_category ON announcement(category);
+CREATE INDEX idx_announcement_scheduled ON announcement(publish_at) WHERE published = FALSE;
+CREATE INDEX idx_announcement_expires ON announcement(expires_at) WHERE is_active = TRUE;
+CREATE INDEX idx_notification_user ON notification(user_id, created_at);
+CREATE INDEX idx_category_parent ON category(parent_id);
+CREATE INDEX idx_category_attribute_category ON category_attribute(category_id);
+CREATE INDEX idx_announcement_image_announcement ON announcement_image(announcement_id, position);
 CREATE INDEX idx_announcement_feedback_recipient ON announcement_feedback(announcement_recipient_id);
 CREATE INDEX idx_cart_user_id ON shopping_cart(user_id);
 CREATE INDEX idx_cart_announcement_id ON shopping_cart(announcement_id);
+CREATE INDEX idx_stock_reservation_announcement ON stock_reservation(announcement_id, expires_at);
+CREATE INDEX idx_price_history_announcement ON price_history(announcement_id, id);
+CREATE INDEX idx_price_history_unprocessed ON price_history(id) WHERE processed = FALSE;
+CREATE INDEX idx_price_watch_announcement ON price_watch(announcement_id);
+CREATE INDEX idx_favorite_user ON favorite(user_id, created_at);
+CREATE INDEX idx_favorite_announcement ON favorite(announcement_id);
+CREATE INDEX idx_follow_seller ON follow(seller_id);
+CREATE INDEX idx_announcement_seller_updated ON announcement(user_seller_id, updated_at DESC, id DESC) WHERE is_active = TRUE;
 
 -- Функция для обновления рейтинга и количества отзывов
 CREATE OR REPLACE FUNCTION update_announcement_rating()
@@ -233,7 +360,40 @@ VALUES
     ('Ольга',   'Семенова', '1991-09-02'::timestamptz, FALSE, CURRENT_DATE, 'semenova@example.com', '+79991110006', 'hash6'),
     ('Павел',   'Морозов',  '1987-12-19'::timestamptz, TRUE,  CURRENT_DATE, 'morozov@example.com',  '+79991110007', 'hash7');
 
--- ============ 2. Вставляем 30 объявлений ============
+-- ============ 2. Вставляем дерево категорий ============
+
+-- id 1-8 совпадают с категориями, которые раньше были просто числами
+INSERT INTO category (id, parent_id, slug, name_ru, name_en)
+VALUES
+    (9,  NULL, 'electronics', 'Электроника',        'Electronics'),
+    (10, NULL, 'home',        'Дом',                'Home'),
+    (6,  NULL, 'transport',   'Транспорт',          'Transport'),
+    (1,  9,    'gadgets',     'Смартфоны и гаджеты','Phones and gadgets'),
+    (2,  9,    'computers',   'Компьютеры',         'Computers'),
+    (3,  9,    'tv-photo',    'ТВ, фото и видео',   'TV, photo and video'),
+    (5,  9,    'audio',       'Аудиотехника',       'Audio'),
+    (7,  9,    'gaming',      'Игры и консоли',     'Gaming'),
+    (4,  10,   'appliances',  'Бытовая техника',    'Appliances'),
+    (8,  10,   'furniture',   'Мебель',             'Furniture');
+
+SELECT setval('category_id_seq', (SELECT MAX(id) FROM category));
+
+INSERT INTO category_attribute (category_id, code, name_ru, name_en, type, allowed_values)
+VALUES
+    (9,  'brand',     'Бренд',          'Brand',        'string', NULL),
+    (9,  'color',     'Цвет',           'Color',        'string', NULL),
+    (9,  'condition', 'Состояние',      'Condition',    'enum',   ARRAY['new', 'used']),
+    (10, 'brand',     'Бренд',          'Brand',        'string', NULL),
+    (10, 'condition', 'Состояние',      'Condition',    'enum',   ARRAY['new', 'used']),
+    (6,  'brand',     'Бренд',          'Brand',        'string', NULL),
+    (6,  'condition', 'Состояние',      'Condition',    'enum',   ARRAY['new', 'used']),
+    (1,  'memory_gb', 'Память, ГБ',     'Storage, GB',  'number', NULL),
+    (2,  'memory_gb', 'Память, ГБ',     'Storage, GB',  'number', NULL),
+    (2,  'ram_gb',    'ОЗУ, ГБ',        'RAM, GB',      'number', NULL),
+    (3,  'diagonal',  'Диагональ, "',   'Diagonal, "',  'number', NULL),
+    (4,  'no_frost',  'No Frost',       'No Frost',     'bool',   NULL);
+
+-- ============ 3. Вставляем 30 объявлений ============
 
 INSERT INTO announcement (name, description, user_seller_id, price, category, discount)
 VALUES
@@ -273,7 +433,31 @@ VALUES
     ('Графический планшет Wacom Intuos',  'Pen, черный',                         (SELECT id FROM users WHERE email = 'semenova@example.com'),  12000,  2, 5),
     ('Ноутбук HP Pavilion 15',           '15.6", Ryzen 5, 8 ГБ ОЗУ, серебристый', (SELECT id FROM users WHERE email = 'morozov@example.com'),  65000,  2, 0);
 
--- ============ 3. Вставляем отзывы (announcement_feedback) ============
+-- Начальная точка истории цены для каждого объявления
+INSERT INTO price_history (announcement_id, price, discount, effective_price, processed)
+SELECT id, price, discount, CEIL(price * (100 - discount) / 100.0), TRUE
+FROM announcement;
+
+-- Атрибуты нескольких объявлений, которые раньше были только в описании
+INSERT INTO announcement_attribute (announcement_id, attribute_id, value)
+SELECT a.id, ca.id, v.value
+FROM (VALUES
+    ('Телефон Samsung Galaxy S21',       9, 'brand',     'Samsung'),
+    ('Телефон Samsung Galaxy S21',       9, 'color',     'черный'),
+    ('Телефон Samsung Galaxy S21',       9, 'condition', 'new'),
+    ('Телефон Samsung Galaxy S21',       1, 'memory_gb', '128'),
+    ('Ноутбук Acer Aspire 5',            9, 'brand',     'Acer'),
+    ('Ноутбук Acer Aspire 5',            2, 'ram_gb',    '8'),
+    ('Смартфон Xiaomi Redmi Note 10',    9, 'brand',     'Xiaomi'),
+    ('Смартфон Xiaomi Redmi Note 10',    9, 'color',     'голубой'),
+    ('Смартфон Xiaomi Redmi Note 10',    1, 'memory_gb', '64'),
+    ('Холодильник Bosch KGN39',          10, 'brand',    'Bosch'),
+    ('Холодильник Bosch KGN39',          4, 'no_frost',  'true')
+) AS v(name, category_id, code, value)
+JOIN announcement a ON a.name = v.name
+JOIN category_attribute ca ON ca.category_id = v.category_id AND ca.code = v.code;
+
+-- ============ 4. Вставляем отзывы (announcement_feedback) ============
 
 -- Для простоты: каждый из 7 пользователей оставляет отзыв на несколько разных объявлений.
 -- Мы гарантируем, что комбинация (announcement_recipient_id, user_writer_id) различна.
@@ -311,7 +495,7 @@ VALUES
     ((SELECT id FROM announcement WHERE name = 'Наушники Bose QuietComfort 35 II'),(SELECT id FROM users WHERE email = 'ivanov@example.com'),'Шумодав отличный, но цена высокая',                   5),
     ((SELECT id FROM announcement WHERE name = 'Монитор ASUS ROG Strix XG279Q'),  (SELECT id FROM users WHERE email = 'petrova@example.com'),'Отзывчивый, но сильно дорогой',                       4);
 
--- ============ 4. Ещё несколько отзывов для большей насыщенности ============
+-- ============ 5. Ещё несколько отзывов для большей насыщенности ============
 
 INSERT INTO announcement_feedback (announcement_recipient_id, user_writer_id, comment, rating)
 VALUES
@@ -2,13 +2,48 @@ package analytics
 
 import (
 	"gafroshka-main/internal/app"
+	"gafroshka-main/internal/kafka"
 	"gopkg.in/yaml.v3"
 	"os"
+	"time"
 )
 
 type Config struct {
-	CfgDB        app.ConfigDB `yaml:"db"`
-	MaxOpenConns int          `yaml:"max_open_conns"`
+	CfgDB          app.ConfigDB      `yaml:"db"`
+	MaxOpenConns   int               `yaml:"max_open_conns"`
+	CfgPreferences ConfigPreferences `yaml:"preferences"`
+	CfgCoPurchase  ConfigCoPurchase  `yaml:"co_purchase"`
+	CfgEvents      ConfigEvents      `yaml:"events"`
+	// CfgConsumer - повторы обработки событий Kafka и топик недоставленных сообщений
+	CfgConsumer kafka.ConsumerConfig `yaml:"consumer"`
+	// GRPCAddr - адрес gRPC API сервиса
+	GRPCAddr string `yaml:"grpc_addr"`
+}
+
+// ConfigPreferences - настройки весов категорий в предпочтениях пользователя
+type ConfigPreferences struct {
+	// HalfLife - за сколько вес, набранный событием, уменьшается вдвое
+	HalfLife time.Duration `yaml:"half_life"`
+	// EventWeights - вклад события каждого типа, не заданные типы берут значение по умолчанию
+	EventWeights map[kafka.EventType]float64 `yaml:"event_weights"`
+}
+
+// ConfigCoPurchase - настройки рекомендаций "покупают вместе"
+type ConfigCoPurchase struct {
+	// HalfLife - за сколько вклад совместной покупки в score уменьшается вдвое
+	HalfLife time.Duration `yaml:"half_life"`
+	// MinSupport - сколько раз пару должны купить вместе, чтобы ее рекомендовать
+	MinSupport int `yaml:"min_support"`
+}
+
+// ConfigEvents - хранение сырых событий для воронок и когорт
+type ConfigEvents struct {
+	// Retention - сколько хранятся сырые события, более старые дневные партиции удаляются
+	Retention time.Duration `yaml:"retention"`
+	// PartitionsAhead - на сколько дней вперед заранее создаются партиции
+	PartitionsAhead int `yaml:"partitions_ahead"`
+	// MaintenanceInterval - как часто создаются новые и удаляются старые партиции
+	MaintenanceInterval time.Duration `yaml:"maintenance_interval"`
 }
 
 func NewConfig(path string) (*Config, error) {
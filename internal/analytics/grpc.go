@@ -0,0 +1,125 @@
+package analytics
+
+import (
+	"context"
+	"gafroshka-main/internal/analytics/analyticspb"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GRPCServer отдает AnalyticsService по gRPC, значения по умолчанию те же, что у HTTP ручек.
+type GRPCServer struct {
+	analyticspb.UnimplementedAnalyticsServiceServer
+	service AnalyticsService
+	logger  *zap.SugaredLogger
+}
+
+func NewGRPCServer(service AnalyticsService, logger *zap.SugaredLogger) *GRPCServer {
+	return &GRPCServer{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// limitOrDefault - limit из запроса, если он положительный
+func limitOrDefault(limit int32, def int) int {
+	if limit > 0 {
+		return int(limit)
+	}
+	return def
+}
+
+func (s *GRPCServer) GetTopCategories(
+	ctx context.Context,
+	req *analyticspb.GetTopCategoriesRequest,
+) (*analyticspb.GetTopCategoriesResponse, error) {
+	if req.GetUserId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	categories, err := s.service.GetTopCategories(ctx, req.GetUserId(), limitOrDefault(req.GetLimit(), defaultTopCategories))
+	if err != nil {
+		s.logger.Errorf("Failed to get user preferences: %v", err)
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	resp := &analyticspb.GetTopCategoriesResponse{Categories: make([]int32, 0, len(categories))}
+	for _, c := range categories {
+		resp.Categories = append(resp.Categories, int32(c))
+	}
+	return resp, nil
+}
+
+func (s *GRPCServer) GetScores(ctx context.Context, req *analyticspb.GetScoresRequest) (*analyticspb.GetScoresResponse, error) {
+	if req.GetUserId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	weights, err := s.service.GetCategoryWeights(ctx, req.GetUserId(), limitOrDefault(req.GetLimit(), defaultListLimit))
+	if err != nil {
+		s.logger.Errorf("Failed to get category weights: %v", err)
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	resp := &analyticspb.GetScoresResponse{Scores: make([]*analyticspb.CategoryScore, 0, len(weights))}
+	for _, w := range weights {
+		resp.Scores = append(resp.Scores, &analyticspb.CategoryScore{Category: int32(w.Category), Weight: w.Weight})
+	}
+	return resp, nil
+}
+
+func (s *GRPCServer) GetAlsoBought(
+	ctx context.Context,
+	req *analyticspb.GetAlsoBoughtRequest,
+) (*analyticspb.GetAlsoBoughtResponse, error) {
+	if req.GetAnnouncementId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "announcement_id is required")
+	}
+
+	items, err := s.service.GetAlsoBought(ctx, req.GetAnnouncementId(), limitOrDefault(req.GetLimit(), defaultListLimit))
+	if err != nil {
+		s.logger.Errorf("Failed to get also bought: %v", err)
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	resp := &analyticspb.GetAlsoBoughtResponse{Items: make([]*analyticspb.AlsoBought, 0, len(items))}
+	for _, item := range items {
+		resp.Items = append(resp.Items, &analyticspb.AlsoBought{
+			AnnouncementId: item.AnnouncementID,
+			Score:          item.Score,
+			Support:        int32(item.Support),
+		})
+	}
+	return resp, nil
+}
+
+func (s *GRPCServer) GetTrends(ctx context.Context, req *analyticspb.GetTrendsRequest) (*analyticspb.GetTrendsResponse, error) {
+	window := defaultTrendWindow
+	if req.GetWindow() != nil {
+		if err := req.GetWindow().CheckValid(); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "window must be valid duration")
+		}
+		window = req.GetWindow().AsDuration()
+		if window < minTrendWindow || window > maxTrendWindow {
+			return nil, status.Error(codes.InvalidArgument, "window must be duration from 1h to 2160h")
+		}
+	}
+
+	trends, err := s.service.GetTrendingCategories(ctx, window, limitOrDefault(req.GetLimit(), defaultListLimit))
+	if err != nil {
+		s.logger.Errorf("Failed to get trending categories: %v", err)
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	resp := &analyticspb.GetTrendsResponse{Categories: make([]*analyticspb.TrendingCategory, 0, len(trends))}
+	for _, t := range trends {
+		resp.Categories = append(resp.Categories, &analyticspb.TrendingCategory{
+			Category:      int32(t.Category),
+			Score:         t.Score,
+			PreviousScore: t.PreviousScore,
+			Events:        t.Events,
+		})
+	}
+	return resp, nil
+}
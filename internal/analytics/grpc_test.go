@@ -0,0 +1,109 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gafroshka-main/internal/analytics/analyticspb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestGRPCServer_GetTopCategories(t *testing.T) {
+	svc := &fakeService{returnCategories: []int{5, 2}}
+	srv := NewGRPCServer(svc, zapTestLogger(t))
+
+	resp, err := srv.GetTopCategories(context.Background(), &analyticspb.GetTopCategoriesRequest{UserId: "u-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if svc.lastUserID != "u-1" || svc.lastLimit != defaultTopCategories {
+		t.Errorf("expected u-1 with default limit, got %q and %d", svc.lastUserID, svc.lastLimit)
+	}
+	if len(resp.GetCategories()) != 2 || resp.GetCategories()[0] != 5 {
+		t.Errorf("unexpected categories: %v", resp.GetCategories())
+	}
+
+	_, err = srv.GetTopCategories(context.Background(), &analyticspb.GetTopCategoriesRequest{})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument without user_id, got %v", err)
+	}
+}
+
+func TestGRPCServer_GetScores(t *testing.T) {
+	svc := &fakeService{returnWeights: []CategoryWeight{{Category: 3, Weight: 2.5}}}
+	srv := NewGRPCServer(svc, zapTestLogger(t))
+
+	resp, err := srv.GetScores(context.Background(), &analyticspb.GetScoresRequest{UserId: "u-1", Limit: 4})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if svc.lastLimit != 4 {
+		t.Errorf("expected limit 4, got %d", svc.lastLimit)
+	}
+	if len(resp.GetScores()) != 1 || resp.GetScores()[0].GetCategory() != 3 || resp.GetScores()[0].GetWeight() != 2.5 {
+		t.Errorf("unexpected scores: %v", resp.GetScores())
+	}
+}
+
+func TestGRPCServer_GetScores_ServiceError(t *testing.T) {
+	svc := &fakeService{returnErr: errors.New("db down")}
+	srv := NewGRPCServer(svc, zapTestLogger(t))
+
+	_, err := srv.GetScores(context.Background(), &analyticspb.GetScoresRequest{UserId: "u-1"})
+	if status.Code(err) != codes.Internal {
+		t.Errorf("expected Internal, got %v", err)
+	}
+}
+
+func TestGRPCServer_GetAlsoBought(t *testing.T) {
+	svc := &fakeService{returnAlsoBought: []AlsoBought{{AnnouncementID: "a2", Score: 1.5, Support: 3}}}
+	srv := NewGRPCServer(svc, zapTestLogger(t))
+
+	resp, err := srv.GetAlsoBought(context.Background(), &analyticspb.GetAlsoBoughtRequest{AnnouncementId: "a1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if svc.lastAnnouncementID != "a1" || svc.lastLimit != defaultListLimit {
+		t.Errorf("expected a1 with default limit, got %q and %d", svc.lastAnnouncementID, svc.lastLimit)
+	}
+	if len(resp.GetItems()) != 1 || resp.GetItems()[0].GetSupport() != 3 {
+		t.Errorf("unexpected items: %v", resp.GetItems())
+	}
+}
+
+func TestGRPCServer_GetTrends(t *testing.T) {
+	svc := &fakeService{returnTrends: []TrendingCategory{{Category: 7, Score: 10, PreviousScore: 4, Events: 6}}}
+	srv := NewGRPCServer(svc, zapTestLogger(t))
+
+	resp, err := srv.GetTrends(context.Background(), &analyticspb.GetTrendsRequest{
+		Window: durationpb.New(6 * time.Hour),
+		Limit:  5,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if svc.lastWindow != 6*time.Hour || svc.lastLimit != 5 {
+		t.Errorf("expected 6h window and limit 5, got %v and %d", svc.lastWindow, svc.lastLimit)
+	}
+	if len(resp.GetCategories()) != 1 || resp.GetCategories()[0].GetPreviousScore() != 4 {
+		t.Errorf("unexpected trends: %v", resp.GetCategories())
+	}
+
+	// без окна - окно по умолчанию, как у HTTP ручки
+	if _, err := srv.GetTrends(context.Background(), &analyticspb.GetTrendsRequest{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if svc.lastWindow != defaultTrendWindow {
+		t.Errorf("expected default window, got %v", svc.lastWindow)
+	}
+
+	_, err = srv.GetTrends(context.Background(), &analyticspb.GetTrendsRequest{Window: durationpb.New(time.Minute)})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for too short window, got %v", err)
+	}
+}
@@ -2,12 +2,43 @@ package analytics
 
 import (
 	"encoding/json"
+	"errors"
+	"gafroshka-main/internal/kafka"
 	"github.com/gorilla/mux"
 	"go.uber.org/zap"
 	"net/http"
 	"strconv"
+	"time"
 )
 
+const (
+	// defaultTopCategories и defaultListLimit - размер ответа, если limit не задан
+	defaultTopCategories = 3
+	defaultListLimit     = 10
+
+	defaultTrendWindow = 24 * time.Hour
+	minTrendWindow     = time.Hour
+	maxTrendWindow     = 90 * 24 * time.Hour
+	defaultStatsWindow = 30 * 24 * time.Hour
+
+	defaultFunnelDays = 7
+	maxFunnelDays     = 92
+	defaultCohortDays = 8 * 7
+	maxCohortDays     = 366
+
+	defaultExperimentDays = 14
+	maxExperimentDays     = 92
+)
+
+// experimentGoals - события, которые можно выбрать целью эксперимента
+var experimentGoals = map[kafka.EventType]struct{}{
+	kafka.EventTypeView:      {},
+	kafka.EventTypeAddToCart: {},
+	kafka.EventTypePurchase:  {},
+	kafka.EventTypeFavorite:  {},
+	kafka.EventTypeSearch:    {},
+}
+
 // Handler работает с интерфейсом AnalyticsService.
 type Handler struct {
 	service AnalyticsService
@@ -29,13 +60,19 @@ func (h *Handler) GetUserPreferences(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	topN := 3 // По умолчанию
+	topN := defaultTopCategories
 	if topParam := r.URL.Query().Get("top"); topParam != "" {
 		if n, err := strconv.Atoi(topParam); err == nil && n > 0 {
 			topN = n
 		}
 	}
 
+	// scores=true отдает категории вместе с весами, без него - только id, как раньше
+	if r.URL.Query().Get("scores") == "true" {
+		h.writeCategoryWeights(w, r, userID, topN)
+		return
+	}
+
 	categories, err := h.service.GetTopCategories(r.Context(), userID, topN)
 	if err != nil {
 		h.logger.Errorf("Failed to get user preferences: %v", err)
@@ -52,3 +89,330 @@ func (h *Handler) GetUserPreferences(w http.ResponseWriter, r *http.Request) {
 		h.logger.Errorf("Failed to encode response: %v", err)
 	}
 }
+
+// GetCategoryWeights отдает веса категорий для персонального ранжирования выдачи
+func (h *Handler) GetCategoryWeights(w http.ResponseWriter, r *http.Request) {
+	userID := mux.Vars(r)["user_id"]
+	if userID == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	topN := defaultListLimit
+	if topParam := r.URL.Query().Get("top"); topParam != "" {
+		if n, err := strconv.Atoi(topParam); err == nil && n > 0 {
+			topN = n
+		}
+	}
+
+	h.writeCategoryWeights(w, r, userID, topN)
+}
+
+func (h *Handler) writeCategoryWeights(w http.ResponseWriter, r *http.Request, userID string, topN int) {
+	weights, err := h.service.GetCategoryWeights(r.Context(), userID, topN)
+	if err != nil {
+		h.logger.Errorf("Failed to get category weights: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if len(weights) == 0 {
+		weights = []CategoryWeight{} // Пустой массив вместо null
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(weights); err != nil {
+		h.logger.Errorf("Failed to encode response: %v", err)
+	}
+}
+
+func (h *Handler) GetAlsoBought(w http.ResponseWriter, r *http.Request) {
+	announcementID := mux.Vars(r)["id"]
+	if announcementID == "" {
+		http.Error(w, "Announcement ID is required", http.StatusBadRequest)
+		return
+	}
+
+	limit := defaultListLimit
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		if n, err := strconv.Atoi(limitParam); err == nil && n > 0 {
+			limit = n
+		}
+	}
+
+	items, err := h.service.GetAlsoBought(r.Context(), announcementID, limit)
+	if err != nil {
+		h.logger.Errorf("Failed to get also bought: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if len(items) == 0 {
+		items = []AlsoBought{} // Пустой массив вместо null
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(items); err != nil {
+		h.logger.Errorf("Failed to encode response: %v", err)
+	}
+}
+
+// GetCategoryTrends отдает категории с наибольшей активностью за окно window
+func (h *Handler) GetCategoryTrends(w http.ResponseWriter, r *http.Request) {
+	window, err := parseWindow(r, defaultTrendWindow)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	limit := defaultListLimit
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		if n, err := strconv.Atoi(limitParam); err == nil && n > 0 {
+			limit = n
+		}
+	}
+
+	trends, err := h.service.GetTrendingCategories(r.Context(), window, limit)
+	if err != nil {
+		h.logger.Errorf("Failed to get category trends: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if len(trends) == 0 {
+		trends = []TrendingCategory{} // Пустой массив вместо null
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(trends); err != nil {
+		h.logger.Errorf("Failed to encode response: %v", err)
+	}
+}
+
+// GetCategoryStats отдает число событий категории за окно window по типам
+func (h *Handler) GetCategoryStats(w http.ResponseWriter, r *http.Request) {
+	category, err := strconv.Atoi(mux.Vars(r)["category"])
+	if err != nil || category <= 0 {
+		http.Error(w, "Category must be positive number", http.StatusBadRequest)
+		return
+	}
+
+	window, err := parseWindow(r, defaultTrendWindow)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	stats, err := h.service.GetCategoryStats(r.Context(), category, window)
+	if err != nil {
+		h.logger.Errorf("Failed to get category stats: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(stats); err != nil {
+		h.logger.Errorf("Failed to encode response: %v", err)
+	}
+}
+
+// parseWindow читает окно статистики из query (например, window=24h), без параметра - def
+func parseWindow(r *http.Request, def time.Duration) (time.Duration, error) {
+	param := r.URL.Query().Get("window")
+	if param == "" {
+		return def, nil
+	}
+
+	window, err := time.ParseDuration(param)
+	if err != nil || window < minTrendWindow || window > maxTrendWindow {
+		return 0, errors.New("window must be duration from 1h to 2160h")
+	}
+
+	return window, nil
+}
+
+// GetAnnouncementStats отдает воронку объявления: просмотры, корзина, покупки и конверсии
+func (h *Handler) GetAnnouncementStats(w http.ResponseWriter, r *http.Request) {
+	announcementID := mux.Vars(r)["id"]
+	if announcementID == "" {
+		http.Error(w, "Announcement ID is required", http.StatusBadRequest)
+		return
+	}
+
+	window, err := parseWindow(r, defaultStatsWindow)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	stats, err := h.service.GetAnnouncementEngagement(r.Context(), announcementID, window)
+	if err != nil {
+		h.logger.Errorf("Failed to get announcement stats: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(stats); err != nil {
+		h.logger.Errorf("Failed to encode response: %v", err)
+	}
+}
+
+// GetSellerStats отдает суммарную воронку всех объявлений продавца
+func (h *Handler) GetSellerStats(w http.ResponseWriter, r *http.Request) {
+	sellerID := mux.Vars(r)["id"]
+	if sellerID == "" {
+		http.Error(w, "Seller ID is required", http.StatusBadRequest)
+		return
+	}
+
+	window, err := parseWindow(r, defaultStatsWindow)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	stats, err := h.service.GetSellerEngagement(r.Context(), sellerID, window)
+	if err != nil {
+		h.logger.Errorf("Failed to get seller stats: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(stats); err != nil {
+		h.logger.Errorf("Failed to encode response: %v", err)
+	}
+}
+
+// GetFunnel отдает воронку поиск → просмотр → корзина → покупка по дням и категориям
+// за даты from..to включительно (YYYY-MM-DD), category сужает выборку до одной категории
+func (h *Handler) GetFunnel(w http.ResponseWriter, r *http.Request) {
+	from, to, err := parseDateRange(r, defaultFunnelDays, maxFunnelDays)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	category := 0
+	if categoryParam := r.URL.Query().Get("category"); categoryParam != "" {
+		category, err = strconv.Atoi(categoryParam)
+		if err != nil || category <= 0 {
+			http.Error(w, "Category must be positive number", http.StatusBadRequest)
+			return
+		}
+	}
+
+	funnel, err := h.service.GetFunnel(r.Context(), from, to, category)
+	if err != nil {
+		h.logger.Errorf("Failed to get funnel: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if len(funnel) == 0 {
+		funnel = []FunnelRow{} // Пустой массив вместо null
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(funnel); err != nil {
+		h.logger.Errorf("Failed to encode response: %v", err)
+	}
+}
+
+// GetCohorts отдает матрицу удержания для когорт, зарегистрированных с from по to включительно
+func (h *Handler) GetCohorts(w http.ResponseWriter, r *http.Request) {
+	from, to, err := parseDateRange(r, defaultCohortDays, maxCohortDays)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	cohorts, err := h.service.GetCohorts(r.Context(), from, to)
+	if err != nil {
+		h.logger.Errorf("Failed to get cohorts: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if len(cohorts) == 0 {
+		cohorts = []Cohort{} // Пустой массив вместо null
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(cohorts); err != nil {
+		h.logger.Errorf("Failed to encode response: %v", err)
+	}
+}
+
+// parseDateRange читает даты from и to (включительно) и возвращает полуинтервал [from, to+1 день).
+// Без to берется сегодняшний день, без from - defaultDays дней до to
+func parseDateRange(r *http.Request, defaultDays, maxDays int) (time.Time, time.Time, error) {
+	q := r.URL.Query()
+
+	now := time.Now().UTC()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if toParam := q.Get("to"); toParam != "" {
+		t, err := time.Parse(time.DateOnly, toParam)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("to must be date in format YYYY-MM-DD")
+		}
+		to = t
+	}
+	to = to.AddDate(0, 0, 1)
+
+	from := to.AddDate(0, 0, -defaultDays)
+	if fromParam := q.Get("from"); fromParam != "" {
+		t, err := time.Parse(time.DateOnly, fromParam)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("from must be date in format YYYY-MM-DD")
+		}
+		from = t
+	}
+
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, errors.New("from must not be after to")
+	}
+	if to.Sub(from) > time.Duration(maxDays)*24*time.Hour {
+		return time.Time{}, time.Time{}, errors.New("date range is too long, max " + strconv.Itoa(maxDays) + " days")
+	}
+
+	return from, to, nil
+}
+
+// GetExperimentResults отдает конверсию вариантов эксперимента в событие goal (по умолчанию покупка)
+// за даты from..to и значимость отличия каждого варианта от контрольного
+func (h *Handler) GetExperimentResults(w http.ResponseWriter, r *http.Request) {
+	key := mux.Vars(r)["key"]
+	if key == "" {
+		http.Error(w, "Experiment key is required", http.StatusBadRequest)
+		return
+	}
+
+	goal := kafka.EventTypePurchase
+	if goalParam := r.URL.Query().Get("goal"); goalParam != "" {
+		goal = kafka.EventType(goalParam)
+		if _, ok := experimentGoals[goal]; !ok {
+			http.Error(w, "goal must be one of: search, view, add_to_cart, favorite, purchase", http.StatusBadRequest)
+			return
+		}
+	}
+
+	from, to, err := parseDateRange(r, defaultExperimentDays, maxExperimentDays)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	results, err := h.service.GetExperimentResults(r.Context(), key, goal, from, to)
+	if err != nil {
+		h.logger.Errorf("Failed to get experiment results: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		h.logger.Errorf("Failed to encode response: %v", err)
+	}
+}
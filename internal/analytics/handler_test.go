@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gorilla/mux"
 )
@@ -20,6 +21,23 @@ type fakeService struct {
 
 	returnCategories []int
 	returnErr        error
+
+	lastAnnouncementID string
+	returnAlsoBought   []AlsoBought
+	returnWeights      []CategoryWeight
+
+	lastWindow     time.Duration
+	lastCategory   int
+	returnTrends   []TrendingCategory
+	returnCatStats CategoryStats
+
+	lastEngagementID string
+	returnEngagement EngagementStats
+
+	lastFrom, lastTo time.Time
+	returnFunnel     []FunnelRow
+
+	lastGoal kafka.EventType
 }
 
 func (f *fakeService) ProcessEvent(ctx context.Context, event kafka.Event) error {
@@ -33,6 +51,71 @@ func (f *fakeService) GetTopCategories(ctx context.Context, userID string, limit
 	return f.returnCategories, f.returnErr
 }
 
+func (f *fakeService) GetCategoryWeights(ctx context.Context, userID string, limit int) ([]CategoryWeight, error) {
+	f.lastUserID = userID
+	f.lastLimit = limit
+	return f.returnWeights, f.returnErr
+}
+
+func (f *fakeService) GetTrendingCategories(ctx context.Context, window time.Duration, limit int) ([]TrendingCategory, error) {
+	f.lastWindow = window
+	f.lastLimit = limit
+	return f.returnTrends, f.returnErr
+}
+
+func (f *fakeService) GetCategoryStats(ctx context.Context, category int, window time.Duration) (CategoryStats, error) {
+	f.lastCategory = category
+	f.lastWindow = window
+	return f.returnCatStats, f.returnErr
+}
+
+func (f *fakeService) GetAnnouncementEngagement(
+	ctx context.Context,
+	announcementID string,
+	window time.Duration,
+) (EngagementStats, error) {
+	f.lastEngagementID = announcementID
+	f.lastWindow = window
+	return f.returnEngagement, f.returnErr
+}
+
+func (f *fakeService) GetSellerEngagement(ctx context.Context, sellerID string, window time.Duration) (EngagementStats, error) {
+	f.lastEngagementID = sellerID
+	f.lastWindow = window
+	return f.returnEngagement, f.returnErr
+}
+
+func (f *fakeService) GetFunnel(ctx context.Context, from, to time.Time, category int) ([]FunnelRow, error) {
+	f.lastFrom, f.lastTo = from, to
+	f.lastCategory = category
+	return f.returnFunnel, f.returnErr
+}
+
+func (f *fakeService) GetCohorts(ctx context.Context, from, to time.Time) ([]Cohort, error) {
+	f.lastFrom, f.lastTo = from, to
+	return nil, f.returnErr
+}
+
+func (f *fakeService) MaintainEvents(ctx context.Context) error { return nil }
+func (f *fakeService) RunEventsMaintenance(ctx context.Context) {}
+
+func (f *fakeService) GetExperimentResults(
+	ctx context.Context,
+	key string,
+	goal kafka.EventType,
+	from, to time.Time,
+) (ExperimentResults, error) {
+	f.lastEngagementID = key
+	f.lastGoal = goal
+	return ExperimentResults{Key: key, Goal: goal}, f.returnErr
+}
+
+func (f *fakeService) GetAlsoBought(ctx context.Context, announcementID string, limit int) ([]AlsoBought, error) {
+	f.lastAnnouncementID = announcementID
+	f.lastLimit = limit
+	return f.returnAlsoBought, f.returnErr
+}
+
 func TestHandler_GetUserPreferences_MissingUserID(t *testing.T) {
 	logger := zapTestLogger(t)
 	svc := &fakeService{}
@@ -140,3 +223,293 @@ func TestHandler_GetUserPreferences_ServiceError(t *testing.T) {
 		t.Errorf("expected status 500, got %d", rr.Code)
 	}
 }
+
+func TestHandler_GetAlsoBought(t *testing.T) {
+	logger := zapTestLogger(t)
+	svc := &fakeService{
+		returnAlsoBought: []AlsoBought{{AnnouncementID: "a2", Score: 1.5, Support: 2}},
+	}
+	handler := NewHandler(svc, logger)
+
+	req := httptest.NewRequest("GET", "/item/a1/also-bought?limit=5", nil)
+	rr := httptest.NewRecorder()
+
+	r := mux.NewRouter()
+	r.HandleFunc("/item/{id}/also-bought", handler.GetAlsoBought).Methods("GET")
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	if svc.lastAnnouncementID != "a1" || svc.lastLimit != 5 {
+		t.Errorf("unexpected service args: id=%s limit=%d", svc.lastAnnouncementID, svc.lastLimit)
+	}
+
+	var got []AlsoBought
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(got) != 1 || got[0].AnnouncementID != "a2" || got[0].Support != 2 {
+		t.Errorf("unexpected response: %+v", got)
+	}
+}
+
+func TestHandler_GetCategoryWeights(t *testing.T) {
+	logger := zapTestLogger(t)
+	svc := &fakeService{returnWeights: []CategoryWeight{{Category: 4, Weight: 7}}}
+	handler := NewHandler(svc, logger)
+
+	req := httptest.NewRequest("GET", "/user/u-1/weights", nil)
+	rr := httptest.NewRecorder()
+
+	r := mux.NewRouter()
+	r.HandleFunc("/user/{user_id}/weights", handler.GetCategoryWeights).Methods("GET")
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	if svc.lastUserID != "u-1" || svc.lastLimit != 10 {
+		t.Errorf("unexpected service args: user=%s limit=%d", svc.lastUserID, svc.lastLimit)
+	}
+
+	var got []CategoryWeight
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(got) != 1 || got[0].Category != 4 || got[0].Weight != 7 {
+		t.Errorf("unexpected response: %+v", got)
+	}
+}
+
+func TestHandler_GetUserPreferences_WithScores(t *testing.T) {
+	logger := zapTestLogger(t)
+	svc := &fakeService{returnWeights: []CategoryWeight{{Category: 4, Weight: 2.5}}}
+	handler := NewHandler(svc, logger)
+
+	req := httptest.NewRequest("GET", "/user/u-1/preferences?top=5&scores=true", nil)
+	rr := httptest.NewRecorder()
+
+	r := mux.NewRouter()
+	r.HandleFunc("/user/{user_id}/preferences", handler.GetUserPreferences).Methods("GET")
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	if svc.lastLimit != 5 {
+		t.Errorf("expected limit 5, got %d", svc.lastLimit)
+	}
+
+	var got []CategoryWeight
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(got) != 1 || got[0].Category != 4 || got[0].Weight != 2.5 {
+		t.Errorf("unexpected response: %+v", got)
+	}
+}
+
+func TestHandler_GetCategoryTrends(t *testing.T) {
+	logger := zapTestLogger(t)
+	svc := &fakeService{returnTrends: []TrendingCategory{{Category: 3, Score: 10, PreviousScore: 4, Events: 6}}}
+	handler := NewHandler(svc, logger)
+
+	req := httptest.NewRequest("GET", "/trends/categories?window=6h&limit=3", nil)
+	rr := httptest.NewRecorder()
+
+	r := mux.NewRouter()
+	r.HandleFunc("/trends/categories", handler.GetCategoryTrends).Methods("GET")
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	if svc.lastWindow != 6*time.Hour || svc.lastLimit != 3 {
+		t.Errorf("unexpected service args: window=%v limit=%d", svc.lastWindow, svc.lastLimit)
+	}
+
+	var got []TrendingCategory
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(got) != 1 || got[0].Category != 3 || got[0].Score != 10 {
+		t.Errorf("unexpected response: %+v", got)
+	}
+}
+
+func TestHandler_GetCategoryTrends_BadWindow(t *testing.T) {
+	logger := zapTestLogger(t)
+	handler := NewHandler(&fakeService{}, logger)
+
+	for _, window := range []string{"abc", "10m", "10000h"} {
+		req := httptest.NewRequest("GET", "/trends/categories?window="+window, nil)
+		rr := httptest.NewRecorder()
+
+		r := mux.NewRouter()
+		r.HandleFunc("/trends/categories", handler.GetCategoryTrends).Methods("GET")
+		r.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("window=%s: expected status 400, got %d", window, rr.Code)
+		}
+	}
+}
+
+func TestHandler_GetCategoryStats(t *testing.T) {
+	logger := zapTestLogger(t)
+	svc := &fakeService{returnCatStats: CategoryStats{
+		Category: 7,
+		Window:   "24h0m0s",
+		Total:    3,
+		Events:   map[kafka.EventType]int64{kafka.EventTypeView: 2, kafka.EventTypePurchase: 1},
+	}}
+	handler := NewHandler(svc, logger)
+
+	req := httptest.NewRequest("GET", "/categories/7/stats", nil)
+	rr := httptest.NewRecorder()
+
+	r := mux.NewRouter()
+	r.HandleFunc("/categories/{category}/stats", handler.GetCategoryStats).Methods("GET")
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	if svc.lastCategory != 7 || svc.lastWindow != defaultTrendWindow {
+		t.Errorf("unexpected service args: category=%d window=%v", svc.lastCategory, svc.lastWindow)
+	}
+
+	var got CategoryStats
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if got.Total != 3 || got.Events[kafka.EventTypeView] != 2 {
+		t.Errorf("unexpected response: %+v", got)
+	}
+}
+
+func TestHandler_GetAnnouncementStats(t *testing.T) {
+	logger := zapTestLogger(t)
+	svc := &fakeService{returnEngagement: EngagementStats{Views: 10, CartAdds: 2, ViewToCart: 0.2}}
+	handler := NewHandler(svc, logger)
+
+	req := httptest.NewRequest("GET", "/announcement/a1/stats?window=168h", nil)
+	rr := httptest.NewRecorder()
+
+	r := mux.NewRouter()
+	r.HandleFunc("/announcement/{id}/stats", handler.GetAnnouncementStats).Methods("GET")
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	if svc.lastEngagementID != "a1" || svc.lastWindow != 168*time.Hour {
+		t.Errorf("unexpected service args: id=%s window=%v", svc.lastEngagementID, svc.lastWindow)
+	}
+
+	var got EngagementStats
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if got.Views != 10 || got.ViewToCart != 0.2 {
+		t.Errorf("unexpected response: %+v", got)
+	}
+}
+
+func TestHandler_GetSellerStats_DefaultWindow(t *testing.T) {
+	logger := zapTestLogger(t)
+	svc := &fakeService{}
+	handler := NewHandler(svc, logger)
+
+	req := httptest.NewRequest("GET", "/seller/s1/stats", nil)
+	rr := httptest.NewRecorder()
+
+	r := mux.NewRouter()
+	r.HandleFunc("/seller/{id}/stats", handler.GetSellerStats).Methods("GET")
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	if svc.lastEngagementID != "s1" || svc.lastWindow != defaultStatsWindow {
+		t.Errorf("unexpected service args: id=%s window=%v", svc.lastEngagementID, svc.lastWindow)
+	}
+}
+
+func TestHandler_GetFunnel(t *testing.T) {
+	logger := zapTestLogger(t)
+	svc := &fakeService{returnFunnel: []FunnelRow{{Day: "2025-01-02", Category: 5, Search: 10, View: 6}}}
+	handler := NewHandler(svc, logger)
+
+	req := httptest.NewRequest("GET", "/funnels?from=2025-01-01&to=2025-01-07&category=5", nil)
+	rr := httptest.NewRecorder()
+
+	r := mux.NewRouter()
+	r.HandleFunc("/funnels", handler.GetFunnel).Methods("GET")
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	// to включительно, поэтому сервис получает начало следующего дня
+	wantFrom := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	wantTo := time.Date(2025, 1, 8, 0, 0, 0, 0, time.UTC)
+	if !svc.lastFrom.Equal(wantFrom) || !svc.lastTo.Equal(wantTo) || svc.lastCategory != 5 {
+		t.Errorf("unexpected service args: from=%v to=%v category=%d", svc.lastFrom, svc.lastTo, svc.lastCategory)
+	}
+
+	var got []FunnelRow
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(got) != 1 || got[0].Search != 10 || got[0].View != 6 {
+		t.Errorf("unexpected response: %+v", got)
+	}
+}
+
+func TestHandler_GetCohorts_BadRange(t *testing.T) {
+	logger := zapTestLogger(t)
+	handler := NewHandler(&fakeService{}, logger)
+
+	for _, query := range []string{
+		"from=2025-02-01&to=2025-01-01",
+		"from=01.01.2025",
+		"from=2020-01-01&to=2025-01-01",
+	} {
+		req := httptest.NewRequest("GET", "/cohorts?"+query, nil)
+		rr := httptest.NewRecorder()
+
+		r := mux.NewRouter()
+		r.HandleFunc("/cohorts", handler.GetCohorts).Methods("GET")
+		r.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", query, rr.Code)
+		}
+	}
+}
+
+func TestHandler_GetExperimentResults(t *testing.T) {
+	logger := zapTestLogger(t)
+	svc := &fakeService{}
+	handler := NewHandler(svc, logger)
+
+	r := mux.NewRouter()
+	r.HandleFunc("/experiments/{key}/results", handler.GetExperimentResults).Methods("GET")
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest("GET", "/experiments/layout/results", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	if svc.lastEngagementID != "layout" || svc.lastGoal != kafka.EventTypePurchase {
+		t.Errorf("unexpected service args: key=%s goal=%s", svc.lastEngagementID, svc.lastGoal)
+	}
+
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest("GET", "/experiments/layout/results?goal=signup", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for unknown goal, got %d", rr.Code)
+	}
+}
@@ -3,16 +3,194 @@ package analytics
 import (
 	"context"
 	"gafroshka-main/internal/kafka"
+	"time"
 )
 
+// AlsoBought — объявление, которое покупали вместе с запрошенным.
+type AlsoBought struct {
+	AnnouncementID string  `json:"announcement_id"`
+	Score          float64 `json:"score"`
+	Support        int     `json:"support"`
+}
+
+// CategoryWeight — вес категории в предпочтениях пользователя.
+type CategoryWeight struct {
+	Category int     `json:"category"`
+	Weight   float64 `json:"weight"`
+}
+
+// CategoryEventCount — число событий одного типа в категории за период.
+type CategoryEventCount struct {
+	Category int             `json:"category"`
+	Type     kafka.EventType `json:"type"`
+	Count    int64           `json:"count"`
+}
+
+// TrendingCategory — категория в глобальных трендах.
+// Score - сумма событий за окно с весами типов, PreviousScore - то же за предыдущее окно той же длины
+type TrendingCategory struct {
+	Category      int     `json:"category"`
+	Score         float64 `json:"score"`
+	PreviousScore float64 `json:"previous_score"`
+	Events        int64   `json:"events"`
+}
+
+// CategoryStats — события категории за окно по типам.
+type CategoryStats struct {
+	Category int                       `json:"category"`
+	Window   string                    `json:"window"`
+	Total    int64                     `json:"total"`
+	Events   map[kafka.EventType]int64 `json:"events"`
+}
+
+// EngagementStats — воронка объявления или всех объявлений продавца за окно.
+// Конверсии - доли от предыдущего шага, 0 если предыдущего шага не было
+type EngagementStats struct {
+	Window         string  `json:"window"`
+	Views          int64   `json:"views"`
+	CartAdds       int64   `json:"cart_adds"`
+	CartRemoves    int64   `json:"cart_removes"`
+	Purchases      int64   `json:"purchases"`
+	ViewToCart     float64 `json:"view_to_cart"`
+	CartToPurchase float64 `json:"cart_to_purchase"`
+}
+
+// FunnelRow — воронка поиск → просмотр → корзина → покупка за день в категории.
+// Каждый шаг - число пользователей, сделавших в этот день его и все предыдущие шаги
+type FunnelRow struct {
+	Day       string `json:"day"`
+	Category  int    `json:"category"`
+	Search    int64  `json:"search"`
+	View      int64  `json:"view"`
+	AddToCart int64  `json:"add_to_cart"`
+	Purchase  int64  `json:"purchase"`
+}
+
+// CohortCell — сколько пользователей когорты Week были активны через Offset недель после регистрации.
+type CohortCell struct {
+	Week   time.Time
+	Offset int
+	Users  int64
+}
+
+// Cohort — строка матрицы удержания: пользователи, зарегистрированные за неделю Week.
+// Active[i] - сколько из них было активно на i-й неделе после регистрации, Retention[i] - доля от Size
+type Cohort struct {
+	Week      string    `json:"week"`
+	Size      int64     `json:"size"`
+	Active    []int64   `json:"active"`
+	Retention []float64 `json:"retention"`
+}
+
+// VariantConversion — сколько пользователей варианта эксперимента дошли до целевого события.
+type VariantConversion struct {
+	Variant   string `json:"variant"`
+	Users     int64  `json:"users"`
+	Converted int64  `json:"converted"`
+}
+
+// VariantResult — конверсия варианта и ее сравнение с контрольным вариантом.
+// ZScore и PValue - двусторонний z-тест разности долей, у контрольного варианта нулевые
+type VariantResult struct {
+	VariantConversion
+	Rate        float64 `json:"rate"`
+	Lift        float64 `json:"lift"`
+	ZScore      float64 `json:"z_score"`
+	PValue      float64 `json:"p_value"`
+	Significant bool    `json:"significant"`
+}
+
+// ExperimentResults — итоги эксперимента по целевому событию Goal.
+type ExperimentResults struct {
+	Key      string          `json:"key"`
+	Goal     kafka.EventType `json:"goal"`
+	Control  string          `json:"control"`
+	Variants []VariantResult `json:"variants"`
+}
+
 // AnalyticsRepo — интерфейс репозитория для работы с предпочтениями пользователей.
 type AnalyticsRepo interface {
-	UpdatePreferences(ctx context.Context, userID string, weights map[int]int) error
-	GetTopCategories(ctx context.Context, userID string, limit int) ([]int, error)
+	// UpdatePreferences прибавляет веса события, случившегося в момент at, к категориям.
+	// Накопленный вес затухает с периодом полураспада halfLife
+	UpdatePreferences(ctx context.Context, userID string, weights map[int]float64, halfLife time.Duration, at time.Time) error
+	// MergeVisitor складывает веса анонимного посетителя с предпочтениями userID,
+	// удаляет предпочтения посетителя и проставляет userID его анонимным событиям
+	MergeVisitor(ctx context.Context, visitorID, userID string, halfLife time.Duration) error
+	// GetTopCategories возвращает limit категорий с наибольшим затухшим на текущий момент весом
+	GetTopCategories(ctx context.Context, userID string, halfLife time.Duration, limit int) ([]int, error)
+	// GetCategoryWeights возвращает limit самых весомых категорий пользователя вместе с затухшими весами
+	GetCategoryWeights(ctx context.Context, userID string, halfLife time.Duration, limit int) ([]CategoryWeight, error)
+	// UpdateCoPurchases учитывает покупку объявлений ids вместе: каждая пара +1 к support и score
+	UpdateCoPurchases(ctx context.Context, ids []string, halfLife time.Duration) error
+	// GetAlsoBought возвращает пары с support >= minSupport по убыванию затухшего score
+	GetAlsoBought(ctx context.Context, announcementID string, halfLife time.Duration, minSupport, limit int) ([]AlsoBought, error)
+	// IncrementCategoryEvents прибавляет событие к почасовым и дневным счетчикам каждой из категорий
+	IncrementCategoryEvents(ctx context.Context, eventType kafka.EventType, categories []int, at time.Time) error
+	// GetCategoryEventCounts суммирует счетчики за [from, to) по категориям и типам событий,
+	// category = 0 - по всем категориям
+	GetCategoryEventCounts(ctx context.Context, from, to time.Time, category int) ([]CategoryEventCount, error)
+	// IncrementAnnouncementStats прибавляет событие к дневным счетчикам объявлений,
+	// непустой sellerID запоминается как продавец этих объявлений
+	IncrementAnnouncementStats(
+		ctx context.Context,
+		eventType kafka.EventType,
+		sellerID string,
+		announcementIDs []string,
+		at time.Time,
+	) error
+	// GetAnnouncementEngagement суммирует счетчики объявления за [from, to), конверсии не считает
+	GetAnnouncementEngagement(ctx context.Context, announcementID string, from, to time.Time) (EngagementStats, error)
+	// GetSellerEngagement суммирует счетчики всех объявлений продавца за [from, to), конверсии не считает
+	GetSellerEngagement(ctx context.Context, sellerID string, from, to time.Time) (EngagementStats, error)
+	// ProcessEventOnce отмечает событие eventID обработанным и выполняет fn в той же транзакции:
+	// записи fn через переданный ей репозиторий фиксируются вместе с отметкой или откатываются целиком.
+	// Уже обработанное событие пропускается без вызова fn, тогда возвращается false
+	ProcessEventOnce(ctx context.Context, eventID string, fn func(repo AnalyticsRepo) error) (bool, error)
+	// DeleteProcessedEventsBefore забывает события, обработанные раньше before, и возвращает их число
+	DeleteProcessedEventsBefore(ctx context.Context, before time.Time) (int64, error)
+	// SaveEvent сохраняет событие в сырой лог
+	SaveEvent(ctx context.Context, event kafka.Event) error
+	// CreateEventPartitions создает дневные партиции сырого лога для каждого из дней,
+	// перенося в них уже лежащие в events_default события. Ошибка одного дня не останавливает остальные
+	CreateEventPartitions(ctx context.Context, days []time.Time) error
+	// DropEventPartitionsBefore удаляет дневные партиции, целиком лежащие раньше before, и возвращает их имена
+	DropEventPartitionsBefore(ctx context.Context, before time.Time) ([]string, error)
+	// DeleteDefaultEventsBefore удаляет события старше before из партиции по умолчанию и возвращает их число
+	DeleteDefaultEventsBefore(ctx context.Context, before time.Time) (int64, error)
+	// GetFunnel считает воронку по дням и категориям за [from, to), category = 0 - по всем категориям
+	GetFunnel(ctx context.Context, from, to time.Time, category int) ([]FunnelRow, error)
+	// GetCohortActivity считает активность по неделям для когорт, зарегистрированных в [from, to)
+	GetCohortActivity(ctx context.Context, from, to time.Time) ([]CohortCell, error)
+	// GetExperimentConversions считает по вариантам эксперимента key пользователей с событиями за [from, to)
+	// и тех из них, у кого было событие goal
+	GetExperimentConversions(
+		ctx context.Context,
+		key string,
+		goal kafka.EventType,
+		from, to time.Time,
+	) ([]VariantConversion, error)
 }
 
 // AnalyticsService — интерфейс сервиса аналитики.
 type AnalyticsService interface {
 	ProcessEvent(ctx context.Context, event kafka.Event) error
 	GetTopCategories(ctx context.Context, userID string, limit int) ([]int, error)
+	GetCategoryWeights(ctx context.Context, userID string, limit int) ([]CategoryWeight, error)
+	GetAlsoBought(ctx context.Context, announcementID string, limit int) ([]AlsoBought, error)
+	GetTrendingCategories(ctx context.Context, window time.Duration, limit int) ([]TrendingCategory, error)
+	GetCategoryStats(ctx context.Context, category int, window time.Duration) (CategoryStats, error)
+	GetAnnouncementEngagement(ctx context.Context, announcementID string, window time.Duration) (EngagementStats, error)
+	GetSellerEngagement(ctx context.Context, sellerID string, window time.Duration) (EngagementStats, error)
+	GetFunnel(ctx context.Context, from, to time.Time, category int) ([]FunnelRow, error)
+	GetCohorts(ctx context.Context, from, to time.Time) ([]Cohort, error)
+	GetExperimentResults(
+		ctx context.Context,
+		key string,
+		goal kafka.EventType,
+		from, to time.Time,
+	) (ExperimentResults, error)
+	// MaintainEvents один раз создает партиции сырого лога и удаляет события старше срока хранения
+	MaintainEvents(ctx context.Context) error
+	// RunEventsMaintenance повторяет MaintainEvents с интервалом, пока не отменен ctx
+	RunEventsMaintenance(ctx context.Context)
 }
@@ -3,13 +3,95 @@ package analytics
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gafroshka-main/internal/kafka"
+	"gafroshka-main/internal/visitor"
+	"github.com/lib/pq"
 	"go.uber.org/zap"
+	"sort"
+	"strings"
+	"time"
 )
 
+// dailyRollupFrom - с какой длины периода счетчики читаются из дневной таблицы, а не почасовой
+const dailyRollupFrom = 7 * 24 * time.Hour
+
+// announcementStatColumns - счетчик в announcement_stats_daily для каждого учитываемого типа события
+var announcementStatColumns = map[kafka.EventType]string{
+	kafka.EventTypeView:           "views",
+	kafka.EventTypeAddToCart:      "cart_adds",
+	kafka.EventTypeRemoveFromCart: "cart_removes",
+	kafka.EventTypePurchase:       "purchases",
+}
+
+// engagementSums - суммы счетчиков воронки, порядок совпадает со scanEngagement
+const engagementSums = `COALESCE(SUM(d.views), 0), COALESCE(SUM(d.cart_adds), 0),
+        COALESCE(SUM(d.cart_removes), 0), COALESCE(SUM(d.purchases), 0)`
+
+// eventPartitionPrefix - префикс дневных партиций events, за ним дата в формате eventPartitionLayout
+const (
+	eventPartitionPrefix = "events_p"
+	eventPartitionLayout = "20060102"
+)
+
+// startOfDay - начало дня t в UTC, граница дневных бакетов
+func startOfDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
 // Repository реализует интерфейс AnalyticsRepo.
 type Repository struct {
 	db     *sql.DB
 	logger *zap.SugaredLogger
+	// tx - транзакция обработки события, открытая ProcessEventOnce, все записи идут в нее
+	tx *sql.Tx
+}
+
+// dbtx - общие методы *sql.DB и *sql.Tx
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// conn - транзакция обработки события, если она открыта, иначе пул соединений
+func (r *Repository) conn() dbtx {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db
+}
+
+// txn - транзакция метода репозитория. Внутри транзакции обработки события метод пишет в нее,
+// а фиксирует или откатывает ее ProcessEventOnce, поэтому Commit и Rollback ничего не делают
+type txn struct {
+	*sql.Tx
+	nested bool
+}
+
+func (t txn) Commit() error {
+	if t.nested {
+		return nil
+	}
+	return t.Tx.Commit()
+}
+
+func (t txn) Rollback() error {
+	if t.nested {
+		return nil
+	}
+	return t.Tx.Rollback()
+}
+
+func (r *Repository) beginTx(ctx context.Context) (txn, error) {
+	if r.tx != nil {
+		return txn{Tx: r.tx, nested: true}, nil
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	return txn{Tx: tx}, err
 }
 
 func NewRepository(db *sql.DB, logger *zap.SugaredLogger) AnalyticsRepo {
@@ -19,21 +101,46 @@ func NewRepository(db *sql.DB, logger *zap.SugaredLogger) AnalyticsRepo {
 	}
 }
 
-func (r *Repository) UpdatePreferences(ctx context.Context, userID string, weights map[int]int) error {
-	tx, err := r.db.BeginTx(ctx, nil)
+// preferenceUpsert складывает веса категории, заданные на разные моменты updated_at:
+// более старый вес затухает до более нового момента, updated_at становится наибольшим из двух.
+// Поздно обработанное событие не сдвигает updated_at назад и не продлевает жизнь накопленному весу
+const preferenceUpsert = `
+        ON CONFLICT (user_id, category)
+        DO UPDATE SET weight = CASE
+                WHEN EXCLUDED.updated_at >= user_preferences.updated_at
+                THEN user_preferences.weight * POWER(0.5, EXTRACT(EPOCH FROM EXCLUDED.updated_at - user_preferences.updated_at) / %[1]s) + EXCLUDED.weight
+                ELSE user_preferences.weight + EXCLUDED.weight * POWER(0.5, EXTRACT(EPOCH FROM user_preferences.updated_at - EXCLUDED.updated_at) / %[1]s)
+            END,
+            updated_at = GREATEST(user_preferences.updated_at, EXCLUDED.updated_at)
+`
+
+func (r *Repository) UpdatePreferences(
+	ctx context.Context,
+	userID string,
+	weights map[int]float64,
+	halfLife time.Duration,
+	at time.Time,
+) error {
+	tx, err := r.beginTx(ctx)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
-	for category, weight := range weights {
-		_, err := tx.ExecContext(ctx, `
-            INSERT INTO user_preferences (user_id, category, weight)
-            VALUES ($1, $2, $3)
-            ON CONFLICT (user_id, category)
-            DO UPDATE SET weight = user_preferences.weight + EXCLUDED.weight
-        `, userID, category, weight)
+	// категории обновляются в стабильном порядке, чтобы параллельные транзакции
+	// одного пользователя не блокировали строки крест-накрест
+	categories := make([]int, 0, len(weights))
+	for category := range weights {
+		categories = append(categories, category)
+	}
+	sort.Ints(categories)
+
+	query := `
+        INSERT INTO user_preferences (user_id, category, weight, updated_at)
+        VALUES ($1, $2, $3, $4)` + fmt.Sprintf(preferenceUpsert, "$5")
 
+	for _, category := range categories {
+		_, err := tx.ExecContext(ctx, query, userID, category, weights[category], at.UTC(), halfLife.Seconds())
 		if err != nil {
 			return err
 		}
@@ -42,14 +149,53 @@ func (r *Repository) UpdatePreferences(ctx context.Context, userID string, weigh
 	return tx.Commit()
 }
 
-func (r *Repository) GetTopCategories(ctx context.Context, userID string, limit int) ([]int, error) {
+func (r *Repository) MergeVisitor(ctx context.Context, visitorID, userID string, halfLife time.Duration) error {
+	tx, err := r.beginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// веса посетителя переносятся со своим updated_at и складываются с весами пользователя
+	// по моментам их последних событий, а не по времени обработки identify
+	from := visitor.Key(visitorID)
+	_, err = tx.ExecContext(ctx, `
+        INSERT INTO user_preferences (user_id, category, weight, updated_at)
+        SELECT $1, category, weight, updated_at
+        FROM user_preferences
+        WHERE user_id = $2
+        ORDER BY category`+fmt.Sprintf(preferenceUpsert, "$3"), userID, from, halfLife.Seconds())
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM user_preferences WHERE user_id = $1`, from); err != nil {
+		return err
+	}
+
+	// анонимные события посетителя попадают в воронки и когорты пользователя
+	_, err = tx.ExecContext(ctx, `
+        UPDATE events SET user_id = $1
+        WHERE visitor_id = $2 AND user_id = ''
+    `, userID, visitorID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// preferenceWeight - вес категории, затухший с момента последнего обновления
+const preferenceWeight = `weight * POWER(0.5, EXTRACT(EPOCH FROM NOW() - updated_at) / $2)`
+
+func (r *Repository) GetTopCategories(ctx context.Context, userID string, halfLife time.Duration, limit int) ([]int, error) {
 	rows, err := r.db.QueryContext(ctx, `
         SELECT category
         FROM user_preferences
         WHERE user_id = $1
-        ORDER BY weight DESC
-        LIMIT $2
-    `, userID, limit)
+        ORDER BY `+preferenceWeight+` DESC
+        LIMIT $3
+    `, userID, halfLife.Seconds(), limit)
 
 	if err != nil {
 		return nil, err
@@ -67,3 +213,544 @@ func (r *Repository) GetTopCategories(ctx context.Context, userID string, limit
 
 	return categories, nil
 }
+
+func (r *Repository) GetCategoryWeights(ctx context.Context, userID string, halfLife time.Duration, limit int) ([]CategoryWeight, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT category, `+preferenceWeight+` AS decayed
+        FROM user_preferences
+        WHERE user_id = $1
+        ORDER BY decayed DESC
+        LIMIT $3
+    `, userID, halfLife.Seconds(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var weights []CategoryWeight
+	for rows.Next() {
+		var cw CategoryWeight
+		if err := rows.Scan(&cw.Category, &cw.Weight); err != nil {
+			return nil, err
+		}
+		weights = append(weights, cw)
+	}
+
+	return weights, rows.Err()
+}
+
+// UpdateCoPurchases обновляет счетчики каждой пары купленных вместе объявлений в обе стороны.
+// Старый score затухает с периодом полураспада halfLife на момент обновления
+func (r *Repository) UpdateCoPurchases(ctx context.Context, ids []string, halfLife time.Duration) error {
+	// пары обновляются в стабильном порядке, чтобы параллельные покупки не ловили deadlock
+	sorted := append([]string(nil), ids...)
+	sort.Strings(sorted)
+
+	tx, err := r.beginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, a := range sorted {
+		for _, b := range sorted {
+			if a == b {
+				continue
+			}
+			_, err := tx.ExecContext(ctx, `
+            INSERT INTO co_purchase (announcement_id, related_id, support, score, updated_at)
+            VALUES ($1, $2, 1, 1, NOW())
+            ON CONFLICT (announcement_id, related_id)
+            DO UPDATE SET support = co_purchase.support + 1,
+                score = co_purchase.score * POWER(0.5, EXTRACT(EPOCH FROM NOW() - co_purchase.updated_at) / $3) + 1,
+                updated_at = NOW()
+        `, a, b, halfLife.Seconds())
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *Repository) GetAlsoBought(
+	ctx context.Context,
+	announcementID string,
+	halfLife time.Duration,
+	minSupport, limit int,
+) ([]AlsoBought, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT related_id, support, score * POWER(0.5, EXTRACT(EPOCH FROM NOW() - updated_at) / $2) AS decayed
+        FROM co_purchase
+        WHERE announcement_id = $1 AND support >= $3
+        ORDER BY decayed DESC
+        LIMIT $4
+    `, announcementID, halfLife.Seconds(), minSupport, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []AlsoBought
+	for rows.Next() {
+		var ab AlsoBought
+		if err := rows.Scan(&ab.AnnouncementID, &ab.Support, &ab.Score); err != nil {
+			return nil, err
+		}
+		result = append(result, ab)
+	}
+
+	return result, rows.Err()
+}
+
+func (r *Repository) IncrementCategoryEvents(
+	ctx context.Context,
+	eventType kafka.EventType,
+	categories []int,
+	at time.Time,
+) error {
+	sorted := append([]int(nil), categories...)
+	sort.Ints(sorted)
+
+	at = at.UTC()
+	hour := at.Truncate(time.Hour)
+	day := startOfDay(at)
+
+	tx, err := r.beginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, category := range sorted {
+		for _, rollup := range []struct {
+			table  string
+			bucket time.Time
+		}{
+			{table: "category_events_hourly", bucket: hour},
+			{table: "category_events_daily", bucket: day},
+		} {
+			_, err := tx.ExecContext(ctx, fmt.Sprintf(`
+            INSERT INTO %[1]s (bucket, category, event_type, count)
+            VALUES ($1, $2, $3, 1)
+            ON CONFLICT (bucket, category, event_type)
+            DO UPDATE SET count = %[1]s.count + 1
+        `, rollup.table), rollup.bucket, category, string(eventType))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *Repository) GetCategoryEventCounts(
+	ctx context.Context,
+	from, to time.Time,
+	category int,
+) ([]CategoryEventCount, error) {
+	table := "category_events_hourly"
+	from = from.UTC().Truncate(time.Hour)
+	if to.Sub(from) >= dailyRollupFrom {
+		table = "category_events_daily"
+		from = startOfDay(from)
+	}
+
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`
+        SELECT category, event_type, SUM(count)
+        FROM %s
+        WHERE bucket >= $1 AND bucket < $2 AND ($3::int = 0 OR category = $3)
+        GROUP BY category, event_type
+    `, table), from, to.UTC(), category)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var counts []CategoryEventCount
+	for rows.Next() {
+		var c CategoryEventCount
+		if err := rows.Scan(&c.Category, &c.Type, &c.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, c)
+	}
+
+	return counts, rows.Err()
+}
+
+func (r *Repository) IncrementAnnouncementStats(
+	ctx context.Context,
+	eventType kafka.EventType,
+	sellerID string,
+	announcementIDs []string,
+	at time.Time,
+) error {
+	column, ok := announcementStatColumns[eventType]
+	if !ok {
+		return fmt.Errorf("event type %q has no announcement counter", eventType)
+	}
+
+	sorted := append([]string(nil), announcementIDs...)
+	sort.Strings(sorted)
+	day := startOfDay(at)
+
+	tx, err := r.beginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, id := range sorted {
+		if sellerID != "" {
+			_, err := tx.ExecContext(ctx, `
+            INSERT INTO announcement_seller (announcement_id, seller_id)
+            VALUES ($1, $2)
+            ON CONFLICT (announcement_id) DO UPDATE SET seller_id = EXCLUDED.seller_id
+        `, id, sellerID)
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err := tx.ExecContext(ctx, fmt.Sprintf(`
+            INSERT INTO announcement_stats_daily (bucket, announcement_id, %[1]s)
+            VALUES ($1, $2, 1)
+            ON CONFLICT (announcement_id, bucket)
+            DO UPDATE SET %[1]s = announcement_stats_daily.%[1]s + 1
+        `, column), day, id)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *Repository) GetAnnouncementEngagement(
+	ctx context.Context,
+	announcementID string,
+	from, to time.Time,
+) (EngagementStats, error) {
+	row := r.db.QueryRowContext(ctx, `
+        SELECT `+engagementSums+`
+        FROM announcement_stats_daily d
+        WHERE d.announcement_id = $1 AND d.bucket >= $2 AND d.bucket < $3
+    `, announcementID, startOfDay(from), to.UTC())
+
+	return scanEngagement(row)
+}
+
+func (r *Repository) GetSellerEngagement(ctx context.Context, sellerID string, from, to time.Time) (EngagementStats, error) {
+	row := r.db.QueryRowContext(ctx, `
+        SELECT `+engagementSums+`
+        FROM announcement_stats_daily d
+        JOIN announcement_seller s ON s.announcement_id = d.announcement_id
+        WHERE s.seller_id = $1 AND d.bucket >= $2 AND d.bucket < $3
+    `, sellerID, startOfDay(from), to.UTC())
+
+	return scanEngagement(row)
+}
+
+func scanEngagement(row *sql.Row) (EngagementStats, error) {
+	var stats EngagementStats
+	err := row.Scan(&stats.Views, &stats.CartAdds, &stats.CartRemoves, &stats.Purchases)
+	return stats, err
+}
+
+func (r *Repository) SaveEvent(ctx context.Context, event kafka.Event) error {
+	categories := event.Categories
+	if categories == nil {
+		categories = []int{}
+	}
+	ids := event.AnnouncementIDs
+	if ids == nil {
+		ids = []string{}
+	}
+
+	experiments := event.Experiments
+	if experiments == nil {
+		experiments = map[string]string{}
+	}
+	experimentsJSON, err := json.Marshal(experiments)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.conn().ExecContext(ctx, `
+        INSERT INTO events (user_id, visitor_id, event_type, announcement_id, seller_id, categories, announcement_ids, experiments, occurred_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+    `, event.UserID, event.VisitorID, string(event.Type), event.AnnouncementID, event.SellerID,
+		pq.Array(categories), pq.Array(ids), experimentsJSON, event.Timestamp.UTC())
+	return err
+}
+
+func (r *Repository) ProcessEventOnce(
+	ctx context.Context,
+	eventID string,
+	fn func(repo AnalyticsRepo) error,
+) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	// параллельная обработка того же события ждет здесь фиксации первой и ничего не вставляет
+	res, err := tx.ExecContext(ctx, `
+        INSERT INTO processed_events (event_id) VALUES ($1)
+        ON CONFLICT (event_id) DO NOTHING
+    `, eventID)
+	if err != nil {
+		return false, err
+	}
+	inserted, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if inserted == 0 {
+		return false, nil
+	}
+
+	if err := fn(&Repository{db: r.db, logger: r.logger, tx: tx}); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+func (r *Repository) DeleteProcessedEventsBefore(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM processed_events WHERE processed_at < $1`, before.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (r *Repository) CreateEventPartitions(ctx context.Context, days []time.Time) error {
+	// ошибка одного дня не мешает создать остальные
+	var errs []error
+	for _, day := range days {
+		if err := r.createEventPartition(ctx, startOfDay(day)); err != nil {
+			errs = append(errs, fmt.Errorf("create partition for %s: %w", day.Format(time.DateOnly), err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// createEventPartition создает партицию дня from. События этого дня, успевшие попасть в events_default,
+// переносятся в новую партицию: иначе Postgres не даст подключить партицию с пересекающимися строками в default
+func (r *Repository) createEventPartition(ctx context.Context, from time.Time) error {
+	// имя и границы собираются из даты, а не из пользовательского ввода
+	name := eventPartitionPrefix + from.Format(eventPartitionLayout)
+	lower, upper := from.Format(time.DateOnly), from.AddDate(0, 0, 1).Format(time.DateOnly)
+
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, `SELECT to_regclass($1) IS NOT NULL`, name).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// новые события дня не должны попасть в default между переносом и подключением партиции
+	statements := []string{
+		`LOCK TABLE events IN SHARE ROW EXCLUSIVE MODE`,
+		fmt.Sprintf(`CREATE TABLE %s (LIKE events INCLUDING DEFAULTS)`, name),
+		fmt.Sprintf(
+			`INSERT INTO %s SELECT * FROM events_default WHERE occurred_at >= '%s' AND occurred_at < '%s'`,
+			name, lower, upper,
+		),
+		fmt.Sprintf(`DELETE FROM events_default WHERE occurred_at >= '%s' AND occurred_at < '%s'`, lower, upper),
+		fmt.Sprintf(`ALTER TABLE events ATTACH PARTITION %s FOR VALUES FROM ('%s') TO ('%s')`, name, lower, upper),
+	}
+	for _, statement := range statements {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// DeleteDefaultEventsBefore удаляет из events_default события старше before.
+// Туда попадают события дней без партиции, и удаление партиций по сроку хранения их не касается
+func (r *Repository) DeleteDefaultEventsBefore(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM events_default WHERE occurred_at < $1`, before.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (r *Repository) DropEventPartitionsBefore(ctx context.Context, before time.Time) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT c.relname
+        FROM pg_inherits i
+        JOIN pg_class c ON c.oid = i.inhrelid
+        JOIN pg_class p ON p.oid = i.inhparent
+        WHERE p.relname = 'events'
+    `)
+	if err != nil {
+		return nil, err
+	}
+
+	var expired []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		// default и чужие таблицы не трогаем
+		if !strings.HasPrefix(name, eventPartitionPrefix) {
+			continue
+		}
+		day, err := time.Parse(eventPartitionLayout, strings.TrimPrefix(name, eventPartitionPrefix))
+		if err != nil {
+			continue
+		}
+		// партиция дня d хранит события до начала d+1
+		if !day.AddDate(0, 0, 1).After(before.UTC()) {
+			expired = append(expired, name)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Strings(expired)
+	for i, name := range expired {
+		if _, err := r.db.ExecContext(ctx, fmt.Sprintf(`DROP TABLE IF EXISTS %s`, name)); err != nil {
+			return expired[:i], err
+		}
+	}
+
+	return expired, nil
+}
+
+func (r *Repository) GetFunnel(ctx context.Context, from, to time.Time, category int) ([]FunnelRow, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        WITH steps AS (
+            SELECT e.occurred_at::date AS day, c.category, e.user_id,
+                   BOOL_OR(e.event_type = 'search') AS searched,
+                   BOOL_OR(e.event_type = 'view') AS viewed,
+                   BOOL_OR(e.event_type = 'add_to_cart') AS carted,
+                   BOOL_OR(e.event_type = 'purchase') AS purchased
+            FROM events e
+            CROSS JOIN LATERAL unnest(e.categories) AS c(category)
+            WHERE e.occurred_at >= $1 AND e.occurred_at < $2 AND e.user_id <> ''
+              AND ($3::int = 0 OR c.category = $3)
+            GROUP BY 1, 2, 3
+        )
+        SELECT day, category,
+               COUNT(*) FILTER (WHERE searched),
+               COUNT(*) FILTER (WHERE searched AND viewed),
+               COUNT(*) FILTER (WHERE searched AND viewed AND carted),
+               COUNT(*) FILTER (WHERE searched AND viewed AND carted AND purchased)
+        FROM steps
+        GROUP BY day, category
+        ORDER BY day, category
+    `, from.UTC(), to.UTC(), category)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var funnel []FunnelRow
+	for rows.Next() {
+		var (
+			row FunnelRow
+			day time.Time
+		)
+		if err := rows.Scan(&day, &row.Category, &row.Search, &row.View, &row.AddToCart, &row.Purchase); err != nil {
+			return nil, err
+		}
+		row.Day = day.Format(time.DateOnly)
+		funnel = append(funnel, row)
+	}
+
+	return funnel, rows.Err()
+}
+
+// GetCohortActivity относит пользователя к неделе события signup, а если оно уже
+// вышло за срок хранения - к неделе первого сохраненного события
+func (r *Repository) GetCohortActivity(ctx context.Context, from, to time.Time) ([]CohortCell, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        WITH cohorts AS (
+            SELECT user_id,
+                   date_trunc('week', COALESCE(MIN(occurred_at) FILTER (WHERE event_type = 'signup'), MIN(occurred_at))) AS week
+            FROM events
+            WHERE user_id <> ''
+            GROUP BY user_id
+        ), activity AS (
+            SELECT DISTINCT user_id, date_trunc('week', occurred_at) AS week
+            FROM events
+            WHERE user_id <> ''
+        )
+        SELECT c.week, (a.week::date - c.week::date) / 7 AS week_offset, COUNT(*)
+        FROM cohorts c
+        JOIN activity a ON a.user_id = c.user_id AND a.week >= c.week
+        WHERE c.week >= $1 AND c.week < $2
+        GROUP BY 1, 2
+        ORDER BY 1, 2
+    `, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cells []CohortCell
+	for rows.Next() {
+		var cell CohortCell
+		if err := rows.Scan(&cell.Week, &cell.Offset, &cell.Users); err != nil {
+			return nil, err
+		}
+		cells = append(cells, cell)
+	}
+
+	return cells, rows.Err()
+}
+
+func (r *Repository) GetExperimentConversions(
+	ctx context.Context,
+	key string,
+	goal kafka.EventType,
+	from, to time.Time,
+) ([]VariantConversion, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT experiments ->> $1 AS variant,
+               COUNT(DISTINCT user_id),
+               COUNT(DISTINCT user_id) FILTER (WHERE event_type = $2)
+        FROM events
+        WHERE occurred_at >= $3 AND occurred_at < $4 AND user_id <> ''
+          AND experiments ->> $1 IS NOT NULL
+        GROUP BY 1
+        ORDER BY 1
+    `, key, string(goal), from.UTC(), to.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var conversions []VariantConversion
+	for rows.Next() {
+		var c VariantConversion
+		if err := rows.Scan(&c.Variant, &c.Users, &c.Converted); err != nil {
+			return nil, err
+		}
+		conversions = append(conversions, c)
+	}
+
+	return conversions, rows.Err()
+}
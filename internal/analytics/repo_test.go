@@ -2,9 +2,12 @@ package analytics
 
 import (
 	"context"
+	"errors"
+	"gafroshka-main/internal/kafka"
 	"go.uber.org/zap"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 )
@@ -24,24 +27,33 @@ func TestRepository_UpdatePreferences(t *testing.T) {
 
 	ctx := context.Background()
 	userID := "user-123"
-	weights := map[int]int{
+	weights := map[int]float64{
 		10: 1,
 		20: 3,
 	}
+	at := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
 
 	// Ожидаем BEGIN
 	mock.ExpectBegin()
 
 	// Для каждой пары category->weight ожидаем ExecContext с нужным SQL и аргументами
-	for category, weight := range weights {
+	// категории обновляются по возрастанию
+	for _, category := range []int{10, 20} {
+		weight := weights[category]
 		// Паттерн регулярки, чтобы не зависеть от пробелов:
+		// вес события затухает от его момента at: поздние события не продлевают накопленный вес
 		mock.ExpectExec(regexp.QuoteMeta(`
-			INSERT INTO user_preferences (user_id, category, weight)
-			VALUES ($1, $2, $3)
+			INSERT INTO user_preferences (user_id, category, weight, updated_at)
+			VALUES ($1, $2, $3, $4)
 			ON CONFLICT (user_id, category)
-			DO UPDATE SET weight = user_preferences.weight + EXCLUDED.weight
+			DO UPDATE SET weight = CASE
+					WHEN EXCLUDED.updated_at >= user_preferences.updated_at
+					THEN user_preferences.weight * POWER(0.5, EXTRACT(EPOCH FROM EXCLUDED.updated_at - user_preferences.updated_at) / $5) + EXCLUDED.weight
+					ELSE user_preferences.weight + EXCLUDED.weight * POWER(0.5, EXTRACT(EPOCH FROM user_preferences.updated_at - EXCLUDED.updated_at) / $5)
+				END,
+				updated_at = GREATEST(user_preferences.updated_at, EXCLUDED.updated_at)
 		`)).
-			WithArgs(userID, category, weight).
+			WithArgs(userID, category, weight, at, float64(86400)).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 	}
 
@@ -49,7 +61,7 @@ func TestRepository_UpdatePreferences(t *testing.T) {
 	mock.ExpectCommit()
 
 	// Вызываем
-	if err := repo.UpdatePreferences(ctx, userID, weights); err != nil {
+	if err := repo.UpdatePreferences(ctx, userID, weights, 24*time.Hour, at); err != nil {
 		t.Errorf("UpdatePreferences returned unexpected error: %v", err)
 	}
 
@@ -84,14 +96,14 @@ func TestRepository_GetTopCategories(t *testing.T) {
 		SELECT category
 		FROM user_preferences
 		WHERE user_id = $1
-		ORDER BY weight DESC
-		LIMIT $2
+		ORDER BY weight * POWER(0.5, EXTRACT(EPOCH FROM NOW() - updated_at) / $2) DESC
+		LIMIT $3
 	`)).
-		WithArgs(userID, limit).
+		WithArgs(userID, float64(86400), limit).
 		WillReturnRows(rows)
 
 	// Вызываем
-	result, err := repo.GetTopCategories(ctx, userID, limit)
+	result, err := repo.GetTopCategories(ctx, userID, 24*time.Hour, limit)
 	if err != nil {
 		t.Fatalf("GetTopCategories returned error: %v", err)
 	}
@@ -113,6 +125,33 @@ func TestRepository_GetTopCategories(t *testing.T) {
 	}
 }
 
+// Тест UpdateCoPurchases: каждая пара обновляется в обе стороны в стабильном порядке.
+func TestRepository_UpdateCoPurchases(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("unexpected error when opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	repo := NewRepository(db, zapTestLogger(t))
+
+	mock.ExpectBegin()
+	for _, pair := range [][2]string{{"a1", "a2"}, {"a2", "a1"}} {
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO co_purchase`)).
+			WithArgs(pair[0], pair[1], float64(3600)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+	}
+	mock.ExpectCommit()
+
+	if err := repo.UpdateCoPurchases(context.Background(), []string{"a2", "a1"}, time.Hour); err != nil {
+		t.Fatalf("UpdateCoPurchases returned error: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
 // Вспомогательная функция для создания «тихого» логгера.
 // Используем zap.NewNop() или похожую реализацию.
 func zapTestLogger(t *testing.T) *zap.SugaredLogger {
@@ -123,3 +162,465 @@ func zapTestLogger(t *testing.T) *zap.SugaredLogger {
 	}
 	return logger.Sugar()
 }
+
+// Тест IncrementCategoryEvents: событие попадает в почасовой и дневной бакеты каждой категории.
+func TestRepository_IncrementCategoryEvents(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("unexpected error when opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	repo := NewRepository(db, zapTestLogger(t))
+
+	at := time.Date(2025, 3, 4, 15, 42, 0, 0, time.UTC)
+	hour := time.Date(2025, 3, 4, 15, 0, 0, 0, time.UTC)
+	day := time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	for _, category := range []int{2, 5} {
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO category_events_hourly`)).
+			WithArgs(hour, category, "view").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO category_events_daily`)).
+			WithArgs(day, category, "view").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+	}
+	mock.ExpectCommit()
+
+	err = repo.IncrementCategoryEvents(context.Background(), kafka.EventTypeView, []int{5, 2}, at)
+	if err != nil {
+		t.Fatalf("IncrementCategoryEvents returned error: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+// Тест GetCategoryEventCounts: длинные периоды читаются из дневной таблицы.
+func TestRepository_GetCategoryEventCounts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("unexpected error when opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	repo := NewRepository(db, zapTestLogger(t))
+
+	to := time.Date(2025, 3, 10, 12, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		from  time.Time
+		table string
+		start time.Time
+	}{
+		{
+			name:  "hourly",
+			from:  to.Add(-24 * time.Hour),
+			table: "category_events_hourly",
+			start: time.Date(2025, 3, 9, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "daily",
+			from:  to.Add(-7 * 24 * time.Hour),
+			table: "category_events_daily",
+			start: time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		mock.ExpectQuery(regexp.QuoteMeta(`FROM `+tt.table)).
+			WithArgs(tt.start, to, 7).
+			WillReturnRows(sqlmock.NewRows([]string{"category", "event_type", "sum"}).
+				AddRow(7, "view", 12))
+
+		counts, err := repo.GetCategoryEventCounts(context.Background(), tt.from, to, 7)
+		if err != nil {
+			t.Fatalf("%s: GetCategoryEventCounts returned error: %v", tt.name, err)
+		}
+		if len(counts) != 1 || counts[0].Type != kafka.EventTypeView || counts[0].Count != 12 {
+			t.Errorf("%s: unexpected counts %+v", tt.name, counts)
+		}
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+// Тест IncrementAnnouncementStats: продавец запоминается, счетчик выбирается по типу события.
+func TestRepository_IncrementAnnouncementStats(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("unexpected error when opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	repo := NewRepository(db, zapTestLogger(t))
+
+	at := time.Date(2025, 3, 4, 15, 42, 0, 0, time.UTC)
+	day := time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO announcement_seller`)).
+		WithArgs("a1", "s1").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`DO UPDATE SET cart_adds = announcement_stats_daily.cart_adds + 1`)).
+		WithArgs(day, "a1").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err = repo.IncrementAnnouncementStats(context.Background(), kafka.EventTypeAddToCart, "s1", []string{"a1"}, at)
+	if err != nil {
+		t.Fatalf("IncrementAnnouncementStats returned error: %v", err)
+	}
+
+	if err := repo.IncrementAnnouncementStats(context.Background(), kafka.EventTypeSearch, "", []string{"a1"}, at); err == nil {
+		t.Errorf("expected error for event type without counter")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+// Тест GetSellerEngagement: счетчики суммируются по объявлениям продавца.
+func TestRepository_GetSellerEngagement(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("unexpected error when opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	repo := NewRepository(db, zapTestLogger(t))
+
+	to := time.Date(2025, 3, 10, 12, 30, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta(`JOIN announcement_seller s ON s.announcement_id = d.announcement_id`)).
+		WithArgs("s1", time.Date(2025, 3, 9, 0, 0, 0, 0, time.UTC), to).
+		WillReturnRows(sqlmock.NewRows([]string{"views", "cart_adds", "cart_removes", "purchases"}).
+			AddRow(100, 20, 5, 8))
+
+	stats, err := repo.GetSellerEngagement(context.Background(), "s1", to.Add(-24*time.Hour), to)
+	if err != nil {
+		t.Fatalf("GetSellerEngagement returned error: %v", err)
+	}
+	expected := EngagementStats{Views: 100, CartAdds: 20, CartRemoves: 5, Purchases: 8}
+	if stats != expected {
+		t.Errorf("expected %+v, got %+v", expected, stats)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+// Тест DropEventPartitionsBefore: удаляются только дневные партиции, целиком лежащие до границы.
+func TestRepository_DropEventPartitionsBefore(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("unexpected error when opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	repo := NewRepository(db, zapTestLogger(t))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM pg_inherits`)).
+		WillReturnRows(sqlmock.NewRows([]string{"relname"}).
+			AddRow("events_default").
+			AddRow("events_p20250102").
+			AddRow("events_p20250101").
+			AddRow("events_p20250103"))
+	mock.ExpectExec(regexp.QuoteMeta(`DROP TABLE IF EXISTS events_p20250101`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(`DROP TABLE IF EXISTS events_p20250102`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// граница внутри 3 января: партиция этого дня еще нужна
+	dropped, err := repo.DropEventPartitionsBefore(context.Background(), time.Date(2025, 1, 3, 10, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("DropEventPartitionsBefore returned error: %v", err)
+	}
+	if len(dropped) != 2 || dropped[0] != "events_p20250101" || dropped[1] != "events_p20250102" {
+		t.Errorf("unexpected dropped partitions: %v", dropped)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+// Тест CreateEventPartitions: партиция покрывает ровно один день, события этого дня
+// переносятся из events_default до подключения партиции, существующие партиции не трогаются.
+func TestRepository_CreateEventPartitions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("unexpected error when opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	repo := NewRepository(db, zapTestLogger(t))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT to_regclass($1) IS NOT NULL`)).
+		WithArgs("events_p20250131").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`LOCK TABLE events IN SHARE ROW EXCLUSIVE MODE`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(`CREATE TABLE events_p20250131 (LIKE events INCLUDING DEFAULTS)`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(
+		`INSERT INTO events_p20250131 SELECT * FROM events_default WHERE occurred_at >= '2025-01-31' AND occurred_at < '2025-02-01'`,
+	)).WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(regexp.QuoteMeta(
+		`DELETE FROM events_default WHERE occurred_at >= '2025-01-31' AND occurred_at < '2025-02-01'`,
+	)).WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(regexp.QuoteMeta(
+		`ALTER TABLE events ATTACH PARTITION events_p20250131 FOR VALUES FROM ('2025-01-31') TO ('2025-02-01')`,
+	)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT to_regclass($1) IS NOT NULL`)).
+		WithArgs("events_p20250201").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	err = repo.CreateEventPartitions(context.Background(), []time.Time{
+		time.Date(2025, 1, 31, 23, 0, 0, 0, time.UTC),
+		time.Date(2025, 2, 1, 5, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("CreateEventPartitions returned error: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+// Тест CreateEventPartitions: ошибка одного дня не мешает создать следующие.
+func TestRepository_CreateEventPartitions_ContinuesAfterError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("unexpected error when opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	repo := NewRepository(db, zapTestLogger(t))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT to_regclass($1) IS NOT NULL`)).
+		WithArgs("events_p20250131").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`LOCK TABLE events`)).
+		WillReturnError(errors.New("lock timeout"))
+	mock.ExpectRollback()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT to_regclass($1) IS NOT NULL`)).
+		WithArgs("events_p20250201").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	err = repo.CreateEventPartitions(context.Background(), []time.Time{
+		time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC),
+		time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+	})
+	if err == nil {
+		t.Fatalf("expected error, got nil")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+// Тест DeleteDefaultEventsBefore: срок хранения применяется к событиям в events_default.
+func TestRepository_DeleteDefaultEventsBefore(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("unexpected error when opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	repo := NewRepository(db, zapTestLogger(t))
+
+	before := time.Date(2025, 1, 3, 10, 0, 0, 0, time.UTC)
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM events_default WHERE occurred_at < $1`)).
+		WithArgs(before).
+		WillReturnResult(sqlmock.NewResult(0, 7))
+
+	deleted, err := repo.DeleteDefaultEventsBefore(context.Background(), before)
+	if err != nil {
+		t.Fatalf("DeleteDefaultEventsBefore returned error: %v", err)
+	}
+	if deleted != 7 {
+		t.Errorf("expected 7 deleted events, got %d", deleted)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+// Тест SaveEvent: варианты экспериментов сохраняются как JSON.
+func TestRepository_SaveEvent(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("unexpected error when opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	repo := NewRepository(db, zapTestLogger(t))
+
+	at := time.Date(2025, 3, 4, 15, 42, 0, 0, time.UTC)
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO events`)).
+		WithArgs("u-1", "v-1", "view", "a1", "s1", "{3}", "{}", []byte(`{"layout":"compact"}`), at).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err = repo.SaveEvent(context.Background(), kafka.Event{
+		UserID:         "u-1",
+		VisitorID:      "v-1",
+		Type:           kafka.EventTypeView,
+		Categories:     []int{3},
+		AnnouncementID: "a1",
+		SellerID:       "s1",
+		Experiments:    map[string]string{"layout": "compact"},
+		Timestamp:      at,
+	})
+	if err != nil {
+		t.Fatalf("SaveEvent returned error: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+// Тест MergeVisitor: веса посетителя переносятся в аккаунт, его строки удаляются,
+// анонимные события получают пользователя - все в одной транзакции.
+func TestRepository_MergeVisitor(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("unexpected error when opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	repo := NewRepository(db, zapTestLogger(t))
+
+	mock.ExpectBegin()
+	// веса посетителя переносятся со своим updated_at, без NOW()
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT $1, category, weight, updated_at`)).
+		WithArgs("u-1", "visitor:v-1", float64(86400)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM user_preferences WHERE user_id = $1`)).
+		WithArgs("visitor:v-1").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE events SET user_id = $1`)).
+		WithArgs("u-1", "v-1").
+		WillReturnResult(sqlmock.NewResult(0, 5))
+	mock.ExpectCommit()
+
+	if err := repo.MergeVisitor(context.Background(), "v-1", "u-1", 24*time.Hour); err != nil {
+		t.Fatalf("MergeVisitor returned error: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+// Тест MergeVisitor: при ошибке транзакция откатывается.
+func TestRepository_MergeVisitor_Error(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("unexpected error when opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	repo := NewRepository(db, zapTestLogger(t))
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO user_preferences`)).
+		WillReturnError(errors.New("db down"))
+	mock.ExpectRollback()
+
+	if err := repo.MergeVisitor(context.Background(), "v-1", "u-1", 24*time.Hour); err == nil {
+		t.Fatalf("expected error, got nil")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+// Тест ProcessEventOnce: записи события идут в одну транзакцию с отметкой id,
+// методы репозитория внутри нее не открывают и не фиксируют свои транзакции.
+func TestRepository_ProcessEventOnce(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("unexpected error when opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	repo := NewRepository(db, zapTestLogger(t))
+	at := time.Date(2025, 3, 4, 15, 42, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO processed_events (event_id) VALUES ($1)`)).
+		WithArgs("e-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO category_events_hourly`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO category_events_daily`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	processed, err := repo.ProcessEventOnce(context.Background(), "e-1", func(tx AnalyticsRepo) error {
+		return tx.IncrementCategoryEvents(context.Background(), kafka.EventTypeView, []int{3}, at)
+	})
+	if err != nil || !processed {
+		t.Fatalf("expected event processed, got %v, %v", processed, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+// Тест ProcessEventOnce: уже обработанное событие пропускается, ошибка откатывает все записи.
+func TestRepository_ProcessEventOnce_SkipsAndRollsBack(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("unexpected error when opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+
+	repo := NewRepository(db, zapTestLogger(t))
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO processed_events`)).
+		WithArgs("e-1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	processed, err := repo.ProcessEventOnce(context.Background(), "e-1", func(AnalyticsRepo) error {
+		t.Fatalf("fn must not be called for a processed event")
+		return nil
+	})
+	if err != nil || processed {
+		t.Fatalf("expected event skipped, got %v, %v", processed, err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO processed_events`)).
+		WithArgs("e-2").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectRollback()
+
+	processed, err = repo.ProcessEventOnce(context.Background(), "e-2", func(AnalyticsRepo) error {
+		return errors.New("db down")
+	})
+	if err == nil || processed {
+		t.Fatalf("expected error, got %v, %v", processed, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
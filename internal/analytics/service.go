@@ -2,51 +2,543 @@ package analytics
 
 import (
 	"context"
+	"errors"
 	"gafroshka-main/internal/kafka"
+	"gafroshka-main/internal/visitor"
 	"go.uber.org/zap"
+	"math"
+	"sort"
+	"time"
 )
 
+const (
+	defaultPreferencesHalfLife = 90 * 24 * time.Hour
+	defaultCoPurchaseHalfLife  = 30 * 24 * time.Hour
+	defaultEventsRetention     = 90 * 24 * time.Hour
+	defaultPartitionsAhead     = 3
+	defaultMaintenanceInterval = time.Hour
+	// controlVariant - имя контрольного варианта, без него контрольным считается первый по имени
+	controlVariant = "control"
+	// significanceLevel - порог p-value, ниже которого разница с контролем считается значимой
+	significanceLevel = 0.05
+	// maxCoPurchaseItems ограничивает число пар с одной покупки: их количество растет квадратично
+	maxCoPurchaseItems = 20
+)
+
+// defaultEventWeights - вклад события в вес категории, если в конфиге он не задан.
+// Избранное - осознанный интерес к товару, весит как покупка
+var defaultEventWeights = map[kafka.EventType]float64{
+	kafka.EventTypeSearch:   1,
+	kafka.EventTypeView:     2,
+	kafka.EventTypePurchase: 3,
+	kafka.EventTypeFavorite: 3,
+	// добавление в корзину раньше приходило как просмотр и весило так же
+	kafka.EventTypeAddToCart:      2,
+	kafka.EventTypeRemoveFromCart: 0,
+}
+
 // Service реализует интерфейс AnalyticsService.
 type Service struct {
-	repo   AnalyticsRepo
-	logger *zap.SugaredLogger
+	repo        AnalyticsRepo
+	logger      *zap.SugaredLogger
+	preferences ConfigPreferences
+	coPurchase  ConfigCoPurchase
+	events      ConfigEvents
 }
 
-func NewService(repo AnalyticsRepo, logger *zap.SugaredLogger) AnalyticsService {
+func NewService(
+	repo AnalyticsRepo,
+	logger *zap.SugaredLogger,
+	preferences ConfigPreferences,
+	coPurchase ConfigCoPurchase,
+	events ConfigEvents,
+) AnalyticsService {
+	if preferences.HalfLife <= 0 {
+		preferences.HalfLife = defaultPreferencesHalfLife
+	}
+	eventWeights := make(map[kafka.EventType]float64, len(defaultEventWeights))
+	for eventType, weight := range defaultEventWeights {
+		eventWeights[eventType] = weight
+	}
+	for eventType, weight := range preferences.EventWeights {
+		eventWeights[eventType] = weight
+	}
+	preferences.EventWeights = eventWeights
+
+	if coPurchase.HalfLife <= 0 {
+		coPurchase.HalfLife = defaultCoPurchaseHalfLife
+	}
+	if coPurchase.MinSupport <= 0 {
+		coPurchase.MinSupport = 1
+	}
+
+	if events.Retention <= 0 {
+		events.Retention = defaultEventsRetention
+	}
+	if events.PartitionsAhead <= 0 {
+		events.PartitionsAhead = defaultPartitionsAhead
+	}
+	if events.MaintenanceInterval <= 0 {
+		events.MaintenanceInterval = defaultMaintenanceInterval
+	}
+
 	return &Service{
-		repo:   repo,
-		logger: logger,
+		repo:        repo,
+		logger:      logger,
+		preferences: preferences,
+		coPurchase:  coPurchase,
+		events:      events,
 	}
 }
 
+// ProcessEvent учитывает событие ровно один раз: все записи события делаются в одной транзакции
+// с отметкой его id, повторная доставка, повтор после ошибки или возврат из DLQ пропускаются
 func (s *Service) ProcessEvent(ctx context.Context, event kafka.Event) error {
-	if event.UserID == "" {
-		return nil // Игнорируем события без пользователя
+	event.Timestamp = eventTime(event)
+	if event.ID == "" {
+		// без id повтор не распознать, событие учитывается как есть
+		return s.processEvent(ctx, s.repo, event)
+	}
+
+	processed, err := s.repo.ProcessEventOnce(ctx, event.ID, func(repo AnalyticsRepo) error {
+		return s.processEvent(ctx, repo, event)
+	})
+	if err != nil {
+		return err
+	}
+	if !processed {
+		s.logger.Infof("event %s already processed, skipping", event.ID)
+	}
+	return nil
+}
+
+func (s *Service) processEvent(ctx context.Context, repo AnalyticsRepo, event kafka.Event) error {
+	if err := repo.SaveEvent(ctx, event); err != nil {
+		return err
 	}
 
-	weights := make(map[int]int)
+	// глобальные счетчики учитывают и анонимные события
+	if err := countCategoryEvent(ctx, repo, event); err != nil {
+		return err
+	}
+	if err := countAnnouncementEvent(ctx, repo, event); err != nil {
+		return err
+	}
+
+	if event.Type == kafka.EventTypeIdentify {
+		return s.identify(ctx, repo, event)
+	}
+
+	owner := preferenceOwner(event)
+	if owner == "" {
+		return nil // Игнорируем события без пользователя и посетителя
+	}
+
+	weight := s.preferences.EventWeights[event.Type]
+	weights := make(map[int]float64)
 	switch event.Type {
 	case kafka.EventTypeSearch:
+		// поиск учитывает все категории выдачи
 		for _, cat := range event.Categories {
-			weights[cat] += 1
+			weights[cat] += weight
 		}
-	case kafka.EventTypeView:
+	case kafka.EventTypeView, kafka.EventTypePurchase, kafka.EventTypeFavorite, kafka.EventTypeAddToCart:
 		if len(event.Categories) > 0 {
-			weights[event.Categories[0]] += 2
+			weights[event.Categories[0]] += weight
 		}
-	case kafka.EventTypePurchase:
-		if len(event.Categories) > 0 {
-			weights[event.Categories[0]] += 3
+	}
+
+	if event.Type == kafka.EventTypePurchase {
+		if err := s.updateCoPurchases(ctx, repo, event.AnnouncementIDs); err != nil {
+			return err
 		}
 	}
 
+	for cat, w := range weights {
+		if w <= 0 {
+			delete(weights, cat)
+		}
+	}
 	if len(weights) == 0 {
 		return nil
 	}
 
-	return s.repo.UpdatePreferences(ctx, event.UserID, weights)
+	return repo.UpdatePreferences(ctx, owner, weights, s.preferences.HalfLife, event.Timestamp)
+}
+
+// preferenceOwner - чьи предпочтения обновляет событие: пользователя, а у анонима - посетителя,
+// чтобы после входа их можно было перенести в аккаунт
+func preferenceOwner(event kafka.Event) string {
+	if event.UserID != "" {
+		return event.UserID
+	}
+	if event.VisitorID != "" {
+		return visitor.Key(event.VisitorID)
+	}
+	return ""
+}
+
+// identify переносит историю анонимного посетителя в аккаунт вошедшего пользователя
+func (s *Service) identify(ctx context.Context, repo AnalyticsRepo, event kafka.Event) error {
+	if event.UserID == "" || event.VisitorID == "" {
+		return nil
+	}
+	return repo.MergeVisitor(ctx, event.VisitorID, event.UserID, s.preferences.HalfLife)
+}
+
+// countCategoryEvent учитывает событие в счетчиках каждой из его категорий по одному разу
+func countCategoryEvent(ctx context.Context, repo AnalyticsRepo, event kafka.Event) error {
+	categories := make([]int, 0, len(event.Categories))
+	seen := make(map[int]struct{}, len(event.Categories))
+	for _, cat := range event.Categories {
+		if _, ok := seen[cat]; ok {
+			continue
+		}
+		seen[cat] = struct{}{}
+		categories = append(categories, cat)
+	}
+	if len(categories) == 0 {
+		return nil
+	}
+
+	return repo.IncrementCategoryEvents(ctx, event.Type, categories, eventTime(event))
+}
+
+// countAnnouncementEvent учитывает событие в воронке объявления. Покупка относится
+// ко всем купленным объявлениям, их продавцы известны по более ранним событиям
+func countAnnouncementEvent(ctx context.Context, repo AnalyticsRepo, event kafka.Event) error {
+	var ids []string
+	sellerID := event.SellerID
+	switch event.Type {
+	case kafka.EventTypeView, kafka.EventTypeAddToCart, kafka.EventTypeRemoveFromCart:
+		if event.AnnouncementID == "" {
+			return nil
+		}
+		ids = []string{event.AnnouncementID}
+	case kafka.EventTypePurchase:
+		ids = uniqueIDs(event.AnnouncementIDs)
+		sellerID = ""
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	return repo.IncrementAnnouncementStats(ctx, event.Type, sellerID, ids, eventTime(event))
+}
+
+// eventTime - время события, для событий без метки - время обработки
+func eventTime(event kafka.Event) time.Time {
+	if event.Timestamp.IsZero() {
+		return time.Now()
+	}
+	return event.Timestamp
+}
+
+// uniqueIDs убирает пустые и повторные id, сохраняя порядок
+func uniqueIDs(ids []string) []string {
+	unique := make([]string, 0, len(ids))
+	seen := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok || id == "" {
+			continue
+		}
+		seen[id] = struct{}{}
+		unique = append(unique, id)
+	}
+	return unique
 }
 
 func (s *Service) GetTopCategories(ctx context.Context, userID string, limit int) ([]int, error) {
-	return s.repo.GetTopCategories(ctx, userID, limit)
+	return s.repo.GetTopCategories(ctx, userID, s.preferences.HalfLife, limit)
+}
+
+func (s *Service) GetCategoryWeights(ctx context.Context, userID string, limit int) ([]CategoryWeight, error) {
+	return s.repo.GetCategoryWeights(ctx, userID, s.preferences.HalfLife, limit)
+}
+
+// updateCoPurchases учитывает объявления одной покупки как купленные вместе
+func (s *Service) updateCoPurchases(ctx context.Context, repo AnalyticsRepo, ids []string) error {
+	unique := uniqueIDs(ids)
+	if len(unique) < 2 {
+		return nil
+	}
+	if len(unique) > maxCoPurchaseItems {
+		s.logger.Warnf("purchase of %d items, only first %d counted as co-purchases", len(unique), maxCoPurchaseItems)
+		unique = unique[:maxCoPurchaseItems]
+	}
+
+	return repo.UpdateCoPurchases(ctx, unique, s.coPurchase.HalfLife)
+}
+
+func (s *Service) GetAlsoBought(ctx context.Context, announcementID string, limit int) ([]AlsoBought, error) {
+	return s.repo.GetAlsoBought(ctx, announcementID, s.coPurchase.HalfLife, s.coPurchase.MinSupport, limit)
+}
+
+// GetTrendingCategories считает тренды за последнее окно window. Типы событий весят так же,
+// как в предпочтениях пользователей, для сравнения отдается оценка за предыдущее окно
+func (s *Service) GetTrendingCategories(ctx context.Context, window time.Duration, limit int) ([]TrendingCategory, error) {
+	now := time.Now()
+
+	current, err := s.repo.GetCategoryEventCounts(ctx, now.Add(-window), now, 0)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := s.repo.GetCategoryEventCounts(ctx, now.Add(-2*window), now.Add(-window), 0)
+	if err != nil {
+		return nil, err
+	}
+
+	trends := make(map[int]*TrendingCategory)
+	for _, c := range current {
+		t, ok := trends[c.Category]
+		if !ok {
+			t = &TrendingCategory{Category: c.Category}
+			trends[c.Category] = t
+		}
+		t.Score += float64(c.Count) * s.preferences.EventWeights[c.Type]
+		t.Events += c.Count
+	}
+	for _, c := range previous {
+		if t, ok := trends[c.Category]; ok {
+			t.PreviousScore += float64(c.Count) * s.preferences.EventWeights[c.Type]
+		}
+	}
+
+	result := make([]TrendingCategory, 0, len(trends))
+	for _, t := range trends {
+		result = append(result, *t)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Score != result[j].Score {
+			return result[i].Score > result[j].Score
+		}
+		return result[i].Category < result[j].Category
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+
+	return result, nil
+}
+
+func (s *Service) GetCategoryStats(ctx context.Context, category int, window time.Duration) (CategoryStats, error) {
+	now := time.Now()
+	counts, err := s.repo.GetCategoryEventCounts(ctx, now.Add(-window), now, category)
+	if err != nil {
+		return CategoryStats{}, err
+	}
+
+	stats := CategoryStats{
+		Category: category,
+		Window:   window.String(),
+		Events:   make(map[kafka.EventType]int64),
+	}
+	for _, c := range counts {
+		stats.Events[c.Type] += c.Count
+		stats.Total += c.Count
+	}
+
+	return stats, nil
+}
+
+func (s *Service) GetAnnouncementEngagement(
+	ctx context.Context,
+	announcementID string,
+	window time.Duration,
+) (EngagementStats, error) {
+	now := time.Now()
+	stats, err := s.repo.GetAnnouncementEngagement(ctx, announcementID, now.Add(-window), now)
+	if err != nil {
+		return EngagementStats{}, err
+	}
+	return withConversions(stats, window), nil
+}
+
+func (s *Service) GetSellerEngagement(ctx context.Context, sellerID string, window time.Duration) (EngagementStats, error) {
+	now := time.Now()
+	stats, err := s.repo.GetSellerEngagement(ctx, sellerID, now.Add(-window), now)
+	if err != nil {
+		return EngagementStats{}, err
+	}
+	return withConversions(stats, window), nil
+}
+
+// withConversions дополняет счетчики окном и конверсиями между шагами воронки
+func withConversions(stats EngagementStats, window time.Duration) EngagementStats {
+	stats.Window = window.String()
+	if stats.Views > 0 {
+		stats.ViewToCart = float64(stats.CartAdds) / float64(stats.Views)
+	}
+	if stats.CartAdds > 0 {
+		stats.CartToPurchase = float64(stats.Purchases) / float64(stats.CartAdds)
+	}
+	return stats
+}
+
+func (s *Service) GetFunnel(ctx context.Context, from, to time.Time, category int) ([]FunnelRow, error) {
+	return s.repo.GetFunnel(ctx, from, to, category)
+}
+
+// GetCohorts собирает матрицу удержания: у каждой когорты столько недель,
+// сколько прошло с ее начала до текущей недели включительно
+func (s *Service) GetCohorts(ctx context.Context, from, to time.Time) ([]Cohort, error) {
+	cells, err := s.repo.GetCohortActivity(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var cohorts []Cohort
+	byWeek := make(map[time.Time]int)
+	for _, cell := range cells {
+		i, ok := byWeek[cell.Week]
+		if !ok {
+			weeks := int(now.Sub(cell.Week)/(7*24*time.Hour)) + 1
+			cohorts = append(cohorts, Cohort{
+				Week:   cell.Week.Format(time.DateOnly),
+				Active: make([]int64, max(weeks, 1)),
+			})
+			i = len(cohorts) - 1
+			byWeek[cell.Week] = i
+		}
+
+		c := &cohorts[i]
+		for len(c.Active) <= cell.Offset {
+			c.Active = append(c.Active, 0)
+		}
+		c.Active[cell.Offset] = cell.Users
+	}
+
+	for i := range cohorts {
+		c := &cohorts[i]
+		// неделя регистрации - всегда неделя активности, поэтому она и есть размер когорты
+		c.Size = c.Active[0]
+		c.Retention = make([]float64, len(c.Active))
+		for j, active := range c.Active {
+			if c.Size > 0 {
+				c.Retention[j] = float64(active) / float64(c.Size)
+			}
+		}
+	}
+
+	return cohorts, nil
+}
+
+func (s *Service) MaintainEvents(ctx context.Context) error {
+	return s.maintainEventPartitions(ctx, time.Now())
+}
+
+func (s *Service) RunEventsMaintenance(ctx context.Context) {
+	ticker := time.NewTicker(s.events.MaintenanceInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := s.MaintainEvents(ctx); err != nil {
+			s.logger.Errorf("Failed to maintain event partitions: %v", err)
+		}
+	}
+}
+
+// maintainEventPartitions создает партиции со вчерашнего дня (для запоздавших событий)
+// на PartitionsAhead дней вперед и удаляет вышедшие за срок хранения события.
+// Ошибка создания партиций не мешает применить срок хранения
+func (s *Service) maintainEventPartitions(ctx context.Context, now time.Time) error {
+	days := make([]time.Time, 0, s.events.PartitionsAhead+2)
+	for i := -1; i <= s.events.PartitionsAhead; i++ {
+		days = append(days, now.AddDate(0, 0, i))
+	}
+	createErr := s.repo.CreateEventPartitions(ctx, days)
+
+	before := now.Add(-s.events.Retention)
+	dropped, dropErr := s.repo.DropEventPartitionsBefore(ctx, before)
+	if len(dropped) > 0 {
+		s.logger.Infof("dropped expired event partitions %v", dropped)
+	}
+
+	deleted, deleteErr := s.repo.DeleteDefaultEventsBefore(ctx, before)
+	if deleted > 0 {
+		s.logger.Infof("deleted %d expired events from the default partition", deleted)
+	}
+
+	// повтор события старше срока хранения уже не придет, его id можно забыть
+	forgotten, forgetErr := s.repo.DeleteProcessedEventsBefore(ctx, before)
+	if forgotten > 0 {
+		s.logger.Infof("forgot %d expired processed event ids", forgotten)
+	}
+
+	return errors.Join(createErr, dropErr, deleteErr, forgetErr)
+}
+
+func (s *Service) GetExperimentResults(
+	ctx context.Context,
+	key string,
+	goal kafka.EventType,
+	from, to time.Time,
+) (ExperimentResults, error) {
+	conversions, err := s.repo.GetExperimentConversions(ctx, key, goal, from, to)
+	if err != nil {
+		return ExperimentResults{}, err
+	}
+
+	results := ExperimentResults{Key: key, Goal: goal, Variants: []VariantResult{}}
+	if len(conversions) == 0 {
+		return results, nil
+	}
+
+	// варианты приходят отсортированными по имени
+	control := conversions[0]
+	for _, c := range conversions {
+		if c.Variant == controlVariant {
+			control = c
+			break
+		}
+	}
+	results.Control = control.Variant
+	controlRate := conversionRate(control)
+
+	for _, c := range conversions {
+		result := VariantResult{VariantConversion: c, Rate: conversionRate(c)}
+		if c.Variant != control.Variant {
+			if controlRate > 0 {
+				result.Lift = result.Rate/controlRate - 1
+			}
+			result.ZScore, result.PValue = twoProportionZTest(control.Converted, control.Users, c.Converted, c.Users)
+			result.Significant = result.PValue < significanceLevel
+		}
+		results.Variants = append(results.Variants, result)
+	}
+
+	return results, nil
+}
+
+func conversionRate(c VariantConversion) float64 {
+	if c.Users == 0 {
+		return 0
+	}
+	return float64(c.Converted) / float64(c.Users)
+}
+
+// twoProportionZTest - двусторонний z-тест разности долей x1/n1 и x2/n2 с объединенной дисперсией.
+// Без данных или без разброса возвращает z = 0 и p = 1
+func twoProportionZTest(x1, n1, x2, n2 int64) (float64, float64) {
+	if n1 == 0 || n2 == 0 {
+		return 0, 1
+	}
+
+	p1 := float64(x1) / float64(n1)
+	p2 := float64(x2) / float64(n2)
+	pooled := float64(x1+x2) / float64(n1+n2)
+	se := math.Sqrt(pooled * (1 - pooled) * (1/float64(n1) + 1/float64(n2)))
+	if se == 0 {
+		return 0, 1
+	}
+
+	z := (p2 - p1) / se
+	return z, math.Erfc(math.Abs(z) / math.Sqrt2)
 }
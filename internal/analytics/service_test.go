@@ -3,8 +3,10 @@ package analytics
 import (
 	"context"
 	"errors"
+	"math"
 	"reflect"
 	"testing"
+	"time"
 
 	"gafroshka-main/internal/kafka"
 )
@@ -13,31 +15,230 @@ import (
 type fakeRepo struct {
 	called      bool
 	lastUserID  string
-	lastWeights map[int]int
+	lastWeights map[int]float64
 	// можно добавлять флаги, чтобы «симулировать» ошибку
 	returnErr error
+
+	lastPrefsHalfLife time.Duration
+
+	rollupCalls          int
+	lastRollupType       kafka.EventType
+	lastRollupCategories []int
+	lastRollupAt         time.Time
+	// returnCounts отдается по очереди на каждый вызов GetCategoryEventCounts
+	returnCounts [][]CategoryEventCount
+	countsFrom   []time.Time
+
+	statsCalls int
+	// failStats - сколько следующих вызовов IncrementAnnouncementStats вернут ошибку
+	failStats        int
+	lastStatsType    kafka.EventType
+	lastStatsSeller  string
+	lastStatsIDs     []string
+	returnEngagement EngagementStats
+
+	savedEvents           []kafka.Event
+	createdPartitions     []time.Time
+	createPartitionsErr   error
+	deleteDefaultBefore   time.Time
+	processedIDs          map[string]bool
+	forgetProcessedBefore time.Time
+	dropBefore            time.Time
+	returnCohortCells     []CohortCell
+	returnConversions     []VariantConversion
+
+	lastCoPurchaseIDs []string
+	lastHalfLife      time.Duration
+	lastMinSupport    int
+	lastPrefsAt       time.Time
+
+	mergeCalls         int
+	lastMergedVisitor  string
+	lastMergedUser     string
+	lastMergedHalfLife time.Duration
 }
 
-func (f *fakeRepo) UpdatePreferences(ctx context.Context, userID string, weights map[int]int) error {
+func (f *fakeRepo) UpdatePreferences(
+	ctx context.Context,
+	userID string,
+	weights map[int]float64,
+	halfLife time.Duration,
+	at time.Time,
+) error {
 	f.called = true
 	f.lastUserID = userID
+	f.lastPrefsHalfLife = halfLife
+	f.lastPrefsAt = at
 	// копируем map, чтобы избежать мутирования извне
-	f.lastWeights = make(map[int]int)
+	f.lastWeights = make(map[int]float64)
 	for k, v := range weights {
 		f.lastWeights[k] = v
 	}
 	return f.returnErr
 }
 
-func (f *fakeRepo) GetTopCategories(ctx context.Context, userID string, limit int) ([]int, error) {
+func (f *fakeRepo) GetTopCategories(ctx context.Context, userID string, halfLife time.Duration, limit int) ([]int, error) {
 	// не требуется для тестирования ProcessEvent
 	return nil, nil
 }
 
+func (f *fakeRepo) GetCategoryWeights(
+	ctx context.Context,
+	userID string,
+	halfLife time.Duration,
+	limit int,
+) ([]CategoryWeight, error) {
+	f.lastPrefsHalfLife = halfLife
+	return nil, nil
+}
+
+func (f *fakeRepo) UpdateCoPurchases(ctx context.Context, ids []string, halfLife time.Duration) error {
+	f.lastCoPurchaseIDs = ids
+	f.lastHalfLife = halfLife
+	return nil
+}
+
+func (f *fakeRepo) IncrementCategoryEvents(
+	ctx context.Context,
+	eventType kafka.EventType,
+	categories []int,
+	at time.Time,
+) error {
+	f.rollupCalls++
+	f.lastRollupType = eventType
+	f.lastRollupCategories = categories
+	f.lastRollupAt = at
+	return nil
+}
+
+func (f *fakeRepo) GetCategoryEventCounts(
+	ctx context.Context,
+	from, to time.Time,
+	category int,
+) ([]CategoryEventCount, error) {
+	f.countsFrom = append(f.countsFrom, from)
+	if len(f.returnCounts) == 0 {
+		return nil, nil
+	}
+	counts := f.returnCounts[0]
+	f.returnCounts = f.returnCounts[1:]
+	return counts, nil
+}
+
+func (f *fakeRepo) IncrementAnnouncementStats(
+	ctx context.Context,
+	eventType kafka.EventType,
+	sellerID string,
+	announcementIDs []string,
+	at time.Time,
+) error {
+	if f.failStats > 0 {
+		f.failStats--
+		return errors.New("stats unavailable")
+	}
+	f.statsCalls++
+	f.lastStatsType = eventType
+	f.lastStatsSeller = sellerID
+	f.lastStatsIDs = announcementIDs
+	return nil
+}
+
+func (f *fakeRepo) GetAnnouncementEngagement(
+	ctx context.Context,
+	announcementID string,
+	from, to time.Time,
+) (EngagementStats, error) {
+	return f.returnEngagement, nil
+}
+
+func (f *fakeRepo) GetSellerEngagement(ctx context.Context, sellerID string, from, to time.Time) (EngagementStats, error) {
+	return f.returnEngagement, nil
+}
+
+func (f *fakeRepo) SaveEvent(ctx context.Context, event kafka.Event) error {
+	f.savedEvents = append(f.savedEvents, event)
+	return nil
+}
+
+// ProcessEventOnce ведет себя как транзакция: при ошибке fn записанное ею откатывается
+func (f *fakeRepo) ProcessEventOnce(ctx context.Context, eventID string, fn func(repo AnalyticsRepo) error) (bool, error) {
+	if f.processedIDs[eventID] {
+		return false, nil
+	}
+
+	saved, rollups, stats := len(f.savedEvents), f.rollupCalls, f.statsCalls
+	if err := fn(f); err != nil {
+		f.savedEvents, f.rollupCalls, f.statsCalls = f.savedEvents[:saved], rollups, stats
+		return false, err
+	}
+
+	if f.processedIDs == nil {
+		f.processedIDs = make(map[string]bool)
+	}
+	f.processedIDs[eventID] = true
+	return true, nil
+}
+
+func (f *fakeRepo) DeleteProcessedEventsBefore(ctx context.Context, before time.Time) (int64, error) {
+	f.forgetProcessedBefore = before
+	return 0, nil
+}
+
+func (f *fakeRepo) CreateEventPartitions(ctx context.Context, days []time.Time) error {
+	f.createdPartitions = days
+	return f.createPartitionsErr
+}
+
+func (f *fakeRepo) DropEventPartitionsBefore(ctx context.Context, before time.Time) ([]string, error) {
+	f.dropBefore = before
+	return nil, nil
+}
+
+func (f *fakeRepo) DeleteDefaultEventsBefore(ctx context.Context, before time.Time) (int64, error) {
+	f.deleteDefaultBefore = before
+	return 0, nil
+}
+
+func (f *fakeRepo) GetFunnel(ctx context.Context, from, to time.Time, category int) ([]FunnelRow, error) {
+	return nil, nil
+}
+
+func (f *fakeRepo) GetCohortActivity(ctx context.Context, from, to time.Time) ([]CohortCell, error) {
+	return f.returnCohortCells, nil
+}
+
+func (f *fakeRepo) GetExperimentConversions(
+	ctx context.Context,
+	key string,
+	goal kafka.EventType,
+	from, to time.Time,
+) ([]VariantConversion, error) {
+	return f.returnConversions, nil
+}
+
+func (f *fakeRepo) GetAlsoBought(
+	ctx context.Context,
+	announcementID string,
+	halfLife time.Duration,
+	minSupport, limit int,
+) ([]AlsoBought, error) {
+	f.lastHalfLife = halfLife
+	f.lastMinSupport = minSupport
+	return nil, nil
+}
+
+func (f *fakeRepo) MergeVisitor(ctx context.Context, visitorID, userID string, halfLife time.Duration) error {
+	f.mergeCalls++
+	f.lastMergedVisitor = visitorID
+	f.lastMergedUser = userID
+	f.lastMergedHalfLife = halfLife
+	return f.returnErr
+}
+
 func TestService_ProcessEvent_EmptyUserID(t *testing.T) {
 	repo := &fakeRepo{}
 	logger := zapTestLogger(t)
-	service := NewService(repo, logger)
+	service := NewService(repo, logger, ConfigPreferences{}, ConfigCoPurchase{}, ConfigEvents{})
 
 	ctx := context.Background()
 	evt := kafka.Event{
@@ -55,10 +256,78 @@ func TestService_ProcessEvent_EmptyUserID(t *testing.T) {
 	}
 }
 
+func TestService_ProcessEvent_AnonymousVisitor(t *testing.T) {
+	repo := &fakeRepo{}
+	service := NewService(repo, zapTestLogger(t), ConfigPreferences{}, ConfigCoPurchase{}, ConfigEvents{})
+
+	evt := kafka.Event{
+		VisitorID:  "v-1",
+		Type:       kafka.EventTypeView,
+		Categories: []int{4},
+	}
+	if err := service.ProcessEvent(context.Background(), evt); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// предпочтения анонима копятся за посетителем до входа
+	if repo.lastUserID != "visitor:v-1" {
+		t.Errorf("expected preferences of \"visitor:v-1\", got %q", repo.lastUserID)
+	}
+	if !reflect.DeepEqual(repo.lastWeights, map[int]float64{4: 2}) {
+		t.Errorf("expected weights {4: 2}, got %v", repo.lastWeights)
+	}
+}
+
+func TestService_ProcessEvent_Identify(t *testing.T) {
+	repo := &fakeRepo{}
+	prefs := ConfigPreferences{HalfLife: 24 * time.Hour}
+	service := NewService(repo, zapTestLogger(t), prefs, ConfigCoPurchase{}, ConfigEvents{})
+
+	evt := kafka.Event{UserID: "u-1", VisitorID: "v-1", Type: kafka.EventTypeIdentify}
+	if err := service.ProcessEvent(context.Background(), evt); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if repo.mergeCalls != 1 || repo.lastMergedVisitor != "v-1" || repo.lastMergedUser != "u-1" {
+		t.Errorf("expected visitor v-1 merged into u-1, got %d calls (%q -> %q)",
+			repo.mergeCalls, repo.lastMergedVisitor, repo.lastMergedUser)
+	}
+	if repo.lastMergedHalfLife != 24*time.Hour {
+		t.Errorf("expected configured half-life, got %v", repo.lastMergedHalfLife)
+	}
+	if repo.called {
+		t.Errorf("expected identify not to update preferences directly")
+	}
+
+	// без посетителя связывать нечего
+	evt.VisitorID = ""
+	if err := service.ProcessEvent(context.Background(), evt); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.mergeCalls != 1 {
+		t.Errorf("expected no merge without visitor, got %d calls", repo.mergeCalls)
+	}
+}
+
+// Тест ProcessEvent: веса затухают от момента события, а не от момента обработки
+func TestService_ProcessEvent_PassesEventTime(t *testing.T) {
+	repo := &fakeRepo{}
+	service := NewService(repo, zapTestLogger(t), ConfigPreferences{}, ConfigCoPurchase{}, ConfigEvents{})
+
+	occurred := time.Now().Add(-3 * time.Hour)
+	evt := kafka.Event{UserID: "u-1", Type: kafka.EventTypeView, Categories: []int{3}, Timestamp: occurred}
+	if err := service.ProcessEvent(context.Background(), evt); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !repo.lastPrefsAt.Equal(occurred) {
+		t.Errorf("expected preferences at %v, got %v", occurred, repo.lastPrefsAt)
+	}
+}
+
 func TestService_ProcessEvent_SearchEvent(t *testing.T) {
 	repo := &fakeRepo{}
 	logger := zapTestLogger(t)
-	service := NewService(repo, logger)
+	service := NewService(repo, logger, ConfigPreferences{}, ConfigCoPurchase{}, ConfigEvents{})
 
 	ctx := context.Background()
 	evt := kafka.Event{
@@ -77,7 +346,7 @@ func TestService_ProcessEvent_SearchEvent(t *testing.T) {
 	if repo.lastUserID != "u-1" {
 		t.Errorf("expected userID \"u-1\", got %s", repo.lastUserID)
 	}
-	expectedWeights := map[int]int{
+	expectedWeights := map[int]float64{
 		3: 2, // две встречи категории 3 → 2 * 1
 		5: 1, // одна встреча категории 5 → 1 * 1
 	}
@@ -89,7 +358,7 @@ func TestService_ProcessEvent_SearchEvent(t *testing.T) {
 func TestService_ProcessEvent_ViewEvent(t *testing.T) {
 	repo := &fakeRepo{}
 	logger := zapTestLogger(t)
-	service := NewService(repo, logger)
+	service := NewService(repo, logger, ConfigPreferences{}, ConfigCoPurchase{}, ConfigEvents{})
 
 	ctx := context.Background()
 	evt := kafka.Event{
@@ -106,7 +375,7 @@ func TestService_ProcessEvent_ViewEvent(t *testing.T) {
 		t.Fatalf("expected repo.UpdatePreferences to be called")
 	}
 	// для VIEW учитывается только первая категория, вес = 2
-	expectedWeights := map[int]int{
+	expectedWeights := map[int]float64{
 		7: 2,
 	}
 	if !reflect.DeepEqual(repo.lastWeights, expectedWeights) {
@@ -117,7 +386,7 @@ func TestService_ProcessEvent_ViewEvent(t *testing.T) {
 func TestService_ProcessEvent_PurchaseEvent(t *testing.T) {
 	repo := &fakeRepo{}
 	logger := zapTestLogger(t)
-	service := NewService(repo, logger)
+	service := NewService(repo, logger, ConfigPreferences{}, ConfigCoPurchase{}, ConfigEvents{})
 
 	ctx := context.Background()
 	evt := kafka.Event{
@@ -134,7 +403,7 @@ func TestService_ProcessEvent_PurchaseEvent(t *testing.T) {
 		t.Fatalf("expected repo.UpdatePreferences to be called")
 	}
 	// для PURCHASE учитывается только первая категория, вес = 3
-	expectedWeights := map[int]int{
+	expectedWeights := map[int]float64{
 		4: 3,
 	}
 	if !reflect.DeepEqual(repo.lastWeights, expectedWeights) {
@@ -142,10 +411,38 @@ func TestService_ProcessEvent_PurchaseEvent(t *testing.T) {
 	}
 }
 
+func TestService_ProcessEvent_FavoriteEvent(t *testing.T) {
+	repo := &fakeRepo{}
+	logger := zapTestLogger(t)
+	service := NewService(repo, logger, ConfigPreferences{}, ConfigCoPurchase{}, ConfigEvents{})
+
+	ctx := context.Background()
+	evt := kafka.Event{
+		UserID:     "u-5",
+		Type:       kafka.EventTypeFavorite,
+		Categories: []int{6},
+	}
+
+	err := service.ProcessEvent(ctx, evt)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !repo.called {
+		t.Fatalf("expected repo.UpdatePreferences to be called")
+	}
+	// для FAVORITE учитывается только первая категория, вес = 3
+	expectedWeights := map[int]float64{
+		6: 3,
+	}
+	if !reflect.DeepEqual(repo.lastWeights, expectedWeights) {
+		t.Errorf("expected weights %v, got %v", expectedWeights, repo.lastWeights)
+	}
+}
+
 func TestService_ProcessEvent_NoCategories(t *testing.T) {
 	repo := &fakeRepo{}
 	logger := zapTestLogger(t)
-	service := NewService(repo, logger)
+	service := NewService(repo, logger, ConfigPreferences{}, ConfigCoPurchase{}, ConfigEvents{})
 
 	ctx := context.Background()
 	evt := kafka.Event{
@@ -166,7 +463,7 @@ func TestService_ProcessEvent_NoCategories(t *testing.T) {
 func TestService_ProcessEvent_RepoError(t *testing.T) {
 	repo := &fakeRepo{returnErr: errors.New("db error")}
 	logger := zapTestLogger(t)
-	service := NewService(repo, logger)
+	service := NewService(repo, logger, ConfigPreferences{}, ConfigCoPurchase{}, ConfigEvents{})
 
 	ctx := context.Background()
 	evt := kafka.Event{
@@ -180,3 +477,472 @@ func TestService_ProcessEvent_RepoError(t *testing.T) {
 		t.Errorf("expected error from repo, got nil")
 	}
 }
+
+func TestService_ProcessEvent_PurchaseCoPurchases(t *testing.T) {
+	repo := &fakeRepo{}
+	logger := zapTestLogger(t)
+	service := NewService(repo, logger, ConfigPreferences{}, ConfigCoPurchase{HalfLife: time.Hour, MinSupport: 3}, ConfigEvents{})
+
+	evt := kafka.Event{
+		UserID:          "u-6",
+		Type:            kafka.EventTypePurchase,
+		Categories:      []int{1},
+		AnnouncementIDs: []string{"a1", "a2", "a1"},
+	}
+
+	if err := service.ProcessEvent(context.Background(), evt); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(repo.lastCoPurchaseIDs, []string{"a1", "a2"}) {
+		t.Errorf("expected deduplicated co-purchase ids, got %v", repo.lastCoPurchaseIDs)
+	}
+	if repo.lastHalfLife != time.Hour {
+		t.Errorf("expected half-life from config, got %v", repo.lastHalfLife)
+	}
+
+	if _, err := service.GetAlsoBought(context.Background(), "a1", 5); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.lastMinSupport != 3 {
+		t.Errorf("expected min support from config, got %d", repo.lastMinSupport)
+	}
+}
+
+func TestService_ProcessEvent_SingleItemPurchase(t *testing.T) {
+	repo := &fakeRepo{}
+	logger := zapTestLogger(t)
+	service := NewService(repo, logger, ConfigPreferences{}, ConfigCoPurchase{}, ConfigEvents{})
+
+	evt := kafka.Event{
+		UserID:          "u-7",
+		Type:            kafka.EventTypePurchase,
+		Categories:      []int{1},
+		AnnouncementIDs: []string{"a1"},
+	}
+
+	if err := service.ProcessEvent(context.Background(), evt); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.lastCoPurchaseIDs != nil {
+		t.Errorf("expected no co-purchase update for single item, got %v", repo.lastCoPurchaseIDs)
+	}
+}
+
+func TestService_ProcessEvent_ConfiguredWeights(t *testing.T) {
+	repo := &fakeRepo{}
+	logger := zapTestLogger(t)
+	service := NewService(repo, logger, ConfigPreferences{
+		HalfLife: 48 * time.Hour,
+		EventWeights: map[kafka.EventType]float64{
+			kafka.EventTypeSearch: 0.5,
+			kafka.EventTypeView:   0,
+		},
+	}, ConfigCoPurchase{}, ConfigEvents{})
+
+	ctx := context.Background()
+	if err := service.ProcessEvent(ctx, kafka.Event{
+		UserID:     "u-1",
+		Type:       kafka.EventTypeSearch,
+		Categories: []int{3, 3, 5},
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedWeights := map[int]float64{3: 1, 5: 0.5}
+	if !reflect.DeepEqual(repo.lastWeights, expectedWeights) {
+		t.Errorf("expected weights %v, got %v", expectedWeights, repo.lastWeights)
+	}
+	if repo.lastPrefsHalfLife != 48*time.Hour {
+		t.Errorf("expected halfLife 48h, got %v", repo.lastPrefsHalfLife)
+	}
+
+	// нулевой вес отключает учет событий этого типа
+	repo.called = false
+	if err := service.ProcessEvent(ctx, kafka.Event{
+		UserID:     "u-1",
+		Type:       kafka.EventTypeView,
+		Categories: []int{7},
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.called {
+		t.Errorf("expected repo.UpdatePreferences NOT to be called for zero weight")
+	}
+
+	// не заданный в конфиге тип берет вес по умолчанию
+	if err := service.ProcessEvent(ctx, kafka.Event{
+		UserID:     "u-1",
+		Type:       kafka.EventTypePurchase,
+		Categories: []int{7},
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(repo.lastWeights, map[int]float64{7: 3}) {
+		t.Errorf("expected default purchase weight 3, got %v", repo.lastWeights)
+	}
+}
+
+func TestService_ProcessEvent_CountsCategoryEvents(t *testing.T) {
+	repo := &fakeRepo{}
+	logger := zapTestLogger(t)
+	service := NewService(repo, logger, ConfigPreferences{}, ConfigCoPurchase{}, ConfigEvents{})
+
+	at := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	// анонимный поиск тоже попадает в глобальные счетчики, каждая категория один раз
+	err := service.ProcessEvent(context.Background(), kafka.Event{
+		Type:       kafka.EventTypeSearch,
+		Categories: []int{3, 3, 5},
+		Timestamp:  at,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.called {
+		t.Errorf("expected repo.UpdatePreferences NOT to be called when userID is empty")
+	}
+	if repo.lastRollupType != kafka.EventTypeSearch || !repo.lastRollupAt.Equal(at) {
+		t.Errorf("unexpected rollup event: type=%s at=%v", repo.lastRollupType, repo.lastRollupAt)
+	}
+	if !reflect.DeepEqual(repo.lastRollupCategories, []int{3, 5}) {
+		t.Errorf("expected categories [3 5], got %v", repo.lastRollupCategories)
+	}
+}
+
+func TestService_GetTrendingCategories(t *testing.T) {
+	repo := &fakeRepo{returnCounts: [][]CategoryEventCount{
+		{
+			{Category: 1, Type: kafka.EventTypeSearch, Count: 4},
+			{Category: 2, Type: kafka.EventTypeView, Count: 1},
+			{Category: 2, Type: kafka.EventTypePurchase, Count: 1},
+			{Category: 3, Type: kafka.EventTypeSearch, Count: 1},
+		},
+		{
+			{Category: 2, Type: kafka.EventTypeView, Count: 3},
+			{Category: 9, Type: kafka.EventTypeView, Count: 10},
+		},
+	}}
+	logger := zapTestLogger(t)
+	service := NewService(repo, logger, ConfigPreferences{}, ConfigCoPurchase{}, ConfigEvents{})
+
+	trends, err := service.GetTrendingCategories(context.Background(), 24*time.Hour, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// категория 2: view 2 + purchase 3 = 5, категория 1: 4 поиска по 1
+	expected := []TrendingCategory{
+		{Category: 2, Score: 5, PreviousScore: 6, Events: 2},
+		{Category: 1, Score: 4, Events: 4},
+	}
+	if !reflect.DeepEqual(trends, expected) {
+		t.Errorf("expected trends %+v, got %+v", expected, trends)
+	}
+	if len(repo.countsFrom) != 2 || repo.countsFrom[0].Sub(repo.countsFrom[1]) != 24*time.Hour {
+		t.Errorf("expected current and previous windows, got %v", repo.countsFrom)
+	}
+}
+
+func TestService_GetCategoryStats(t *testing.T) {
+	repo := &fakeRepo{returnCounts: [][]CategoryEventCount{{
+		{Category: 7, Type: kafka.EventTypeView, Count: 5},
+		{Category: 7, Type: kafka.EventTypeFavorite, Count: 2},
+	}}}
+	logger := zapTestLogger(t)
+	service := NewService(repo, logger, ConfigPreferences{}, ConfigCoPurchase{}, ConfigEvents{})
+
+	stats, err := service.GetCategoryStats(context.Background(), 7, 48*time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := CategoryStats{
+		Category: 7,
+		Window:   "48h0m0s",
+		Total:    7,
+		Events:   map[kafka.EventType]int64{kafka.EventTypeView: 5, kafka.EventTypeFavorite: 2},
+	}
+	if !reflect.DeepEqual(stats, expected) {
+		t.Errorf("expected stats %+v, got %+v", expected, stats)
+	}
+}
+
+func TestService_ProcessEvent_CountsAnnouncementEvents(t *testing.T) {
+	logger := zapTestLogger(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		event      kafka.Event
+		wantCalls  int
+		wantSeller string
+		wantIDs    []string
+	}{
+		{
+			name:       "анонимный просмотр",
+			event:      kafka.Event{Type: kafka.EventTypeView, AnnouncementID: "a1", SellerID: "s1"},
+			wantCalls:  1,
+			wantSeller: "s1",
+			wantIDs:    []string{"a1"},
+		},
+		{
+			name:      "удаление из корзины без продавца",
+			event:     kafka.Event{UserID: "u-1", Type: kafka.EventTypeRemoveFromCart, AnnouncementID: "a1"},
+			wantCalls: 1,
+			wantIDs:   []string{"a1"},
+		},
+		{
+			name: "покупка учитывается по каждому объявлению",
+			event: kafka.Event{
+				UserID:          "u-1",
+				Type:            kafka.EventTypePurchase,
+				Categories:      []int{1},
+				AnnouncementIDs: []string{"a1", "a2", "a1"},
+			},
+			wantCalls: 1,
+			wantIDs:   []string{"a1", "a2"},
+		},
+		{
+			name:  "избранное не входит в воронку",
+			event: kafka.Event{UserID: "u-1", Type: kafka.EventTypeFavorite, AnnouncementID: "a1", Categories: []int{1}},
+		},
+		{
+			name:  "просмотр без объявления",
+			event: kafka.Event{UserID: "u-1", Type: kafka.EventTypeView, Categories: []int{1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeRepo{}
+			service := NewService(repo, logger, ConfigPreferences{}, ConfigCoPurchase{}, ConfigEvents{})
+
+			if err := service.ProcessEvent(ctx, tt.event); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if repo.statsCalls != tt.wantCalls {
+				t.Fatalf("expected %d IncrementAnnouncementStats calls, got %d", tt.wantCalls, repo.statsCalls)
+			}
+			if tt.wantCalls == 0 {
+				return
+			}
+			if repo.lastStatsType != tt.event.Type || repo.lastStatsSeller != tt.wantSeller {
+				t.Errorf("unexpected stats call: type=%s seller=%q", repo.lastStatsType, repo.lastStatsSeller)
+			}
+			if !reflect.DeepEqual(repo.lastStatsIDs, tt.wantIDs) {
+				t.Errorf("expected ids %v, got %v", tt.wantIDs, repo.lastStatsIDs)
+			}
+		})
+	}
+}
+
+func TestService_ProcessEvent_AddToCartWeight(t *testing.T) {
+	repo := &fakeRepo{}
+	logger := zapTestLogger(t)
+	service := NewService(repo, logger, ConfigPreferences{}, ConfigCoPurchase{}, ConfigEvents{})
+
+	err := service.ProcessEvent(context.Background(), kafka.Event{
+		UserID:         "u-1",
+		Type:           kafka.EventTypeAddToCart,
+		Categories:     []int{4},
+		AnnouncementID: "a1",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(repo.lastWeights, map[int]float64{4: 2}) {
+		t.Errorf("expected add_to_cart weight 2, got %v", repo.lastWeights)
+	}
+}
+
+func TestService_GetAnnouncementEngagement_Conversions(t *testing.T) {
+	repo := &fakeRepo{returnEngagement: EngagementStats{Views: 40, CartAdds: 10, CartRemoves: 3, Purchases: 4}}
+	logger := zapTestLogger(t)
+	service := NewService(repo, logger, ConfigPreferences{}, ConfigCoPurchase{}, ConfigEvents{})
+
+	stats, err := service.GetAnnouncementEngagement(context.Background(), "a1", 24*time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.ViewToCart != 0.25 || stats.CartToPurchase != 0.4 || stats.Window != "24h0m0s" {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+
+	// без просмотров и корзины конверсии нулевые, а не NaN
+	repo.returnEngagement = EngagementStats{}
+	stats, err = service.GetSellerEngagement(context.Background(), "s1", 24*time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.ViewToCart != 0 || stats.CartToPurchase != 0 {
+		t.Errorf("expected zero conversions, got %+v", stats)
+	}
+}
+
+func TestService_ProcessEvent_SavesRawEvent(t *testing.T) {
+	repo := &fakeRepo{}
+	logger := zapTestLogger(t)
+	service := NewService(repo, logger, ConfigPreferences{}, ConfigCoPurchase{}, ConfigEvents{})
+
+	// событие без метки времени сохраняется со временем обработки
+	before := time.Now()
+	if err := service.ProcessEvent(context.Background(), kafka.Event{UserID: "u-1", Type: kafka.EventTypeSignup}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(repo.savedEvents) != 1 {
+		t.Fatalf("expected one saved event, got %d", len(repo.savedEvents))
+	}
+	if ev := repo.savedEvents[0]; ev.Type != kafka.EventTypeSignup || ev.Timestamp.Before(before) {
+		t.Errorf("unexpected saved event: %+v", ev)
+	}
+	if repo.called {
+		t.Errorf("expected signup NOT to change preferences")
+	}
+}
+
+func TestService_MaintainEventPartitions(t *testing.T) {
+	repo := &fakeRepo{}
+	logger := zapTestLogger(t)
+	service := NewService(repo, logger, ConfigPreferences{}, ConfigCoPurchase{}, ConfigEvents{
+		Retention:       30 * 24 * time.Hour,
+		PartitionsAhead: 2,
+	}).(*Service)
+
+	now := time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)
+	if err := service.maintainEventPartitions(context.Background(), now); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// вчера, сегодня и два дня вперед
+	if len(repo.createdPartitions) != 4 ||
+		!repo.createdPartitions[0].Equal(now.AddDate(0, 0, -1)) ||
+		!repo.createdPartitions[3].Equal(now.AddDate(0, 0, 2)) {
+		t.Errorf("unexpected partitions: %v", repo.createdPartitions)
+	}
+	if !repo.dropBefore.Equal(now.Add(-30 * 24 * time.Hour)) {
+		t.Errorf("unexpected retention border: %v", repo.dropBefore)
+	}
+	// события дней без партиции тоже удаляются по сроку хранения
+	if !repo.deleteDefaultBefore.Equal(now.Add(-30 * 24 * time.Hour)) {
+		t.Errorf("unexpected default partition retention border: %v", repo.deleteDefaultBefore)
+	}
+	if !repo.forgetProcessedBefore.Equal(now.Add(-30 * 24 * time.Hour)) {
+		t.Errorf("unexpected processed events retention border: %v", repo.forgetProcessedBefore)
+	}
+}
+
+// Тест ProcessEvent: второй шаг падает один раз, повтор и повторная доставка
+// не учитывают событие дважды
+func TestService_ProcessEvent_RetryCountsOnce(t *testing.T) {
+	repo := &fakeRepo{failStats: 1}
+	service := NewService(repo, zapTestLogger(t), ConfigPreferences{}, ConfigCoPurchase{}, ConfigEvents{})
+
+	evt := kafka.Event{
+		ID:             "e-1",
+		UserID:         "u-1",
+		Type:           kafka.EventTypeView,
+		Categories:     []int{3},
+		AnnouncementID: "a1",
+		SellerID:       "s1",
+	}
+
+	if err := service.ProcessEvent(context.Background(), evt); err == nil {
+		t.Fatalf("expected error on first attempt")
+	}
+	// повтор consumer после ошибки и повторная доставка того же сообщения
+	for i := 0; i < 2; i++ {
+		if err := service.ProcessEvent(context.Background(), evt); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if len(repo.savedEvents) != 1 || repo.rollupCalls != 1 || repo.statsCalls != 1 {
+		t.Errorf("expected event counted once, got saved=%d rollups=%d stats=%d",
+			len(repo.savedEvents), repo.rollupCalls, repo.statsCalls)
+	}
+}
+
+// Тест maintainEventPartitions: ошибка создания партиций не отменяет срок хранения
+func TestService_MaintainEventPartitions_CreateError(t *testing.T) {
+	repo := &fakeRepo{createPartitionsErr: errors.New("lock timeout")}
+	service := NewService(repo, zapTestLogger(t), ConfigPreferences{}, ConfigCoPurchase{}, ConfigEvents{
+		Retention: 30 * 24 * time.Hour,
+	}).(*Service)
+
+	now := time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)
+	if err := service.maintainEventPartitions(context.Background(), now); err == nil {
+		t.Fatalf("expected error, got nil")
+	}
+	if repo.dropBefore.IsZero() || repo.deleteDefaultBefore.IsZero() {
+		t.Errorf("expected retention to run after partition error")
+	}
+}
+
+func TestService_GetCohorts(t *testing.T) {
+	week := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -14)
+	repo := &fakeRepo{returnCohortCells: []CohortCell{
+		{Week: week, Offset: 0, Users: 10},
+		{Week: week, Offset: 2, Users: 4},
+	}}
+	logger := zapTestLogger(t)
+	service := NewService(repo, logger, ConfigPreferences{}, ConfigCoPurchase{}, ConfigEvents{})
+
+	cohorts, err := service.GetCohorts(context.Background(), week, week.AddDate(0, 0, 7))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// неделя без активности заполняется нулем
+	expected := []Cohort{{
+		Week:      week.Format(time.DateOnly),
+		Size:      10,
+		Active:    []int64{10, 0, 4},
+		Retention: []float64{1, 0, 0.4},
+	}}
+	if !reflect.DeepEqual(cohorts, expected) {
+		t.Errorf("expected cohorts %+v, got %+v", expected, cohorts)
+	}
+}
+
+func TestService_GetExperimentResults(t *testing.T) {
+	repo := &fakeRepo{returnConversions: []VariantConversion{
+		{Variant: "compact", Users: 1000, Converted: 150},
+		{Variant: "control", Users: 1000, Converted: 100},
+		{Variant: "wide", Users: 1000, Converted: 105},
+	}}
+	logger := zapTestLogger(t)
+	service := NewService(repo, logger, ConfigPreferences{}, ConfigCoPurchase{}, ConfigEvents{})
+
+	results, err := service.GetExperimentResults(
+		context.Background(), "layout", kafka.EventTypePurchase, time.Time{}, time.Now(),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if results.Control != "control" || len(results.Variants) != 3 {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+
+	compact, control, wide := results.Variants[0], results.Variants[1], results.Variants[2]
+	if control.ZScore != 0 || control.PValue != 0 || control.Significant {
+		t.Errorf("control must not be compared with itself: %+v", control)
+	}
+	// 15% против 10% на 1000 пользователей - значимо (z ≈ 3.4), 10.5% против 10% - нет
+	if !compact.Significant || compact.ZScore < 3.3 || compact.ZScore > 3.5 {
+		t.Errorf("expected significant compact variant, got %+v", compact)
+	}
+	if math.Abs(compact.Lift-0.5) > 1e-9 {
+		t.Errorf("expected lift 0.5, got %v", compact.Lift)
+	}
+	if wide.Significant || wide.PValue < 0.5 {
+		t.Errorf("expected insignificant wide variant, got %+v", wide)
+	}
+}
+
+func TestTwoProportionZTest_NoData(t *testing.T) {
+	if z, p := twoProportionZTest(0, 0, 5, 10); z != 0 || p != 1 {
+		t.Errorf("expected z=0 p=1 without control users, got z=%v p=%v", z, p)
+	}
+	if z, p := twoProportionZTest(0, 10, 0, 10); z != 0 || p != 1 {
+		t.Errorf("expected z=0 p=1 without conversions, got z=%v p=%v", z, p)
+	}
+}
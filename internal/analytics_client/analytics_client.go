@@ -0,0 +1,34 @@
+package analyticsclient
+
+import (
+	"context"
+	"time"
+)
+
+// AnalyticsClient клиент сервиса аналитики для основного сервиса
+//
+//go:generate mockgen -source=analytics_client.go -destination=../mocks/mock_analytics_client.go -package=mocks
+type AnalyticsClient interface {
+	// GetCategoryWeights возвращает веса limit самых весомых категорий пользователя или посетителя
+	GetCategoryWeights(ctx context.Context, userID string, limit int) (map[int]float64, error)
+}
+
+// Config - адрес сервиса аналитики и параметры устойчивости клиента
+type Config struct {
+	// BaseURL - адрес HTTP API сервиса аналитики
+	BaseURL string `yaml:"base_url"`
+	// GRPCAddr - адрес gRPC API сервиса аналитики, если задан, клиент ходит по gRPC вместо HTTP
+	GRPCAddr string `yaml:"grpc_addr"`
+	// Timeout - таймаут одной попытки запроса
+	Timeout time.Duration `yaml:"timeout"`
+	// Retries - сколько раз повторять запрос после сетевой ошибки или ответа 5xx
+	Retries int `yaml:"retries"`
+	// RetryBackoff - пауза перед первым повтором, дальше удваивается
+	RetryBackoff time.Duration `yaml:"retry_backoff"`
+	// BreakerThreshold - после скольких неудачных запросов подряд клиент перестает ходить в сервис
+	BreakerThreshold int `yaml:"breaker_threshold"`
+	// BreakerCooldown - сколько клиент не ходит в сервис после срабатывания, затем пробует один запрос
+	BreakerCooldown time.Duration `yaml:"breaker_cooldown"`
+	// CacheTTL - сколько веса категорий хранятся в Redis, 0 - не кешировать
+	CacheTTL time.Duration `yaml:"cache_ttl"`
+}
@@ -0,0 +1,67 @@
+package analyticsclient
+
+import (
+	"sync"
+	"time"
+)
+
+// breaker - circuit breaker: после threshold неудач подряд запросы не выполняются cooldown,
+// затем пропускается один пробный запрос, и его успех снова открывает дорогу остальным
+type breaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openUntil time.Time
+	// probing - пробный запрос после cooldown уже выполняется, остальные ждут его результата
+	probing bool
+	now     func() time.Time
+}
+
+func newBreaker(threshold int, cooldown time.Duration) *breaker {
+	return &breaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+	}
+}
+
+// allow сообщает, можно ли выполнить запрос
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return true
+	}
+	if b.probing || b.now().Before(b.openUntil) {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+// record учитывает результат запроса, пропущенного allow
+func (b *breaker) record(ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if ok {
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openUntil = b.now().Add(b.cooldown)
+	}
+}
+
+// release завершает запрос, результат которого не говорит о здоровье сервиса
+func (b *breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
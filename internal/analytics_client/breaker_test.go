@@ -0,0 +1,46 @@
+package analyticsclient
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker_HalfOpen(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2025, 3, 4, 12, 0, 0, 0, time.UTC)
+	b := newBreaker(2, time.Minute)
+	b.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		assert.True(t, b.allow())
+		b.record(false)
+	}
+	assert.False(t, b.allow())
+
+	// после cooldown проходит один пробный запрос
+	now = now.Add(time.Minute)
+	assert.True(t, b.allow())
+	assert.False(t, b.allow())
+
+	// неудачная проба снова открывает breaker на cooldown
+	b.record(false)
+	assert.False(t, b.allow())
+
+	now = now.Add(time.Minute)
+	assert.True(t, b.allow())
+	b.record(true)
+	assert.True(t, b.allow())
+	assert.True(t, b.allow())
+}
+
+func TestBreaker_SuccessResetsFailures(t *testing.T) {
+	t.Parallel()
+	b := newBreaker(2, time.Minute)
+
+	b.record(false)
+	b.record(true)
+	b.record(false)
+	assert.True(t, b.allow())
+}
@@ -0,0 +1,217 @@
+package analyticsclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	myErr "gafroshka-main/internal/types/errors"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+const (
+	defaultBaseURL          = "http://analytics-service:8082"
+	defaultTimeout          = 500 * time.Millisecond
+	defaultRetryBackoff     = 50 * time.Millisecond
+	defaultBreakerThreshold = 5
+	defaultBreakerCooldown  = 30 * time.Second
+)
+
+// cacheKeyPrefix - префикс ключей Redis с весами категорий
+const cacheKeyPrefix = "analytics:weights:"
+
+// transport - одна попытка запроса к сервису аналитики по HTTP или gRPC
+type transport interface {
+	categoryWeights(ctx context.Context, userID string, limit int) (map[int]float64, error)
+	// retryable - стоит ли повторять запрос после ошибки: сервис недоступен или упал
+	retryable(err error) bool
+}
+
+// Client ходит в сервис аналитики: каждая попытка ограничена таймаутом, сбои сервиса
+// повторяются, а при серии неудач circuit breaker на время перестает нагружать сервис.
+// Веса категорий кешируются в Redis
+type Client struct {
+	transport   transport
+	conn        *grpc.ClientConn
+	redisClient *redis.Client
+	logger      *zap.SugaredLogger
+	cfg         Config
+	breaker     *breaker
+}
+
+// New создает клиент по конфигу: по gRPC, если задан GRPCAddr, иначе по HTTP
+func New(cfg Config, redisClient *redis.Client, logger *zap.SugaredLogger) (*Client, error) {
+	if cfg.GRPCAddr == "" {
+		return NewHTTPClient(cfg, redisClient, logger), nil
+	}
+
+	conn, err := grpc.NewClient(cfg.GRPCAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create analytics gRPC client: %w", err)
+	}
+	c := NewGRPCClient(cfg, conn, redisClient, logger)
+	c.conn = conn
+	return c, nil
+}
+
+func NewHTTPClient(cfg Config, redisClient *redis.Client, logger *zap.SugaredLogger) *Client {
+	cfg = withDefaults(cfg)
+	return newClient(newHTTPTransport(cfg.BaseURL), cfg, redisClient, logger)
+}
+
+// NewGRPCClient создает клиент поверх соединения conn, закрывает его вызывающий
+func NewGRPCClient(
+	cfg Config,
+	conn grpc.ClientConnInterface,
+	redisClient *redis.Client,
+	logger *zap.SugaredLogger,
+) *Client {
+	return newClient(newGRPCTransport(conn), withDefaults(cfg), redisClient, logger)
+}
+
+func newClient(t transport, cfg Config, redisClient *redis.Client, logger *zap.SugaredLogger) *Client {
+	return &Client{
+		transport:   t,
+		redisClient: redisClient,
+		logger:      logger,
+		cfg:         cfg,
+		breaker:     newBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown),
+	}
+}
+
+// withDefaults подставляет значения по умолчанию вместо незаданных
+func withDefaults(cfg Config) Config {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = defaultBaseURL
+	}
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = defaultRetryBackoff
+	}
+	if cfg.BreakerThreshold <= 0 {
+		cfg.BreakerThreshold = defaultBreakerThreshold
+	}
+	if cfg.BreakerCooldown <= 0 {
+		cfg.BreakerCooldown = defaultBreakerCooldown
+	}
+	return cfg
+}
+
+// Close закрывает gRPC соединение, созданное New
+func (c *Client) Close() error {
+	if c.conn == nil {
+		return nil
+	}
+	return c.conn.Close()
+}
+
+func (c *Client) GetCategoryWeights(ctx context.Context, userID string, limit int) (map[int]float64, error) {
+	key := fmt.Sprintf("%s%s:%d", cacheKeyPrefix, userID, limit)
+	if weights, ok := c.cached(ctx, key); ok {
+		return weights, nil
+	}
+
+	var weights map[int]float64
+	err := c.call(ctx, func(ctx context.Context) error {
+		var err error
+		weights, err = c.transport.categoryWeights(ctx, userID, limit)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	c.store(ctx, key, weights)
+
+	return weights, nil
+}
+
+// call выполняет attempt через circuit breaker с повторами, каждая попытка - со своим таймаутом.
+// Ошибка, которую не стоит повторять (плохой запрос), не считается неудачей для breaker: сервис работает
+func (c *Client) call(ctx context.Context, attempt func(ctx context.Context) error) error {
+	if !c.breaker.allow() {
+		return fmt.Errorf("%w: circuit breaker is open", myErr.ErrAnalyticsUnavailable)
+	}
+
+	backoff := c.cfg.RetryBackoff
+	var err error
+	for i := 0; ; i++ {
+		err = c.try(ctx, attempt)
+		if err == nil || !c.transport.retryable(err) || i >= c.cfg.Retries || ctx.Err() != nil {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+
+	// отмена вызывающим ничего не говорит о здоровье сервиса
+	if ctx.Err() != nil {
+		c.breaker.release()
+		return ctx.Err()
+	}
+
+	failed := err != nil && c.transport.retryable(err)
+	c.breaker.record(!failed)
+	if failed {
+		return fmt.Errorf("%w: %v", myErr.ErrAnalyticsUnavailable, err)
+	}
+	return err
+}
+
+// try - одна попытка с таймаутом
+func (c *Client) try(ctx context.Context, attempt func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+	defer cancel()
+	return attempt(ctx)
+}
+
+// cached читает веса из Redis, ошибки Redis не мешают сходить в сервис
+func (c *Client) cached(ctx context.Context, key string) (map[int]float64, bool) {
+	if c.redisClient == nil || c.cfg.CacheTTL <= 0 {
+		return nil, false
+	}
+
+	data, err := c.redisClient.Get(ctx, key).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			c.logger.Warnw("Failed to read analytics cache", "key", key, zap.Error(err))
+		}
+		return nil, false
+	}
+
+	var weights map[int]float64
+	if err := json.Unmarshal(data, &weights); err != nil {
+		c.logger.Warnw("Failed to decode analytics cache", "key", key, zap.Error(err))
+		return nil, false
+	}
+	return weights, true
+}
+
+// store кладет веса в Redis на CacheTTL
+func (c *Client) store(ctx context.Context, key string, weights map[int]float64) {
+	if c.redisClient == nil || c.cfg.CacheTTL <= 0 {
+		return
+	}
+
+	data, err := json.Marshal(weights)
+	if err != nil {
+		c.logger.Warnw("Failed to encode analytics cache", "key", key, zap.Error(err))
+		return
+	}
+	if err := c.redisClient.Set(ctx, key, data, c.cfg.CacheTTL).Err(); err != nil {
+		c.logger.Warnw("Failed to write analytics cache", "key", key, zap.Error(err))
+	}
+}
@@ -0,0 +1,120 @@
+package analyticsclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	myErr "gafroshka-main/internal/types/errors"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+)
+
+// setupServer поднимает сервис аналитики, отвечающий кодами из statuses по очереди,
+// после них - 200 с весами. hits считает запросы
+func setupServer(t *testing.T, statuses ...int) (*httptest.Server, *int32) {
+	t.Helper()
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&hits, 1)
+		if int(n) <= len(statuses) {
+			w.WriteHeader(statuses[n-1])
+			return
+		}
+		assert.Equal(t, "/user/u1/weights", r.URL.Path)
+		assert.Equal(t, "10", r.URL.Query().Get("top"))
+		_, _ = w.Write([]byte(`[{"category": 3, "weight": 2.5}, {"category": 7, "weight": 1}]`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &hits
+}
+
+func newTestClient(t *testing.T, cfg Config, rdb *redis.Client) *Client {
+	t.Helper()
+	if cfg.RetryBackoff == 0 {
+		cfg.RetryBackoff = time.Millisecond
+	}
+	return NewHTTPClient(cfg, rdb, zaptest.NewLogger(t).Sugar())
+}
+
+func TestGetCategoryWeights_Cached(t *testing.T) {
+	t.Parallel()
+	srv, hits := setupServer(t)
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer mr.Close()
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+
+	c := newTestClient(t, Config{BaseURL: srv.URL, CacheTTL: time.Minute}, rdb)
+
+	for i := 0; i < 2; i++ {
+		weights, err := c.GetCategoryWeights(context.Background(), "u1", 10)
+		assert.NoError(t, err)
+		assert.Equal(t, map[int]float64{3: 2.5, 7: 1}, weights)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(hits))
+	assert.Equal(t, time.Minute, mr.TTL("analytics:weights:u1:10"))
+}
+
+func TestGetCategoryWeights_RetriesServerErrors(t *testing.T) {
+	t.Parallel()
+	srv, hits := setupServer(t, http.StatusInternalServerError, http.StatusBadGateway)
+
+	c := newTestClient(t, Config{BaseURL: srv.URL, Retries: 2}, nil)
+
+	weights, err := c.GetCategoryWeights(context.Background(), "u1", 10)
+	assert.NoError(t, err)
+	assert.Len(t, weights, 2)
+	assert.Equal(t, int32(3), atomic.LoadInt32(hits))
+}
+
+func TestGetCategoryWeights_ClientErrorNotRetried(t *testing.T) {
+	t.Parallel()
+	srv, hits := setupServer(t, http.StatusBadRequest)
+
+	c := newTestClient(t, Config{BaseURL: srv.URL, Retries: 2}, nil)
+
+	_, err := c.GetCategoryWeights(context.Background(), "u1", 10)
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, myErr.ErrAnalyticsUnavailable)
+	assert.Equal(t, int32(1), atomic.LoadInt32(hits))
+}
+
+func TestGetCategoryWeights_Timeout(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, Config{BaseURL: srv.URL, Timeout: 20 * time.Millisecond}, nil)
+
+	start := time.Now()
+	_, err := c.GetCategoryWeights(context.Background(), "u1", 10)
+	assert.ErrorIs(t, err, myErr.ErrAnalyticsUnavailable)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+}
+
+func TestGetCategoryWeights_BreakerOpens(t *testing.T) {
+	t.Parallel()
+	srv, hits := setupServer(t,
+		http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError)
+
+	c := newTestClient(t, Config{BaseURL: srv.URL, BreakerThreshold: 2, BreakerCooldown: time.Hour}, nil)
+
+	for i := 0; i < 3; i++ {
+		_, err := c.GetCategoryWeights(context.Background(), "u1", 10)
+		assert.ErrorIs(t, err, myErr.ErrAnalyticsUnavailable)
+	}
+	// третий запрос не дошел до сервиса
+	assert.Equal(t, int32(2), atomic.LoadInt32(hits))
+}
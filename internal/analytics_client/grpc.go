@@ -0,0 +1,49 @@
+package analyticsclient
+
+import (
+	"context"
+
+	"gafroshka-main/internal/analytics/analyticspb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// grpcTransport ходит в gRPC API сервиса аналитики
+type grpcTransport struct {
+	client analyticspb.AnalyticsServiceClient
+}
+
+func newGRPCTransport(conn grpc.ClientConnInterface) *grpcTransport {
+	return &grpcTransport{
+		client: analyticspb.NewAnalyticsServiceClient(conn),
+	}
+}
+
+func (t *grpcTransport) categoryWeights(ctx context.Context, userID string, limit int) (map[int]float64, error) {
+	resp, err := t.client.GetScores(ctx, &analyticspb.GetScoresRequest{
+		UserId: userID,
+		Limit:  int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	weights := make(map[int]float64, len(resp.GetScores()))
+	for _, s := range resp.GetScores() {
+		weights[int(s.GetCategory())] = s.GetWeight()
+	}
+	return weights, nil
+}
+
+// retryable - сервис недоступен, не успел ответить или упал
+func (t *grpcTransport) retryable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted,
+		codes.Aborted, codes.Internal, codes.Unknown:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,85 @@
+package analyticsclient
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+
+	"gafroshka-main/internal/analytics/analyticspb"
+	myErr "gafroshka-main/internal/types/errors"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// fakeAnalyticsServer отвечает ошибками из errs по очереди, после них - весами
+type fakeAnalyticsServer struct {
+	analyticspb.UnimplementedAnalyticsServiceServer
+	errs []error
+	hits int32
+}
+
+func (s *fakeAnalyticsServer) GetScores(
+	ctx context.Context,
+	req *analyticspb.GetScoresRequest,
+) (*analyticspb.GetScoresResponse, error) {
+	n := int(atomic.AddInt32(&s.hits, 1))
+	if n <= len(s.errs) {
+		return nil, s.errs[n-1]
+	}
+	if req.GetUserId() != "u1" || req.GetLimit() != 10 {
+		return nil, status.Error(codes.InvalidArgument, "unexpected request")
+	}
+	return &analyticspb.GetScoresResponse{Scores: []*analyticspb.CategoryScore{
+		{Category: 3, Weight: 2.5},
+		{Category: 7, Weight: 1},
+	}}, nil
+}
+
+func setupGRPCClient(t *testing.T, srv *fakeAnalyticsServer, cfg Config) *Client {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	analyticspb.RegisterAnalyticsServiceServer(s, srv)
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return NewGRPCClient(cfg, conn, nil, zaptest.NewLogger(t).Sugar())
+}
+
+func TestGRPCClient_GetCategoryWeights(t *testing.T) {
+	t.Parallel()
+	srv := &fakeAnalyticsServer{errs: []error{status.Error(codes.Unavailable, "starting")}}
+	c := setupGRPCClient(t, srv, Config{Retries: 1})
+
+	weights, err := c.GetCategoryWeights(context.Background(), "u1", 10)
+	assert.NoError(t, err)
+	assert.Equal(t, map[int]float64{3: 2.5, 7: 1}, weights)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&srv.hits))
+}
+
+func TestGRPCClient_InvalidArgumentNotRetried(t *testing.T) {
+	t.Parallel()
+	srv := &fakeAnalyticsServer{errs: []error{status.Error(codes.InvalidArgument, "user_id is required")}}
+	c := setupGRPCClient(t, srv, Config{Retries: 2})
+
+	_, err := c.GetCategoryWeights(context.Background(), "u1", 10)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.NotErrorIs(t, err, myErr.ErrAnalyticsUnavailable)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&srv.hits))
+}
@@ -0,0 +1,83 @@
+package analyticsclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// httpTransport ходит в HTTP API сервиса аналитики
+type httpTransport struct {
+	httpClient *http.Client
+	baseURL    string
+}
+
+func newHTTPTransport(baseURL string) *httpTransport {
+	return &httpTransport{
+		httpClient: &http.Client{},
+		baseURL:    baseURL,
+	}
+}
+
+// categoryWeight - элемент ответа /user/{user_id}/weights
+type categoryWeight struct {
+	Category int     `json:"category"`
+	Weight   float64 `json:"weight"`
+}
+
+func (t *httpTransport) categoryWeights(ctx context.Context, userID string, limit int) (map[int]float64, error) {
+	var resp []categoryWeight
+	path := fmt.Sprintf("/user/%s/weights?top=%d", url.PathEscape(userID), limit)
+	if err := t.get(ctx, path, &resp); err != nil {
+		return nil, err
+	}
+
+	weights := make(map[int]float64, len(resp))
+	for _, cw := range resp {
+		weights[cw.Category] = cw.Weight
+	}
+	return weights, nil
+}
+
+// statusError - ответ сервиса с неуспешным кодом
+type statusError struct {
+	code int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("analytics responded with status %d", e.code)
+}
+
+// get выполняет GET path и декодирует ответ в dst
+func (t *httpTransport) get(ctx context.Context, path string, dst interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, t.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return &statusError{code: resp.StatusCode}
+	}
+
+	return json.NewDecoder(resp.Body).Decode(dst)
+}
+
+// retryable - сетевая ошибка, таймаут или 5xx
+func (t *httpTransport) retryable(err error) bool {
+	var se *statusError
+	if errors.As(err, &se) {
+		return se.code >= http.StatusInternalServerError
+	}
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	return !errors.As(err, &syntaxErr) && !errors.As(err, &typeErr)
+}
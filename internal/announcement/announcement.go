@@ -4,8 +4,12 @@ import (
 	"time"
 
 	types "gafroshka-main/internal/types/announcement"
+	esDoc "gafroshka-main/internal/types/elastic"
 )
 
+// MaxRenewPeriod - на сколько вперед от текущего момента объявление можно продлить
+const MaxRenewPeriod = 90 * 24 * time.Hour
+
 type Announcement struct {
 	ID           string    `json:"id"`
 	Name         string    `json:"name"`
@@ -14,18 +18,53 @@ type Announcement struct {
 	Price        int64     `json:"price"`
 	Category     int       `json:"category"`
 	Discount     int       `json:"discount"`
+	Quantity     int       `json:"quantity"`
 	IsActive     bool      `json:"is_active"`
 	Rating       float64   `json:"rating"`
 	RatingCount  int       `json:"rating_count"`
 	CreatedAt    time.Time `json:"created_at"`
+	PublishAt    time.Time `json:"publish_at"`
+	ExpiresAt    time.Time `json:"expires_at"`
 	Searching    bool      `json:"searching"`
+	// FavoritesCount - сколько пользователей добавили объявление в избранное
+	FavoritesCount int `json:"favorites_count"`
+	// IsDraft - черновик: не виден в выдаче, поиске и корзине до публикации
+	IsDraft bool `json:"is_draft"`
+	// Attributes заполняется только при получении одного объявления
+	Attributes map[string]string `json:"attributes,omitempty"`
+	// Images - фотографии объявления в порядке показа
+	Images []types.Image `json:"images,omitempty"`
+}
+
+// SimilarAnnouncement - похожее объявление и причины, по которым оно предложено:
+// similar_text, same_category, similar_price
+type SimilarAnnouncement struct {
+	Announcement
+	Reasons []string `json:"reasons"`
 }
 
 //go:generate mockgen -source=announcement.go -destination=../mocks/mock_announcement_repo.go -package=mocks
 type AnnouncementRepo interface {
 	Create(a types.CreateAnnouncement) (*Announcement, error)
-	GetTopN(limit int, categories []int) ([]Announcement, error)
-	Search(query string) ([]Announcement, error)
+	// GetTopN возвращает выдачу с учетом весов категорий пользователя, пустые prefs - холодный старт
+	GetTopN(limit int, prefs map[int]float64) ([]Announcement, error)
+	Search(filter types.SearchFilter) ([]Announcement, error)
+	Facets(filter types.SearchFilter) (esDoc.Facets, error)
 	GetByID(id string) (*Announcement, error)
 	GetInfoForShoppingCart(ids []string) ([]types.InfoForSC, error)
+	UpdateAttributes(id string, attributes map[string]string) (map[string]string, error)
+	// Renew продлевает публикацию объявления, period <= 0 - срок по умолчанию
+	Renew(id string, period time.Duration) (*Announcement, error)
+	// Update частично изменяет объявление, ошибки валидации - errors.FieldErrors
+	Update(id string, u types.UpdateAnnouncement) (*Announcement, error)
+	// Publish публикует черновик после полной проверки полей
+	Publish(id string) (*Announcement, error)
+	// GetBySeller возвращает страницу объявлений продавца без черновиков и общее их число
+	GetBySeller(sellerID string, filter types.SellerFilter) ([]Announcement, int, error)
+	// GetSellerStats считает сводку по объявлениям продавца для витрины
+	GetSellerStats(sellerID string) (types.SellerStats, error)
+	// GetForCompare одним запросом возвращает объявления для сравнения, порядок не гарантируется
+	GetForCompare(ids []string) ([]types.CompareItem, error)
+	// GetSimilar подбирает активные объявления других продавцов, похожие на объявление id
+	GetSimilar(id string, limit int) ([]SimilarAnnouncement, error)
 }
@@ -0,0 +1,73 @@
+package announcement
+
+import (
+	"encoding/json"
+
+	types "gafroshka-main/internal/types/announcement"
+	"gafroshka-main/internal/types/errors"
+
+	"github.com/lib/pq"
+)
+
+// GetForCompare одним запросом достает объявления для сравнения вместе
+// с рейтингом продавца и атрибутами, черновики не возвращаются
+func (ar *AnnouncementDBRepository) GetForCompare(ids []string) ([]types.CompareItem, error) {
+	query := `
+	SELECT a.id, a.name, a.user_seller_id, COALESCE(a.category, 0), a.price, a.discount, a.is_active,
+		   a.rating, a.rating_count, u.rating, u.rating_count,
+		   COALESCE((
+			   SELECT json_object_agg(ca.code, aa.value)
+			   FROM announcement_attribute aa
+			   JOIN category_attribute ca ON ca.id = aa.attribute_id
+			   WHERE aa.announcement_id = a.id
+		   ), '{}')
+	FROM announcement a
+	JOIN users u ON u.id = a.user_seller_id
+	WHERE a.id = ANY($1) AND a.is_draft = FALSE
+	`
+
+	rows, err := ar.DB.Query(query, pq.Array(ids))
+	if err != nil {
+		ar.Logger.Errorf("Error getting announcements %v for compare: %v", ids, err)
+		return nil, errors.ErrDBInternal
+	}
+	defer rows.Close()
+
+	items := make([]types.CompareItem, 0, len(ids))
+	for rows.Next() {
+		var (
+			it         types.CompareItem
+			attributes []byte
+		)
+		err := rows.Scan(
+			&it.ID,
+			&it.Name,
+			&it.SellerID,
+			&it.Category,
+			&it.Price,
+			&it.Discount,
+			&it.IsActive,
+			&it.Rating,
+			&it.RatingCount,
+			&it.SellerRating,
+			&it.SellerRatingCount,
+			&attributes,
+		)
+		if err != nil {
+			ar.Logger.Errorf("Error scanning announcement for compare: %v", err)
+			return nil, errors.ErrDBInternal
+		}
+		if err := json.Unmarshal(attributes, &it.Attributes); err != nil {
+			ar.Logger.Errorf("Error decoding attributes of announcement %s: %v", it.ID, err)
+			return nil, errors.ErrDBInternal
+		}
+		it.EffectivePrice = types.EffectivePrice(it.Price, it.Discount)
+		items = append(items, it)
+	}
+	if err := rows.Err(); err != nil {
+		ar.Logger.Errorf("Rows iteration error: %v", err)
+		return nil, errors.ErrDBInternal
+	}
+
+	return items, nil
+}
@@ -0,0 +1,215 @@
+package announcement
+
+import (
+	"fmt"
+	"math"
+
+	"gafroshka-main/internal/types/errors"
+
+	"github.com/lib/pq"
+)
+
+const (
+	// explorationShare - доля выдачи из категорий, которых нет в предпочтениях пользователя
+	explorationShare = 0.2
+	// recencyDays - за сколько дней вклад новизны падает в e раз
+	recencyDays = 14
+	// ratingConfidence - сколько оценок нужно, чтобы рейтинг учитывался наполовину
+	ratingConfidence = 5
+)
+
+// rankColumns - поля объявления в выдаче, порядок совпадает со Scan в queryRanked
+const rankColumns = `a.id, a.name, a.description, a.user_seller_id, a.price, COALESCE(a.category, 0), a.discount, a.quantity, a.is_active, a.rating, a.rating_count, a.created_at, a.publish_at, a.expires_at, a.favorites_count`
+
+// globalScore - оценка объявления без учета пользователя:
+// популярность (избранное и отзывы, логарифм гасит лидеров), рейтинг с поправкой
+// на число оценок и экспоненциально затухающая новизна публикации
+var globalScore = fmt.Sprintf(`(
+	LN(1 + a.favorites_count + COALESCE(a.rating_count, 0))
+	+ COALESCE(a.rating, 0) / 5 * COALESCE(a.rating_count, 0) / (COALESCE(a.rating_count, 0) + %d)
+	+ EXP(-EXTRACT(EPOCH FROM NOW() - a.publish_at) / 86400 / %d)
+)`, ratingConfidence, recencyDays)
+
+// affinityWeight - насколько категория с максимальным весом пользователя важнее остальных слагаемых
+const affinityWeight = 2
+
+// GetTopN возвращает выдачу, смешанную из глобальной оценки и весов категорий пользователя.
+// Без весов (аноним или новый пользователь) выдача строится только по глобальной оценке,
+// иначе explorationShare мест отдается лучшим объявлениям из других категорий
+func (ar *AnnouncementDBRepository) GetTopN(limit int, prefs map[int]float64) ([]Announcement, error) {
+	expanded, err := ar.expandPrefs(prefs)
+	if err != nil {
+		return nil, err
+	}
+	categories, weights := normalizePrefs(expanded)
+
+	personal, err := ar.queryRanked(fmt.Sprintf(`
+		WITH prefs AS (
+			SELECT * FROM unnest($1::int[], $2::float8[]) AS p(category, weight)
+		)
+		SELECT %s
+		FROM announcement a
+		LEFT JOIN prefs p ON p.category = a.category
+		WHERE a.is_active = TRUE
+		ORDER BY %s + %d * COALESCE(p.weight, 0) DESC, a.id
+		LIMIT $3
+	`, rankColumns, globalScore, affinityWeight), pq.Array(categories), pq.Array(weights), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	exploreCount := int(math.Ceil(float64(limit) * explorationShare))
+	if len(categories) == 0 || exploreCount == 0 {
+		if err = ar.attachImages(personal); err != nil {
+			return nil, err
+		}
+		return personal, nil
+	}
+
+	explore, err := ar.queryRanked(fmt.Sprintf(`
+		SELECT %s
+		FROM announcement a
+		WHERE a.is_active = TRUE AND NOT COALESCE(a.category = ANY($1), FALSE)
+		ORDER BY %s DESC, a.id
+		LIMIT $2
+	`, rankColumns, globalScore), pq.Array(categories), exploreCount)
+	if err != nil {
+		return nil, err
+	}
+
+	result := blendExploration(personal, explore, limit)
+	if err = ar.attachImages(result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// expandPrefs переносит вес родительской категории на все дочерние: интерес к "Электронике"
+// поднимает и объявления из "Телефонов". Если категория попала в дерево нескольких
+// предпочтений, берется наибольший вес
+func (ar *AnnouncementDBRepository) expandPrefs(prefs map[int]float64) (map[int]float64, error) {
+	expanded := make(map[int]float64, len(prefs))
+	for category, w := range prefs {
+		if w <= 0 {
+			continue
+		}
+
+		ids, err := ar.CategoryRepo.ExpandWithDescendants([]int{category})
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			expanded[id] = math.Max(expanded[id], w)
+		}
+	}
+
+	return expanded, nil
+}
+
+// normalizePrefs делит веса на максимальный, чтобы вклад предпочтений
+// не зависел от того, сколько событий накопил пользователь
+func normalizePrefs(prefs map[int]float64) ([]int, []float64) {
+	var maxWeight float64
+	for _, w := range prefs {
+		maxWeight = math.Max(maxWeight, w)
+	}
+	if maxWeight <= 0 {
+		return nil, nil
+	}
+
+	categories := make([]int, 0, len(prefs))
+	weights := make([]float64, 0, len(prefs))
+	for category, w := range prefs {
+		if w <= 0 {
+			continue
+		}
+		categories = append(categories, category)
+		weights = append(weights, w/maxWeight)
+	}
+
+	return categories, weights
+}
+
+// blendExploration ставит объявления из explore на каждое 1/explorationShare-е место выдачи,
+// остальные места занимает personal. Если один из списков кончился, выдачу добирает другой
+func blendExploration(personal, explore []Announcement, limit int) []Announcement {
+	step := int(math.Round(1 / explorationShare))
+	result := make([]Announcement, 0, limit)
+	seen := make(map[string]struct{}, limit)
+
+	take := func(list []Announcement) ([]Announcement, bool) {
+		for len(list) > 0 {
+			a := list[0]
+			list = list[1:]
+			if _, ok := seen[a.ID]; ok {
+				continue
+			}
+			seen[a.ID] = struct{}{}
+			result = append(result, a)
+			return list, true
+		}
+		return list, false
+	}
+
+	for len(result) < limit {
+		var ok bool
+		if (len(result)+1)%step == 0 {
+			if explore, ok = take(explore); !ok {
+				personal, ok = take(personal)
+			}
+		} else {
+			if personal, ok = take(personal); !ok {
+				explore, ok = take(explore)
+			}
+		}
+		if !ok {
+			break
+		}
+	}
+
+	return result
+}
+
+// queryRanked выполняет запрос выдачи и сканирует объявления в порядке ранжирования
+func (ar *AnnouncementDBRepository) queryRanked(query string, args ...interface{}) ([]Announcement, error) {
+	rows, err := ar.DB.Query(query, args...)
+	if err != nil {
+		ar.Logger.Errorf("Error getting ranked announcements: %v", err)
+		return nil, errors.ErrDBInternal
+	}
+	defer rows.Close()
+
+	var announcements []Announcement
+	for rows.Next() {
+		var a Announcement
+		err := rows.Scan(
+			&a.ID,
+			&a.Name,
+			&a.Description,
+			&a.UserSellerID,
+			&a.Price,
+			&a.Category,
+			&a.Discount,
+			&a.Quantity,
+			&a.IsActive,
+			&a.Rating,
+			&a.RatingCount,
+			&a.CreatedAt,
+			&a.PublishAt,
+			&a.ExpiresAt,
+			&a.FavoritesCount,
+		)
+		if err != nil {
+			ar.Logger.Errorf("Error scanning ranked announcement: %v", err)
+			return nil, errors.ErrDBInternal
+		}
+		announcements = append(announcements, a)
+	}
+	if err := rows.Err(); err != nil {
+		ar.Logger.Errorf("Rows iteration error: %v", err)
+		return nil, errors.ErrDBInternal
+	}
+
+	return announcements, nil
+}
@@ -0,0 +1,91 @@
+package announcement
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ids(anns []Announcement) []string {
+	result := make([]string, len(anns))
+	for i, a := range anns {
+		result[i] = a.ID
+	}
+	return result
+}
+
+func anns(ids ...string) []Announcement {
+	result := make([]Announcement, len(ids))
+	for i, id := range ids {
+		result[i] = Announcement{ID: id}
+	}
+	return result
+}
+
+func TestBlendExploration(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		personal []Announcement
+		explore  []Announcement
+		limit    int
+		expected []string
+	}{
+		{
+			name:     "каждое пятое место - другая категория",
+			personal: anns("p1", "p2", "p3", "p4", "p5", "p6", "p7", "p8", "p9", "p10"),
+			explore:  anns("e1", "e2"),
+			limit:    10,
+			expected: []string{"p1", "p2", "p3", "p4", "e1", "p5", "p6", "p7", "p8", "e2"},
+		},
+		{
+			name:     "нехватку персональных добирает исследование",
+			personal: anns("p1"),
+			explore:  anns("e1", "e2"),
+			limit:    5,
+			expected: []string{"p1", "e1", "e2"},
+		},
+		{
+			name:     "дубликаты пропускаются",
+			personal: anns("p1", "p2", "p3", "p4", "e1", "p5"),
+			explore:  anns("e1"),
+			limit:    6,
+			expected: []string{"p1", "p2", "p3", "p4", "e1", "p5"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ids(blendExploration(tt.personal, tt.explore, tt.limit)))
+		})
+	}
+}
+
+func TestNormalizePrefs(t *testing.T) {
+	t.Parallel()
+
+	categories, weights := normalizePrefs(map[int]float64{3: 8, 5: 2, 7: 0})
+	got := make(map[int]float64, len(categories))
+	for i, c := range categories {
+		got[c] = weights[i]
+	}
+	assert.Equal(t, map[int]float64{3: 1, 5: 0.25}, got)
+
+	categories, weights = normalizePrefs(nil)
+	assert.Nil(t, categories)
+	assert.Nil(t, weights)
+}
+
+func TestExpandPrefs(t *testing.T) {
+	t.Parallel()
+	ar := &AnnouncementDBRepository{CategoryRepo: &fakeCategoryRepo{children: map[int][]int{
+		1: {2, 3},
+		3: {4},
+	}}}
+
+	// вес родителя достается всем потомкам, у пересечений берется наибольший
+	expanded, err := ar.expandPrefs(map[int]float64{1: 2, 3: 5, 7: 1, 8: 0})
+	require.NoError(t, err)
+	assert.Equal(t, map[int]float64{1: 2, 2: 2, 3: 5, 4: 5, 7: 1}, expanded)
+}
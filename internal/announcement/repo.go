@@ -5,12 +5,14 @@ import (
 	"database/sql"
 	"fmt"
 	"strings"
+	"time"
 
+	announcementimage "gafroshka-main/internal/announcement_image"
+	"gafroshka-main/internal/category"
 	elastic "gafroshka-main/internal/elastic_search"
 
-	"github.com/lib/pq"
-
 	types "gafroshka-main/internal/types/announcement"
+	esDoc "gafroshka-main/internal/types/elastic"
 	"gafroshka-main/internal/types/errors"
 
 	"go.uber.org/zap"
@@ -20,19 +22,67 @@ type AnnouncementDBRepository struct {
 	DB             *sql.DB
 	Logger         *zap.SugaredLogger
 	ElasticService *elastic.ElasticService
+	CategoryRepo   category.CategoryRepo
+	ImageRepo      announcementimage.ImageRepo
+	// Lifetime - срок публикации объявления, он же срок продления по умолчанию
+	Lifetime time.Duration
 }
 
-func NewAnnouncementDBRepository(db *sql.DB, l *zap.SugaredLogger, es *elastic.ElasticService) *AnnouncementDBRepository {
+func NewAnnouncementDBRepository(
+	db *sql.DB,
+	l *zap.SugaredLogger,
+	es *elastic.ElasticService,
+	cr category.CategoryRepo,
+	ir announcementimage.ImageRepo,
+	lifetime time.Duration,
+) *AnnouncementDBRepository {
 	return &AnnouncementDBRepository{
 		DB:             db,
 		Logger:         l,
 		ElasticService: es,
+		CategoryRepo:   cr,
+		ImageRepo:      ir,
+		Lifetime:       lifetime,
 	}
 }
 
 func (ar *AnnouncementDBRepository) Create(a types.CreateAnnouncement) (*Announcement, error) {
 	var newAnn Announcement
 
+	// по умолчанию объявление - один товар
+	quantity := a.Quantity
+	if quantity == 0 {
+		quantity = 1
+	}
+
+	schema, attributes, err := ar.validate(fields{
+		Name:       a.Name,
+		Price:      a.Price,
+		Category:   a.Category,
+		Discount:   a.Discount,
+		Quantity:   quantity,
+		Attributes: a.Attributes,
+	}, a.Draft)
+	if err != nil {
+		return nil, err
+	}
+
+	// объявление с publish_at в будущем создается неопубликованным, его активирует планировщик
+	// черновик не публикуется, пока его не опубликуют явно
+	now := time.Now()
+	publishAt := now
+	if a.PublishAt != nil && a.PublishAt.After(now) {
+		publishAt = *a.PublishAt
+	}
+	published := !a.Draft && !publishAt.After(now)
+
+	tx, err := ar.DB.Begin()
+	if err != nil {
+		ar.Logger.Errorf("Error starting transaction: %v", err)
+		return nil, errors.ErrDBInternal
+	}
+	defer tx.Rollback()
+
 	query := `
 	INSERT INTO announcement (
 		name, 
@@ -40,19 +90,30 @@ func (ar *AnnouncementDBRepository) Create(a types.CreateAnnouncement) (*Announc
 		user_seller_id, 
 		price, 
 		category, 
-		discount
-	) VALUES ($1, $2, $3, $4, $5, $6)
-	RETURNING id, name, description, user_seller_id, price, category, discount, is_active, rating, rating_count, created_at
+		discount,
+		quantity,
+		publish_at,
+		expires_at,
+		published,
+		is_active,
+		is_draft
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $10, $11)
+	RETURNING id, name, description, user_seller_id, price, COALESCE(category, 0), discount, quantity, is_active, rating, rating_count, created_at, publish_at, expires_at, is_draft, favorites_count
 	`
 
-	err := ar.DB.QueryRow(
+	err = tx.QueryRow(
 		query,
 		a.Name,
 		a.Description,
 		a.UserSellerID,
 		a.Price,
-		a.Category,
+		nullableCategory(a.Category),
 		a.Discount,
+		quantity,
+		publishAt,
+		publishAt.Add(ar.Lifetime),
+		published,
+		a.Draft,
 	).Scan(
 		&newAnn.ID,
 		&newAnn.Name,
@@ -61,10 +122,15 @@ func (ar *AnnouncementDBRepository) Create(a types.CreateAnnouncement) (*Announc
 		&newAnn.Price,
 		&newAnn.Category,
 		&newAnn.Discount,
+		&newAnn.Quantity,
 		&newAnn.IsActive,
 		&newAnn.Rating,
 		&newAnn.RatingCount,
 		&newAnn.CreatedAt,
+		&newAnn.PublishAt,
+		&newAnn.ExpiresAt,
+		&newAnn.IsDraft,
+		&newAnn.FavoritesCount,
 	)
 
 	if err != nil {
@@ -72,69 +138,189 @@ func (ar *AnnouncementDBRepository) Create(a types.CreateAnnouncement) (*Announc
 		return nil, errors.ErrDBInternal
 	}
 
+	if err = ar.insertAttributes(tx, newAnn.ID, schema, attributes); err != nil {
+		return nil, err
+	}
+
+	// начальная цена - точка отсчета истории, о ней никого не уведомляем
+	if err = ar.insertPriceHistory(tx, newAnn.ID, newAnn.Price, newAnn.Discount, true); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		ar.Logger.Errorf("Error committing announcement: %v", err)
+		return nil, errors.ErrDBInternal
+	}
+
+	if len(attributes) > 0 {
+		newAnn.Attributes = attributes
+	}
+
 	return &newAnn, nil
 }
 
-func (ar *AnnouncementDBRepository) GetTopN(limit int, categories []int) ([]Announcement, error) {
-	var (
-		query string
-		args  []interface{}
-	)
+// insertAttributes записывает провалидированные значения атрибутов объявления
+func (ar *AnnouncementDBRepository) insertAttributes(
+	tx *sql.Tx,
+	announcementID string,
+	schema []category.Attribute,
+	attributes map[string]string,
+) error {
+	for _, attr := range schema {
+		value, ok := attributes[attr.Code]
+		if !ok {
+			continue
+		}
 
-	if len(categories) > 0 {
-		query = `
-			SELECT id, name, description, user_seller_id, price, category, discount, is_active, rating, rating_count, created_at
-			FROM announcement
-			WHERE is_active = TRUE AND category = ANY($1)
-			ORDER BY rating DESC, rating_count DESC
-			LIMIT $2
-		`
-		args = append(args, pq.Array(categories), limit)
-	} else {
-		query = `
-			SELECT id, name, description, user_seller_id, price, category, discount, is_active, rating, rating_count, created_at
-			FROM announcement
-			WHERE is_active = TRUE
-			ORDER BY rating DESC, rating_count DESC
-			LIMIT $1
-		`
-		args = append(args, limit)
+		_, err := tx.Exec(`
+		INSERT INTO announcement_attribute (announcement_id, attribute_id, value)
+		VALUES ($1, $2, $3)
+		`, announcementID, attr.ID, value)
+		if err != nil {
+			ar.Logger.Errorf("Error inserting attribute %s of announcement %s: %v", attr.Code, announcementID, err)
+			return errors.ErrDBInternal
+		}
 	}
 
-	rows, err := ar.DB.Query(query, args...)
+	return nil
+}
+
+// insertPriceHistory записывает цену объявления в историю
+// Необработанные (processed = FALSE) записи разбирает pricewatch.Notifier
+func (ar *AnnouncementDBRepository) insertPriceHistory(tx *sql.Tx, announcementID string, price int64, discount int, processed bool) error {
+	_, err := tx.Exec(`
+	INSERT INTO price_history (announcement_id, price, discount, effective_price, processed)
+	VALUES ($1, $2, $3, $4, $5)
+	`, announcementID, price, discount, types.EffectivePrice(price, discount), processed)
+	if err != nil {
+		ar.Logger.Errorf("Error writing price history of announcement %s: %v", announcementID, err)
+		return errors.ErrDBInternal
+	}
+
+	return nil
+}
+
+// getAttributes возвращает значения атрибутов объявления
+func (ar *AnnouncementDBRepository) getAttributes(announcementID string) (map[string]string, error) {
+	query := `
+	SELECT ca.code, aa.value
+	FROM announcement_attribute aa
+	JOIN category_attribute ca ON ca.id = aa.attribute_id
+	WHERE aa.announcement_id = $1
+	`
+	rows, err := ar.DB.Query(query, announcementID)
 	if err != nil {
-		ar.Logger.Errorf("Error getting top %d announcements: %v", limit, err)
+		ar.Logger.Errorf("Error getting attributes of announcement %s: %v", announcementID, err)
 		return nil, errors.ErrDBInternal
 	}
 	defer rows.Close()
 
-	var announcements []Announcement
+	attributes := make(map[string]string)
 	for rows.Next() {
-		var a Announcement
-		err := rows.Scan(
-			&a.ID,
-			&a.Name,
-			&a.Description,
-			&a.UserSellerID,
-			&a.Price,
-			&a.Category,
-			&a.Discount,
-			&a.IsActive,
-			&a.Rating,
-			&a.RatingCount,
-			&a.CreatedAt,
-		)
-		if err != nil {
+		var code, value string
+		if err := rows.Scan(&code, &value); err != nil {
+			ar.Logger.Errorf("Error scanning attribute: %v", err)
 			return nil, errors.ErrDBInternal
 		}
-		announcements = append(announcements, a)
+		attributes[code] = value
+	}
+
+	if err := rows.Err(); err != nil {
+		ar.Logger.Errorf("Rows iteration error: %v", err)
+		return nil, errors.ErrDBInternal
+	}
+
+	return attributes, nil
+}
+
+// UpdateAttributes заменяет значения атрибутов объявления провалидированными
+// и снимает отметку об индексации, чтобы ETL переиндексировал документ
+func (ar *AnnouncementDBRepository) UpdateAttributes(id string, attributes map[string]string) (map[string]string, error) {
+	ann, err := ar.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := ar.CategoryRepo.GetAttributes(ann.Category)
+	if err != nil {
+		return nil, err
+	}
+	validated, err := category.ValidateAttributes(schema, attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := ar.DB.Begin()
+	if err != nil {
+		ar.Logger.Errorf("Error starting transaction: %v", err)
+		return nil, errors.ErrDBInternal
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec(`DELETE FROM announcement_attribute WHERE announcement_id = $1`, id); err != nil {
+		ar.Logger.Errorf("Error deleting attributes of announcement %s: %v", id, err)
+		return nil, errors.ErrDBInternal
+	}
+
+	if err = ar.insertAttributes(tx, id, schema, validated); err != nil {
+		return nil, err
+	}
+
+	if _, err = tx.Exec(`UPDATE announcement SET searching = FALSE, updated_at = NOW() WHERE id = $1`, id); err != nil {
+		ar.Logger.Errorf("Error resetting search flag of announcement %s: %v", id, err)
+		return nil, errors.ErrDBInternal
+	}
+
+	if err = tx.Commit(); err != nil {
+		ar.Logger.Errorf("Error committing attributes: %v", err)
+		return nil, errors.ErrDBInternal
+	}
+
+	return validated, nil
+}
+
+// searchFilter переводит фильтр поиска в фильтр ES, раскрывая категорию до всех потомков
+func (ar *AnnouncementDBRepository) searchFilter(filter types.SearchFilter) (esDoc.SearchFilter, error) {
+	var esFilter esDoc.SearchFilter
+
+	if len(filter.Attributes) > 0 {
+		// тип атрибута задает категория, без нее значение нельзя привести к индексируемому виду
+		if filter.CategoryID <= 0 {
+			return esFilter, fmt.Errorf("%w: attribute filters require category", errors.ErrInvalidAttribute)
+		}
+		schema, err := ar.CategoryRepo.GetAttributes(filter.CategoryID)
+		if err != nil {
+			return esFilter, err
+		}
+		// значения нормализуются так же, как при сохранении, иначе 16.0 не найдет проиндексированное 16
+		attributes, err := category.ValidateAttributeValues(schema, filter.Attributes)
+		if err != nil {
+			return esFilter, err
+		}
+		esFilter.Attributes = attributes
 	}
 
-	return announcements, nil
+	if filter.CategoryID > 0 {
+		expanded, err := ar.CategoryRepo.ExpandWithDescendants([]int{filter.CategoryID})
+		if err != nil {
+			return esFilter, err
+		}
+		if len(expanded) == 0 {
+			return esFilter, errors.ErrUnknownCategory
+		}
+		esFilter.Categories = expanded
+	}
+
+	return esFilter, nil
 }
 
-func (ar *AnnouncementDBRepository) Search(query string) ([]Announcement, error) {
-	docs, err := ar.ElasticService.SearchByName(context.Background(), query)
+func (ar *AnnouncementDBRepository) Search(filter types.SearchFilter) ([]Announcement, error) {
+	esFilter, err := ar.searchFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	docs, err := ar.ElasticService.SearchByName(context.Background(), filter.Query, esFilter)
 	if err != nil {
 		ar.Logger.Errorf("Elastic search error: %v", err)
 		return nil, errors.ErrSearch
@@ -166,12 +352,16 @@ func (ar *AnnouncementDBRepository) Search(query string) ([]Announcement, error)
 		    price, 
 		    category, 
 		    discount, 
+		    quantity, 
 		    is_active, 
 		    rating, 
 		    rating_count, 
-		    created_at
+		    created_at,
+		    publish_at,
+		    expires_at,
+		    favorites_count
 		FROM announcement
-		WHERE id IN (%s)
+		WHERE id IN (%s) AND is_draft = FALSE
 	`,
 		strings.Join(placeholders, ","),
 	)
@@ -195,10 +385,14 @@ func (ar *AnnouncementDBRepository) Search(query string) ([]Announcement, error)
 			&a.Price,
 			&a.Category,
 			&a.Discount,
+			&a.Quantity,
 			&a.IsActive,
 			&a.Rating,
 			&a.RatingCount,
 			&a.CreatedAt,
+			&a.PublishAt,
+			&a.ExpiresAt,
+			&a.FavoritesCount,
 		); err != nil {
 			ar.Logger.Errorf("Row scan failed: %v", err)
 			return nil, errors.ErrDBInternal
@@ -218,14 +412,51 @@ func (ar *AnnouncementDBRepository) Search(query string) ([]Announcement, error)
 		}
 	}
 
+	if err = ar.attachImages(result); err != nil {
+		return nil, err
+	}
+
 	return result, nil
 }
 
+// Facets возвращает распределение значений атрибутов категории среди найденных объявлений
+func (ar *AnnouncementDBRepository) Facets(filter types.SearchFilter) (esDoc.Facets, error) {
+	if filter.CategoryID <= 0 {
+		return nil, errors.ErrUnknownCategory
+	}
+
+	esFilter, err := ar.searchFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := ar.CategoryRepo.GetAttributes(filter.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+	if len(schema) == 0 {
+		return esDoc.Facets{}, nil
+	}
+
+	codes := make([]string, 0, len(schema))
+	for _, attr := range schema {
+		codes = append(codes, attr.Code)
+	}
+
+	facets, err := ar.ElasticService.AttributeFacets(context.Background(), filter.Query, esFilter, codes)
+	if err != nil {
+		ar.Logger.Errorf("Elastic facets error: %v", err)
+		return nil, errors.ErrSearch
+	}
+
+	return facets, nil
+}
+
 func (ar *AnnouncementDBRepository) GetByID(id string) (*Announcement, error) {
 	var a Announcement
 
 	query := `
-	SELECT id, name, description, user_seller_id, price, category, discount, is_active, rating, rating_count, created_at 
+	SELECT id, name, description, user_seller_id, price, COALESCE(category, 0), discount, quantity, is_active, rating, rating_count, created_at, publish_at, expires_at, is_draft, favorites_count
 	FROM announcement 
 	WHERE id = $1
 	`
@@ -238,10 +469,15 @@ func (ar *AnnouncementDBRepository) GetByID(id string) (*Announcement, error) {
 		&a.Price,
 		&a.Category,
 		&a.Discount,
+		&a.Quantity,
 		&a.IsActive,
 		&a.Rating,
 		&a.RatingCount,
 		&a.CreatedAt,
+		&a.PublishAt,
+		&a.ExpiresAt,
+		&a.IsDraft,
+		&a.FavoritesCount,
 	)
 
 	if err != nil {
@@ -252,9 +488,46 @@ func (ar *AnnouncementDBRepository) GetByID(id string) (*Announcement, error) {
 		return nil, errors.ErrDBInternal
 	}
 
+	attributes, err := ar.getAttributes(a.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(attributes) > 0 {
+		a.Attributes = attributes
+	}
+
+	images, err := ar.ImageRepo.GetByAnnouncementIDs([]string{a.ID})
+	if err != nil {
+		return nil, err
+	}
+	a.Images = images[a.ID]
+
 	return &a, nil
 }
 
+// attachImages подгружает фотографии для списка объявлений одним запросом
+func (ar *AnnouncementDBRepository) attachImages(announcements []Announcement) error {
+	if len(announcements) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(announcements))
+	for i, a := range announcements {
+		ids[i] = a.ID
+	}
+
+	images, err := ar.ImageRepo.GetByAnnouncementIDs(ids)
+	if err != nil {
+		return err
+	}
+
+	for i := range announcements {
+		announcements[i].Images = images[announcements[i].ID]
+	}
+
+	return nil
+}
+
 func (ar *AnnouncementDBRepository) GetInfoForShoppingCart(ids []string) ([]types.InfoForSC, error) {
 	if len(ids) == 0 {
 		// Если нет id, сразу возвращаем пустой слайс
@@ -270,9 +543,9 @@ func (ar *AnnouncementDBRepository) GetInfoForShoppingCart(ids []string) ([]type
 	}
 
 	query := `
-	SELECT id, name, price, discount, is_active, rating
+	SELECT id, name, price, discount, quantity, is_active, rating
 	FROM announcement
-	WHERE id IN (` + strings.Join(placeholders, ",") + `)
+	WHERE id IN (` + strings.Join(placeholders, ",") + `) AND is_draft = FALSE
 	`
 
 	rows, err := ar.DB.Query(query, args...)
@@ -290,6 +563,7 @@ func (ar *AnnouncementDBRepository) GetInfoForShoppingCart(ids []string) ([]type
 			&info.Name,
 			&info.Price,
 			&info.Discount,
+			&info.Quantity,
 			&info.IsActive,
 			&info.Rating,
 		); err != nil {
@@ -299,5 +573,211 @@ func (ar *AnnouncementDBRepository) GetInfoForShoppingCart(ids []string) ([]type
 		infos = append(infos, info)
 	}
 
+	images, err := ar.ImageRepo.GetByAnnouncementIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range infos {
+		infos[i].Images = images[infos[i].ID]
+	}
+
 	return infos, nil
 }
+
+// Renew продлевает публикацию объявления на period (по умолчанию на Lifetime)
+// Срок отсчитывается от текущего expires_at, а если он уже прошел - от текущего момента,
+// но не дальше MaxRenewPeriod от текущего момента, иначе повторные продления копились бы без предела
+// Снятое по сроку объявление снова становится активным и попадает в поиск
+func (ar *AnnouncementDBRepository) Renew(id string, period time.Duration) (*Announcement, error) {
+	if period <= 0 {
+		period = ar.Lifetime
+	}
+	period = min(period, MaxRenewPeriod)
+
+	query := `
+	UPDATE announcement
+	SET expires_at = LEAST(GREATEST(expires_at, NOW()) + $2 * INTERVAL '1 second', NOW() + $3 * INTERVAL '1 second'),
+		expiry_notified = FALSE,
+		is_active = published AND quantity > 0,
+		searching = CASE WHEN is_active THEN searching ELSE FALSE END,
+		updated_at = NOW()
+	WHERE id = $1
+	`
+	res, err := ar.DB.Exec(query, id, int64(period.Seconds()), int64(MaxRenewPeriod.Seconds()))
+	if err != nil {
+		ar.Logger.Errorf("Error renewing announcement %s: %v", id, err)
+		return nil, errors.ErrDBInternal
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		ar.Logger.Errorf("Error getting affected rows: %v", err)
+		return nil, errors.ErrDBInternal
+	}
+	if affected == 0 {
+		return nil, errors.ErrNotFound
+	}
+
+	return ar.GetByID(id)
+}
+
+// Update частично изменяет объявление: незаданные поля остаются прежними
+// Черновик проверяется мягко, опубликованное объявление - как при публикации
+// Активность пересчитывается по остатку, активное объявление переиндексируется
+func (ar *AnnouncementDBRepository) Update(id string, u types.UpdateAnnouncement) (*Announcement, error) {
+	ann, err := ar.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.PublishAt != nil && !ann.IsDraft {
+		return nil, errors.FieldErrors{"publish_at": "can be changed only for a draft"}
+	}
+
+	f := fieldsOf(ann)
+	description := ann.Description
+	publishAt := ann.PublishAt
+	if u.Name != nil {
+		f.Name = *u.Name
+	}
+	if u.Description != nil {
+		description = *u.Description
+	}
+	if u.Price != nil {
+		f.Price = *u.Price
+	}
+	if u.Category != nil {
+		f.Category = *u.Category
+	}
+	if u.Discount != nil {
+		f.Discount = *u.Discount
+	}
+	if u.Quantity != nil {
+		f.Quantity = *u.Quantity
+	}
+	if u.PublishAt != nil {
+		publishAt = *u.PublishAt
+	}
+	if u.Attributes != nil {
+		f.Attributes = u.Attributes
+	}
+
+	schema, attributes, err := ar.validate(f, ann.IsDraft)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := ar.DB.Begin()
+	if err != nil {
+		ar.Logger.Errorf("Error starting transaction: %v", err)
+		return nil, errors.ErrDBInternal
+	}
+	defer tx.Rollback()
+
+	// searching сбрасывается только у объявления, которое остается активным,
+	// снятое с продажи планировщик уберет из поиска по старой отметке
+	query := `
+	UPDATE announcement
+	SET name = $2,
+		description = $3,
+		price = $4,
+		category = $5,
+		discount = $6,
+		quantity = $7,
+		publish_at = $8,
+		is_active = published AND $7 > 0 AND expires_at > NOW(),
+		searching = searching AND NOT (published AND $7 > 0 AND expires_at > NOW()),
+		updated_at = NOW()
+	WHERE id = $1
+	`
+	_, err = tx.Exec(
+		query,
+		id,
+		f.Name,
+		description,
+		f.Price,
+		nullableCategory(f.Category),
+		f.Discount,
+		f.Quantity,
+		publishAt,
+	)
+	if err != nil {
+		ar.Logger.Errorf("Error updating announcement %s: %v", id, err)
+		return nil, errors.ErrDBInternal
+	}
+
+	if _, err = tx.Exec(`DELETE FROM announcement_attribute WHERE announcement_id = $1`, id); err != nil {
+		ar.Logger.Errorf("Error deleting attributes of announcement %s: %v", id, err)
+		return nil, errors.ErrDBInternal
+	}
+	if err = ar.insertAttributes(tx, id, schema, attributes); err != nil {
+		return nil, err
+	}
+
+	if f.Price != ann.Price || f.Discount != ann.Discount {
+		if err = ar.insertPriceHistory(tx, id, f.Price, f.Discount, false); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		ar.Logger.Errorf("Error committing announcement update: %v", err)
+		return nil, errors.ErrDBInternal
+	}
+
+	return ar.GetByID(id)
+}
+
+// Publish проверяет черновик как полноценное объявление и публикует его
+// Если publish_at черновика в будущем, объявление ждет планировщика, иначе публикуется сразу
+// Срок публикации отсчитывается от момента публикации
+func (ar *AnnouncementDBRepository) Publish(id string) (*Announcement, error) {
+	ann, err := ar.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !ann.IsDraft {
+		return nil, errors.ErrNotDraft
+	}
+
+	if _, _, err = ar.validate(fieldsOf(ann), false); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	publishAt := ann.PublishAt
+	if publishAt.Before(now) {
+		publishAt = now
+	}
+	published := !publishAt.After(now)
+
+	query := `
+	UPDATE announcement
+	SET is_draft = FALSE,
+		published = $2,
+		is_active = $2 AND quantity > 0,
+		publish_at = $3,
+		expires_at = $4,
+		expiry_notified = FALSE,
+		searching = FALSE,
+		updated_at = NOW()
+	WHERE id = $1 AND is_draft = TRUE
+	`
+	res, err := ar.DB.Exec(query, id, published, publishAt, publishAt.Add(ar.Lifetime))
+	if err != nil {
+		ar.Logger.Errorf("Error publishing announcement %s: %v", id, err)
+		return nil, errors.ErrDBInternal
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		ar.Logger.Errorf("Error getting affected rows: %v", err)
+		return nil, errors.ErrDBInternal
+	}
+	if affected == 0 {
+		// черновик успели опубликовать параллельным запросом
+		return nil, errors.ErrNotDraft
+	}
+
+	return ar.GetByID(id)
+}
@@ -0,0 +1,99 @@
+package announcement
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"gafroshka-main/internal/category"
+	types "gafroshka-main/internal/types/announcement"
+	myErr "gafroshka-main/internal/types/errors"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// fakeCategoryRepo отдает одну схему атрибутов для любой категории
+// и потомков категорий из children
+type fakeCategoryRepo struct {
+	attributes []category.Attribute
+	children   map[int][]int
+}
+
+func (f *fakeCategoryRepo) GetAll() ([]category.Category, error)       { return nil, nil }
+func (f *fakeCategoryRepo) GetByID(id int) (*category.Category, error) { return nil, nil }
+func (f *fakeCategoryRepo) Exists(id int) (bool, error)                { return true, nil }
+func (f *fakeCategoryRepo) ExpandWithDescendants(ids []int) ([]int, error) {
+	result := append([]int(nil), ids...)
+	for i := 0; i < len(result); i++ {
+		result = append(result, f.children[result[i]]...)
+	}
+	return result, nil
+}
+func (f *fakeCategoryRepo) GetAttributes(categoryID int) ([]category.Attribute, error) {
+	return f.attributes, nil
+}
+
+func TestSearchFilter_NormalizesAttributes(t *testing.T) {
+	t.Parallel()
+	ar := &AnnouncementDBRepository{CategoryRepo: &fakeCategoryRepo{attributes: []category.Attribute{
+		{Code: "memory", Type: category.AttributeNumber},
+		{Code: "nfc", Type: category.AttributeBool},
+		{Code: "brand", Type: category.AttributeString},
+	}}}
+
+	esFilter, err := ar.searchFilter(types.SearchFilter{
+		CategoryID: 3,
+		Attributes: map[string]string{"memory": "16.0", "nfc": "1", "brand": "Samsung"},
+	})
+
+	require.NoError(t, err)
+	// так же ValidateAttributes сохраняет значения, которые потом индексируются
+	assert.Equal(t, map[string]string{"memory": "16", "nfc": "true", "brand": "Samsung"}, esFilter.Attributes)
+	assert.Equal(t, []int{3}, esFilter.Categories)
+}
+
+func TestSearchFilter_InvalidAttributes(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		filter types.SearchFilter
+	}{
+		{name: "не число", filter: types.SearchFilter{CategoryID: 3, Attributes: map[string]string{"memory": "много"}}},
+		{name: "не bool", filter: types.SearchFilter{CategoryID: 3, Attributes: map[string]string{"nfc": "да"}}},
+		{name: "без категории", filter: types.SearchFilter{Attributes: map[string]string{"memory": "16"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ar := &AnnouncementDBRepository{CategoryRepo: &fakeCategoryRepo{attributes: []category.Attribute{
+				{Code: "memory", Type: category.AttributeNumber},
+				{Code: "nfc", Type: category.AttributeBool},
+			}}}
+
+			_, err := ar.searchFilter(tt.filter)
+			assert.ErrorIs(t, err, myErr.ErrInvalidAttribute)
+		})
+	}
+}
+
+func TestRenew_PeriodCappedByMax(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ar := &AnnouncementDBRepository{DB: db, Logger: zaptest.NewLogger(t).Sugar(), Lifetime: 30 * 24 * time.Hour}
+
+	// срок дольше максимального урезается до MaxRenewPeriod
+	maxSeconds := int64(MaxRenewPeriod.Seconds())
+	mock.ExpectExec(regexp.QuoteMeta("updated_at = NOW()")).
+		WithArgs("ann-1", maxSeconds, maxSeconds).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	_, err = ar.Renew("ann-1", 2*MaxRenewPeriod)
+	assert.ErrorIs(t, err, myErr.ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
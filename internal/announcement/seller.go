@@ -0,0 +1,122 @@
+package announcement
+
+import (
+	"fmt"
+
+	types "gafroshka-main/internal/types/announcement"
+	"gafroshka-main/internal/types/errors"
+)
+
+// sellerStatusConditions - условия выборки по статусу,
+// черновики и объявления, запланированные к публикации, в витрину не попадают
+var sellerStatusConditions = map[string]string{
+	types.SellerStatusAll:    "is_draft = FALSE AND published = TRUE",
+	types.SellerStatusActive: "is_draft = FALSE AND published = TRUE AND is_active = TRUE",
+	types.SellerStatusSold:   "is_draft = FALSE AND published = TRUE AND quantity = 0",
+}
+
+// sellerSortOrders - допустимые сортировки, id в конце делает пагинацию стабильной
+var sellerSortOrders = map[string]string{
+	types.SellerSortNewest:    "created_at DESC, id",
+	types.SellerSortOldest:    "created_at ASC, id",
+	types.SellerSortPriceAsc:  "price ASC, id",
+	types.SellerSortPriceDesc: "price DESC, id",
+	types.SellerSortRating:    "rating DESC, rating_count DESC, id",
+}
+
+// GetBySeller возвращает страницу объявлений продавца и общее число объявлений под фильтром
+func (ar *AnnouncementDBRepository) GetBySeller(sellerID string, filter types.SellerFilter) ([]Announcement, int, error) {
+	condition, ok := sellerStatusConditions[filter.Status]
+	if !ok {
+		condition = sellerStatusConditions[types.SellerStatusAll]
+	}
+	order, ok := sellerSortOrders[filter.Sort]
+	if !ok {
+		order = sellerSortOrders[types.SellerSortNewest]
+	}
+
+	var total int
+	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM announcement WHERE user_seller_id = $1 AND %s`, condition)
+	if err := ar.DB.QueryRow(countQuery, sellerID).Scan(&total); err != nil {
+		ar.Logger.Errorf("Error counting announcements of seller %s: %v", sellerID, err)
+		return nil, 0, errors.ErrDBInternal
+	}
+
+	query := fmt.Sprintf(`
+	SELECT id, name, description, user_seller_id, price, COALESCE(category, 0), discount, quantity, is_active, rating, rating_count, created_at, publish_at, expires_at, favorites_count
+	FROM announcement
+	WHERE user_seller_id = $1 AND %s
+	ORDER BY %s
+	LIMIT $2 OFFSET $3
+	`, condition, order)
+
+	rows, err := ar.DB.Query(query, sellerID, filter.Limit, filter.Offset)
+	if err != nil {
+		ar.Logger.Errorf("Error getting announcements of seller %s: %v", sellerID, err)
+		return nil, 0, errors.ErrDBInternal
+	}
+	defer rows.Close()
+
+	announcements := make([]Announcement, 0, filter.Limit)
+	for rows.Next() {
+		var a Announcement
+		err := rows.Scan(
+			&a.ID,
+			&a.Name,
+			&a.Description,
+			&a.UserSellerID,
+			&a.Price,
+			&a.Category,
+			&a.Discount,
+			&a.Quantity,
+			&a.IsActive,
+			&a.Rating,
+			&a.RatingCount,
+			&a.CreatedAt,
+			&a.PublishAt,
+			&a.ExpiresAt,
+			&a.FavoritesCount,
+		)
+		if err != nil {
+			ar.Logger.Errorf("Error scanning announcement of seller %s: %v", sellerID, err)
+			return nil, 0, errors.ErrDBInternal
+		}
+		announcements = append(announcements, a)
+	}
+	if err := rows.Err(); err != nil {
+		ar.Logger.Errorf("Rows iteration error: %v", err)
+		return nil, 0, errors.ErrDBInternal
+	}
+
+	if err = ar.attachImages(announcements); err != nil {
+		return nil, 0, err
+	}
+
+	return announcements, total, nil
+}
+
+// GetSellerStats считает сводку по объявлениям продавца для витрины
+func (ar *AnnouncementDBRepository) GetSellerStats(sellerID string) (types.SellerStats, error) {
+	query := `
+	SELECT COUNT(*),
+		   COUNT(*) FILTER (WHERE is_active = TRUE),
+		   COUNT(*) FILTER (WHERE quantity = 0),
+		   COALESCE(AVG(rating) FILTER (WHERE rating_count > 0), 0)
+	FROM announcement
+	WHERE user_seller_id = $1 AND is_draft = FALSE AND published = TRUE
+	`
+
+	var stats types.SellerStats
+	err := ar.DB.QueryRow(query, sellerID).Scan(
+		&stats.TotalCount,
+		&stats.ActiveCount,
+		&stats.SoldCount,
+		&stats.AverageRating,
+	)
+	if err != nil {
+		ar.Logger.Errorf("Error getting stats of seller %s: %v", sellerID, err)
+		return types.SellerStats{}, errors.ErrDBInternal
+	}
+
+	return stats, nil
+}
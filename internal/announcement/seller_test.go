@@ -0,0 +1,40 @@
+package announcement
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestSellerStatusConditions_OnlyPublished(t *testing.T) {
+	t.Parallel()
+	// запланированные объявления не видны в витрине, пока не наступит publish_at
+	for status, condition := range sellerStatusConditions {
+		assert.Contains(t, condition, "is_draft = FALSE", status)
+		assert.Contains(t, condition, "published = TRUE", status)
+	}
+}
+
+func TestGetSellerStats_OnlyPublished(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ar := &AnnouncementDBRepository{DB: db, Logger: zaptest.NewLogger(t).Sugar()}
+
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE user_seller_id = $1 AND is_draft = FALSE AND published = TRUE")).
+		WithArgs("seller-1").
+		WillReturnRows(sqlmock.NewRows([]string{"total", "active", "sold", "rating"}).AddRow(3, 2, 1, 4.5))
+
+	stats, err := ar.GetSellerStats("seller-1")
+	require.NoError(t, err)
+	assert.Equal(t, 3, stats.TotalCount)
+	assert.Equal(t, 2, stats.ActiveCount)
+	assert.Equal(t, 1, stats.SoldCount)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,123 @@
+package announcement
+
+import (
+	"context"
+
+	types "gafroshka-main/internal/types/announcement"
+	esDoc "gafroshka-main/internal/types/elastic"
+	"gafroshka-main/internal/types/errors"
+
+	"github.com/lib/pq"
+)
+
+const (
+	// similarPriceBand - ширина ценового коридора похожих объявлений: ±30% от цены со скидкой
+	similarPriceBand = 0.3
+	// similarOverfetch - во сколько раз больше кандидатов запрашивать у ES,
+	// чтобы после отсева неактивных в Postgres хватило на limit
+	similarOverfetch = 2
+)
+
+// GetSimilar подбирает похожие объявления через more_like_this в ES
+// и оставляет только активные объявления других продавцов в порядке релевантности
+func (ar *AnnouncementDBRepository) GetSimilar(id string, limit int) ([]SimilarAnnouncement, error) {
+	source, err := ar.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if source.IsDraft {
+		return nil, errors.ErrNotFound
+	}
+
+	price := types.EffectivePrice(source.Price, source.Discount)
+	filter := esDoc.SimilarFilter{
+		ExcludeSellerID: source.UserSellerID,
+		Category:        source.Category,
+		PriceFrom:       int64(float64(price) * (1 - similarPriceBand)),
+		PriceTo:         int64(float64(price) * (1 + similarPriceBand)),
+	}
+
+	hits, err := ar.ElasticService.SimilarTo(context.Background(), id, filter, limit*similarOverfetch)
+	if err != nil {
+		ar.Logger.Errorf("Elastic similar error: %v", err)
+		return nil, errors.ErrSearch
+	}
+	if len(hits) == 0 {
+		return []SimilarAnnouncement{}, nil
+	}
+
+	ids := make([]string, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.ID
+	}
+
+	// в индексе могут остаться снятые с продажи объявления и документы без seller_id,
+	// поэтому активность и продавца проверяем по Postgres
+	query := `
+	SELECT id, name, description, user_seller_id, price, COALESCE(category, 0), discount, quantity, is_active, rating, rating_count, created_at, publish_at, expires_at, favorites_count
+	FROM announcement
+	WHERE id = ANY($1) AND is_active = TRUE AND is_draft = FALSE AND user_seller_id <> $2
+	`
+	rows, err := ar.DB.Query(query, pq.Array(ids), source.UserSellerID)
+	if err != nil {
+		ar.Logger.Errorf("Error getting similar announcements of %s: %v", id, err)
+		return nil, errors.ErrDBInternal
+	}
+	defer rows.Close()
+
+	annByID := make(map[string]Announcement, len(ids))
+	for rows.Next() {
+		var a Announcement
+		err := rows.Scan(
+			&a.ID,
+			&a.Name,
+			&a.Description,
+			&a.UserSellerID,
+			&a.Price,
+			&a.Category,
+			&a.Discount,
+			&a.Quantity,
+			&a.IsActive,
+			&a.Rating,
+			&a.RatingCount,
+			&a.CreatedAt,
+			&a.PublishAt,
+			&a.ExpiresAt,
+			&a.FavoritesCount,
+		)
+		if err != nil {
+			ar.Logger.Errorf("Error scanning similar announcement: %v", err)
+			return nil, errors.ErrDBInternal
+		}
+		annByID[a.ID] = a
+	}
+	if err := rows.Err(); err != nil {
+		ar.Logger.Errorf("Rows iteration error: %v", err)
+		return nil, errors.ErrDBInternal
+	}
+
+	anns := make([]Announcement, 0, limit)
+	reasons := make([][]string, 0, limit)
+	for _, hit := range hits {
+		a, ok := annByID[hit.ID]
+		if !ok {
+			continue
+		}
+		anns = append(anns, a)
+		reasons = append(reasons, hit.Reasons)
+		if len(anns) == limit {
+			break
+		}
+	}
+
+	if err = ar.attachImages(anns); err != nil {
+		return nil, err
+	}
+
+	result := make([]SimilarAnnouncement, len(anns))
+	for i, a := range anns {
+		result[i] = SimilarAnnouncement{Announcement: a, Reasons: reasons[i]}
+	}
+
+	return result, nil
+}
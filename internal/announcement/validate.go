@@ -0,0 +1,113 @@
+package announcement
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"gafroshka-main/internal/category"
+	"gafroshka-main/internal/types/errors"
+)
+
+const (
+	// maxNameLength совпадает с VARCHAR(100) колонки announcement.name
+	maxNameLength = 100
+	maxDiscount   = 100
+)
+
+// fields - поля объявления, которые проверяются при создании, изменении и публикации
+type fields struct {
+	Name       string
+	Price      int64
+	Category   int
+	Discount   int
+	Quantity   int
+	Attributes map[string]string
+}
+
+func fieldsOf(a *Announcement) fields {
+	return fields{
+		Name:       a.Name,
+		Price:      a.Price,
+		Category:   a.Category,
+		Discount:   a.Discount,
+		Quantity:   a.Quantity,
+		Attributes: a.Attributes,
+	}
+}
+
+// validate проверяет поля объявления и собирает ошибки по каждому полю в errors.FieldErrors
+// Черновик можно сохранить незаполненным, проверяются только переданные значения,
+// перед публикацией обязательны все поля и обязательные атрибуты категории
+// Возвращает схему атрибутов категории и нормализованные значения атрибутов
+func (ar *AnnouncementDBRepository) validate(f fields, draft bool) ([]category.Attribute, map[string]string, error) {
+	fe := errors.FieldErrors{}
+
+	switch {
+	case utf8.RuneCountInString(f.Name) > maxNameLength:
+		fe.Add("name", fmt.Sprintf("must be at most %d characters", maxNameLength))
+	case !draft && strings.TrimSpace(f.Name) == "":
+		fe.Add("name", "is required")
+	}
+
+	switch {
+	case f.Price < 0:
+		fe.Add("price", "must not be negative")
+	case !draft && f.Price == 0:
+		fe.Add("price", "must be positive")
+	}
+
+	if f.Discount < 0 || f.Discount > maxDiscount {
+		fe.Add("discount", fmt.Sprintf("must be between 0 and %d", maxDiscount))
+	}
+
+	switch {
+	case f.Quantity < 0:
+		fe.Add("quantity", "must not be negative")
+	case !draft && f.Quantity == 0:
+		fe.Add("quantity", "must be positive")
+	}
+
+	var (
+		schema     []category.Attribute
+		attributes map[string]string
+	)
+	switch {
+	case f.Category != 0:
+		exists, err := ar.CategoryRepo.Exists(f.Category)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !exists {
+			fe.Add("category", errors.ErrUnknownCategory.Error())
+			break
+		}
+
+		schema, err = ar.CategoryRepo.GetAttributes(f.Category)
+		if err != nil {
+			return nil, nil, err
+		}
+		validateAttributes := category.ValidateAttributes
+		if draft {
+			validateAttributes = category.ValidateAttributeValues
+		}
+		attributes, err = validateAttributes(schema, f.Attributes)
+		if err != nil {
+			fe.Add("attributes", err.Error())
+		}
+	case !draft:
+		fe.Add("category", "is required")
+	case len(f.Attributes) > 0:
+		fe.Add("attributes", "category must be set before attributes")
+	}
+
+	return schema, attributes, fe.OrNil()
+}
+
+// nullableCategory сохраняет незаданную категорию черновика как NULL
+func nullableCategory(c int) interface{} {
+	if c == 0 {
+		return nil
+	}
+	return c
+}
@@ -0,0 +1,23 @@
+package announcementimage
+
+import (
+	types "gafroshka-main/internal/types/announcement"
+)
+
+// ImageRepo интерфейс для работы с фотографиями объявлений
+//
+//go:generate mockgen -source=image.go -destination=../mocks/mock_image_repo.go -package=mocks
+type ImageRepo interface {
+	// Create добавляет фотографию в конец списка фотографий объявления
+	// storageKeys - ключи всех файлов в BlobStore (оригинал и превью), нужны для удаления
+	Create(announcementID string, img types.Image, storageKeys []string) (*types.Image, error)
+	// GetByAnnouncementIDs возвращает фотографии нескольких объявлений одним запросом,
+	// отсортированные по позиции
+	GetByAnnouncementIDs(ids []string) (map[string][]types.Image, error)
+	// Count возвращает количество фотографий объявления
+	Count(announcementID string) (int, error)
+	// Delete удаляет фотографию и возвращает ключи файлов, которые нужно удалить из BlobStore
+	Delete(announcementID, imageID string) ([]string, error)
+	// Reorder задает новый порядок фотографий, imageIDs должен содержать все фотографии объявления
+	Reorder(announcementID string, imageIDs []string) error
+}
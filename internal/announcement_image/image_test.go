@@ -0,0 +1,218 @@
+package announcementimage
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+
+	types "gafroshka-main/internal/types/announcement"
+	myErr "gafroshka-main/internal/types/errors"
+)
+
+func setup(t *testing.T) (*ImageDBRepository, sqlmock.Sqlmock, func()) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка при создании mock db: %s", err)
+	}
+
+	repo := NewImageDBRepository(db, zaptest.NewLogger(t).Sugar())
+
+	return repo, mock, func() { db.Close() }
+}
+
+func testImage(w, h int) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: 100, A: 255})
+		}
+	}
+	return img
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	assert.NoError(t, jpeg.Encode(&buf, img, nil))
+	return buf.Bytes()
+}
+
+func TestProcess_JPEG(t *testing.T) {
+	t.Parallel()
+	p := NewProcessor(1<<20, map[string]int{"small": 50, "large": 1000})
+
+	// вставляем APP1 (EXIF) сегмент сразу после SOI, он не должен попасть в результат
+	raw := encodeJPEG(t, testImage(200, 100))
+	exif := append([]byte{0xFF, 0xE1, 0x00, 0x10}, []byte("Exif\x00\x00secret")...)
+	withExif := append(append(append([]byte{}, raw[:2]...), exif...), raw[2:]...)
+
+	res, err := p.Process(withExif)
+	assert.NoError(t, err)
+	assert.Equal(t, "image/jpeg", res.ContentType)
+	assert.Equal(t, "jpg", res.Ext)
+	assert.False(t, bytes.Contains(res.Original, []byte("secret")))
+
+	small, err := jpeg.DecodeConfig(bytes.NewReader(res.Thumbnails["small"]))
+	assert.NoError(t, err)
+	assert.Equal(t, 50, small.Width)
+	assert.Equal(t, 25, small.Height)
+
+	// превью больше оригинала не увеличивается
+	large, err := jpeg.DecodeConfig(bytes.NewReader(res.Thumbnails["large"]))
+	assert.NoError(t, err)
+	assert.Equal(t, 200, large.Width)
+}
+
+func TestProcess_PNG(t *testing.T) {
+	t.Parallel()
+	p := NewProcessor(1<<20, map[string]int{"small": 10})
+
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, testImage(20, 40)))
+
+	res, err := p.Process(buf.Bytes())
+	assert.NoError(t, err)
+	assert.Equal(t, "png", res.Ext)
+
+	cfg, err := png.DecodeConfig(bytes.NewReader(res.Thumbnails["small"]))
+	assert.NoError(t, err)
+	assert.Equal(t, 5, cfg.Width)
+	assert.Equal(t, 10, cfg.Height)
+}
+
+func TestProcess_Rejects(t *testing.T) {
+	t.Parallel()
+	p := NewProcessor(100, DefaultThumbnailSizes)
+
+	_, err := p.Process([]byte("<html><body>not an image</body></html>"))
+	assert.True(t, errors.Is(err, myErr.ErrUnsupportedImage))
+
+	_, err = p.Process(make([]byte, 101))
+	assert.True(t, errors.Is(err, myErr.ErrImageTooLarge))
+}
+
+func TestGetByAnnouncementIDs(t *testing.T) {
+	t.Parallel()
+	repo, mock, cleanup := setup(t)
+	defer cleanup()
+
+	rows := sqlmock.NewRows([]string{"announcement_id", "id", "position", "url", "thumbnails"}).
+		AddRow("a1", "i1", 1, "/static/images/a1/i1.jpg", []byte(`{"small":"/static/images/a1/i1_small.jpg"}`)).
+		AddRow("a1", "i2", 2, "/static/images/a1/i2.jpg", []byte(`{}`)).
+		AddRow("a2", "i3", 1, "/static/images/a2/i3.png", []byte(`{}`))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT announcement_id, id, position, url, thumbnails FROM announcement_image")).
+		WithArgs(pq.Array([]string{"a1", "a2"})).
+		WillReturnRows(rows)
+
+	got, err := repo.GetByAnnouncementIDs([]string{"a1", "a2"})
+	assert.NoError(t, err)
+	assert.Len(t, got["a1"], 2)
+	assert.Len(t, got["a2"], 1)
+	assert.Equal(t, "/static/images/a1/i1_small.jpg", got["a1"][0].Thumbnails["small"])
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreate(t *testing.T) {
+	t.Parallel()
+	repo, mock, cleanup := setup(t)
+	defer cleanup()
+
+	// вместе с фотографией сдвигается updated_at объявления для ленты подписок
+	mock.ExpectQuery(regexp.QuoteMeta("UPDATE announcement SET updated_at = NOW() WHERE id = $1 ) INSERT INTO announcement_image")).
+		WithArgs("a1", "/u.jpg", []byte(`{"small":"/s.jpg"}`), pq.Array([]string{"u.jpg", "s.jpg"})).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "position"}).AddRow("i1", 3))
+
+	got, err := repo.Create("a1", types.Image{
+		URL:        "/u.jpg",
+		Thumbnails: map[string]string{"small": "/s.jpg"},
+	}, []string{"u.jpg", "s.jpg"})
+	assert.NoError(t, err)
+	assert.Equal(t, "i1", got.ID)
+	assert.Equal(t, 3, got.Position)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDelete_NotFound(t *testing.T) {
+	t.Parallel()
+	repo, mock, cleanup := setup(t)
+	defer cleanup()
+
+	mock.ExpectQuery(regexp.QuoteMeta("UPDATE announcement SET updated_at = NOW() WHERE id IN (SELECT announcement_id FROM deleted)")).
+		WithArgs("i1", "a1").
+		WillReturnRows(sqlmock.NewRows([]string{"storage_keys"}))
+
+	_, err := repo.Delete("a1", "i1")
+	assert.True(t, errors.Is(err, myErr.ErrNotFound))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReorder(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name          string
+		order         []string
+		mockBehavior  func(mock sqlmock.Sqlmock)
+		expectedError error
+	}{
+		{
+			name:  "новый порядок",
+			order: []string{"i2", "i1"},
+			mockBehavior: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT id FROM announcement_image WHERE announcement_id = $1 FOR UPDATE")).
+					WithArgs("a1").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("i1").AddRow("i2"))
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE announcement_image SET position = $1 WHERE id = $2")).
+					WithArgs(1, "i2").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE announcement_image SET position = $1 WHERE id = $2")).
+					WithArgs(2, "i1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE announcement SET updated_at = NOW() WHERE id = $1")).
+					WithArgs("a1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:  "не все фотографии",
+			order: []string{"i1", "i1"},
+			mockBehavior: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT id FROM announcement_image WHERE announcement_id = $1 FOR UPDATE")).
+					WithArgs("a1").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("i1").AddRow("i2"))
+				mock.ExpectRollback()
+			},
+			expectedError: myErr.ErrInvalidImageOrder,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock, cleanup := setup(t)
+			defer cleanup()
+
+			tt.mockBehavior(mock)
+
+			err := repo.Reorder("a1", tt.order)
+			if tt.expectedError != nil {
+				assert.True(t, errors.Is(err, tt.expectedError))
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
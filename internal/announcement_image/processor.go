@@ -0,0 +1,174 @@
+package announcementimage
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	myErr "gafroshka-main/internal/types/errors"
+)
+
+const (
+	// maxPixels - защита от "декомпрессионных бомб": маленький файл с огромным разрешением
+	maxPixels   = 40_000_000
+	jpegQuality = 85
+)
+
+// DefaultThumbnailSizes - размеры превью по большей стороне
+var DefaultThumbnailSizes = map[string]int{
+	"small":  160,
+	"medium": 480,
+	"large":  1024,
+}
+
+// ProcessedImage - перекодированный оригинал и превью, готовые к сохранению
+type ProcessedImage struct {
+	ContentType string
+	Ext         string
+	Original    []byte
+	Thumbnails  map[string][]byte
+}
+
+// Processor - проверяет загруженные изображения и готовит превью
+type Processor struct {
+	MaxSize        int64
+	ThumbnailSizes map[string]int
+}
+
+func NewProcessor(maxSize int64, thumbnailSizes map[string]int) *Processor {
+	return &Processor{
+		MaxSize:        maxSize,
+		ThumbnailSizes: thumbnailSizes,
+	}
+}
+
+// Process - определяет формат по содержимому (а не по заголовкам клиента),
+// декодирует и заново кодирует изображение. Перекодирование отбрасывает все
+// метаданные, в том числе EXIF с геолокацией
+func (p *Processor) Process(data []byte) (*ProcessedImage, error) {
+	if int64(len(data)) > p.MaxSize {
+		return nil, myErr.ErrImageTooLarge
+	}
+
+	contentType := http.DetectContentType(data)
+	switch contentType {
+	case "image/jpeg", "image/png", "image/gif":
+	default:
+		return nil, myErr.ErrUnsupportedImage
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, myErr.ErrUnsupportedImage
+	}
+	if cfg.Width*cfg.Height > maxPixels {
+		return nil, myErr.ErrImageTooLarge
+	}
+
+	var src image.Image
+	switch contentType {
+	case "image/jpeg":
+		src, err = jpeg.Decode(bytes.NewReader(data))
+	case "image/png":
+		src, err = png.Decode(bytes.NewReader(data))
+	default:
+		// у gif берем только первый кадр
+		src, err = gif.Decode(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, myErr.ErrUnsupportedImage
+	}
+
+	// jpeg остается jpeg, остальное сохраняем в png, чтобы не терять прозрачность
+	result := &ProcessedImage{
+		ContentType: "image/png",
+		Ext:         "png",
+		Thumbnails:  make(map[string][]byte, len(p.ThumbnailSizes)),
+	}
+	if contentType == "image/jpeg" {
+		result.ContentType = "image/jpeg"
+		result.Ext = "jpg"
+	}
+
+	if result.Original, err = encode(src, result.Ext); err != nil {
+		return nil, err
+	}
+
+	for name, size := range p.ThumbnailSizes {
+		thumb, err := encode(resize(src, size), result.Ext)
+		if err != nil {
+			return nil, err
+		}
+		result.Thumbnails[name] = thumb
+	}
+
+	return result, nil
+}
+
+func encode(img image.Image, ext string) ([]byte, error) {
+	var buf bytes.Buffer
+
+	var err error
+	if ext == "jpg" {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+	} else {
+		err = png.Encode(&buf, img)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// resize - уменьшает изображение так, чтобы большая сторона была не больше maxSide
+// Каждый пиксель результата - среднее по соответствующему прямоугольнику исходника
+func resize(src image.Image, maxSide int) image.Image {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= maxSide && h <= maxSide {
+		return src
+	}
+
+	nw, nh := maxSide, maxSide
+	if w >= h {
+		nh = max(1, h*maxSide/w)
+	} else {
+		nw = max(1, w*maxSide/h)
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, nw, nh))
+	for y := 0; y < nh; y++ {
+		sy0 := b.Min.Y + y*h/nh
+		sy1 := max(sy0+1, b.Min.Y+(y+1)*h/nh)
+		for x := 0; x < nw; x++ {
+			sx0 := b.Min.X + x*w/nw
+			sx1 := max(sx0+1, b.Min.X+(x+1)*w/nw)
+
+			var r, g, bl, a, n uint64
+			for sy := sy0; sy < sy1; sy++ {
+				for sx := sx0; sx < sx1; sx++ {
+					c := color.NRGBA64Model.Convert(src.At(sx, sy)).(color.NRGBA64)
+					r += uint64(c.R)
+					g += uint64(c.G)
+					bl += uint64(c.B)
+					a += uint64(c.A)
+					n++
+				}
+			}
+
+			dst.Set(x, y, color.NRGBA64{
+				R: uint16(r / n),
+				G: uint16(g / n),
+				B: uint16(bl / n),
+				A: uint16(a / n),
+			})
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,208 @@
+package announcementimage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	types "gafroshka-main/internal/types/announcement"
+	myErr "gafroshka-main/internal/types/errors"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+type ImageDBRepository struct {
+	DB     *sql.DB
+	Logger *zap.SugaredLogger
+}
+
+func NewImageDBRepository(db *sql.DB, l *zap.SugaredLogger) *ImageDBRepository {
+	return &ImageDBRepository{
+		DB:     db,
+		Logger: l,
+	}
+}
+
+// Create добавляет фотографию в конец списка фотографий объявления
+// Вместе с ней сдвигается updated_at объявления, чтобы изменение попало в ленту подписок
+func (ir *ImageDBRepository) Create(announcementID string, img types.Image, storageKeys []string) (*types.Image, error) {
+	thumbnails, err := json.Marshal(img.Thumbnails)
+	if err != nil {
+		ir.Logger.Errorf("Error marshaling thumbnails: %v", err)
+		return nil, myErr.ErrDBInternal
+	}
+
+	query := `
+	WITH touched AS (
+		UPDATE announcement SET updated_at = NOW() WHERE id = $1
+	)
+	INSERT INTO announcement_image (announcement_id, position, url, thumbnails, storage_keys)
+	VALUES (
+		$1,
+		(SELECT COALESCE(MAX(position), 0) + 1 FROM announcement_image WHERE announcement_id = $1),
+		$2, $3, $4
+	)
+	RETURNING id, position
+	`
+	created := img
+	err = ir.DB.QueryRow(query, announcementID, img.URL, thumbnails, pq.Array(storageKeys)).
+		Scan(&created.ID, &created.Position)
+	if err != nil {
+		ir.Logger.Errorf("Error creating image of announcement %s: %v", announcementID, err)
+		return nil, myErr.ErrDBInternal
+	}
+
+	return &created, nil
+}
+
+// GetByAnnouncementIDs возвращает фотографии нескольких объявлений одним запросом
+func (ir *ImageDBRepository) GetByAnnouncementIDs(ids []string) (map[string][]types.Image, error) {
+	result := make(map[string][]types.Image, len(ids))
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	query := `
+	SELECT announcement_id, id, position, url, thumbnails
+	FROM announcement_image
+	WHERE announcement_id = ANY($1)
+	ORDER BY announcement_id, position
+	`
+	rows, err := ir.DB.Query(query, pq.Array(ids))
+	if err != nil {
+		ir.Logger.Errorf("Error getting images of announcements: %v", err)
+		return nil, myErr.ErrDBInternal
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			announcementID string
+			img            types.Image
+			thumbnails     []byte
+		)
+		if err := rows.Scan(&announcementID, &img.ID, &img.Position, &img.URL, &thumbnails); err != nil {
+			ir.Logger.Errorf("Error scanning image row: %v", err)
+			return nil, myErr.ErrDBInternal
+		}
+		if err := json.Unmarshal(thumbnails, &img.Thumbnails); err != nil {
+			ir.Logger.Errorf("Error unmarshaling thumbnails of image %s: %v", img.ID, err)
+			return nil, myErr.ErrDBInternal
+		}
+		result[announcementID] = append(result[announcementID], img)
+	}
+
+	if err := rows.Err(); err != nil {
+		ir.Logger.Errorf("Rows iteration error: %v", err)
+		return nil, myErr.ErrDBInternal
+	}
+
+	return result, nil
+}
+
+// Count возвращает количество фотографий объявления
+func (ir *ImageDBRepository) Count(announcementID string) (int, error) {
+	query := `SELECT COUNT(*) FROM announcement_image WHERE announcement_id = $1`
+
+	var count int
+	if err := ir.DB.QueryRow(query, announcementID).Scan(&count); err != nil {
+		ir.Logger.Errorf("Error counting images of announcement %s: %v", announcementID, err)
+		return 0, myErr.ErrDBInternal
+	}
+
+	return count, nil
+}
+
+// Delete удаляет фотографию и возвращает ключи ее файлов в BlobStore
+// updated_at объявления сдвигается, только если фотография действительно удалена
+func (ir *ImageDBRepository) Delete(announcementID, imageID string) ([]string, error) {
+	query := `
+	WITH deleted AS (
+		DELETE FROM announcement_image
+		WHERE id = $1 AND announcement_id = $2
+		RETURNING announcement_id, storage_keys
+	), touched AS (
+		UPDATE announcement SET updated_at = NOW() WHERE id IN (SELECT announcement_id FROM deleted)
+	)
+	SELECT storage_keys FROM deleted
+	`
+
+	var keys []string
+	err := ir.DB.QueryRow(query, imageID, announcementID).Scan(pq.Array(&keys))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, myErr.ErrNotFound
+		}
+		ir.Logger.Errorf("Error deleting image %s: %v", imageID, err)
+		return nil, myErr.ErrDBInternal
+	}
+
+	return keys, nil
+}
+
+// Reorder задает новый порядок фотографий: позиция равна индексу в imageIDs + 1
+func (ir *ImageDBRepository) Reorder(announcementID string, imageIDs []string) error {
+	tx, err := ir.DB.Begin()
+	if err != nil {
+		ir.Logger.Errorf("Error starting transaction: %v", err)
+		return myErr.ErrDBInternal
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(
+		`SELECT id FROM announcement_image WHERE announcement_id = $1 FOR UPDATE`,
+		announcementID,
+	)
+	if err != nil {
+		ir.Logger.Errorf("Error locking images of announcement %s: %v", announcementID, err)
+		return myErr.ErrDBInternal
+	}
+
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			ir.Logger.Errorf("Error scanning image id: %v", err)
+			return myErr.ErrDBInternal
+		}
+		existing[id] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		ir.Logger.Errorf("Rows iteration error: %v", err)
+		return myErr.ErrDBInternal
+	}
+
+	// новый порядок должен быть перестановкой текущих фотографий
+	if len(imageIDs) != len(existing) {
+		return myErr.ErrInvalidImageOrder
+	}
+	seen := make(map[string]bool, len(imageIDs))
+	for _, id := range imageIDs {
+		if !existing[id] || seen[id] {
+			return myErr.ErrInvalidImageOrder
+		}
+		seen[id] = true
+	}
+
+	for i, id := range imageIDs {
+		if _, err := tx.Exec(`UPDATE announcement_image SET position = $1 WHERE id = $2`, i+1, id); err != nil {
+			ir.Logger.Errorf("Error updating position of image %s: %v", id, err)
+			return myErr.ErrDBInternal
+		}
+	}
+
+	if _, err := tx.Exec(`UPDATE announcement SET updated_at = NOW() WHERE id = $1`, announcementID); err != nil {
+		ir.Logger.Errorf("Error touching announcement %s: %v", announcementID, err)
+		return myErr.ErrDBInternal
+	}
+
+	if err := tx.Commit(); err != nil {
+		ir.Logger.Errorf("Error committing image order: %v", err)
+		return myErr.ErrDBInternal
+	}
+
+	return nil
+}
@@ -1,20 +1,32 @@
 package app
 
 import (
+	"fmt"
 	"os"
 	"time"
 
+	analyticsclient "gafroshka-main/internal/analytics_client"
+	"gafroshka-main/internal/announcement"
+	"gafroshka-main/internal/experiment"
+
 	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
-	CfgDB           ConfigDB      `yaml:"db"`
-	CfgES           ConfigES      `yaml:"es"`
-	ETLTimeout      time.Duration `yaml:"etl_search_timeout"`
-	MaxOpenConns    int           `yaml:"max_open_conns"`
-	Secret          string        `yaml:"secret"`
-	ServerPort      string        `yaml:"srv_port"`
-	SessionDuration time.Duration `yaml:"session_duration"`
+	CfgAnalytics       analyticsclient.Config  `yaml:"analytics"`
+	CfgDB              ConfigDB                `yaml:"db"`
+	CfgES              ConfigES                `yaml:"es"`
+	CfgImages          ConfigImages            `yaml:"images"`
+	ETLTimeout         time.Duration           `yaml:"etl_search_timeout"`
+	Experiments        []experiment.Experiment `yaml:"experiments"`
+	CfgLifecycle       ConfigLifecycle         `yaml:"lifecycle"`
+	MaxOpenConns       int                     `yaml:"max_open_conns"`
+	PriceWatchInterval time.Duration           `yaml:"price_watch_interval"`
+	CfgRecentlyViewed  ConfigRecentlyViewed    `yaml:"recently_viewed"`
+	ReservationTTL     time.Duration           `yaml:"reservation_ttl"`
+	Secret             string                  `yaml:"secret"`
+	ServerPort         string                  `yaml:"srv_port"`
+	SessionDuration    time.Duration           `yaml:"session_duration"`
 }
 
 type ConfigDB struct {
@@ -29,6 +41,32 @@ type ConfigES struct {
 	Index string `yaml:"index"`
 }
 
+// ConfigLifecycle - сроки публикации объявлений
+type ConfigLifecycle struct {
+	// Lifetime - срок публикации нового объявления и стандартный срок продления
+	Lifetime time.Duration `yaml:"lifetime"`
+	// Interval - период запуска планировщика публикации и снятия объявлений
+	Interval time.Duration `yaml:"interval"`
+	// ExpiryNotice - за сколько до окончания срока уведомлять продавца, 0 - не уведомлять
+	ExpiryNotice time.Duration `yaml:"expiry_notice"`
+}
+
+// ConfigRecentlyViewed - список недавно просмотренных объявлений в Redis
+type ConfigRecentlyViewed struct {
+	// MaxItems - сколько последних просмотров хранится на пользователя
+	MaxItems int `yaml:"max_items"`
+	// TTL - сколько список живет после последнего просмотра
+	TTL time.Duration `yaml:"ttl"`
+}
+
+// ConfigImages - хранение и ограничения загружаемых фотографий объявлений
+type ConfigImages struct {
+	Dir                string `yaml:"dir"`
+	BaseURL            string `yaml:"base_url"`
+	MaxSize            int64  `yaml:"max_size"`
+	MaxPerAnnouncement int    `yaml:"max_per_announcement"`
+}
+
 func NewConfig(configPath string) (*Config, error) {
 	cfg, err := os.ReadFile(configPath)
 	if err != nil {
@@ -41,5 +79,11 @@ func NewConfig(configPath string) (*Config, error) {
 		return nil, err
 	}
 
+	// продление не может быть дольше MaxRenewPeriod, поэтому и стандартный срок не может
+	if c.CfgLifecycle.Lifetime > announcement.MaxRenewPeriod {
+		return nil, fmt.Errorf("lifecycle.lifetime %s exceeds max renew period %s",
+			c.CfgLifecycle.Lifetime, announcement.MaxRenewPeriod)
+	}
+
 	return &c, nil
 }
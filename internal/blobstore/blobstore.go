@@ -0,0 +1,17 @@
+package blobstore
+
+import (
+	"context"
+	"io"
+)
+
+// BlobStore - хранилище бинарных файлов (изображений объявлений)
+// Сейчас есть только локальная реализация, S3-совместимая появится позже
+type BlobStore interface {
+	// Put - сохраняет содержимое r под ключом key, перезаписывая существующий файл
+	Put(ctx context.Context, key string, r io.Reader) error
+	// Delete - удаляет файл по ключу, отсутствие файла ошибкой не считается
+	Delete(ctx context.Context, key string) error
+	// URL - возвращает публичный URL файла по ключу
+	URL(key string) string
+}
@@ -0,0 +1,133 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	myErr "gafroshka-main/internal/types/errors"
+
+	"go.uber.org/zap"
+)
+
+// LocalStore - BlobStore поверх локальной файловой системы
+// Файлы отдаются статикой по BaseURL
+type LocalStore struct {
+	Dir     string
+	BaseURL string
+	Logger  *zap.SugaredLogger
+}
+
+func NewLocalStore(dir, baseURL string, logger *zap.SugaredLogger) (*LocalStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &LocalStore{
+		Dir:     dir,
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		Logger:  logger,
+	}, nil
+}
+
+// path - переводит ключ в путь на диске, не выпуская за пределы Dir
+func (s *LocalStore) path(key string) (string, error) {
+	clean := filepath.Clean("/" + key)
+	if clean == "/" {
+		return "", myErr.ErrBadBlobKey
+	}
+
+	return filepath.Join(s.Dir, filepath.FromSlash(clean)), nil
+}
+
+func (s *LocalStore) Put(ctx context.Context, key string, r io.Reader) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		s.Logger.Errorf("Failed to create dir for blob %s: %v", key, err)
+		return err
+	}
+
+	// пишем во временный файл и переименовываем, чтобы не отдавать недописанный файл
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".upload-*")
+	if err != nil {
+		s.Logger.Errorf("Failed to create temp file for blob %s: %v", key, err)
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		s.Logger.Errorf("Failed to write blob %s: %v", key, err)
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	if err = ctx.Err(); err != nil {
+		return err
+	}
+
+	if err = os.Rename(tmp.Name(), p); err != nil {
+		s.Logger.Errorf("Failed to move blob %s: %v", key, err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *LocalStore) Delete(_ context.Context, key string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err = os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+		s.Logger.Errorf("Failed to delete blob %s: %v", key, err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *LocalStore) URL(key string) string {
+	return fmt.Sprintf("%s/%s", s.BaseURL, strings.TrimLeft(key, "/"))
+}
+
+// Handler - отдает сохраненные файлы по ключу. Каталоги не отдаются: на них 404, а не листинг
+func (s *LocalStore) Handler() http.Handler {
+	return http.FileServer(filesOnly{http.Dir(s.Dir)})
+}
+
+// filesOnly - файловая система, в которой видны только обычные файлы
+type filesOnly struct {
+	fs http.FileSystem
+}
+
+func (f filesOnly) Open(name string) (http.File, error) {
+	file, err := f.fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if info.IsDir() {
+		file.Close()
+		return nil, os.ErrNotExist
+	}
+
+	return file, nil
+}
@@ -0,0 +1,81 @@
+package blobstore
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	myErr "gafroshka-main/internal/types/errors"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestLocalStore_PutDelete(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	store, err := NewLocalStore(dir, "/static/images/", zaptest.NewLogger(t).Sugar())
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	err = store.Put(ctx, "announcements/a1/img/original.jpg", strings.NewReader("data"))
+	assert.NoError(t, err)
+
+	content, err := os.ReadFile(filepath.Join(dir, "announcements", "a1", "img", "original.jpg"))
+	assert.NoError(t, err)
+	assert.Equal(t, "data", string(content))
+
+	assert.Equal(t, "/static/images/announcements/a1/img/original.jpg", store.URL("announcements/a1/img/original.jpg"))
+
+	assert.NoError(t, store.Delete(ctx, "announcements/a1/img/original.jpg"))
+	_, err = os.Stat(filepath.Join(dir, "announcements", "a1", "img", "original.jpg"))
+	assert.True(t, os.IsNotExist(err))
+
+	// повторное удаление не ошибка
+	assert.NoError(t, store.Delete(ctx, "announcements/a1/img/original.jpg"))
+}
+
+func TestLocalStore_PathTraversal(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	store, err := NewLocalStore(filepath.Join(dir, "images"), "/static/images", zaptest.NewLogger(t).Sugar())
+	assert.NoError(t, err)
+
+	err = store.Put(context.Background(), "../../escape.txt", strings.NewReader("x"))
+	assert.NoError(t, err)
+
+	// файл оказался внутри Dir, а не выше
+	_, err = os.Stat(filepath.Join(dir, "images", "escape.txt"))
+	assert.NoError(t, err)
+	_, err = os.Stat(filepath.Join(dir, "escape.txt"))
+	assert.True(t, os.IsNotExist(err))
+
+	assert.ErrorIs(t, store.Put(context.Background(), "", strings.NewReader("x")), myErr.ErrBadBlobKey)
+}
+
+func TestLocalStore_Handler_NoDirectoryListing(t *testing.T) {
+	t.Parallel()
+	store, err := NewLocalStore(t.TempDir(), "/static/images", zaptest.NewLogger(t).Sugar())
+	assert.NoError(t, err)
+	assert.NoError(t, store.Put(context.Background(), "announcements/a1/img/original.jpg", strings.NewReader("data")))
+
+	get := func(path string) *http.Response {
+		rr := httptest.NewRecorder()
+		store.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		return rr.Result()
+	}
+
+	resp := get("/announcements/a1/img/original.jpg")
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "data", string(body))
+
+	for _, dir := range []string{"/", "/announcements/", "/announcements/a1", "/announcements/a1/img/"} {
+		assert.Equal(t, http.StatusNotFound, get(dir).StatusCode, dir)
+	}
+}
@@ -0,0 +1,111 @@
+package category
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	myErr "gafroshka-main/internal/types/errors"
+)
+
+type AttributeType string
+
+const (
+	AttributeString AttributeType = "string"
+	AttributeNumber AttributeType = "number"
+	AttributeBool   AttributeType = "bool"
+	AttributeEnum   AttributeType = "enum"
+
+	maxAttributeValueLength = 255
+)
+
+// Attribute описание атрибута в схеме категории
+type Attribute struct {
+	ID            int           `json:"id"`
+	CategoryID    int           `json:"category_id"`
+	Code          string        `json:"code"`
+	NameRu        string        `json:"name_ru"`
+	NameEn        string        `json:"name_en"`
+	Type          AttributeType `json:"type"`
+	AllowedValues []string      `json:"allowed_values,omitempty"`
+	Required      bool          `json:"required"`
+}
+
+// ValidateAttributes проверяет значения атрибутов по схеме категории
+// Возвращает нормализованные значения (bool и number приводятся к каноничному виду)
+// и ошибку, обернутую в ErrInvalidAttribute, с кодом проблемного атрибута
+func ValidateAttributes(schema []Attribute, values map[string]string) (map[string]string, error) {
+	result, err := ValidateAttributeValues(schema, values)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range schema {
+		if _, ok := result[a.Code]; a.Required && !ok {
+			return nil, fmt.Errorf("%w: %s is required", myErr.ErrInvalidAttribute, a.Code)
+		}
+	}
+
+	return result, nil
+}
+
+// ValidateAttributeValues проверяет только переданные значения, не требуя обязательных атрибутов
+// Используется для черновиков, которые заполняются по частям
+func ValidateAttributeValues(schema []Attribute, values map[string]string) (map[string]string, error) {
+	byCode := make(map[string]Attribute, len(schema))
+	for _, a := range schema {
+		byCode[a.Code] = a
+	}
+
+	result := make(map[string]string, len(values))
+	for code, raw := range values {
+		attr, ok := byCode[code]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s is not defined for this category", myErr.ErrInvalidAttribute, code)
+		}
+
+		value := strings.TrimSpace(raw)
+		if value == "" {
+			// пустое значение равносильно отсутствию атрибута
+			continue
+		}
+		if len(value) > maxAttributeValueLength {
+			return nil, fmt.Errorf("%w: %s is too long", myErr.ErrInvalidAttribute, code)
+		}
+
+		switch attr.Type {
+		case AttributeNumber:
+			n, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s must be a number", myErr.ErrInvalidAttribute, code)
+			}
+			value = strconv.FormatFloat(n, 'f', -1, 64)
+		case AttributeBool:
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s must be true or false", myErr.ErrInvalidAttribute, code)
+			}
+			value = strconv.FormatBool(b)
+		case AttributeEnum:
+			if !contains(attr.AllowedValues, value) {
+				return nil, fmt.Errorf(
+					"%w: %s must be one of [%s]",
+					myErr.ErrInvalidAttribute, code, strings.Join(attr.AllowedValues, ", "),
+				)
+			}
+		}
+
+		result[code] = value
+	}
+
+	return result, nil
+}
+
+func contains(values []string, v string) bool {
+	for _, allowed := range values {
+		if allowed == v {
+			return true
+		}
+	}
+	return false
+}
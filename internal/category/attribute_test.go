@@ -0,0 +1,80 @@
+package category
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	myErr "gafroshka-main/internal/types/errors"
+)
+
+func TestValidateAttributes(t *testing.T) {
+	t.Parallel()
+	schema := []Attribute{
+		{ID: 1, Code: "brand", Type: AttributeString},
+		{ID: 2, Code: "memory_gb", Type: AttributeNumber},
+		{ID: 3, Code: "no_frost", Type: AttributeBool},
+		{ID: 4, Code: "condition", Type: AttributeEnum, AllowedValues: []string{"new", "used"}, Required: true},
+	}
+
+	tests := []struct {
+		name     string
+		values   map[string]string
+		expected map[string]string
+		wantErr  bool
+	}{
+		{
+			name:     "валидные значения нормализуются",
+			values:   map[string]string{"brand": " Samsung ", "memory_gb": "128.0", "no_frost": "1", "condition": "new"},
+			expected: map[string]string{"brand": "Samsung", "memory_gb": "128", "no_frost": "true", "condition": "new"},
+		},
+		{
+			name:    "неизвестный атрибут",
+			values:  map[string]string{"condition": "new", "size": "XL"},
+			wantErr: true,
+		},
+		{
+			name:    "не число",
+			values:  map[string]string{"condition": "new", "memory_gb": "много"},
+			wantErr: true,
+		},
+		{
+			name:    "значение не из списка",
+			values:  map[string]string{"condition": "broken"},
+			wantErr: true,
+		},
+		{
+			name:    "нет обязательного атрибута",
+			values:  map[string]string{"brand": "Samsung"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ValidateAttributes(schema, tt.values)
+			if tt.wantErr {
+				assert.True(t, errors.Is(err, myErr.ErrInvalidAttribute))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestValidateAttributeValues_SkipsRequired(t *testing.T) {
+	t.Parallel()
+	schema := []Attribute{
+		{ID: 1, Code: "brand", Type: AttributeString},
+		{ID: 4, Code: "condition", Type: AttributeEnum, AllowedValues: []string{"new", "used"}, Required: true},
+	}
+
+	got, err := ValidateAttributeValues(schema, map[string]string{"brand": "Samsung"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"brand": "Samsung"}, got)
+
+	_, err = ValidateAttributeValues(schema, map[string]string{"condition": "broken"})
+	assert.True(t, errors.Is(err, myErr.ErrInvalidAttribute))
+}
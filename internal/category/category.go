@@ -0,0 +1,57 @@
+package category
+
+// Category структура категории объявлений
+type Category struct {
+	ID       int         `json:"id"`
+	ParentID *int        `json:"parent_id,omitempty"`
+	Slug     string      `json:"slug"`
+	NameRu   string      `json:"name_ru"`
+	NameEn   string      `json:"name_en"`
+	Children []*Category `json:"children,omitempty"`
+}
+
+// CategoryRepo интерфейс для работы с деревом категорий
+//
+//go:generate mockgen -source=category.go -destination=../mocks/mock_category_repo.go -package=mocks
+type CategoryRepo interface {
+	// GetAll возвращает плоский список всех категорий
+	GetAll() ([]Category, error)
+	// GetByID возвращает категорию по id
+	GetByID(id int) (*Category, error)
+	// Exists проверяет, что категория с таким id существует
+	Exists(id int) (bool, error)
+	// ExpandWithDescendants возвращает переданные категории вместе со всеми их потомками
+	ExpandWithDescendants(ids []int) ([]int, error)
+	// GetAttributes возвращает схему атрибутов категории, включая унаследованные от предков
+	GetAttributes(categoryID int) ([]Attribute, error)
+}
+
+// BuildTree собирает дерево из плоского списка категорий
+// Возвращает корневые категории, у каждой заполнены Children
+func BuildTree(categories []Category) []*Category {
+	nodes := make(map[int]*Category, len(categories))
+	for i := range categories {
+		c := categories[i]
+		c.Children = nil
+		nodes[c.ID] = &c
+	}
+
+	roots := make([]*Category, 0)
+	for i := range categories {
+		node := nodes[categories[i].ID]
+		if node.ParentID == nil {
+			roots = append(roots, node)
+			continue
+		}
+
+		parent, ok := nodes[*node.ParentID]
+		if !ok {
+			// родитель не найден — считаем категорию корневой
+			roots = append(roots, node)
+			continue
+		}
+		parent.Children = append(parent.Children, node)
+	}
+
+	return roots
+}
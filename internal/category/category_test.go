@@ -0,0 +1,175 @@
+package category
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+
+	myErr "gafroshka-main/internal/types/errors"
+)
+
+func setup(t *testing.T) (*CategoryDBRepository, sqlmock.Sqlmock, func()) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка при создании mock db: %s", err)
+	}
+
+	repo := NewCategoryDBRepository(db, zaptest.NewLogger(t).Sugar())
+
+	return repo, mock, func() { db.Close() }
+}
+
+func TestGetAll(t *testing.T) {
+	t.Parallel()
+	repo, mock, cleanup := setup(t)
+	defer cleanup()
+
+	rows := sqlmock.NewRows([]string{"id", "parent_id", "slug", "name_ru", "name_en"}).
+		AddRow(9, nil, "electronics", "Электроника", "Electronics").
+		AddRow(1, 9, "gadgets", "Смартфоны и гаджеты", "Phones and gadgets")
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, parent_id, slug, name_ru, name_en FROM category")).
+		WillReturnRows(rows)
+
+	got, err := repo.GetAll()
+	assert.NoError(t, err)
+	assert.Len(t, got, 2)
+	assert.Nil(t, got[0].ParentID)
+	if assert.NotNil(t, got[1].ParentID) {
+		assert.Equal(t, 9, *got[1].ParentID)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetByID_NotFound(t *testing.T) {
+	t.Parallel()
+	repo, mock, cleanup := setup(t)
+	defer cleanup()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, parent_id, slug, name_ru, name_en FROM category WHERE id = $1")).
+		WithArgs(42).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "parent_id", "slug", "name_ru", "name_en"}))
+
+	_, err := repo.GetByID(42)
+	assert.True(t, errors.Is(err, myErr.ErrNotFound))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExists(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name          string
+		mockBehavior  func(mock sqlmock.Sqlmock)
+		expected      bool
+		expectedError error
+	}{
+		{
+			name: "категория есть",
+			mockBehavior: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS(SELECT 1 FROM category WHERE id = $1)")).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+			},
+			expected: true,
+		},
+		{
+			name: "ошибка БД",
+			mockBehavior: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS(SELECT 1 FROM category WHERE id = $1)")).
+					WithArgs(1).
+					WillReturnError(errors.New("db error"))
+			},
+			expectedError: myErr.ErrDBInternal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock, cleanup := setup(t)
+			defer cleanup()
+
+			tt.mockBehavior(mock)
+
+			got, err := repo.Exists(1)
+			if tt.expectedError != nil {
+				assert.True(t, errors.Is(err, tt.expectedError))
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestExpandWithDescendants(t *testing.T) {
+	t.Parallel()
+	repo, mock, cleanup := setup(t)
+	defer cleanup()
+
+	mock.ExpectQuery(regexp.QuoteMeta("WITH RECURSIVE tree AS")).
+		WithArgs(pq.Array([]int{9})).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(9).AddRow(1).AddRow(2))
+
+	got, err := repo.ExpandWithDescendants([]int{9})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{9, 1, 2}, got)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExpandWithDescendants_Empty(t *testing.T) {
+	t.Parallel()
+	repo, mock, cleanup := setup(t)
+	defer cleanup()
+
+	got, err := repo.ExpandWithDescendants(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAttributes(t *testing.T) {
+	t.Parallel()
+	repo, mock, cleanup := setup(t)
+	defer cleanup()
+
+	rows := sqlmock.NewRows([]string{"id", "category_id", "code", "name_ru", "name_en", "type", "allowed_values", "required"}).
+		AddRow(1, 9, "brand", "Бренд", "Brand", "string", nil, false).
+		AddRow(3, 9, "condition", "Состояние", "Condition", "enum", "{new,used}", true)
+	mock.ExpectQuery(regexp.QuoteMeta("WITH RECURSIVE ancestors AS")).
+		WithArgs(1).
+		WillReturnRows(rows)
+
+	got, err := repo.GetAttributes(1)
+	assert.NoError(t, err)
+	if assert.Len(t, got, 2) {
+		assert.Equal(t, AttributeEnum, got[1].Type)
+		assert.Equal(t, []string{"new", "used"}, got[1].AllowedValues)
+		assert.True(t, got[1].Required)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBuildTree(t *testing.T) {
+	t.Parallel()
+	parent := 9
+	categories := []Category{
+		{ID: 1, ParentID: &parent, Slug: "gadgets"},
+		{ID: 6, Slug: "transport"},
+		{ID: 9, Slug: "electronics"},
+	}
+
+	roots := BuildTree(categories)
+
+	assert.Len(t, roots, 2)
+	assert.Equal(t, 6, roots[0].ID)
+	assert.Equal(t, 9, roots[1].ID)
+	if assert.Len(t, roots[1].Children, 1) {
+		assert.Equal(t, 1, roots[1].Children[0].ID)
+	}
+}
@@ -0,0 +1,189 @@
+package category
+
+import (
+	"database/sql"
+	"errors"
+
+	myErr "gafroshka-main/internal/types/errors"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+type CategoryDBRepository struct {
+	DB     *sql.DB
+	Logger *zap.SugaredLogger
+}
+
+func NewCategoryDBRepository(db *sql.DB, l *zap.SugaredLogger) *CategoryDBRepository {
+	return &CategoryDBRepository{
+		DB:     db,
+		Logger: l,
+	}
+}
+
+// GetAll возвращает плоский список всех категорий
+func (cr *CategoryDBRepository) GetAll() ([]Category, error) {
+	query := `
+	SELECT id, parent_id, slug, name_ru, name_en
+	FROM category
+	ORDER BY id
+	`
+	rows, err := cr.DB.Query(query)
+	if err != nil {
+		cr.Logger.Errorf("Error getting categories: %v", err)
+		return nil, myErr.ErrDBInternal
+	}
+	defer rows.Close()
+
+	var categories []Category
+	for rows.Next() {
+		var (
+			c        Category
+			parentID sql.NullInt64
+		)
+		if err := rows.Scan(&c.ID, &parentID, &c.Slug, &c.NameRu, &c.NameEn); err != nil {
+			cr.Logger.Errorf("Error scanning category row: %v", err)
+			return nil, myErr.ErrDBInternal
+		}
+		if parentID.Valid {
+			p := int(parentID.Int64)
+			c.ParentID = &p
+		}
+		categories = append(categories, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		cr.Logger.Errorf("Rows iteration error: %v", err)
+		return nil, myErr.ErrDBInternal
+	}
+
+	return categories, nil
+}
+
+// GetByID возвращает категорию по id
+func (cr *CategoryDBRepository) GetByID(id int) (*Category, error) {
+	query := `
+	SELECT id, parent_id, slug, name_ru, name_en
+	FROM category
+	WHERE id = $1
+	`
+	var (
+		c        Category
+		parentID sql.NullInt64
+	)
+	err := cr.DB.QueryRow(query, id).Scan(&c.ID, &parentID, &c.Slug, &c.NameRu, &c.NameEn)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, myErr.ErrNotFound
+		}
+		cr.Logger.Errorf("Error getting category by ID: %v", err)
+		return nil, myErr.ErrDBInternal
+	}
+	if parentID.Valid {
+		p := int(parentID.Int64)
+		c.ParentID = &p
+	}
+
+	return &c, nil
+}
+
+// Exists проверяет, что категория с таким id существует
+func (cr *CategoryDBRepository) Exists(id int) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM category WHERE id = $1)`
+
+	var exists bool
+	if err := cr.DB.QueryRow(query, id).Scan(&exists); err != nil {
+		cr.Logger.Errorf("Error checking category %d: %v", id, err)
+		return false, myErr.ErrDBInternal
+	}
+
+	return exists, nil
+}
+
+// ExpandWithDescendants возвращает переданные категории вместе со всеми их потомками
+// Нужна, чтобы поиск по родительской категории находил объявления из дочерних
+func (cr *CategoryDBRepository) ExpandWithDescendants(ids []int) ([]int, error) {
+	if len(ids) == 0 {
+		return []int{}, nil
+	}
+
+	query := `
+	WITH RECURSIVE tree AS (
+		SELECT id FROM category WHERE id = ANY($1)
+		UNION
+		SELECT c.id FROM category c JOIN tree t ON c.parent_id = t.id
+	)
+	SELECT id FROM tree
+	`
+	rows, err := cr.DB.Query(query, pq.Array(ids))
+	if err != nil {
+		cr.Logger.Errorf("Error expanding categories %v: %v", ids, err)
+		return nil, myErr.ErrDBInternal
+	}
+	defer rows.Close()
+
+	result := make([]int, 0, len(ids))
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			cr.Logger.Errorf("Error scanning category id: %v", err)
+			return nil, myErr.ErrDBInternal
+		}
+		result = append(result, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		cr.Logger.Errorf("Rows iteration error: %v", err)
+		return nil, myErr.ErrDBInternal
+	}
+
+	return result, nil
+}
+
+// GetAttributes возвращает схему атрибутов категории, включая унаследованные от предков
+func (cr *CategoryDBRepository) GetAttributes(categoryID int) ([]Attribute, error) {
+	query := `
+	WITH RECURSIVE ancestors AS (
+		SELECT id, parent_id FROM category WHERE id = $1
+		UNION
+		SELECT c.id, c.parent_id FROM category c JOIN ancestors a ON c.id = a.parent_id
+	)
+	SELECT ca.id, ca.category_id, ca.code, ca.name_ru, ca.name_en, ca.type, ca.allowed_values, ca.required
+	FROM category_attribute ca
+	JOIN ancestors a ON ca.category_id = a.id
+	ORDER BY ca.id
+	`
+	rows, err := cr.DB.Query(query, categoryID)
+	if err != nil {
+		cr.Logger.Errorf("Error getting attributes of category %d: %v", categoryID, err)
+		return nil, myErr.ErrDBInternal
+	}
+	defer rows.Close()
+
+	attributes := make([]Attribute, 0)
+	for rows.Next() {
+		var a Attribute
+		if err := rows.Scan(
+			&a.ID,
+			&a.CategoryID,
+			&a.Code,
+			&a.NameRu,
+			&a.NameEn,
+			&a.Type,
+			pq.Array(&a.AllowedValues),
+			&a.Required,
+		); err != nil {
+			cr.Logger.Errorf("Error scanning category attribute: %v", err)
+			return nil, myErr.ErrDBInternal
+		}
+		attributes = append(attributes, a)
+	}
+
+	if err := rows.Err(); err != nil {
+		cr.Logger.Errorf("Rows iteration error: %v", err)
+		return nil, myErr.ErrDBInternal
+	}
+
+	return attributes, nil
+}
@@ -10,6 +10,8 @@ import (
 	"go.uber.org/zap"
 )
 
+const facetSize = 20
+
 type ElasticService struct {
 	Client *elasticsearch.Client
 	Logger *zap.SugaredLogger
@@ -109,19 +111,103 @@ func (s *ElasticService) BulkIndex(ctx context.Context, docs []esDoc.ElasticDoc)
 	return nil
 }
 
-// SearchByName - ищет товар по имени с использованием полнотекствоого поиска
-// Принимает запрос, возвращает массив подходящих документов ElasticDoc и error
-func (s *ElasticService) SearchByName(ctx context.Context, query string) ([]esDoc.ElasticDoc, error) {
-	searchQuery := map[string]interface{}{
-		"query": map[string]interface{}{
+// BulkDelete - удаляет документы из индекса по id
+// Отсутствие документа в индексе ошибкой не считается
+func (s *ElasticService) BulkDelete(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+
+	for _, id := range ids {
+		meta := map[string]map[string]string{
+			"delete": {
+				"_index": s.Index,
+				"_id":    id,
+			},
+		}
+		metaLine, err := json.Marshal(meta)
+		if err != nil {
+			s.Logger.Errorw("Failed to marshal bulk meta", zap.Error(err))
+			return err
+		}
+
+		buf.Write(metaLine)
+		buf.WriteByte('\n')
+	}
+
+	res, err := s.Client.Bulk(bytes.NewReader(buf.Bytes()), s.Client.Bulk.WithContext(ctx))
+	if err != nil {
+		s.Logger.Errorw("Bulk delete request failed", zap.Error(err))
+
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		s.Logger.Errorw("Bulk delete returned error", zap.String("response", res.String()))
+
+		return myErr.ErrIndexing
+	}
+
+	return nil
+}
+
+// buildSearchQuery - собирает запрос: полнотекстовый поиск по имени
+// (или все документы при пустом запросе) и фильтры по категориям и атрибутам
+func buildSearchQuery(query string, filter esDoc.SearchFilter) map[string]interface{} {
+	var must map[string]interface{}
+	if query == "" {
+		must = map[string]interface{}{
+			"match_all": map[string]interface{}{},
+		}
+	} else {
+		must = map[string]interface{}{
 			"match": map[string]interface{}{
 				"name": map[string]interface{}{
 					"query":     query,
 					"fuzziness": "AUTO",
 				},
 			},
+		}
+	}
+
+	filters := make([]interface{}, 0, len(filter.Attributes)+1)
+	if len(filter.Categories) > 0 {
+		filters = append(filters, map[string]interface{}{
+			"terms": map[string]interface{}{
+				"category": filter.Categories,
+			},
+		})
+	}
+	for code, value := range filter.Attributes {
+		filters = append(filters, map[string]interface{}{
+			"term": map[string]interface{}{
+				"attributes." + code: value,
+			},
+		})
+	}
+
+	if len(filters) == 0 {
+		return must
+	}
+
+	return map[string]interface{}{
+		"bool": map[string]interface{}{
+			"must":   must,
+			"filter": filters,
 		},
 	}
+}
+
+// SearchByName - ищет товар по имени с использованием полнотекствоого поиска
+// Принимает запрос и фильтры по категориям и атрибутам (пустые - без фильтра),
+// возвращает массив подходящих документов ElasticDoc и error
+func (s *ElasticService) SearchByName(ctx context.Context, query string, filter esDoc.SearchFilter) ([]esDoc.ElasticDoc, error) {
+	searchQuery := map[string]interface{}{
+		"query": buildSearchQuery(query, filter),
+	}
 
 	var buf bytes.Buffer
 	if err := json.NewEncoder(&buf).Encode(searchQuery); err != nil {
@@ -167,6 +253,220 @@ func (s *ElasticService) SearchByName(ctx context.Context, query string) ([]esDo
 	return results, nil
 }
 
+// AttributeFacets - считает количество документов по значениям атрибутов
+// Принимает запрос, фильтры и коды атрибутов, по которым нужны фасеты, возвращает Facets и error
+func (s *ElasticService) AttributeFacets(
+	ctx context.Context,
+	query string,
+	filter esDoc.SearchFilter,
+	codes []string,
+) (esDoc.Facets, error) {
+	aggs := make(map[string]interface{}, len(codes))
+	for _, code := range codes {
+		aggs[code] = map[string]interface{}{
+			"terms": map[string]interface{}{
+				"field": "attributes." + code,
+				"size":  facetSize,
+			},
+		}
+	}
+
+	searchQuery := map[string]interface{}{
+		"size":  0,
+		"query": buildSearchQuery(query, filter),
+		"aggs":  aggs,
+	}
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(searchQuery); err != nil {
+		s.Logger.Errorw("Failed to encode facets query", zap.Error(err))
+		return nil, err
+	}
+
+	res, err := s.Client.Search(
+		s.Client.Search.WithContext(ctx),
+		s.Client.Search.WithIndex(s.Index),
+		s.Client.Search.WithBody(&buf),
+	)
+	if err != nil {
+		s.Logger.Errorw("Failed to perform facets query", zap.Error(err))
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		s.Logger.Errorw("Elasticsearch facets error", zap.String("response", res.String()))
+		return nil, myErr.ErrSearch
+	}
+
+	var esResp struct {
+		Aggregations map[string]struct {
+			Buckets []struct {
+				Key      string `json:"key"`
+				DocCount int64  `json:"doc_count"`
+			} `json:"buckets"`
+		} `json:"aggregations"`
+	}
+
+	if err = json.NewDecoder(res.Body).Decode(&esResp); err != nil {
+		s.Logger.Errorw("Failed to decode facets response", zap.Error(err))
+		return nil, err
+	}
+
+	facets := make(esDoc.Facets, len(esResp.Aggregations))
+	for code, agg := range esResp.Aggregations {
+		buckets := make([]esDoc.FacetBucket, 0, len(agg.Buckets))
+		for _, b := range agg.Buckets {
+			buckets = append(buckets, esDoc.FacetBucket{Value: b.Key, Count: b.DocCount})
+		}
+		facets[code] = buckets
+	}
+
+	return facets, nil
+}
+
+// buildSimilarQuery - собирает запрос похожих объявлений: текст похож (more_like_this)
+// или категория совпадает, попадание в ценовой коридор поднимает выше.
+// Имена запросов возвращаются в matched_queries и служат причинами подбора
+func buildSimilarQuery(id, index string, filter esDoc.SimilarFilter) map[string]interface{} {
+	candidates := []interface{}{
+		map[string]interface{}{
+			"more_like_this": map[string]interface{}{
+				"fields":          []string{"name", "description"},
+				"like":            []interface{}{map[string]interface{}{"_index": index, "_id": id}},
+				"min_term_freq":   1,
+				"min_doc_freq":    1,
+				"max_query_terms": 25,
+				"_name":           esDoc.SimilarReasonText,
+			},
+		},
+	}
+	if filter.Category != 0 {
+		candidates = append(candidates, map[string]interface{}{
+			"term": map[string]interface{}{
+				"category": map[string]interface{}{
+					"value": filter.Category,
+					"_name": esDoc.SimilarReasonCategory,
+				},
+			},
+		})
+	}
+
+	mustNot := []interface{}{
+		map[string]interface{}{
+			"ids": map[string]interface{}{"values": []string{id}},
+		},
+	}
+	if filter.ExcludeSellerID != "" {
+		mustNot = append(mustNot, map[string]interface{}{
+			"term": map[string]interface{}{"seller_id": filter.ExcludeSellerID},
+		})
+	}
+
+	query := map[string]interface{}{
+		"must": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"should":               candidates,
+				"minimum_should_match": 1,
+			},
+		},
+		"must_not": mustNot,
+	}
+	if filter.PriceTo > 0 {
+		query["should"] = []interface{}{
+			map[string]interface{}{
+				"range": map[string]interface{}{
+					"price": map[string]interface{}{
+						"gte":   filter.PriceFrom,
+						"lte":   filter.PriceTo,
+						"_name": esDoc.SimilarReasonPrice,
+					},
+				},
+			},
+		}
+	}
+
+	return map[string]interface{}{"bool": query}
+}
+
+// SimilarTo - ищет объявления, похожие на документ с id
+// Принимает id документа, фильтр и количество, возвращает найденные объявления с причинами и error
+func (s *ElasticService) SimilarTo(ctx context.Context, id string, filter esDoc.SimilarFilter, size int) ([]esDoc.SimilarHit, error) {
+	searchQuery := map[string]interface{}{
+		"size":    size,
+		"_source": false,
+		"query":   buildSimilarQuery(id, s.Index, filter),
+	}
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(searchQuery); err != nil {
+		s.Logger.Errorw("Failed to encode similar query", zap.Error(err))
+		return nil, err
+	}
+
+	res, err := s.Client.Search(
+		s.Client.Search.WithContext(ctx),
+		s.Client.Search.WithIndex(s.Index),
+		s.Client.Search.WithBody(&buf),
+	)
+	if err != nil {
+		s.Logger.Errorw("Failed to perform similar query", zap.Error(err))
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		s.Logger.Errorw("Elasticsearch similar error", zap.String("response", res.String()))
+		return nil, myErr.ErrSearch
+	}
+
+	var esResp struct {
+		Hits struct {
+			Hits []struct {
+				ID             string   `json:"_id"`
+				MatchedQueries []string `json:"matched_queries"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+
+	if err = json.NewDecoder(res.Body).Decode(&esResp); err != nil {
+		s.Logger.Errorw("Failed to decode similar response", zap.Error(err))
+		return nil, err
+	}
+
+	hits := make([]esDoc.SimilarHit, 0, len(esResp.Hits.Hits))
+	for _, hit := range esResp.Hits.Hits {
+		hits = append(hits, esDoc.SimilarHit{ID: hit.ID, Reasons: hit.MatchedQueries})
+	}
+
+	return hits, nil
+}
+
+// Атрибуты объявлений индексируются как keyword,
+// чтобы по ним можно было фильтровать и строить фасеты
+var (
+	attributesProperty = map[string]interface{}{
+		"type": "object",
+	}
+	// sellerIDProperty и priceProperty нужны для подбора похожих объявлений
+	sellerIDProperty = map[string]interface{}{
+		"type": "keyword",
+	}
+	priceProperty = map[string]interface{}{
+		"type": "long",
+	}
+	attributesDynamicTemplates = []interface{}{
+		map[string]interface{}{
+			"attributes_as_keyword": map[string]interface{}{
+				"path_match": "attributes.*",
+				"mapping": map[string]interface{}{
+					"type": "keyword",
+				},
+			},
+		},
+	}
+)
+
 // EnsureIndex - проверяет, существует ли индекс с нужными настройками, если нет - создает его
 // Возвращает error
 func (s *ElasticService) EnsureIndex(ctx context.Context) error {
@@ -179,7 +479,7 @@ func (s *ElasticService) EnsureIndex(ctx context.Context) error {
 
 	if res.StatusCode == 200 {
 		s.Logger.Infof("Index '%s' already exists", s.Index)
-		return nil
+		return s.ensureMapping(ctx)
 	}
 
 	settings := map[string]interface{}{
@@ -214,7 +514,11 @@ func (s *ElasticService) EnsureIndex(ctx context.Context) error {
 				"category": map[string]interface{}{
 					"type": "integer",
 				},
+				"seller_id":  sellerIDProperty,
+				"price":      priceProperty,
+				"attributes": attributesProperty,
 			},
+			"dynamic_templates": attributesDynamicTemplates,
 		},
 	}
 
@@ -242,3 +546,40 @@ func (s *ElasticService) EnsureIndex(ctx context.Context) error {
 	s.Logger.Infof("Index '%s' created successfully", s.Index)
 	return nil
 }
+
+// ensureMapping - добавляет маппинг атрибутов, продавца и цены в индекс, созданный до их появления
+// Возвращает error
+func (s *ElasticService) ensureMapping(ctx context.Context) error {
+	mapping := map[string]interface{}{
+		"dynamic_templates": attributesDynamicTemplates,
+		"properties": map[string]interface{}{
+			"seller_id":  sellerIDProperty,
+			"price":      priceProperty,
+			"attributes": attributesProperty,
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(mapping); err != nil {
+		s.Logger.Errorw("Failed to encode attributes mapping", zap.Error(err))
+		return err
+	}
+
+	res, err := s.Client.Indices.PutMapping(
+		[]string{s.Index},
+		&buf,
+		s.Client.Indices.PutMapping.WithContext(ctx),
+	)
+	if err != nil {
+		s.Logger.Errorw("Failed to put attributes mapping", zap.Error(err))
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		s.Logger.Errorw("Elasticsearch put mapping error", zap.String("response", res.String()))
+		return myErr.ErrIndexing
+	}
+
+	return nil
+}
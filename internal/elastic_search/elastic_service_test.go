@@ -227,3 +227,88 @@ func TestBulkIndex(t *testing.T) {
 		})
 	}
 }
+
+func TestBulkDelete(t *testing.T) {
+	t.Parallel()
+	transport := &mockTransport{
+		RoundTripFn: func(req *http.Request) (*http.Response, error) {
+			body, err := io.ReadAll(req.Body)
+			assert.NoError(t, err)
+			assert.Contains(t, string(body), `{"delete":{"_id":"a1","_index":"test-index"}}`)
+			assert.Contains(t, string(body), `{"delete":{"_id":"a2","_index":"test-index"}}`)
+			return elasticOKResponse(`{"errors":false,"items":[]}`), nil
+		},
+	}
+
+	service := setupTestService(t, transport)
+	assert.NoError(t, service.BulkDelete(context.Background(), []string{"a1", "a2"}))
+}
+
+func TestSearchByName_WithFilter(t *testing.T) {
+	t.Parallel()
+	transport := &mockTransport{
+		RoundTripFn: func(req *http.Request) (*http.Response, error) {
+			body, err := io.ReadAll(req.Body)
+			assert.NoError(t, err)
+			assert.Contains(t, string(body), `"terms":{"category":[9,1]}`)
+			assert.Contains(t, string(body), `"term":{"attributes.brand":"Samsung"}`)
+			return elasticOKResponse(`{"hits":{"hits":[{"_source":{"id":"a1","name":"Galaxy"}}]}}`), nil
+		},
+	}
+
+	service := setupTestService(t, transport)
+	docs, err := service.SearchByName(context.Background(), "galaxy", esDoc.SearchFilter{
+		Categories: []int{9, 1},
+		Attributes: map[string]string{"brand": "Samsung"},
+	})
+
+	assert.NoError(t, err)
+	if assert.Len(t, docs, 1) {
+		assert.Equal(t, "a1", docs[0].ID)
+	}
+}
+
+func TestAttributeFacets(t *testing.T) {
+	t.Parallel()
+	transport := &mockTransport{
+		RoundTripFn: func(req *http.Request) (*http.Response, error) {
+			body, err := io.ReadAll(req.Body)
+			assert.NoError(t, err)
+			assert.Contains(t, string(body), `"field":"attributes.brand"`)
+			return elasticOKResponse(`{"aggregations":{"brand":{"buckets":[{"key":"Samsung","doc_count":3},{"key":"Xiaomi","doc_count":1}]}}}`), nil
+		},
+	}
+
+	service := setupTestService(t, transport)
+	facets, err := service.AttributeFacets(context.Background(), "", esDoc.SearchFilter{Categories: []int{1}}, []string{"brand"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []esDoc.FacetBucket{{Value: "Samsung", Count: 3}, {Value: "Xiaomi", Count: 1}}, facets["brand"])
+}
+
+func TestSimilarTo(t *testing.T) {
+	t.Parallel()
+	transport := &mockTransport{
+		RoundTripFn: func(req *http.Request) (*http.Response, error) {
+			body, err := io.ReadAll(req.Body)
+			assert.NoError(t, err)
+			assert.Contains(t, string(body), `"like":[{"_id":"a1","_index":"test-index"}]`)
+			assert.Contains(t, string(body), `"term":{"seller_id":"s1"}`)
+			assert.Contains(t, string(body), `"price":{"_name":"similar_price","gte":700,"lte":1300}`)
+			return elasticOKResponse(`{"hits":{"hits":[{"_id":"a2","matched_queries":["similar_text","same_category"]}]}}`), nil
+		},
+	}
+
+	service := setupTestService(t, transport)
+	hits, err := service.SimilarTo(context.Background(), "a1", esDoc.SimilarFilter{
+		ExcludeSellerID: "s1",
+		Category:        3,
+		PriceFrom:       700,
+		PriceTo:         1300,
+	}, 10)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []esDoc.SimilarHit{
+		{ID: "a2", Reasons: []string{esDoc.SimilarReasonText, esDoc.SimilarReasonCategory}},
+	}, hits)
+}
@@ -5,12 +5,14 @@ import (
 	"errors"
 	"gafroshka-main/internal/announcement"
 	"gafroshka-main/internal/types/elastic"
+	"reflect"
 	"regexp"
 	"testing"
 	"time"
 
 	"gafroshka-main/internal/etl"
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
 	"go.uber.org/zap"
 )
 
@@ -26,14 +28,18 @@ func TestPostgresExtractor_ExtractNew(t *testing.T) {
 		{
 			name: "success with two rows",
 			mockQuery: func(mock sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"id", "name", "description", "category", "user_seller_id", "created_at"}).
-					AddRow("id1", "name1", "desc1", 1, "seller1", time.Now()).
-					AddRow("id2", "name2", "desc2", 2, "seller2", time.Now())
+				rows := sqlmock.NewRows([]string{"id", "name", "description", "category", "user_seller_id", "price", "discount", "created_at"}).
+					AddRow("id1", "name1", "desc1", 1, "seller1", 1000, 0, time.Now()).
+					AddRow("id2", "name2", "desc2", 2, "seller2", 2000, 10, time.Now())
 				mock.ExpectQuery(regexp.QuoteMeta(`
-					SELECT id, name, description, category, user_seller_id, created_at
+					SELECT id, name, description, category, user_seller_id, price, discount, created_at
 					FROM announcement
 					WHERE searching = FALSE AND is_active = TRUE
 				`)).WillReturnRows(rows)
+				mock.ExpectQuery(regexp.QuoteMeta(`FROM announcement_attribute aa`)).
+					WithArgs(pq.Array([]string{"id1", "id2"})).
+					WillReturnRows(sqlmock.NewRows([]string{"announcement_id", "code", "value"}).
+						AddRow("id1", "brand", "Samsung"))
 			},
 			expectedError: false,
 			expectedCount: 2,
@@ -42,7 +48,7 @@ func TestPostgresExtractor_ExtractNew(t *testing.T) {
 			name: "query error",
 			mockQuery: func(mock sqlmock.Sqlmock) {
 				mock.ExpectQuery(regexp.QuoteMeta(`
-					SELECT id, name, description, category, user_seller_id, created_at
+					SELECT id, name, description, category, user_seller_id, price, discount, created_at
 					FROM announcement
 					WHERE searching = FALSE AND is_active = TRUE
 				`)).WillReturnError(errors.New("query failed"))
@@ -52,13 +58,16 @@ func TestPostgresExtractor_ExtractNew(t *testing.T) {
 		{
 			name: "rows iteration error",
 			mockQuery: func(mock sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"id", "name", "description", "category", "user_seller_id", "created_at"}).
-					AddRow("id1", "name1", "desc1", 1, "seller1", time.Now())
+				rows := sqlmock.NewRows([]string{"id", "name", "description", "category", "user_seller_id", "price", "discount", "created_at"}).
+					AddRow("id1", "name1", "desc1", 1, "seller1", 1000, 0, time.Now())
 				mock.ExpectQuery(regexp.QuoteMeta(`
-					SELECT id, name, description, category, user_seller_id, created_at
+					SELECT id, name, description, category, user_seller_id, price, discount, created_at
 					FROM announcement
 					WHERE searching = FALSE AND is_active = TRUE
 				`)).WillReturnRows(rows).RowsWillBeClosed()
+				mock.ExpectQuery(regexp.QuoteMeta(`FROM announcement_attribute aa`)).
+					WithArgs(pq.Array([]string{"id1"})).
+					WillReturnRows(sqlmock.NewRows([]string{"announcement_id", "code", "value"}))
 			},
 			expectedError: false,
 			expectedCount: 1,
@@ -129,6 +138,24 @@ func TestTransformer_Transform(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "announcement with attributes",
+			input: []announcement.Announcement{
+				{ID: "1", Name: "A1", Category: 1, Attributes: map[string]string{"brand": "Samsung"}},
+			},
+			expect: []elastic.ElasticDoc{
+				{ID: "1", Name: "A1", Category: 1, Attributes: map[string]string{"brand": "Samsung"}},
+			},
+		},
+		{
+			name: "price with discount and seller",
+			input: []announcement.Announcement{
+				{ID: "1", Name: "A1", UserSellerID: "s1", Price: 999, Discount: 10, Category: 1},
+			},
+			expect: []elastic.ElasticDoc{
+				{ID: "1", Name: "A1", SellerID: "s1", Price: 900, Category: 1},
+			},
+		},
 		{
 			name: "multiple announcements",
 			input: []announcement.Announcement{
@@ -152,7 +179,7 @@ func TestTransformer_Transform(t *testing.T) {
 			}
 
 			for i := range got {
-				if got[i] != tt.expect[i] {
+				if !reflect.DeepEqual(got[i], tt.expect[i]) {
 					t.Errorf("expected %v, got %v", tt.expect[i], got[i])
 				}
 			}
@@ -3,6 +3,8 @@ package etl
 import (
 	"database/sql"
 	"gafroshka-main/internal/announcement"
+
+	"github.com/lib/pq"
 	"go.uber.org/zap"
 	"golang.org/x/net/context"
 )
@@ -24,7 +26,7 @@ func NewPostgresExtractor(db *sql.DB, logger *zap.SugaredLogger) *PostgresExtrac
 func (e *PostgresExtractor) ExtractNew(ctx context.Context) ([]announcement.Announcement, error) {
 	query :=
 		`
-		SELECT id, name, description, category, user_seller_id, created_at
+		SELECT id, name, description, category, user_seller_id, price, discount, created_at
 		FROM announcement
 		WHERE searching = FALSE AND is_active = TRUE
 		`
@@ -41,7 +43,7 @@ func (e *PostgresExtractor) ExtractNew(ctx context.Context) ([]announcement.Anno
 
 	for rows.Next() {
 		var a announcement.Announcement
-		err := rows.Scan(&a.ID, &a.Name, &a.Description, &a.Category, &a.UserSellerID, &a.CreatedAt)
+		err := rows.Scan(&a.ID, &a.Name, &a.Description, &a.Category, &a.UserSellerID, &a.Price, &a.Discount, &a.CreatedAt)
 		if err != nil {
 			e.Logger.Error("Failed to scan rows", zap.Error(err))
 
@@ -55,5 +57,62 @@ func (e *PostgresExtractor) ExtractNew(ctx context.Context) ([]announcement.Anno
 		return nil, err
 	}
 
+	if len(result) == 0 {
+		return result, nil
+	}
+
+	if err := e.extractAttributes(ctx, result); err != nil {
+		return nil, err
+	}
+
 	return result, nil
 }
+
+// extractAttributes - одним запросом подтягивает атрибуты для пачки объявлений
+func (e *PostgresExtractor) extractAttributes(ctx context.Context, anns []announcement.Announcement) error {
+	ids := make([]string, len(anns))
+	byID := make(map[string]*announcement.Announcement, len(anns))
+	for i := range anns {
+		ids[i] = anns[i].ID
+		byID[anns[i].ID] = &anns[i]
+	}
+
+	query :=
+		`
+		SELECT aa.announcement_id, ca.code, aa.value
+		FROM announcement_attribute aa
+		JOIN category_attribute ca ON ca.id = aa.attribute_id
+		WHERE aa.announcement_id = ANY($1)
+		`
+
+	rows, err := e.DB.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		e.Logger.Error("Failed to query attributes", zap.Error(err))
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, code, value string
+		if err := rows.Scan(&id, &code, &value); err != nil {
+			e.Logger.Error("Failed to scan attributes", zap.Error(err))
+			return err
+		}
+
+		a, ok := byID[id]
+		if !ok {
+			continue
+		}
+		if a.Attributes == nil {
+			a.Attributes = make(map[string]string)
+		}
+		a.Attributes[code] = value
+	}
+
+	if err := rows.Err(); err != nil {
+		e.Logger.Error("Error during attributes iteration", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
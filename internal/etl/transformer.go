@@ -2,6 +2,7 @@ package etl
 
 import (
 	"gafroshka-main/internal/announcement"
+	typesAnn "gafroshka-main/internal/types/announcement"
 	"gafroshka-main/internal/types/elastic"
 	"go.uber.org/zap"
 )
@@ -26,6 +27,9 @@ func (t *Transformer) Transform(input []announcement.Announcement) []elastic.Ela
 			Name:        a.Name,
 			Description: a.Description,
 			Category:    a.Category,
+			SellerID:    a.UserSellerID,
+			Price:       typesAnn.EffectivePrice(a.Price, a.Discount),
+			Attributes:  a.Attributes,
 		})
 	}
 
@@ -0,0 +1,108 @@
+package experiment
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+)
+
+// Experiment - A/B эксперимент: пользователи делятся между вариантами пропорционально весам
+type Experiment struct {
+	Key      string    `yaml:"key"`
+	Enabled  bool      `yaml:"enabled"`
+	Variants []Variant `yaml:"variants"`
+}
+
+// Variant - вариант эксперимента, Weight - его доля трафика относительно суммы весов
+type Variant struct {
+	Name   string `yaml:"name"`
+	Weight int    `yaml:"weight"`
+}
+
+// Assigner распределяет пользователей по вариантам включенных экспериментов.
+// Распределение детерминировано: один и тот же пользователь всегда попадает в тот же вариант,
+// пока не поменялись веса
+type Assigner struct {
+	experiments []Experiment
+}
+
+func NewAssigner(experiments []Experiment) (*Assigner, error) {
+	seen := make(map[string]struct{}, len(experiments))
+	enabled := make([]Experiment, 0, len(experiments))
+	for _, e := range experiments {
+		if e.Key == "" {
+			return nil, fmt.Errorf("experiment without key")
+		}
+		if _, ok := seen[e.Key]; ok {
+			return nil, fmt.Errorf("experiment %q: duplicate key", e.Key)
+		}
+		seen[e.Key] = struct{}{}
+
+		if len(e.Variants) < 2 {
+			return nil, fmt.Errorf("experiment %q: at least two variants required", e.Key)
+		}
+		for _, v := range e.Variants {
+			if v.Name == "" || v.Weight <= 0 {
+				return nil, fmt.Errorf("experiment %q: variant needs name and positive weight", e.Key)
+			}
+		}
+
+		if e.Enabled {
+			enabled = append(enabled, e)
+		}
+	}
+
+	return &Assigner{experiments: enabled}, nil
+}
+
+// Assign возвращает вариант пользователя в каждом включенном эксперименте
+func (a *Assigner) Assign(userID string) map[string]string {
+	if userID == "" || len(a.experiments) == 0 {
+		return nil
+	}
+
+	assignments := make(map[string]string, len(a.experiments))
+	for _, e := range a.experiments {
+		assignments[e.Key] = e.variantFor(userID)
+	}
+	return assignments
+}
+
+// variantFor выбирает вариант по хешу ключа эксперимента и пользователя.
+// Ключ входит в хеш, чтобы разбиения разных экспериментов не совпадали
+func (e Experiment) variantFor(userID string) string {
+	total := 0
+	for _, v := range e.Variants {
+		total += v.Weight
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(e.Key + ":" + userID))
+	bucket := int(h.Sum32() % uint32(total))
+
+	for _, v := range e.Variants {
+		if bucket < v.Weight {
+			return v.Name
+		}
+		bucket -= v.Weight
+	}
+	return e.Variants[len(e.Variants)-1].Name
+}
+
+type ctxKey struct{}
+
+// WithAssignments кладет варианты пользователя в контекст запроса
+func WithAssignments(ctx context.Context, assignments map[string]string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, assignments)
+}
+
+// FromContext возвращает варианты пользователя из контекста, nil если их нет
+func FromContext(ctx context.Context) map[string]string {
+	assignments, _ := ctx.Value(ctxKey{}).(map[string]string)
+	return assignments
+}
+
+// VariantFromContext возвращает вариант эксперимента key, пустую строку если пользователь в нем не участвует
+func VariantFromContext(ctx context.Context, key string) string {
+	return FromContext(ctx)[key]
+}
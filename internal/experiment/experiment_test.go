@@ -0,0 +1,64 @@
+package experiment
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAssigner_Validation(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		experiments []Experiment
+	}{
+		{name: "без ключа", experiments: []Experiment{{Variants: []Variant{{"a", 1}, {"b", 1}}}}},
+		{name: "один вариант", experiments: []Experiment{{Key: "e", Variants: []Variant{{"a", 1}}}}},
+		{name: "нулевой вес", experiments: []Experiment{{Key: "e", Variants: []Variant{{"a", 1}, {"b", 0}}}}},
+		{name: "повтор ключа", experiments: []Experiment{
+			{Key: "e", Variants: []Variant{{"a", 1}, {"b", 1}}},
+			{Key: "e", Variants: []Variant{{"a", 1}, {"b", 1}}},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewAssigner(tt.experiments)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestAssign_DeterministicAndWeighted(t *testing.T) {
+	t.Parallel()
+	a, err := NewAssigner([]Experiment{
+		{Key: "layout", Enabled: true, Variants: []Variant{{"control", 90}, {"compact", 10}}},
+		{Key: "disabled", Variants: []Variant{{"control", 1}, {"new", 1}}},
+	})
+	assert.NoError(t, err)
+
+	counts := make(map[string]int)
+	for i := 0; i < 10000; i++ {
+		userID := fmt.Sprintf("user-%d", i)
+		assignments := a.Assign(userID)
+		assert.Equal(t, assignments, a.Assign(userID))
+		assert.NotContains(t, assignments, "disabled")
+		counts[assignments["layout"]]++
+	}
+
+	// доли близки к весам
+	assert.InDelta(t, 9000, counts["control"], 300)
+	assert.InDelta(t, 1000, counts["compact"], 300)
+	assert.Nil(t, a.Assign(""))
+}
+
+func TestContext(t *testing.T) {
+	t.Parallel()
+	ctx := WithAssignments(context.Background(), map[string]string{"layout": "compact"})
+
+	assert.Equal(t, "compact", VariantFromContext(ctx, "layout"))
+	assert.Equal(t, "", VariantFromContext(ctx, "other"))
+	assert.Nil(t, FromContext(context.Background()))
+}
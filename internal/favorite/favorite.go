@@ -0,0 +1,21 @@
+package favorite
+
+import "time"
+
+// Favorite объявление в избранном пользователя
+type Favorite struct {
+	AnnouncementID string    `json:"announcement_id"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// FavoriteRepo интерфейс для работы с избранным пользователей
+//
+//go:generate mockgen -source=favorite.go -destination=../mocks/mock_favorite_repo.go -package=mocks
+type FavoriteRepo interface {
+	// Add добавляет объявление в избранное, false - если оно уже там было
+	Add(userID, announcementID string) (bool, error)
+	// Remove убирает объявление из избранного
+	Remove(userID, announcementID string) error
+	// GetByUserID возвращает избранное пользователя, недавно добавленные первыми
+	GetByUserID(userID string) ([]Favorite, error)
+}
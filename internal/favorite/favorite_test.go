@@ -0,0 +1,107 @@
+package favorite
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+
+	myErr "gafroshka-main/internal/types/errors"
+)
+
+func setup(t *testing.T) (*FavoriteDBRepository, sqlmock.Sqlmock, func()) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка при создании mock db: %s", err)
+	}
+
+	repo := NewFavoriteDBRepository(db, zaptest.NewLogger(t).Sugar())
+
+	return repo, mock, func() { db.Close() }
+}
+
+func TestAdd(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name         string
+		mockBehavior func(mock sqlmock.Sqlmock)
+		expected     bool
+	}{
+		{
+			name: "новое избранное увеличивает счетчик",
+			mockBehavior: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO favorite")).
+					WithArgs("u1", "a1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE announcement SET favorites_count = GREATEST(favorites_count + $1, 0)")).
+					WithArgs(1, "a1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expected: true,
+		},
+		{
+			name: "повторное добавление",
+			mockBehavior: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO favorite")).
+					WithArgs("u1", "a1").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock, cleanup := setup(t)
+			defer cleanup()
+
+			tt.mockBehavior(mock)
+
+			added, err := repo.Add("u1", "a1")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, added)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRemove_NotFound(t *testing.T) {
+	t.Parallel()
+	repo, mock, cleanup := setup(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM favorite")).
+		WithArgs("u1", "a1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	assert.ErrorIs(t, repo.Remove("u1", "a1"), myErr.ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetByUserID(t *testing.T) {
+	t.Parallel()
+	repo, mock, cleanup := setup(t)
+	defer cleanup()
+
+	now := time.Now()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT announcement_id, created_at FROM favorite")).
+		WithArgs("u1").
+		WillReturnRows(sqlmock.NewRows([]string{"announcement_id", "created_at"}).
+			AddRow("a2", now).
+			AddRow("a1", now.Add(-time.Hour)))
+
+	got, err := repo.GetByUserID("u1")
+	assert.NoError(t, err)
+	assert.Equal(t, []Favorite{{AnnouncementID: "a2", CreatedAt: now}, {AnnouncementID: "a1", CreatedAt: now.Add(-time.Hour)}}, got)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,145 @@
+package favorite
+
+import (
+	"database/sql"
+
+	myErr "gafroshka-main/internal/types/errors"
+
+	"go.uber.org/zap"
+)
+
+type FavoriteDBRepository struct {
+	DB     *sql.DB
+	Logger *zap.SugaredLogger
+}
+
+func NewFavoriteDBRepository(db *sql.DB, l *zap.SugaredLogger) *FavoriteDBRepository {
+	return &FavoriteDBRepository{
+		DB:     db,
+		Logger: l,
+	}
+}
+
+// Add добавляет объявление в избранное и увеличивает счетчик объявления
+// Повторное добавление ничего не меняет
+func (fr *FavoriteDBRepository) Add(userID, announcementID string) (bool, error) {
+	tx, err := fr.DB.Begin()
+	if err != nil {
+		fr.Logger.Errorf("Error starting transaction: %v", err)
+		return false, myErr.ErrDBInternal
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+	INSERT INTO favorite (user_id, announcement_id)
+	VALUES ($1, $2)
+	ON CONFLICT (user_id, announcement_id) DO NOTHING
+	`, userID, announcementID)
+	if err != nil {
+		fr.Logger.Errorf("Error adding favorite %s of user %s: %v", announcementID, userID, err)
+		return false, myErr.ErrDBInternal
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		fr.Logger.Errorf("Error getting affected rows: %v", err)
+		return false, myErr.ErrDBInternal
+	}
+	if affected == 0 {
+		return false, nil
+	}
+
+	if err = fr.changeCount(tx, announcementID, 1); err != nil {
+		return false, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		fr.Logger.Errorf("Error committing favorite: %v", err)
+		return false, myErr.ErrDBInternal
+	}
+
+	return true, nil
+}
+
+// Remove убирает объявление из избранного и уменьшает счетчик объявления
+func (fr *FavoriteDBRepository) Remove(userID, announcementID string) error {
+	tx, err := fr.DB.Begin()
+	if err != nil {
+		fr.Logger.Errorf("Error starting transaction: %v", err)
+		return myErr.ErrDBInternal
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`DELETE FROM favorite WHERE user_id = $1 AND announcement_id = $2`, userID, announcementID)
+	if err != nil {
+		fr.Logger.Errorf("Error removing favorite %s of user %s: %v", announcementID, userID, err)
+		return myErr.ErrDBInternal
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		fr.Logger.Errorf("Error getting affected rows: %v", err)
+		return myErr.ErrDBInternal
+	}
+	if affected == 0 {
+		return myErr.ErrNotFound
+	}
+
+	if err = fr.changeCount(tx, announcementID, -1); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		fr.Logger.Errorf("Error committing favorite removal: %v", err)
+		return myErr.ErrDBInternal
+	}
+
+	return nil
+}
+
+// changeCount меняет денормализованный счетчик избранного у объявления
+func (fr *FavoriteDBRepository) changeCount(tx *sql.Tx, announcementID string, delta int) error {
+	_, err := tx.Exec(
+		`UPDATE announcement SET favorites_count = GREATEST(favorites_count + $1, 0) WHERE id = $2`,
+		delta, announcementID,
+	)
+	if err != nil {
+		fr.Logger.Errorf("Error updating favorites count of %s: %v", announcementID, err)
+		return myErr.ErrDBInternal
+	}
+
+	return nil
+}
+
+// GetByUserID возвращает избранное пользователя, недавно добавленные первыми
+func (fr *FavoriteDBRepository) GetByUserID(userID string) ([]Favorite, error) {
+	query := `
+	SELECT announcement_id, created_at
+	FROM favorite
+	WHERE user_id = $1
+	ORDER BY created_at DESC
+	`
+	rows, err := fr.DB.Query(query, userID)
+	if err != nil {
+		fr.Logger.Errorf("Error getting favorites of user %s: %v", userID, err)
+		return nil, myErr.ErrDBInternal
+	}
+	defer rows.Close()
+
+	favorites := make([]Favorite, 0)
+	for rows.Next() {
+		var f Favorite
+		if err := rows.Scan(&f.AnnouncementID, &f.CreatedAt); err != nil {
+			fr.Logger.Errorf("Error scanning favorite: %v", err)
+			return nil, myErr.ErrDBInternal
+		}
+		favorites = append(favorites, f)
+	}
+
+	if err := rows.Err(); err != nil {
+		fr.Logger.Errorf("Rows iteration error: %v", err)
+		return nil, myErr.ErrDBInternal
+	}
+
+	return favorites, nil
+}
@@ -0,0 +1,65 @@
+package follow
+
+import (
+	"encoding/base64"
+	"strings"
+	"time"
+
+	myErr "gafroshka-main/internal/types/errors"
+)
+
+// FeedItem объявление в ленте подписок
+type FeedItem struct {
+	AnnouncementID string    `json:"announcement_id"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// Cursor - позиция в ленте: последний отданный элемент страницы
+type Cursor struct {
+	UpdatedAt      time.Time
+	AnnouncementID string
+}
+
+// Encode упаковывает курсор в непрозрачную строку для клиента
+func (c Cursor) Encode() string {
+	raw := c.UpdatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.AnnouncementID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParseCursor разбирает строку, полученную из Encode
+func ParseCursor(s string) (Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, myErr.ErrBadCursor
+	}
+
+	updatedAt, id, ok := strings.Cut(string(raw), "|")
+	if !ok || id == "" {
+		return Cursor{}, myErr.ErrBadCursor
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, updatedAt)
+	if err != nil {
+		return Cursor{}, myErr.ErrBadCursor
+	}
+
+	return Cursor{UpdatedAt: t, AnnouncementID: id}, nil
+}
+
+// FollowRepo интерфейс для подписок на продавцов
+//
+// Лента строится при чтении джойном подписок с объявлениями по индексу
+// (user_seller_id, updated_at): в отличие от рассылки в Redis при публикации
+// она не раздувается у продавцов с большим числом подписчиков
+// и сразу учитывает снятые с продажи объявления
+//
+//go:generate mockgen -source=follow.go -destination=../mocks/mock_follow_repo.go -package=mocks
+type FollowRepo interface {
+	// Follow подписывает на продавца, false - если подписка уже была
+	Follow(followerID, sellerID string) (bool, error)
+	// Unfollow отменяет подписку
+	Unfollow(followerID, sellerID string) error
+	// GetFeed возвращает активные объявления продавцов из подписок,
+	// свежие первыми, начиная после курсора (nil - с начала)
+	GetFeed(followerID string, after *Cursor, limit int) ([]FeedItem, error)
+}
@@ -0,0 +1,131 @@
+package follow
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+
+	myErr "gafroshka-main/internal/types/errors"
+)
+
+func setup(t *testing.T) (*FollowDBRepository, sqlmock.Sqlmock, func()) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка при создании mock db: %s", err)
+	}
+
+	repo := NewFollowDBRepository(db, zaptest.NewLogger(t).Sugar())
+
+	return repo, mock, func() { db.Close() }
+}
+
+func TestFollow(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name          string
+		sellerID      string
+		mockBehavior  func(mock sqlmock.Sqlmock)
+		expected      bool
+		expectedError error
+	}{
+		{
+			name:     "новая подписка",
+			sellerID: "s1",
+			mockBehavior: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO follow")).
+					WithArgs("u1", "s1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			expected: true,
+		},
+		{
+			name:     "повторная подписка",
+			sellerID: "s1",
+			mockBehavior: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO follow")).
+					WithArgs("u1", "s1").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			expected: false,
+		},
+		{
+			name:          "подписка на себя",
+			sellerID:      "u1",
+			mockBehavior:  func(mock sqlmock.Sqlmock) {},
+			expectedError: myErr.ErrFollowSelf,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock, cleanup := setup(t)
+			defer cleanup()
+
+			tt.mockBehavior(mock)
+
+			added, err := repo.Follow("u1", tt.sellerID)
+			if tt.expectedError != nil {
+				assert.True(t, errors.Is(err, tt.expectedError))
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, added)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUnfollow_NotFound(t *testing.T) {
+	t.Parallel()
+	repo, mock, cleanup := setup(t)
+	defer cleanup()
+
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM follow WHERE follower_id = $1 AND seller_id = $2")).
+		WithArgs("u1", "s1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := repo.Unfollow("u1", "s1")
+	assert.True(t, errors.Is(err, myErr.ErrNotFound))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetFeed_AfterCursor(t *testing.T) {
+	t.Parallel()
+	repo, mock, cleanup := setup(t)
+	defer cleanup()
+
+	cursor := &Cursor{UpdatedAt: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC), AnnouncementID: "a3"}
+	updatedAt := time.Date(2025, 1, 1, 11, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(regexp.QuoteMeta("(a.updated_at, a.id) < ($3, $4)")).
+		WithArgs("u1", 2, cursor.UpdatedAt, "a3").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "updated_at"}).
+			AddRow("a2", updatedAt).
+			AddRow("a1", updatedAt))
+
+	items, err := repo.GetFeed("u1", cursor, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []FeedItem{
+		{AnnouncementID: "a2", UpdatedAt: updatedAt},
+		{AnnouncementID: "a1", UpdatedAt: updatedAt},
+	}, items)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCursor_RoundTrip(t *testing.T) {
+	t.Parallel()
+	c := Cursor{UpdatedAt: time.Date(2025, 1, 1, 12, 0, 0, 123456000, time.UTC), AnnouncementID: "a1"}
+
+	got, err := ParseCursor(c.Encode())
+	assert.NoError(t, err)
+	assert.Equal(t, c, got)
+
+	_, err = ParseCursor("not a cursor")
+	assert.True(t, errors.Is(err, myErr.ErrBadCursor))
+}
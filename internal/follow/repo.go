@@ -0,0 +1,114 @@
+package follow
+
+import (
+	"database/sql"
+
+	myErr "gafroshka-main/internal/types/errors"
+
+	"go.uber.org/zap"
+)
+
+type FollowDBRepository struct {
+	DB     *sql.DB
+	Logger *zap.SugaredLogger
+}
+
+func NewFollowDBRepository(db *sql.DB, l *zap.SugaredLogger) *FollowDBRepository {
+	return &FollowDBRepository{
+		DB:     db,
+		Logger: l,
+	}
+}
+
+// Follow подписывает пользователя на продавца, повторная подписка ничего не меняет
+func (fr *FollowDBRepository) Follow(followerID, sellerID string) (bool, error) {
+	if followerID == sellerID {
+		return false, myErr.ErrFollowSelf
+	}
+
+	res, err := fr.DB.Exec(`
+	INSERT INTO follow (follower_id, seller_id)
+	VALUES ($1, $2)
+	ON CONFLICT (follower_id, seller_id) DO NOTHING
+	`, followerID, sellerID)
+	if err != nil {
+		fr.Logger.Errorf("Error following seller %s by user %s: %v", sellerID, followerID, err)
+		return false, myErr.ErrDBInternal
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		fr.Logger.Errorf("Error getting affected rows: %v", err)
+		return false, myErr.ErrDBInternal
+	}
+
+	return affected > 0, nil
+}
+
+// Unfollow отменяет подписку пользователя на продавца
+func (fr *FollowDBRepository) Unfollow(followerID, sellerID string) error {
+	res, err := fr.DB.Exec(`DELETE FROM follow WHERE follower_id = $1 AND seller_id = $2`, followerID, sellerID)
+	if err != nil {
+		fr.Logger.Errorf("Error unfollowing seller %s by user %s: %v", sellerID, followerID, err)
+		return myErr.ErrDBInternal
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		fr.Logger.Errorf("Error getting affected rows: %v", err)
+		return myErr.ErrDBInternal
+	}
+	if affected == 0 {
+		return myErr.ErrNotFound
+	}
+
+	return nil
+}
+
+// GetFeed возвращает страницу ленты, сравнение кортежей (updated_at, id)
+// дает стабильную пагинацию при одинаковом времени изменения
+func (fr *FollowDBRepository) GetFeed(followerID string, after *Cursor, limit int) ([]FeedItem, error) {
+	query := `
+	SELECT a.id, a.updated_at
+	FROM follow f
+	JOIN announcement a ON a.user_seller_id = f.seller_id
+	WHERE f.follower_id = $1 AND a.is_active = TRUE
+	ORDER BY a.updated_at DESC, a.id DESC
+	LIMIT $2
+	`
+	args := []interface{}{followerID, limit}
+	if after != nil {
+		query = `
+		SELECT a.id, a.updated_at
+		FROM follow f
+		JOIN announcement a ON a.user_seller_id = f.seller_id
+		WHERE f.follower_id = $1 AND a.is_active = TRUE AND (a.updated_at, a.id) < ($3, $4)
+		ORDER BY a.updated_at DESC, a.id DESC
+		LIMIT $2
+		`
+		args = append(args, after.UpdatedAt, after.AnnouncementID)
+	}
+
+	rows, err := fr.DB.Query(query, args...)
+	if err != nil {
+		fr.Logger.Errorf("Error getting feed of user %s: %v", followerID, err)
+		return nil, myErr.ErrDBInternal
+	}
+	defer rows.Close()
+
+	items := make([]FeedItem, 0, limit)
+	for rows.Next() {
+		var it FeedItem
+		if err := rows.Scan(&it.AnnouncementID, &it.UpdatedAt); err != nil {
+			fr.Logger.Errorf("Error scanning feed item: %v", err)
+			return nil, myErr.ErrDBInternal
+		}
+		items = append(items, it)
+	}
+	if err := rows.Err(); err != nil {
+		fr.Logger.Errorf("Rows iteration error: %v", err)
+		return nil, myErr.ErrDBInternal
+	}
+
+	return items, nil
+}
@@ -7,16 +7,21 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"testing"
 	"time"
 
 	repoAnn "gafroshka-main/internal/announcement"
 	"gafroshka-main/internal/kafka"
 	"gafroshka-main/internal/middleware"
+	"gafroshka-main/internal/mocks"
 	"gafroshka-main/internal/session"
 	typesAnn "gafroshka-main/internal/types/announcement"
+	esDoc "gafroshka-main/internal/types/elastic"
 	myErr "gafroshka-main/internal/types/errors"
+	"gafroshka-main/internal/visitor"
 
+	"github.com/golang/mock/gomock"
 	"github.com/gorilla/mux"
 	"go.uber.org/zap"
 )
@@ -38,15 +43,54 @@ type fakeAnnRepo struct {
 	returnGetByIDErr error
 
 	// Для GetTopN
-	lastGetTopNLimit      int
-	lastGetTopNCategories []int
-	returnGetTopNAnns     []repoAnn.Announcement
-	returnGetTopNErr      error
+	lastGetTopNLimit  int
+	lastGetTopNPrefs  map[int]float64
+	returnGetTopNAnns []repoAnn.Announcement
+	returnGetTopNErr  error
 
 	// Для Search
-	lastSearchQuery  string
-	returnSearchAnns []repoAnn.Announcement
-	returnSearchErr  error
+	lastSearchQuery    string
+	lastSearchCategory int
+	lastSearchFilter   typesAnn.SearchFilter
+	returnSearchAnns   []repoAnn.Announcement
+	returnSearchErr    error
+
+	// Для Facets
+	lastFacetsFilter typesAnn.SearchFilter
+	returnFacets     esDoc.Facets
+	returnFacetsErr  error
+
+	// Для UpdateAttributes
+	lastUpdateAttributes map[string]string
+	returnAttributes     map[string]string
+	returnAttributesErr  error
+
+	// Для Renew
+	lastRenewPeriod time.Duration
+	returnRenewAnn  *repoAnn.Announcement
+	returnRenewErr  error
+
+	// Для Update
+	lastUpdateInput typesAnn.UpdateAnnouncement
+	returnUpdateAnn *repoAnn.Announcement
+	returnUpdateErr error
+
+	// Для GetInfoForShoppingCart
+	returnInfos []typesAnn.InfoForSC
+
+	// Для GetForCompare
+	lastCompareIDs []string
+	returnCompare  []typesAnn.CompareItem
+
+	// Для GetSimilar
+	lastSimilarLimit int
+	returnSimilar    []repoAnn.SimilarAnnouncement
+	returnSimilarErr error
+
+	// Для Publish
+	publishCalled    bool
+	returnPublishAnn *repoAnn.Announcement
+	returnPublishErr error
 }
 
 func (f *fakeAnnRepo) Create(a typesAnn.CreateAnnouncement) (*repoAnn.Announcement, error) {
@@ -59,20 +103,65 @@ func (f *fakeAnnRepo) GetByID(id string) (*repoAnn.Announcement, error) {
 	return f.returnGetByIDAnn, f.returnGetByIDErr
 }
 
-func (f *fakeAnnRepo) GetTopN(limit int, categories []int) ([]repoAnn.Announcement, error) {
+func (f *fakeAnnRepo) GetTopN(limit int, prefs map[int]float64) ([]repoAnn.Announcement, error) {
 	f.lastGetTopNLimit = limit
-	f.lastGetTopNCategories = append([]int(nil), categories...)
+	f.lastGetTopNPrefs = prefs
 	return f.returnGetTopNAnns, f.returnGetTopNErr
 }
 
-func (f *fakeAnnRepo) Search(query string) ([]repoAnn.Announcement, error) {
-	f.lastSearchQuery = query
+func (f *fakeAnnRepo) Search(filter typesAnn.SearchFilter) ([]repoAnn.Announcement, error) {
+	f.lastSearchQuery = filter.Query
+	f.lastSearchCategory = filter.CategoryID
+	f.lastSearchFilter = filter
 	return f.returnSearchAnns, f.returnSearchErr
 }
 
-// Add stub для GetInfoForShoppingCart, чтобы интерфейс был полностью удовлетворён.
+func (f *fakeAnnRepo) Facets(filter typesAnn.SearchFilter) (esDoc.Facets, error) {
+	f.lastFacetsFilter = filter
+	return f.returnFacets, f.returnFacetsErr
+}
+
+func (f *fakeAnnRepo) UpdateAttributes(id string, attributes map[string]string) (map[string]string, error) {
+	f.lastUpdateAttributes = attributes
+	return f.returnAttributes, f.returnAttributesErr
+}
+
+func (f *fakeAnnRepo) Renew(id string, period time.Duration) (*repoAnn.Announcement, error) {
+	f.lastRenewPeriod = period
+	return f.returnRenewAnn, f.returnRenewErr
+}
+
+func (f *fakeAnnRepo) Update(id string, u typesAnn.UpdateAnnouncement) (*repoAnn.Announcement, error) {
+	f.lastUpdateInput = u
+	return f.returnUpdateAnn, f.returnUpdateErr
+}
+
+func (f *fakeAnnRepo) Publish(id string) (*repoAnn.Announcement, error) {
+	f.publishCalled = true
+	return f.returnPublishAnn, f.returnPublishErr
+}
+
+func (f *fakeAnnRepo) GetBySeller(sellerID string, filter typesAnn.SellerFilter) ([]repoAnn.Announcement, int, error) {
+	return nil, 0, nil
+}
+
+func (f *fakeAnnRepo) GetSellerStats(sellerID string) (typesAnn.SellerStats, error) {
+	return typesAnn.SellerStats{}, nil
+}
+
+func (f *fakeAnnRepo) GetForCompare(ids []string) ([]typesAnn.CompareItem, error) {
+	f.lastCompareIDs = ids
+	return f.returnCompare, nil
+}
+
+func (f *fakeAnnRepo) GetSimilar(id string, limit int) ([]repoAnn.SimilarAnnouncement, error) {
+	f.lastSimilarLimit = limit
+	return f.returnSimilar, f.returnSimilarErr
+}
+
+// GetInfoForShoppingCart возвращает заранее заданные infos в порядке, не совпадающем с ids
 func (f *fakeAnnRepo) GetInfoForShoppingCart(ids []string) ([]typesAnn.InfoForSC, error) {
-	return nil, nil
+	return f.returnInfos, nil
 }
 
 // fakeProducer реализует интерфейс kafka.EventProducer.
@@ -90,6 +179,25 @@ func (f *fakeProducer) Close() error {
 	return nil
 }
 
+// fakeRecentlyViewed реализует интерфейс recentlyviewed.RecentlyViewedRepo.
+type fakeRecentlyViewed struct {
+	added     []string
+	returnIDs []string
+}
+
+func (f *fakeRecentlyViewed) Add(ctx context.Context, userID, announcementID string) error {
+	f.added = append(f.added, userID+":"+announcementID)
+	return nil
+}
+
+func (f *fakeRecentlyViewed) Get(ctx context.Context, userID string, limit int) ([]string, error) {
+	return f.returnIDs, nil
+}
+
+func (f *fakeRecentlyViewed) Merge(ctx context.Context, fromID, toID string) error {
+	return nil
+}
+
 // zapTestLogger создаёт «тихий» SugaredLogger для тестов.
 func zapTestLogger(t *testing.T) *zap.SugaredLogger {
 	t.Helper()
@@ -108,7 +216,7 @@ func TestCreate_InvalidJSON(t *testing.T) {
 	logger := zapTestLogger(t)
 	repo := &fakeAnnRepo{}
 	prod := &fakeProducer{}
-	handler := NewAnnouncementHandler(logger, repo, prod)
+	handler := NewAnnouncementHandler(logger, repo, prod, &fakeRecentlyViewed{}, nil)
 
 	req := httptest.NewRequest(http.MethodPost, "/announcement", bytes.NewBufferString(`{bad json`))
 	rr := httptest.NewRecorder()
@@ -125,7 +233,7 @@ func TestCreate_RepoError(t *testing.T) {
 	logger := zapTestLogger(t)
 	repo := &fakeAnnRepo{returnCreateErr: errors.New("db failure")}
 	prod := &fakeProducer{}
-	handler := NewAnnouncementHandler(logger, repo, prod)
+	handler := NewAnnouncementHandler(logger, repo, prod, &fakeRecentlyViewed{}, nil)
 
 	input := typesAnn.CreateAnnouncement{
 		Name:         "Test",
@@ -167,7 +275,7 @@ func TestCreate_Success_NoEvents(t *testing.T) {
 	}
 	repo := &fakeAnnRepo{returnCreateAnn: returnAnn, returnCreateErr: nil}
 	prod := &fakeProducer{}
-	handler := NewAnnouncementHandler(logger, repo, prod)
+	handler := NewAnnouncementHandler(logger, repo, prod, &fakeRecentlyViewed{}, nil)
 
 	input := typesAnn.CreateAnnouncement{
 		Name:         returnAnn.Name,
@@ -191,7 +299,7 @@ func TestCreate_Success_NoEvents(t *testing.T) {
 	if rr.Code != http.StatusCreated {
 		t.Fatalf("expected status 201, got %d", rr.Code)
 	}
-	if repo.lastCreateInput != input {
+	if !reflect.DeepEqual(repo.lastCreateInput, input) {
 		t.Errorf("expected repo.Create to receive %+v, got %+v", input, repo.lastCreateInput)
 	}
 	if len(prod.calledEvents) != 0 {
@@ -215,7 +323,7 @@ func TestGetByID_MissingID(t *testing.T) {
 	logger := zapTestLogger(t)
 	repo := &fakeAnnRepo{}
 	prod := &fakeProducer{}
-	handler := NewAnnouncementHandler(logger, repo, prod)
+	handler := NewAnnouncementHandler(logger, repo, prod, &fakeRecentlyViewed{}, nil)
 
 	// если URL без id ("/announcement//"), mux сам отбрасывает на 301 Redirect
 	req := httptest.NewRequest(http.MethodGet, "/announcement//", nil)
@@ -235,7 +343,7 @@ func TestGetByID_NotFound(t *testing.T) {
 	logger := zapTestLogger(t)
 	repo := &fakeAnnRepo{returnGetByIDErr: myErr.ErrNotFound}
 	prod := &fakeProducer{}
-	handler := NewAnnouncementHandler(logger, repo, prod)
+	handler := NewAnnouncementHandler(logger, repo, prod, &fakeRecentlyViewed{}, nil)
 
 	req := httptest.NewRequest(http.MethodGet, "/announcement/nonexistent", nil)
 	rr := httptest.NewRecorder()
@@ -256,7 +364,7 @@ func TestGetByID_RepoError(t *testing.T) {
 	logger := zapTestLogger(t)
 	repo := &fakeAnnRepo{returnGetByIDErr: errors.New("db fail")}
 	prod := &fakeProducer{}
-	handler := NewAnnouncementHandler(logger, repo, prod)
+	handler := NewAnnouncementHandler(logger, repo, prod, &fakeRecentlyViewed{}, nil)
 
 	req := httptest.NewRequest(http.MethodGet, "/announcement/anyid", nil)
 	rr := httptest.NewRecorder()
@@ -287,7 +395,7 @@ func TestGetByID_Success_NoUser(t *testing.T) {
 	}
 	repo := &fakeAnnRepo{returnGetByIDAnn: expectedAnn, returnGetByIDErr: nil}
 	prod := &fakeProducer{}
-	handler := NewAnnouncementHandler(logger, repo, prod)
+	handler := NewAnnouncementHandler(logger, repo, prod, &fakeRecentlyViewed{}, nil)
 
 	req := httptest.NewRequest(http.MethodGet, "/announcement/ann-789", nil)
 	rr := httptest.NewRecorder()
@@ -310,6 +418,13 @@ func TestGetByID_Success_NoUser(t *testing.T) {
 	if repo.lastGetByIDInput != "ann-789" {
 		t.Errorf("expected repo.GetByID to be called with \"ann-789\", got %q", repo.lastGetByIDInput)
 	}
+	// анонимный просмотр учитывается в статистике объявления, но без пользователя
+	if len(prod.calledEvents) != 1 {
+		t.Fatalf("expected one view event, got %d", len(prod.calledEvents))
+	}
+	if ev := prod.calledEvents[0]; ev.UserID != "" || ev.AnnouncementID != "ann-789" || ev.SellerID != "seller-1" {
+		t.Errorf("unexpected view event: %+v", ev)
+	}
 }
 
 // ----------------------------
@@ -320,7 +435,7 @@ func TestGetTopN_InvalidJSON(t *testing.T) {
 	logger := zapTestLogger(t)
 	repo := &fakeAnnRepo{}
 	prod := &fakeProducer{}
-	handler := NewAnnouncementHandler(logger, repo, prod)
+	handler := NewAnnouncementHandler(logger, repo, prod, &fakeRecentlyViewed{}, nil)
 
 	req := httptest.NewRequest(http.MethodPost, "/announcements/top", bytes.NewBufferString(`{invalid}`))
 	rr := httptest.NewRecorder()
@@ -335,7 +450,7 @@ func TestGetTopN_InvalidLimit(t *testing.T) {
 	logger := zapTestLogger(t)
 	repo := &fakeAnnRepo{}
 	prod := &fakeProducer{}
-	handler := NewAnnouncementHandler(logger, repo, prod)
+	handler := NewAnnouncementHandler(logger, repo, prod, &fakeRecentlyViewed{}, nil)
 
 	body, _ := json.Marshal(map[string]int{"limit": 0})
 	req := httptest.NewRequest(http.MethodPost, "/announcements/top", bytes.NewBuffer(body))
@@ -351,7 +466,7 @@ func TestGetTopN_RepoError_NoUser(t *testing.T) {
 	logger := zapTestLogger(t)
 	repo := &fakeAnnRepo{returnGetTopNErr: errors.New("db fail")}
 	prod := &fakeProducer{}
-	handler := NewAnnouncementHandler(logger, repo, prod)
+	handler := NewAnnouncementHandler(logger, repo, prod, &fakeRecentlyViewed{}, nil)
 
 	body, _ := json.Marshal(map[string]int{"limit": 2})
 	req := httptest.NewRequest(http.MethodPost, "/announcements/top", bytes.NewBuffer(body))
@@ -365,8 +480,82 @@ func TestGetTopN_RepoError_NoUser(t *testing.T) {
 	if repo.lastGetTopNLimit != 2 {
 		t.Errorf("expected repo.GetTopN limit=2, got %d", repo.lastGetTopNLimit)
 	}
-	if len(repo.lastGetTopNCategories) != 0 {
-		t.Errorf("expected repo.GetTopN categories empty, got %v", repo.lastGetTopNCategories)
+	if len(repo.lastGetTopNPrefs) != 0 {
+		t.Errorf("expected repo.GetTopN preferences empty, got %v", repo.lastGetTopNPrefs)
+	}
+}
+
+func TestGetTopN_UsesAnalyticsWeights(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	analytics := mocks.NewMockAnalyticsClient(ctrl)
+	repo := &fakeAnnRepo{}
+	handler := NewAnnouncementHandler(zapTestLogger(t), repo, &fakeProducer{}, &fakeRecentlyViewed{}, analytics)
+
+	analytics.EXPECT().
+		GetCategoryWeights(gomock.Any(), "user-1", maxPreferenceCategories).
+		Return(map[int]float64{3: 2.5}, nil)
+
+	body, _ := json.Marshal(map[string]interface{}{"user_id": "user-1", "limit": 5})
+	req := httptest.NewRequest(http.MethodPost, "/announcements/top", bytes.NewBuffer(body))
+	rr := httptest.NewRecorder()
+
+	handler.GetTopN(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	if !reflect.DeepEqual(repo.lastGetTopNPrefs, map[int]float64{3: 2.5}) {
+		t.Errorf("expected preferences from analytics, got %v", repo.lastGetTopNPrefs)
+	}
+}
+
+func TestGetTopN_AnalyticsUnavailable(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	analytics := mocks.NewMockAnalyticsClient(ctrl)
+	repo := &fakeAnnRepo{}
+	handler := NewAnnouncementHandler(zapTestLogger(t), repo, &fakeProducer{}, &fakeRecentlyViewed{}, analytics)
+
+	analytics.EXPECT().
+		GetCategoryWeights(gomock.Any(), "user-1", maxPreferenceCategories).
+		Return(nil, myErr.ErrAnalyticsUnavailable)
+
+	body, _ := json.Marshal(map[string]interface{}{"user_id": "user-1", "limit": 5})
+	req := httptest.NewRequest(http.MethodPost, "/announcements/top", bytes.NewBuffer(body))
+	rr := httptest.NewRecorder()
+
+	handler.GetTopN(rr, req)
+
+	// без аналитики выдача все равно строится
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	if len(repo.lastGetTopNPrefs) != 0 {
+		t.Errorf("expected repo.GetTopN preferences empty, got %v", repo.lastGetTopNPrefs)
+	}
+}
+
+func TestGetTopN_AnonymousVisitorWeights(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	analytics := mocks.NewMockAnalyticsClient(ctrl)
+	repo := &fakeAnnRepo{}
+	handler := NewAnnouncementHandler(zapTestLogger(t), repo, &fakeProducer{}, &fakeRecentlyViewed{}, analytics)
+
+	analytics.EXPECT().
+		GetCategoryWeights(gomock.Any(), "visitor:v1", maxPreferenceCategories).
+		Return(map[int]float64{4: 1}, nil)
+
+	body, _ := json.Marshal(map[string]int{"limit": 5})
+	req := httptest.NewRequest(http.MethodPost, "/announcements/top", bytes.NewBuffer(body))
+	req = req.WithContext(visitor.WithID(req.Context(), "v1"))
+	rr := httptest.NewRecorder()
+
+	handler.GetTopN(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	if !reflect.DeepEqual(repo.lastGetTopNPrefs, map[int]float64{4: 1}) {
+		t.Errorf("expected preferences of visitor, got %v", repo.lastGetTopNPrefs)
 	}
 }
 
@@ -402,7 +591,7 @@ func TestGetTopN_Success_NoUser(t *testing.T) {
 	}
 	repo := &fakeAnnRepo{returnGetTopNAnns: expectedAnns, returnGetTopNErr: nil}
 	prod := &fakeProducer{}
-	handler := NewAnnouncementHandler(logger, repo, prod)
+	handler := NewAnnouncementHandler(logger, repo, prod, &fakeRecentlyViewed{}, nil)
 
 	body, _ := json.Marshal(map[string]int{"limit": 2})
 	req := httptest.NewRequest(http.MethodPost, "/announcements/top", bytes.NewBuffer(body))
@@ -416,8 +605,8 @@ func TestGetTopN_Success_NoUser(t *testing.T) {
 	if repo.lastGetTopNLimit != 2 {
 		t.Errorf("expected repo.GetTopN limit=2, got %d", repo.lastGetTopNLimit)
 	}
-	if len(repo.lastGetTopNCategories) != 0 {
-		t.Errorf("expected repo.GetTopN categories empty, got %v", repo.lastGetTopNCategories)
+	if len(repo.lastGetTopNPrefs) != 0 {
+		t.Errorf("expected repo.GetTopN preferences empty, got %v", repo.lastGetTopNPrefs)
 	}
 
 	var got []repoAnn.Announcement
@@ -440,7 +629,7 @@ func TestSearch_MissingQuery(t *testing.T) {
 	logger := zapTestLogger(t)
 	repo := &fakeAnnRepo{}
 	prod := &fakeProducer{}
-	handler := NewAnnouncementHandler(logger, repo, prod)
+	handler := NewAnnouncementHandler(logger, repo, prod, &fakeRecentlyViewed{}, nil)
 
 	req := httptest.NewRequest(http.MethodGet, "/announcements/search", nil)
 	rr := httptest.NewRecorder()
@@ -458,7 +647,7 @@ func TestSearch_RepoError(t *testing.T) {
 	logger := zapTestLogger(t)
 	repo := &fakeAnnRepo{returnSearchErr: errors.New("db fail")}
 	prod := &fakeProducer{}
-	handler := NewAnnouncementHandler(logger, repo, prod)
+	handler := NewAnnouncementHandler(logger, repo, prod, &fakeRecentlyViewed{}, nil)
 
 	req := httptest.NewRequest(http.MethodGet, "/announcements/search?q=test", nil)
 	rr := httptest.NewRecorder()
@@ -474,3 +663,602 @@ func TestSearch_RepoError(t *testing.T) {
 		t.Errorf("expected repo.Search query=\"test\", got %q", repo.lastSearchQuery)
 	}
 }
+
+func TestCreate_UnknownCategory(t *testing.T) {
+	logger := zapTestLogger(t)
+	repo := &fakeAnnRepo{returnCreateErr: myErr.ErrUnknownCategory}
+	prod := &fakeProducer{}
+	handler := NewAnnouncementHandler(logger, repo, prod, &fakeRecentlyViewed{}, nil)
+
+	body, _ := json.Marshal(typesAnn.CreateAnnouncement{Name: "Test", Price: 100, Category: 999})
+	req := httptest.NewRequest(http.MethodPost, "/announcement", bytes.NewBuffer(body))
+	rr := httptest.NewRecorder()
+
+	handler.Create(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rr.Code)
+	}
+}
+
+func TestSearch_WithCategory(t *testing.T) {
+	logger := zapTestLogger(t)
+	repo := &fakeAnnRepo{returnSearchAnns: []repoAnn.Announcement{}}
+	prod := &fakeProducer{}
+	handler := NewAnnouncementHandler(logger, repo, prod, &fakeRecentlyViewed{}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/announcements/search?q=phone&category=9", nil)
+	rr := httptest.NewRecorder()
+
+	r := mux.NewRouter()
+	r.HandleFunc("/announcements/search", handler.Search).Methods(http.MethodGet)
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	if repo.lastSearchCategory != 9 {
+		t.Errorf("expected repo.Search category=9, got %d", repo.lastSearchCategory)
+	}
+}
+
+func TestSearch_InvalidCategory(t *testing.T) {
+	logger := zapTestLogger(t)
+	repo := &fakeAnnRepo{}
+	prod := &fakeProducer{}
+	handler := NewAnnouncementHandler(logger, repo, prod, &fakeRecentlyViewed{}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/announcements/search?q=phone&category=abc", nil)
+	rr := httptest.NewRecorder()
+
+	r := mux.NewRouter()
+	r.HandleFunc("/announcements/search", handler.Search).Methods(http.MethodGet)
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rr.Code)
+	}
+	if repo.lastSearchQuery != "" {
+		t.Errorf("expected repo.Search NOT to be called, got query %q", repo.lastSearchQuery)
+	}
+}
+
+func TestSearch_WithAttributes(t *testing.T) {
+	logger := zapTestLogger(t)
+	repo := &fakeAnnRepo{returnSearchAnns: []repoAnn.Announcement{}}
+	prod := &fakeProducer{}
+	handler := NewAnnouncementHandler(logger, repo, prod, &fakeRecentlyViewed{}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/announcements/search?q=phone&attr.brand=Samsung&attr.condition=new", nil)
+	rr := httptest.NewRecorder()
+
+	r := mux.NewRouter()
+	r.HandleFunc("/announcements/search", handler.Search).Methods(http.MethodGet)
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	expected := map[string]string{"brand": "Samsung", "condition": "new"}
+	if !reflect.DeepEqual(repo.lastSearchFilter.Attributes, expected) {
+		t.Errorf("expected attributes %v, got %v", expected, repo.lastSearchFilter.Attributes)
+	}
+}
+
+func TestFacets_MissingCategory(t *testing.T) {
+	logger := zapTestLogger(t)
+	repo := &fakeAnnRepo{}
+	handler := NewAnnouncementHandler(logger, repo, &fakeProducer{}, &fakeRecentlyViewed{}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/announcements/facets?q=phone", nil)
+	rr := httptest.NewRecorder()
+
+	handler.Facets(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rr.Code)
+	}
+}
+
+func TestFacets_Success(t *testing.T) {
+	logger := zapTestLogger(t)
+	repo := &fakeAnnRepo{returnFacets: esDoc.Facets{
+		"brand": {{Value: "Samsung", Count: 2}},
+	}}
+	handler := NewAnnouncementHandler(logger, repo, &fakeProducer{}, &fakeRecentlyViewed{}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/announcements/facets?category=1", nil)
+	rr := httptest.NewRecorder()
+
+	handler.Facets(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	if repo.lastFacetsFilter.CategoryID != 1 {
+		t.Errorf("expected category 1, got %d", repo.lastFacetsFilter.CategoryID)
+	}
+
+	var got esDoc.Facets
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if len(got["brand"]) != 1 || got["brand"][0].Count != 2 {
+		t.Errorf("unexpected facets: %v", got)
+	}
+}
+
+// ----------------------------
+// Тесты для метода UpdateAttributes
+// ----------------------------
+
+func updateAttributesRequest(t *testing.T, userID string, body string) *http.Request {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPut, "/announcement/ann-1/attributes", bytes.NewBufferString(body))
+	req = mux.SetURLVars(req, map[string]string{"id": "ann-1"})
+	if userID != "" {
+		req = req.WithContext(middleware.ContextWithSession(req.Context(), &session.Session{UserID: userID}))
+	}
+	return req
+}
+
+func TestUpdateAttributes_NotOwner(t *testing.T) {
+	logger := zapTestLogger(t)
+	repo := &fakeAnnRepo{returnGetByIDAnn: &repoAnn.Announcement{ID: "ann-1", UserSellerID: "seller-1"}}
+	handler := NewAnnouncementHandler(logger, repo, &fakeProducer{}, &fakeRecentlyViewed{}, nil)
+
+	rr := httptest.NewRecorder()
+	handler.UpdateAttributes(rr, updateAttributesRequest(t, "other-user", `{"brand":"Apple"}`))
+
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("expected status 403, got %d", rr.Code)
+	}
+	if repo.lastUpdateAttributes != nil {
+		t.Errorf("expected repo.UpdateAttributes NOT to be called")
+	}
+}
+
+func TestUpdateAttributes_NoSession(t *testing.T) {
+	logger := zapTestLogger(t)
+	repo := &fakeAnnRepo{}
+	handler := NewAnnouncementHandler(logger, repo, &fakeProducer{}, &fakeRecentlyViewed{}, nil)
+
+	rr := httptest.NewRecorder()
+	handler.UpdateAttributes(rr, updateAttributesRequest(t, "", `{"brand":"Apple"}`))
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("expected status 401, got %d", rr.Code)
+	}
+}
+
+func TestUpdateAttributes_Invalid(t *testing.T) {
+	logger := zapTestLogger(t)
+	repo := &fakeAnnRepo{
+		returnGetByIDAnn:    &repoAnn.Announcement{ID: "ann-1", UserSellerID: "seller-1"},
+		returnAttributesErr: myErr.ErrInvalidAttribute,
+	}
+	handler := NewAnnouncementHandler(logger, repo, &fakeProducer{}, &fakeRecentlyViewed{}, nil)
+
+	rr := httptest.NewRecorder()
+	handler.UpdateAttributes(rr, updateAttributesRequest(t, "seller-1", `{"condition":"broken"}`))
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rr.Code)
+	}
+}
+
+func TestUpdateAttributes_Success(t *testing.T) {
+	logger := zapTestLogger(t)
+	repo := &fakeAnnRepo{
+		returnGetByIDAnn: &repoAnn.Announcement{ID: "ann-1", UserSellerID: "seller-1"},
+		returnAttributes: map[string]string{"brand": "Apple"},
+	}
+	handler := NewAnnouncementHandler(logger, repo, &fakeProducer{}, &fakeRecentlyViewed{}, nil)
+
+	rr := httptest.NewRecorder()
+	handler.UpdateAttributes(rr, updateAttributesRequest(t, "seller-1", `{"brand":"Apple"}`))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	if repo.lastUpdateAttributes["brand"] != "Apple" {
+		t.Errorf("expected repo.UpdateAttributes to receive brand=Apple, got %v", repo.lastUpdateAttributes)
+	}
+}
+
+func renewRequest(t *testing.T, userID, body string) *http.Request {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/announcement/ann-1/renew", bytes.NewBufferString(body))
+	req = mux.SetURLVars(req, map[string]string{"id": "ann-1"})
+	if userID != "" {
+		req = req.WithContext(middleware.ContextWithSession(req.Context(), &session.Session{UserID: userID}))
+	}
+	return req
+}
+
+func TestRenew_DefaultPeriod(t *testing.T) {
+	logger := zapTestLogger(t)
+	expiresAt := time.Now().Add(30 * 24 * time.Hour)
+	repo := &fakeAnnRepo{
+		returnGetByIDAnn: &repoAnn.Announcement{ID: "ann-1", UserSellerID: "seller-1"},
+		returnRenewAnn:   &repoAnn.Announcement{ID: "ann-1", UserSellerID: "seller-1", ExpiresAt: expiresAt},
+	}
+	handler := NewAnnouncementHandler(logger, repo, &fakeProducer{}, &fakeRecentlyViewed{}, nil)
+
+	rr := httptest.NewRecorder()
+	handler.Renew(rr, renewRequest(t, "seller-1", ""))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	if repo.lastRenewPeriod != 0 {
+		t.Errorf("expected default period, got %v", repo.lastRenewPeriod)
+	}
+}
+
+func TestRenew_Days(t *testing.T) {
+	logger := zapTestLogger(t)
+	repo := &fakeAnnRepo{
+		returnGetByIDAnn: &repoAnn.Announcement{ID: "ann-1", UserSellerID: "seller-1"},
+		returnRenewAnn:   &repoAnn.Announcement{ID: "ann-1", UserSellerID: "seller-1"},
+	}
+	handler := NewAnnouncementHandler(logger, repo, &fakeProducer{}, &fakeRecentlyViewed{}, nil)
+
+	rr := httptest.NewRecorder()
+	handler.Renew(rr, renewRequest(t, "seller-1", `{"days":7}`))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	if repo.lastRenewPeriod != 7*24*time.Hour {
+		t.Errorf("expected 7 days, got %v", repo.lastRenewPeriod)
+	}
+}
+
+func TestRenew_TooLong(t *testing.T) {
+	logger := zapTestLogger(t)
+	repo := &fakeAnnRepo{}
+	handler := NewAnnouncementHandler(logger, repo, &fakeProducer{}, &fakeRecentlyViewed{}, nil)
+
+	rr := httptest.NewRecorder()
+	handler.Renew(rr, renewRequest(t, "seller-1", `{"days":365}`))
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rr.Code)
+	}
+}
+
+func TestRenew_NotOwner(t *testing.T) {
+	logger := zapTestLogger(t)
+	repo := &fakeAnnRepo{
+		returnGetByIDAnn: &repoAnn.Announcement{ID: "ann-1", UserSellerID: "seller-1"},
+	}
+	handler := NewAnnouncementHandler(logger, repo, &fakeProducer{}, &fakeRecentlyViewed{}, nil)
+
+	rr := httptest.NewRecorder()
+	handler.Renew(rr, renewRequest(t, "other-user", ""))
+
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("expected status 403, got %d", rr.Code)
+	}
+}
+
+func TestCreate_ValidationErrors(t *testing.T) {
+	logger := zapTestLogger(t)
+	repo := &fakeAnnRepo{returnCreateErr: myErr.FieldErrors{"name": "is required", "price": "must be positive"}}
+	handler := NewAnnouncementHandler(logger, repo, &fakeProducer{}, &fakeRecentlyViewed{}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/announcement", bytes.NewBufferString(`{"category":1}`))
+	rr := httptest.NewRecorder()
+	handler.Create(rr, req)
+
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status 422, got %d", rr.Code)
+	}
+	var got struct {
+		Fields map[string]string `json:"fields"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if got.Fields["name"] != "is required" || got.Fields["price"] != "must be positive" {
+		t.Errorf("unexpected field errors: %v", got.Fields)
+	}
+}
+
+func TestGetByID_DraftHiddenFromOthers(t *testing.T) {
+	logger := zapTestLogger(t)
+	repo := &fakeAnnRepo{
+		returnGetByIDAnn: &repoAnn.Announcement{ID: "ann-1", UserSellerID: "seller-1", IsDraft: true},
+	}
+	handler := NewAnnouncementHandler(logger, repo, &fakeProducer{}, &fakeRecentlyViewed{}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/announcement/ann-1", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "ann-1"})
+	rr := httptest.NewRecorder()
+	handler.GetByID(rr, req)
+
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", rr.Code)
+	}
+}
+
+func ownerRequest(method, target, userID, body string) *http.Request {
+	req := httptest.NewRequest(method, target, bytes.NewBufferString(body))
+	req = mux.SetURLVars(req, map[string]string{"id": "ann-1"})
+	return req.WithContext(middleware.ContextWithSession(req.Context(), &session.Session{UserID: userID}))
+}
+
+func TestUpdate_Partial(t *testing.T) {
+	logger := zapTestLogger(t)
+	repo := &fakeAnnRepo{
+		returnGetByIDAnn: &repoAnn.Announcement{ID: "ann-1", UserSellerID: "seller-1", IsDraft: true},
+		returnUpdateAnn:  &repoAnn.Announcement{ID: "ann-1", UserSellerID: "seller-1", IsDraft: true, Price: 500},
+	}
+	handler := NewAnnouncementHandler(logger, repo, &fakeProducer{}, &fakeRecentlyViewed{}, nil)
+
+	rr := httptest.NewRecorder()
+	handler.Update(rr, ownerRequest(http.MethodPatch, "/api/announcement/ann-1", "seller-1", `{"price":500}`))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	if repo.lastUpdateInput.Price == nil || *repo.lastUpdateInput.Price != 500 {
+		t.Errorf("expected price 500 in update, got %+v", repo.lastUpdateInput)
+	}
+	if repo.lastUpdateInput.Name != nil {
+		t.Errorf("expected name to stay unset, got %q", *repo.lastUpdateInput.Name)
+	}
+}
+
+func TestUpdate_NotOwner(t *testing.T) {
+	logger := zapTestLogger(t)
+	repo := &fakeAnnRepo{
+		returnGetByIDAnn: &repoAnn.Announcement{ID: "ann-1", UserSellerID: "seller-1"},
+	}
+	handler := NewAnnouncementHandler(logger, repo, &fakeProducer{}, &fakeRecentlyViewed{}, nil)
+
+	rr := httptest.NewRecorder()
+	handler.Update(rr, ownerRequest(http.MethodPatch, "/api/announcement/ann-1", "other-user", `{"price":500}`))
+
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("expected status 403, got %d", rr.Code)
+	}
+}
+
+func TestPublish_ValidationErrors(t *testing.T) {
+	logger := zapTestLogger(t)
+	repo := &fakeAnnRepo{
+		returnGetByIDAnn: &repoAnn.Announcement{ID: "ann-1", UserSellerID: "seller-1", IsDraft: true},
+		returnPublishErr: myErr.FieldErrors{"category": "is required"},
+	}
+	handler := NewAnnouncementHandler(logger, repo, &fakeProducer{}, &fakeRecentlyViewed{}, nil)
+
+	rr := httptest.NewRecorder()
+	handler.Publish(rr, ownerRequest(http.MethodPost, "/api/announcement/ann-1/publish", "seller-1", ""))
+
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status 422, got %d", rr.Code)
+	}
+}
+
+func TestPublish_AlreadyPublished(t *testing.T) {
+	logger := zapTestLogger(t)
+	repo := &fakeAnnRepo{
+		returnGetByIDAnn: &repoAnn.Announcement{ID: "ann-1", UserSellerID: "seller-1"},
+		returnPublishErr: myErr.ErrNotDraft,
+	}
+	handler := NewAnnouncementHandler(logger, repo, &fakeProducer{}, &fakeRecentlyViewed{}, nil)
+
+	rr := httptest.NewRecorder()
+	handler.Publish(rr, ownerRequest(http.MethodPost, "/api/announcement/ann-1/publish", "seller-1", ""))
+
+	if rr.Code != http.StatusConflict {
+		t.Errorf("expected status 409, got %d", rr.Code)
+	}
+}
+
+func TestPublish_Success(t *testing.T) {
+	logger := zapTestLogger(t)
+	repo := &fakeAnnRepo{
+		returnGetByIDAnn: &repoAnn.Announcement{ID: "ann-1", UserSellerID: "seller-1", IsDraft: true},
+		returnPublishAnn: &repoAnn.Announcement{ID: "ann-1", UserSellerID: "seller-1", IsActive: true},
+	}
+	handler := NewAnnouncementHandler(logger, repo, &fakeProducer{}, &fakeRecentlyViewed{}, nil)
+
+	rr := httptest.NewRecorder()
+	handler.Publish(rr, ownerRequest(http.MethodPost, "/api/announcement/ann-1/publish", "seller-1", ""))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	if !repo.publishCalled {
+		t.Errorf("expected repo.Publish to be called")
+	}
+}
+
+func TestGetByID_WithUser_SavesRecentlyViewed(t *testing.T) {
+	logger := zapTestLogger(t)
+	repo := &fakeAnnRepo{returnGetByIDAnn: &repoAnn.Announcement{ID: "ann-1", Category: 2}}
+	prod := &fakeProducer{}
+	recent := &fakeRecentlyViewed{}
+	handler := NewAnnouncementHandler(logger, repo, prod, recent, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/announcement/ann-1", nil)
+	req = req.WithContext(middleware.ContextWithSession(req.Context(), &session.Session{UserID: "user-1"}))
+	rr := httptest.NewRecorder()
+
+	r := mux.NewRouter()
+	r.HandleFunc("/announcement/{id}", handler.GetByID).Methods(http.MethodGet)
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	if !reflect.DeepEqual(recent.added, []string{"user-1:ann-1"}) {
+		t.Errorf("expected view of ann-1 by user-1 to be saved, got %v", recent.added)
+	}
+	if len(prod.calledEvents) != 1 {
+		t.Errorf("expected one view event, got %d", len(prod.calledEvents))
+	}
+}
+
+func recentlyViewedRequest(userID, sessionUserID string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/api/user/"+userID+"/recently-viewed", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": userID})
+	return req.WithContext(middleware.ContextWithSession(req.Context(), &session.Session{UserID: sessionUserID}))
+}
+
+func TestRecentlyViewed_KeepsViewOrder(t *testing.T) {
+	logger := zapTestLogger(t)
+	repo := &fakeAnnRepo{returnInfos: []typesAnn.InfoForSC{
+		{ID: "ann-1", Name: "Диван"},
+		{ID: "ann-3", Name: "Стол"},
+	}}
+	recent := &fakeRecentlyViewed{returnIDs: []string{"ann-3", "ann-2", "ann-1"}}
+	handler := NewAnnouncementHandler(logger, repo, &fakeProducer{}, recent, nil)
+
+	rr := httptest.NewRecorder()
+	handler.RecentlyViewed(rr, recentlyViewedRequest("user-1", "user-1"))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	var got []typesAnn.InfoForSC
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	// ann-2 уже снято с продажи и не вернулось из Postgres
+	if len(got) != 2 || got[0].ID != "ann-3" || got[1].ID != "ann-1" {
+		t.Errorf("unexpected recently viewed: %+v", got)
+	}
+}
+
+func TestRecentlyViewed_OtherUser(t *testing.T) {
+	logger := zapTestLogger(t)
+	handler := NewAnnouncementHandler(logger, &fakeAnnRepo{}, &fakeProducer{}, &fakeRecentlyViewed{}, nil)
+
+	rr := httptest.NewRecorder()
+	handler.RecentlyViewed(rr, recentlyViewedRequest("user-1", "user-2"))
+
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("expected status 403, got %d", rr.Code)
+	}
+}
+
+func compareRequest(body string) *http.Request {
+	return httptest.NewRequest(http.MethodPost, "/api/announcements/compare", bytes.NewBufferString(body))
+}
+
+func TestCompare_FlagsDifferences(t *testing.T) {
+	logger := zapTestLogger(t)
+	repo := &fakeAnnRepo{returnCompare: []typesAnn.CompareItem{
+		{ID: "ann-2", EffectivePrice: 900, Price: 1000, Discount: 10, SellerRating: 4.5,
+			Attributes: map[string]string{"ram": "16", "cpu": "i7"}},
+		{ID: "ann-1", EffectivePrice: 900, Price: 900, SellerRating: 4.5,
+			Attributes: map[string]string{"ram": "8"}},
+	}}
+	handler := NewAnnouncementHandler(logger, repo, &fakeProducer{}, &fakeRecentlyViewed{}, nil)
+
+	rr := httptest.NewRecorder()
+	handler.Compare(rr, compareRequest(`{"ids": ["ann-1", "ann-2", "ann-1"]}`))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if !reflect.DeepEqual(repo.lastCompareIDs, []string{"ann-1", "ann-2"}) {
+		t.Errorf("expected deduplicated ids, got %v", repo.lastCompareIDs)
+	}
+
+	var got comparison
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if got.Announcements[0].ID != "ann-1" || got.Announcements[1].ID != "ann-2" {
+		t.Errorf("expected request order, got %+v", got.Announcements)
+	}
+
+	differs := make(map[string]bool)
+	for _, row := range got.Fields {
+		differs[row.Field] = row.Differs
+	}
+	if differs["effective_price"] || differs["seller_rating"] || !differs["price"] || !differs["discount"] {
+		t.Errorf("unexpected field differences: %+v", differs)
+	}
+
+	if len(got.Attributes) != 2 {
+		t.Fatalf("expected union of 2 attributes, got %+v", got.Attributes)
+	}
+	cpu := got.Attributes[0]
+	if cpu.Field != "cpu" || cpu.Values[0] != nil || cpu.Values[1] != "i7" || !cpu.Differs {
+		t.Errorf("unexpected cpu row: %+v", cpu)
+	}
+}
+
+func TestCompare_Missing(t *testing.T) {
+	logger := zapTestLogger(t)
+	repo := &fakeAnnRepo{returnCompare: []typesAnn.CompareItem{{ID: "ann-1"}}}
+	handler := NewAnnouncementHandler(logger, repo, &fakeProducer{}, &fakeRecentlyViewed{}, nil)
+
+	rr := httptest.NewRecorder()
+	handler.Compare(rr, compareRequest(`{"ids": ["ann-1", "ann-2"]}`))
+
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", rr.Code)
+	}
+}
+
+func TestCompare_TooFewIDs(t *testing.T) {
+	logger := zapTestLogger(t)
+	handler := NewAnnouncementHandler(logger, &fakeAnnRepo{}, &fakeProducer{}, &fakeRecentlyViewed{}, nil)
+
+	rr := httptest.NewRecorder()
+	handler.Compare(rr, compareRequest(`{"ids": ["ann-1", "ann-1"]}`))
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rr.Code)
+	}
+}
+
+func similarRequest(target string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	return mux.SetURLVars(req, map[string]string{"id": "ann-1"})
+}
+
+func TestSimilar_ReturnsReasons(t *testing.T) {
+	logger := zapTestLogger(t)
+	repo := &fakeAnnRepo{returnSimilar: []repoAnn.SimilarAnnouncement{
+		{Announcement: repoAnn.Announcement{ID: "ann-2"}, Reasons: []string{"similar_text", "similar_price"}},
+	}}
+	handler := NewAnnouncementHandler(logger, repo, &fakeProducer{}, &fakeRecentlyViewed{}, nil)
+
+	rr := httptest.NewRecorder()
+	handler.Similar(rr, similarRequest("/api/announcement/ann-1/similar?limit=500"))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	if repo.lastSimilarLimit != maxSimilarLimit {
+		t.Errorf("expected limit capped at %d, got %d", maxSimilarLimit, repo.lastSimilarLimit)
+	}
+	var got []repoAnn.SimilarAnnouncement
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if len(got) != 1 || got[0].ID != "ann-2" || !reflect.DeepEqual(got[0].Reasons, []string{"similar_text", "similar_price"}) {
+		t.Errorf("unexpected similar: %+v", got)
+	}
+}
+
+func TestSimilar_NotFound(t *testing.T) {
+	logger := zapTestLogger(t)
+	repo := &fakeAnnRepo{returnSimilarErr: myErr.ErrNotFound}
+	handler := NewAnnouncementHandler(logger, repo, &fakeProducer{}, &fakeRecentlyViewed{}, nil)
+
+	rr := httptest.NewRecorder()
+	handler.Similar(rr, similarRequest("/api/announcement/ann-1/similar"))
+
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", rr.Code)
+	}
+}
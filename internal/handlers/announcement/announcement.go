@@ -6,32 +6,60 @@ import (
 	"fmt"
 	"gafroshka-main/internal/kafka"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
 	"go.uber.org/zap"
 
+	analyticsclient "gafroshka-main/internal/analytics_client"
 	"gafroshka-main/internal/announcement"
+	"gafroshka-main/internal/contextutil"
+	recentlyviewed "gafroshka-main/internal/recently_viewed"
 	typesAnn "gafroshka-main/internal/types/announcement"
 	myErr "gafroshka-main/internal/types/errors"
+	"gafroshka-main/internal/visitor"
 )
 
-// AnnouncementHandler работает с AnnouncementRepo и EventProducer интерфейсами.
+const (
+	// attrParamPrefix - префикс параметров запроса с фильтрами по атрибутам: attr.brand=Samsung
+	attrParamPrefix = "attr."
+	// maxRenewDays - максимальный срок одного продления объявления
+	maxRenewDays = int(announcement.MaxRenewPeriod / (24 * time.Hour))
+
+	defaultRecentlyViewedLimit = 20
+	maxRecentlyViewedLimit     = 100
+
+	defaultSimilarLimit = 10
+	maxSimilarLimit     = 50
+
+	// maxPreferenceCategories - сколько самых весомых категорий пользователя учитывать в выдаче
+	maxPreferenceCategories = 10
+)
+
+// AnnouncementHandler работает с AnnouncementRepo, EventProducer, RecentlyViewedRepo и AnalyticsClient интерфейсами.
 type AnnouncementHandler struct {
-	Logger           *zap.SugaredLogger
-	AnnouncementRepo announcement.AnnouncementRepo
-	EventProducer    kafka.EventProducer
+	Logger             *zap.SugaredLogger
+	AnnouncementRepo   announcement.AnnouncementRepo
+	EventProducer      kafka.EventProducer
+	RecentlyViewedRepo recentlyviewed.RecentlyViewedRepo
+	AnalyticsClient    analyticsclient.AnalyticsClient
 }
 
 func NewAnnouncementHandler(
 	l *zap.SugaredLogger,
 	ar announcement.AnnouncementRepo,
 	kp kafka.EventProducer,
+	rv recentlyviewed.RecentlyViewedRepo,
+	ac analyticsclient.AnalyticsClient,
 ) *AnnouncementHandler {
 	return &AnnouncementHandler{
-		Logger:           l,
-		AnnouncementRepo: ar,
-		EventProducer:    kp,
+		Logger:             l,
+		AnnouncementRepo:   ar,
+		EventProducer:      kp,
+		RecentlyViewedRepo: rv,
+		AnalyticsClient:    ac,
 	}
 }
 
@@ -45,6 +73,17 @@ func (h *AnnouncementHandler) Create(w http.ResponseWriter, r *http.Request) {
 
 	ann, err := h.AnnouncementRepo.Create(input)
 	if err != nil {
+		var fe myErr.FieldErrors
+		if errors.As(err, &fe) {
+			myErr.SendFieldErrorsTo(w, fe, h.Logger)
+			return
+		}
+		if errors.Is(err, myErr.ErrUnknownCategory) ||
+			errors.Is(err, myErr.ErrInvalidAttribute) ||
+			errors.Is(err, myErr.ErrInvalidQuantity) {
+			myErr.SendErrorTo(w, err, http.StatusBadRequest, h.Logger)
+			return
+		}
 		myErr.SendErrorTo(w, err, http.StatusInternalServerError, h.Logger)
 		return
 	}
@@ -59,7 +98,15 @@ func (h *AnnouncementHandler) Create(w http.ResponseWriter, r *http.Request) {
 	h.Logger.Infof("announcement created: %s", ann.ID)
 }
 
-// GetByID handles GET /announcement/{id}
+// requestUserID - пользователь сессии, если она есть
+// Параметр пути user_id не учитывается: ручки публичные, и по нему кто угодно
+// писал бы просмотры и предпочтения за чужой аккаунт
+func requestUserID(r *http.Request) string {
+	userID, _ := contextutil.GetUserIDFromContext(r.Context())
+	return userID
+}
+
+// GetByID handles GET /announcement/{id} and GET /announcement/{id}/{user_id}
 func (h *AnnouncementHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
@@ -78,20 +125,40 @@ func (h *AnnouncementHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Отправляем событие "view" в Kafka, если есть user_id в запросе
-	userID := vars["user_id"]
-	if userID != "" {
-		event := kafka.Event{
-			UserID:     userID,
-			Type:       kafka.EventTypeView,
-			Categories: []int{ann.Category},
-			Timestamp:  time.Now(),
+	// черновик виден только продавцу
+	if ann.IsDraft {
+		sellerID, ok := contextutil.GetUserIDFromContext(r.Context())
+		if !ok || sellerID != ann.UserSellerID {
+			myErr.SendErrorTo(w, myErr.ErrNotFound, http.StatusNotFound, h.Logger)
+			return
 		}
-		if err := h.EventProducer.SendEvent(r.Context(), event); err != nil {
-			h.Logger.Warnf("failed to send view event: %v", err)
+	}
+
+	// Запоминаем просмотр за пользователем, а без него - за анонимным посетителем:
+	// после входа его просмотры переносятся в аккаунт. Событие "view" уходит в Kafka в обоих случаях
+	userID := requestUserID(r)
+	viewerID := userID
+	if viewerID == "" {
+		if visitorID, ok := visitor.FromContext(r.Context()); ok {
+			viewerID = visitor.Key(visitorID)
+		}
+	}
+	if viewerID != "" {
+		if err := h.RecentlyViewedRepo.Add(r.Context(), viewerID, ann.ID); err != nil {
+			h.Logger.Warnf("failed to save recently viewed: %v", err)
 		}
-	} else {
-		h.Logger.Infof("user_id not provided, skipping analytics event for GetByID")
+	}
+
+	event := kafka.Event{
+		UserID:         userID,
+		Type:           kafka.EventTypeView,
+		Categories:     []int{ann.Category},
+		AnnouncementID: ann.ID,
+		SellerID:       ann.UserSellerID,
+		Timestamp:      time.Now(),
+	}
+	if err := h.EventProducer.SendEvent(r.Context(), event); err != nil {
+		h.Logger.Warnf("failed to send view event: %v", err)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -121,24 +188,25 @@ func (h *AnnouncementHandler) GetTopN(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var categories []int
-	if input.UserID != "" {
-		// Запрос к сервису аналитики
-		url := fmt.Sprintf("http://localhost:8082/user/%s/preferences?top=%d", input.UserID, input.Limit)
-		resp, err := http.Get(url)
-		if err == nil {
-			defer resp.Body.Close()
-			if resp.StatusCode == http.StatusOK {
-				if err := json.NewDecoder(resp.Body).Decode(&categories); err != nil {
-					h.Logger.Warnf("Failed to decode user preferences: %v", err)
-				}
-			}
-		} else {
+	// Веса категорий из сервиса аналитики: у анонима - накопленные за посетителем.
+	// Без весов или при недоступной аналитике выдача строится как для нового пользователя
+	ownerID := input.UserID
+	if ownerID == "" {
+		if visitorID, ok := visitor.FromContext(r.Context()); ok {
+			ownerID = visitor.Key(visitorID)
+		}
+	}
+	var prefs map[int]float64
+	if ownerID != "" {
+		weights, err := h.AnalyticsClient.GetCategoryWeights(r.Context(), ownerID, maxPreferenceCategories)
+		if err != nil {
 			h.Logger.Warnf("Failed to get user preferences: %v", err)
+		} else {
+			prefs = weights
 		}
 	}
 
-	anns, err := h.AnnouncementRepo.GetTopN(input.Limit, categories)
+	anns, err := h.AnnouncementRepo.GetTopN(input.Limit, prefs)
 	if err != nil {
 		myErr.SendErrorTo(w, err, http.StatusInternalServerError, h.Logger)
 		return
@@ -151,19 +219,58 @@ func (h *AnnouncementHandler) GetTopN(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	h.Logger.Infof("fetched top %d announcements for user %s, categories %v", input.Limit, input.UserID, categories)
+	h.Logger.Infof("fetched top %d announcements for user %s, preferences %v", input.Limit, input.UserID, prefs)
+}
+
+// parseSearchFilter разбирает параметры поиска: q, category и attr.<code>=<value>
+func parseSearchFilter(r *http.Request) (typesAnn.SearchFilter, error) {
+	values := r.URL.Query()
+	filter := typesAnn.SearchFilter{
+		Query: values.Get("q"),
+	}
+
+	// category необязательный: поиск по родительской категории включает дочерние
+	if c := values.Get("category"); c != "" {
+		n, err := strconv.Atoi(c)
+		if err != nil || n <= 0 {
+			return filter, myErr.ErrUnknownCategory
+		}
+		filter.CategoryID = n
+	}
+
+	for key, vals := range values {
+		code, ok := strings.CutPrefix(key, attrParamPrefix)
+		if !ok || code == "" || len(vals) == 0 {
+			continue
+		}
+		if filter.Attributes == nil {
+			filter.Attributes = make(map[string]string)
+		}
+		filter.Attributes[code] = vals[0]
+	}
+
+	return filter, nil
 }
 
-// Search handles GET /announcements/search?q=...&user_id=...
+// Search handles GET /announcements/search and GET /announcements/search/{user_id}
+// with ?q=...&category=...&attr.<code>=...
 func (h *AnnouncementHandler) Search(w http.ResponseWriter, r *http.Request) {
-	q := r.URL.Query().Get("q")
-	if q == "" {
+	filter, err := parseSearchFilter(r)
+	if err != nil {
+		myErr.SendErrorTo(w, err, http.StatusBadRequest, h.Logger)
+		return
+	}
+	if filter.Query == "" {
 		myErr.SendErrorTo(w, errors.New("missing query parameter"), http.StatusBadRequest, h.Logger)
 		return
 	}
 
-	anns, err := h.AnnouncementRepo.Search(q)
+	anns, err := h.AnnouncementRepo.Search(filter)
 	if err != nil {
+		if errors.Is(err, myErr.ErrUnknownCategory) || errors.Is(err, myErr.ErrInvalidAttribute) {
+			myErr.SendErrorTo(w, err, http.StatusBadRequest, h.Logger)
+			return
+		}
 		myErr.SendErrorTo(w, err, http.StatusInternalServerError, h.Logger)
 		return
 	}
@@ -178,10 +285,11 @@ func (h *AnnouncementHandler) Search(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Отправляем событие "search" в Kafka, если есть user_id в запросе
-	vars := mux.Vars(r)
-	userID := vars["user_id"]
-	if userID != "" {
+	// Отправляем событие "search" в Kafka за пользователем или анонимным посетителем,
+	// продюсер сам добавит id посетителя из контекста
+	userID := requestUserID(r)
+	_, isVisitor := visitor.FromContext(r.Context())
+	if userID != "" || isVisitor {
 		event := kafka.Event{
 			UserID:     userID,
 			Type:       kafka.EventTypeSearch,
@@ -192,7 +300,7 @@ func (h *AnnouncementHandler) Search(w http.ResponseWriter, r *http.Request) {
 			h.Logger.Warnf("failed to send search event: %v", err)
 		}
 	} else {
-		h.Logger.Infof("user_id not provided, skipping analytics event for Search")
+		h.Logger.Infof("user not identified, skipping analytics event for Search")
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -202,5 +310,304 @@ func (h *AnnouncementHandler) Search(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	h.Logger.Infof("searched announcements with query: %s", q)
+	h.Logger.Infof("searched announcements with query: %s", filter.Query)
+}
+
+// Facets handles GET /announcements/facets?category=...&q=...&attr.<code>=...
+// Возвращает количество объявлений по каждому значению атрибутов категории
+func (h *AnnouncementHandler) Facets(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseSearchFilter(r)
+	if err != nil || filter.CategoryID == 0 {
+		myErr.SendErrorTo(w, myErr.ErrUnknownCategory, http.StatusBadRequest, h.Logger)
+		return
+	}
+
+	facets, err := h.AnnouncementRepo.Facets(filter)
+	if err != nil {
+		if errors.Is(err, myErr.ErrUnknownCategory) || errors.Is(err, myErr.ErrInvalidAttribute) {
+			myErr.SendErrorTo(w, err, http.StatusBadRequest, h.Logger)
+			return
+		}
+		myErr.SendErrorTo(w, err, http.StatusInternalServerError, h.Logger)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(facets); err != nil {
+		myErr.SendErrorTo(w, err, http.StatusInternalServerError, h.Logger)
+		return
+	}
+}
+
+// UpdateAttributes handles PUT /announcement/{id}/attributes
+// Принимает JSON-объект код атрибута -> значение, заменяет им текущие атрибуты
+func (h *AnnouncementHandler) UpdateAttributes(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	var input map[string]string
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		myErr.SendErrorTo(w, myErr.ErrInvalidJSONPayload, http.StatusBadRequest, h.Logger)
+		return
+	}
+
+	if !h.checkOwner(w, r, id) {
+		return
+	}
+
+	attributes, err := h.AnnouncementRepo.UpdateAttributes(id, input)
+	if err != nil {
+		switch {
+		case errors.Is(err, myErr.ErrInvalidAttribute):
+			myErr.SendErrorTo(w, err, http.StatusBadRequest, h.Logger)
+		case errors.Is(err, myErr.ErrNotFound):
+			myErr.SendErrorTo(w, err, http.StatusNotFound, h.Logger)
+		default:
+			myErr.SendErrorTo(w, err, http.StatusInternalServerError, h.Logger)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(attributes); err != nil {
+		myErr.SendErrorTo(w, err, http.StatusInternalServerError, h.Logger)
+		return
+	}
+
+	h.Logger.Infof("updated attributes of announcement %s", id)
+}
+
+// Renew handles POST /announcement/{id}/renew
+// Необязательное тело {"days": N} задает срок продления, без него - стандартный срок публикации
+func (h *AnnouncementHandler) Renew(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	var input struct {
+		Days int `json:"days"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			myErr.SendErrorTo(w, myErr.ErrInvalidJSONPayload, http.StatusBadRequest, h.Logger)
+			return
+		}
+	}
+	if input.Days < 0 || input.Days > maxRenewDays {
+		myErr.SendErrorTo(w, fmt.Errorf("days must be between 1 and %d", maxRenewDays), http.StatusBadRequest, h.Logger)
+		return
+	}
+
+	if !h.checkOwner(w, r, id) {
+		return
+	}
+
+	ann, err := h.AnnouncementRepo.Renew(id, time.Duration(input.Days)*24*time.Hour)
+	if err != nil {
+		if errors.Is(err, myErr.ErrNotFound) {
+			myErr.SendErrorTo(w, err, http.StatusNotFound, h.Logger)
+			return
+		}
+		myErr.SendErrorTo(w, err, http.StatusInternalServerError, h.Logger)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(ann); err != nil {
+		h.Logger.Warnw("error writing response", "err", err)
+		return
+	}
+
+	h.Logger.Infof("announcement %s renewed until %s", id, ann.ExpiresAt)
+}
+
+// Update handles PATCH /announcement/{id}
+// Частично изменяет объявление, в теле передаются только изменяемые поля
+func (h *AnnouncementHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	var input typesAnn.UpdateAnnouncement
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		myErr.SendErrorTo(w, myErr.ErrInvalidJSONPayload, http.StatusBadRequest, h.Logger)
+		return
+	}
+
+	if !h.checkOwner(w, r, id) {
+		return
+	}
+
+	ann, err := h.AnnouncementRepo.Update(id, input)
+	if err != nil {
+		var fe myErr.FieldErrors
+		switch {
+		case errors.As(err, &fe):
+			myErr.SendFieldErrorsTo(w, fe, h.Logger)
+		case errors.Is(err, myErr.ErrNotFound):
+			myErr.SendErrorTo(w, err, http.StatusNotFound, h.Logger)
+		default:
+			myErr.SendErrorTo(w, err, http.StatusInternalServerError, h.Logger)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(ann); err != nil {
+		h.Logger.Warnw("error writing response", "err", err)
+		return
+	}
+
+	h.Logger.Infof("announcement %s updated", id)
+}
+
+// Publish handles POST /announcement/{id}/publish
+// Проверяет черновик целиком и публикует его, ошибки возвращаются по полям со статусом 422
+func (h *AnnouncementHandler) Publish(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	if !h.checkOwner(w, r, id) {
+		return
+	}
+
+	ann, err := h.AnnouncementRepo.Publish(id)
+	if err != nil {
+		var fe myErr.FieldErrors
+		switch {
+		case errors.As(err, &fe):
+			myErr.SendFieldErrorsTo(w, fe, h.Logger)
+		case errors.Is(err, myErr.ErrNotDraft):
+			myErr.SendErrorTo(w, err, http.StatusConflict, h.Logger)
+		case errors.Is(err, myErr.ErrNotFound):
+			myErr.SendErrorTo(w, err, http.StatusNotFound, h.Logger)
+		default:
+			myErr.SendErrorTo(w, err, http.StatusInternalServerError, h.Logger)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(ann); err != nil {
+		h.Logger.Warnw("error writing response", "err", err)
+		return
+	}
+
+	h.Logger.Infof("announcement %s published, active: %t", id, ann.IsActive)
+}
+
+// RecentlyViewed handles GET /user/{id}/recently-viewed?limit=N
+// Список доступен только самому пользователю, снятые и удаленные объявления пропускаются
+func (h *AnnouncementHandler) RecentlyViewed(w http.ResponseWriter, r *http.Request) {
+	userID := mux.Vars(r)["id"]
+
+	sessionUserID, ok := contextutil.GetUserIDFromContext(r.Context())
+	if !ok {
+		myErr.SendErrorTo(w, myErr.ErrNoAuth, http.StatusUnauthorized, h.Logger)
+		return
+	}
+	if sessionUserID != userID {
+		myErr.SendErrorTo(w, myErr.ErrForbidden, http.StatusForbidden, h.Logger)
+		return
+	}
+
+	limit := defaultRecentlyViewedLimit
+	if l := r.URL.Query().Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n <= 0 {
+			myErr.SendErrorTo(w, errors.New("limit must be positive number"), http.StatusBadRequest, h.Logger)
+			return
+		}
+		limit = min(n, maxRecentlyViewedLimit)
+	}
+
+	ids, err := h.RecentlyViewedRepo.Get(r.Context(), userID, limit)
+	if err != nil {
+		myErr.SendErrorTo(w, err, http.StatusInternalServerError, h.Logger)
+		return
+	}
+
+	infos, err := h.AnnouncementRepo.GetInfoForShoppingCart(ids)
+	if err != nil {
+		myErr.SendErrorTo(w, err, http.StatusInternalServerError, h.Logger)
+		return
+	}
+
+	// GetInfoForShoppingCart не сохраняет порядок, восстанавливаем его по времени просмотра
+	infoByID := make(map[string]typesAnn.InfoForSC, len(infos))
+	for _, info := range infos {
+		infoByID[info.ID] = info
+	}
+	result := make([]typesAnn.InfoForSC, 0, len(ids))
+	for _, id := range ids {
+		if info, ok := infoByID[id]; ok {
+			result = append(result, info)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		h.Logger.Warnw("error writing response", "err", err)
+		return
+	}
+}
+
+// Similar handles GET /announcement/{id}/similar?limit=N
+// Возвращает похожие объявления других продавцов, у каждого - причины подбора
+func (h *AnnouncementHandler) Similar(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	limit := defaultSimilarLimit
+	if l := r.URL.Query().Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n <= 0 {
+			myErr.SendErrorTo(w, errors.New("limit must be positive number"), http.StatusBadRequest, h.Logger)
+			return
+		}
+		limit = min(n, maxSimilarLimit)
+	}
+
+	similar, err := h.AnnouncementRepo.GetSimilar(id, limit)
+	if err != nil {
+		if errors.Is(err, myErr.ErrNotFound) {
+			myErr.SendErrorTo(w, err, http.StatusNotFound, h.Logger)
+			return
+		}
+		myErr.SendErrorTo(w, err, http.StatusInternalServerError, h.Logger)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(similar); err != nil {
+		h.Logger.Warnw("error writing response", "err", err)
+		return
+	}
+}
+
+// checkOwner проверяет, что объявление принадлежит пользователю из сессии
+// При ошибке сам отправляет ответ и возвращает false
+func (h *AnnouncementHandler) checkOwner(w http.ResponseWriter, r *http.Request, annID string) bool {
+	userID, ok := contextutil.GetUserIDFromContext(r.Context())
+	if !ok {
+		myErr.SendErrorTo(w, myErr.ErrNoAuth, http.StatusUnauthorized, h.Logger)
+		return false
+	}
+
+	ann, err := h.AnnouncementRepo.GetByID(annID)
+	if err != nil {
+		if errors.Is(err, myErr.ErrNotFound) {
+			myErr.SendErrorTo(w, err, http.StatusNotFound, h.Logger)
+			return false
+		}
+		myErr.SendErrorTo(w, err, http.StatusInternalServerError, h.Logger)
+		return false
+	}
+
+	if ann.UserSellerID != userID {
+		myErr.SendErrorTo(w, myErr.ErrForbidden, http.StatusForbidden, h.Logger)
+		return false
+	}
+
+	return true
 }
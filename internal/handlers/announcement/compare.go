@@ -0,0 +1,159 @@
+package announcement
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	typesAnn "gafroshka-main/internal/types/announcement"
+	myErr "gafroshka-main/internal/types/errors"
+)
+
+// maxCompareItems - сколько объявлений можно сравнить за раз
+const maxCompareItems = 10
+
+// compareRow - строка сравнения: значения по объявлениям в порядке запроса
+type compareRow struct {
+	Field   string        `json:"field"`
+	Values  []interface{} `json:"values"`
+	Differs bool          `json:"differs"`
+}
+
+// comparison - таблица сравнения: колонки - объявления, строки - поля и атрибуты
+type comparison struct {
+	Announcements []typesAnn.CompareItem `json:"announcements"`
+	Fields        []compareRow           `json:"fields"`
+	// Attributes - объединение атрибутов всех объявлений, null - у объявления атрибута нет
+	Attributes []compareRow `json:"attributes"`
+}
+
+// compareFields - сравниваемые поля объявления в порядке вывода
+var compareFields = []struct {
+	name  string
+	value func(it typesAnn.CompareItem) interface{}
+}{
+	{"effective_price", func(it typesAnn.CompareItem) interface{} { return it.EffectivePrice }},
+	{"price", func(it typesAnn.CompareItem) interface{} { return it.Price }},
+	{"discount", func(it typesAnn.CompareItem) interface{} { return it.Discount }},
+	{"rating", func(it typesAnn.CompareItem) interface{} { return it.Rating }},
+	{"rating_count", func(it typesAnn.CompareItem) interface{} { return it.RatingCount }},
+	{"seller_rating", func(it typesAnn.CompareItem) interface{} { return it.SellerRating }},
+	{"seller_rating_count", func(it typesAnn.CompareItem) interface{} { return it.SellerRatingCount }},
+	{"category", func(it typesAnn.CompareItem) interface{} { return it.Category }},
+	{"is_active", func(it typesAnn.CompareItem) interface{} { return it.IsActive }},
+}
+
+// Compare handles POST /announcements/compare
+// Принимает {"ids": [...]} и возвращает таблицу сравнения с отметками отличающихся полей
+func (h *AnnouncementHandler) Compare(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		IDs []string `json:"ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		myErr.SendErrorTo(w, errors.New("invalid JSON payload"), http.StatusBadRequest, h.Logger)
+		return
+	}
+
+	ids := make([]string, 0, len(input.IDs))
+	seen := make(map[string]struct{}, len(input.IDs))
+	for _, id := range input.IDs {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		ids = append(ids, id)
+	}
+	if len(ids) < 2 || len(ids) > maxCompareItems {
+		err := fmt.Errorf("ids must contain from 2 to %d different announcements", maxCompareItems)
+		myErr.SendErrorTo(w, err, http.StatusBadRequest, h.Logger)
+		return
+	}
+
+	items, err := h.AnnouncementRepo.GetForCompare(ids)
+	if err != nil {
+		myErr.SendErrorTo(w, err, http.StatusInternalServerError, h.Logger)
+		return
+	}
+
+	// восстанавливаем порядок запроса, отсутствующие объявления - 404
+	byID := make(map[string]typesAnn.CompareItem, len(items))
+	for _, it := range items {
+		byID[it.ID] = it
+	}
+	ordered := make([]typesAnn.CompareItem, 0, len(ids))
+	var missing []string
+	for _, id := range ids {
+		it, ok := byID[id]
+		if !ok {
+			missing = append(missing, id)
+			continue
+		}
+		ordered = append(ordered, it)
+	}
+	if len(missing) > 0 {
+		err := fmt.Errorf("%w: %s", myErr.ErrNotFound, strings.Join(missing, ", "))
+		myErr.SendErrorTo(w, err, http.StatusNotFound, h.Logger)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(buildComparison(ordered)); err != nil {
+		h.Logger.Warnw("error writing response", "err", err)
+		return
+	}
+}
+
+// buildComparison раскладывает объявления по строкам сравнения
+func buildComparison(items []typesAnn.CompareItem) comparison {
+	c := comparison{
+		Announcements: items,
+		Fields:        make([]compareRow, 0, len(compareFields)),
+	}
+
+	for _, f := range compareFields {
+		values := make([]interface{}, len(items))
+		for i, it := range items {
+			values[i] = f.value(it)
+		}
+		c.Fields = append(c.Fields, compareRow{Field: f.name, Values: values, Differs: differs(values)})
+	}
+
+	codes := make(map[string]struct{})
+	for _, it := range items {
+		for code := range it.Attributes {
+			codes[code] = struct{}{}
+		}
+	}
+	sorted := make([]string, 0, len(codes))
+	for code := range codes {
+		sorted = append(sorted, code)
+	}
+	sort.Strings(sorted)
+
+	c.Attributes = make([]compareRow, 0, len(sorted))
+	for _, code := range sorted {
+		values := make([]interface{}, len(items))
+		for i, it := range items {
+			if v, ok := it.Attributes[code]; ok {
+				values[i] = v
+			}
+		}
+		c.Attributes = append(c.Attributes, compareRow{Field: code, Values: values, Differs: differs(values)})
+	}
+
+	return c
+}
+
+// differs - есть ли среди значений хотя бы два разных, отсутствующее значение тоже считается отличием
+func differs(values []interface{}) bool {
+	for _, v := range values[1:] {
+		if v != values[0] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,271 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"gafroshka-main/internal/announcement"
+	announcementimage "gafroshka-main/internal/announcement_image"
+	"gafroshka-main/internal/blobstore"
+	"gafroshka-main/internal/contextutil"
+	typesAnn "gafroshka-main/internal/types/announcement"
+	myErr "gafroshka-main/internal/types/errors"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+const (
+	// imagesFormField - имя поля multipart-формы с файлами
+	imagesFormField = "images"
+	// multipartOverhead - запас на заголовки частей multipart-запроса
+	multipartOverhead = 1 << 20
+)
+
+// ImageHandler ручки для загрузки, сортировки и удаления фотографий объявления
+type ImageHandler struct {
+	Logger             *zap.SugaredLogger
+	ImageRepo          announcementimage.ImageRepo
+	AnnouncementRepo   announcement.AnnouncementRepo
+	BlobStore          blobstore.BlobStore
+	Processor          *announcementimage.Processor
+	MaxPerAnnouncement int
+}
+
+func NewImageHandler(
+	l *zap.SugaredLogger,
+	ir announcementimage.ImageRepo,
+	ar announcement.AnnouncementRepo,
+	bs blobstore.BlobStore,
+	p *announcementimage.Processor,
+	maxPerAnnouncement int,
+) *ImageHandler {
+	return &ImageHandler{
+		Logger:             l,
+		ImageRepo:          ir,
+		AnnouncementRepo:   ar,
+		BlobStore:          bs,
+		Processor:          p,
+		MaxPerAnnouncement: maxPerAnnouncement,
+	}
+}
+
+// Upload handles POST /announcement/{id}/images
+// Принимает multipart-форму с одним или несколькими файлами в поле images
+func (h *ImageHandler) Upload(w http.ResponseWriter, r *http.Request) {
+	annID := mux.Vars(r)["id"]
+	if !h.checkOwner(w, r, annID) {
+		return
+	}
+
+	count, err := h.ImageRepo.Count(annID)
+	if err != nil {
+		myErr.SendErrorTo(w, err, http.StatusInternalServerError, h.Logger)
+		return
+	}
+	free := h.MaxPerAnnouncement - count
+	if free <= 0 {
+		myErr.SendErrorTo(w, myErr.ErrTooManyImages, http.StatusBadRequest, h.Logger)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, int64(free)*h.Processor.MaxSize+multipartOverhead)
+	reader, err := r.MultipartReader()
+	if err != nil {
+		myErr.SendErrorTo(w, errors.New("expected multipart/form-data"), http.StatusBadRequest, h.Logger)
+		return
+	}
+
+	// сначала читаем и проверяем все файлы, чтобы не сохранить запрос наполовину
+	var processed []*announcementimage.ProcessedImage
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			myErr.SendErrorTo(w, myErr.ErrImageTooLarge, http.StatusRequestEntityTooLarge, h.Logger)
+			return
+		}
+		if part.FormName() != imagesFormField || part.FileName() == "" {
+			continue
+		}
+
+		if len(processed) == free {
+			myErr.SendErrorTo(w, myErr.ErrTooManyImages, http.StatusBadRequest, h.Logger)
+			return
+		}
+
+		data, err := io.ReadAll(io.LimitReader(part, h.Processor.MaxSize+1))
+		if err != nil {
+			myErr.SendErrorTo(w, myErr.ErrImageTooLarge, http.StatusRequestEntityTooLarge, h.Logger)
+			return
+		}
+
+		img, err := h.Processor.Process(data)
+		if err != nil {
+			switch {
+			case errors.Is(err, myErr.ErrImageTooLarge):
+				myErr.SendErrorTo(w, err, http.StatusRequestEntityTooLarge, h.Logger)
+			case errors.Is(err, myErr.ErrUnsupportedImage):
+				myErr.SendErrorTo(w, err, http.StatusUnsupportedMediaType, h.Logger)
+			default:
+				myErr.SendErrorTo(w, err, http.StatusInternalServerError, h.Logger)
+			}
+			return
+		}
+		processed = append(processed, img)
+	}
+
+	if len(processed) == 0 {
+		myErr.SendErrorTo(w, errors.New("no images in form field images"), http.StatusBadRequest, h.Logger)
+		return
+	}
+
+	created := make([]typesAnn.Image, 0, len(processed))
+	for _, img := range processed {
+		saved, err := h.save(r, annID, img)
+		if err != nil {
+			myErr.SendErrorTo(w, err, http.StatusInternalServerError, h.Logger)
+			return
+		}
+		created = append(created, *saved)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(created); err != nil {
+		h.Logger.Warnw("error writing response", "err", err)
+		return
+	}
+
+	h.Logger.Infof("uploaded %d images to announcement %s", len(created), annID)
+}
+
+// save кладет оригинал и превью в BlobStore и записывает фотографию в БД
+// Если запись в БД не удалась, уже сохраненные файлы удаляются
+func (h *ImageHandler) save(r *http.Request, annID string, img *announcementimage.ProcessedImage) (*typesAnn.Image, error) {
+	name := uuid.NewString()
+
+	files := map[string][]byte{
+		fmt.Sprintf("%s/%s.%s", annID, name, img.Ext): img.Original,
+	}
+	image := typesAnn.Image{
+		URL:        h.BlobStore.URL(fmt.Sprintf("%s/%s.%s", annID, name, img.Ext)),
+		Thumbnails: make(map[string]string, len(img.Thumbnails)),
+	}
+	for size, data := range img.Thumbnails {
+		key := fmt.Sprintf("%s/%s_%s.%s", annID, name, size, img.Ext)
+		files[key] = data
+		image.Thumbnails[size] = h.BlobStore.URL(key)
+	}
+
+	keys := make([]string, 0, len(files))
+	for key, data := range files {
+		if err := h.BlobStore.Put(r.Context(), key, bytes.NewReader(data)); err != nil {
+			h.deleteBlobs(r, keys)
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	saved, err := h.ImageRepo.Create(annID, image, keys)
+	if err != nil {
+		h.deleteBlobs(r, keys)
+		return nil, err
+	}
+
+	return saved, nil
+}
+
+func (h *ImageHandler) deleteBlobs(r *http.Request, keys []string) {
+	for _, key := range keys {
+		if err := h.BlobStore.Delete(r.Context(), key); err != nil {
+			h.Logger.Warnf("failed to delete blob %s: %v", key, err)
+		}
+	}
+}
+
+// Reorder handles PUT /announcement/{id}/images/order
+// Принимает JSON-массив id всех фотографий объявления в новом порядке
+func (h *ImageHandler) Reorder(w http.ResponseWriter, r *http.Request) {
+	annID := mux.Vars(r)["id"]
+
+	var order []string
+	if err := json.NewDecoder(r.Body).Decode(&order); err != nil {
+		myErr.SendErrorTo(w, myErr.ErrInvalidJSONPayload, http.StatusBadRequest, h.Logger)
+		return
+	}
+
+	if !h.checkOwner(w, r, annID) {
+		return
+	}
+
+	if err := h.ImageRepo.Reorder(annID, order); err != nil {
+		if errors.Is(err, myErr.ErrInvalidImageOrder) {
+			myErr.SendErrorTo(w, err, http.StatusBadRequest, h.Logger)
+			return
+		}
+		myErr.SendErrorTo(w, err, http.StatusInternalServerError, h.Logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Delete handles DELETE /announcement/{id}/images/{imageID}
+func (h *ImageHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	annID, imageID := vars["id"], vars["imageID"]
+
+	if !h.checkOwner(w, r, annID) {
+		return
+	}
+
+	keys, err := h.ImageRepo.Delete(annID, imageID)
+	if err != nil {
+		if errors.Is(err, myErr.ErrNotFound) {
+			myErr.SendErrorTo(w, err, http.StatusNotFound, h.Logger)
+			return
+		}
+		myErr.SendErrorTo(w, err, http.StatusInternalServerError, h.Logger)
+		return
+	}
+
+	// запись уже удалена, поэтому ошибки удаления файлов только логируем
+	h.deleteBlobs(r, keys)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// checkOwner проверяет, что объявление принадлежит пользователю из сессии
+// При ошибке сам отправляет ответ и возвращает false
+func (h *ImageHandler) checkOwner(w http.ResponseWriter, r *http.Request, annID string) bool {
+	userID, ok := contextutil.GetUserIDFromContext(r.Context())
+	if !ok {
+		myErr.SendErrorTo(w, myErr.ErrNoAuth, http.StatusUnauthorized, h.Logger)
+		return false
+	}
+
+	ann, err := h.AnnouncementRepo.GetByID(annID)
+	if err != nil {
+		if errors.Is(err, myErr.ErrNotFound) {
+			myErr.SendErrorTo(w, err, http.StatusNotFound, h.Logger)
+			return false
+		}
+		myErr.SendErrorTo(w, err, http.StatusInternalServerError, h.Logger)
+		return false
+	}
+
+	if ann.UserSellerID != userID {
+		myErr.SendErrorTo(w, myErr.ErrForbidden, http.StatusForbidden, h.Logger)
+		return false
+	}
+
+	return true
+}
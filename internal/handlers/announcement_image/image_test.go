@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gafroshka-main/internal/announcement"
+	announcementimage "gafroshka-main/internal/announcement_image"
+	"gafroshka-main/internal/blobstore"
+	"gafroshka-main/internal/middleware"
+	"gafroshka-main/internal/mocks"
+	"gafroshka-main/internal/session"
+	typesAnn "gafroshka-main/internal/types/announcement"
+	myErr "gafroshka-main/internal/types/errors"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+)
+
+type testDeps struct {
+	imageRepo *mocks.MockImageRepo
+	annRepo   *mocks.MockAnnouncementRepo
+	dir       string
+}
+
+func setupHandler(t *testing.T) (*ImageHandler, testDeps) {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	logger := zaptest.NewLogger(t).Sugar()
+
+	dir := t.TempDir()
+	store, err := blobstore.NewLocalStore(dir, "/static/images", logger)
+	assert.NoError(t, err)
+
+	deps := testDeps{
+		imageRepo: mocks.NewMockImageRepo(ctrl),
+		annRepo:   mocks.NewMockAnnouncementRepo(ctrl),
+		dir:       dir,
+	}
+	processor := announcementimage.NewProcessor(1<<20, map[string]int{"small": 8})
+
+	return NewImageHandler(logger, deps.imageRepo, deps.annRepo, store, processor, 2), deps
+}
+
+func pngBytes(t *testing.T) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, 16, 16))
+	img.Set(1, 1, color.NRGBA{R: 255, A: 255})
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func uploadRequest(t *testing.T, userID string, files ...[]byte) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for i, data := range files {
+		fw, err := mw.CreateFormFile(imagesFormField, strings.Repeat("x", i+1)+".png")
+		assert.NoError(t, err)
+		_, err = fw.Write(data)
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, mw.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/api/announcement/a1/images", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	req = mux.SetURLVars(req, map[string]string{"id": "a1"})
+	return req.WithContext(middleware.ContextWithSession(req.Context(), &session.Session{UserID: userID}))
+}
+
+func TestUpload_Success(t *testing.T) {
+	t.Parallel()
+	h, deps := setupHandler(t)
+
+	deps.annRepo.EXPECT().GetByID("a1").Return(&announcement.Announcement{ID: "a1", UserSellerID: "u1"}, nil)
+	deps.imageRepo.EXPECT().Count("a1").Return(0, nil)
+	deps.imageRepo.EXPECT().Create("a1", gomock.Any(), gomock.Len(2)).
+		DoAndReturn(func(annID string, img typesAnn.Image, keys []string) (*typesAnn.Image, error) {
+			img.ID = "i1"
+			img.Position = 1
+			return &img, nil
+		})
+
+	w := httptest.NewRecorder()
+	h.Upload(w, uploadRequest(t, "u1", pngBytes(t)))
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var got []typesAnn.Image
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	if assert.Len(t, got, 1) {
+		assert.True(t, strings.HasPrefix(got[0].URL, "/static/images/a1/"))
+		assert.Contains(t, got[0].Thumbnails, "small")
+	}
+
+	// оригинал и превью лежат на диске
+	files, err := filepath.Glob(filepath.Join(deps.dir, "a1", "*.png"))
+	assert.NoError(t, err)
+	assert.Len(t, files, 2)
+}
+
+func TestUpload_UnsupportedType(t *testing.T) {
+	t.Parallel()
+	h, deps := setupHandler(t)
+
+	deps.annRepo.EXPECT().GetByID("a1").Return(&announcement.Announcement{ID: "a1", UserSellerID: "u1"}, nil)
+	deps.imageRepo.EXPECT().Count("a1").Return(0, nil)
+
+	w := httptest.NewRecorder()
+	h.Upload(w, uploadRequest(t, "u1", []byte("%PDF-1.4 not an image")))
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	entries, _ := os.ReadDir(deps.dir)
+	assert.Empty(t, entries)
+}
+
+func TestUpload_TooMany(t *testing.T) {
+	t.Parallel()
+	h, deps := setupHandler(t)
+
+	deps.annRepo.EXPECT().GetByID("a1").Return(&announcement.Announcement{ID: "a1", UserSellerID: "u1"}, nil)
+	deps.imageRepo.EXPECT().Count("a1").Return(1, nil)
+
+	w := httptest.NewRecorder()
+	h.Upload(w, uploadRequest(t, "u1", pngBytes(t), pngBytes(t)))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), myErr.ErrTooManyImages.Error())
+}
+
+func TestUpload_NotOwner(t *testing.T) {
+	t.Parallel()
+	h, deps := setupHandler(t)
+
+	deps.annRepo.EXPECT().GetByID("a1").Return(&announcement.Announcement{ID: "a1", UserSellerID: "owner"}, nil)
+
+	w := httptest.NewRecorder()
+	h.Upload(w, uploadRequest(t, "u1", pngBytes(t)))
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestReorder_InvalidOrder(t *testing.T) {
+	t.Parallel()
+	h, deps := setupHandler(t)
+
+	deps.annRepo.EXPECT().GetByID("a1").Return(&announcement.Announcement{ID: "a1", UserSellerID: "u1"}, nil)
+	deps.imageRepo.EXPECT().Reorder("a1", []string{"i2"}).Return(myErr.ErrInvalidImageOrder)
+
+	req := httptest.NewRequest(http.MethodPut, "/api/announcement/a1/images/order", strings.NewReader(`["i2"]`))
+	req = mux.SetURLVars(req, map[string]string{"id": "a1"})
+	req = req.WithContext(middleware.ContextWithSession(req.Context(), &session.Session{UserID: "u1"}))
+
+	w := httptest.NewRecorder()
+	h.Reorder(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestDelete_RemovesBlobs(t *testing.T) {
+	t.Parallel()
+	h, deps := setupHandler(t)
+
+	key := "a1/i1.png"
+	assert.NoError(t, os.MkdirAll(filepath.Join(deps.dir, "a1"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(deps.dir, key), []byte("data"), 0o644))
+
+	deps.annRepo.EXPECT().GetByID("a1").Return(&announcement.Announcement{ID: "a1", UserSellerID: "u1"}, nil)
+	deps.imageRepo.EXPECT().Delete("a1", "i1").Return([]string{key}, nil)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/announcement/a1/images/i1", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "a1", "imageID": "i1"})
+	req = req.WithContext(middleware.ContextWithSession(req.Context(), &session.Session{UserID: "u1"}))
+
+	w := httptest.NewRecorder()
+	h.Delete(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	_, err := os.Stat(filepath.Join(deps.dir, key))
+	assert.True(t, os.IsNotExist(err))
+}
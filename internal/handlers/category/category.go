@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"gafroshka-main/internal/category"
+	myErr "gafroshka-main/internal/types/errors"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// CategoryHandler ручки для дерева категорий
+type CategoryHandler struct {
+	Logger       *zap.SugaredLogger
+	CategoryRepo category.CategoryRepo
+}
+
+func NewCategoryHandler(l *zap.SugaredLogger, cr category.CategoryRepo) *CategoryHandler {
+	return &CategoryHandler{
+		Logger:       l,
+		CategoryRepo: cr,
+	}
+}
+
+// GetTree handles GET /categories
+// Возвращает дерево категорий, корневые категории содержат дочерние в children
+func (h *CategoryHandler) GetTree(w http.ResponseWriter, r *http.Request) {
+	categories, err := h.CategoryRepo.GetAll()
+	if err != nil {
+		myErr.SendErrorTo(w, err, http.StatusInternalServerError, h.Logger)
+		return
+	}
+
+	tree := category.BuildTree(categories)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(tree); err != nil {
+		h.Logger.Warnw("error writing response", "err", err)
+		return
+	}
+}
+
+// GetAttributes handles GET /categories/{id}/attributes
+// Возвращает схему атрибутов категории вместе с унаследованными от родителей
+func (h *CategoryHandler) GetAttributes(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil || id <= 0 {
+		myErr.SendErrorTo(w, myErr.ErrBadID, http.StatusBadRequest, h.Logger)
+		return
+	}
+
+	if _, err = h.CategoryRepo.GetByID(id); err != nil {
+		if errors.Is(err, myErr.ErrNotFound) {
+			myErr.SendErrorTo(w, myErr.ErrUnknownCategory, http.StatusNotFound, h.Logger)
+			return
+		}
+		myErr.SendErrorTo(w, err, http.StatusInternalServerError, h.Logger)
+		return
+	}
+
+	attributes, err := h.CategoryRepo.GetAttributes(id)
+	if err != nil {
+		myErr.SendErrorTo(w, err, http.StatusInternalServerError, h.Logger)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(attributes); err != nil {
+		h.Logger.Warnw("error writing response", "err", err)
+		return
+	}
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gafroshka-main/internal/category"
+	"gafroshka-main/internal/mocks"
+	myErr "gafroshka-main/internal/types/errors"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+)
+
+func setupHandler(t *testing.T) (*CategoryHandler, *mocks.MockCategoryRepo, func()) {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	mockRepo := mocks.NewMockCategoryRepo(ctrl)
+	logger := zaptest.NewLogger(t).Sugar()
+	return NewCategoryHandler(logger, mockRepo), mockRepo, func() { ctrl.Finish() }
+}
+
+func TestGetTree_Success(t *testing.T) {
+	t.Parallel()
+	h, mockRepo, teardown := setupHandler(t)
+	defer teardown()
+
+	parent := 9
+	mockRepo.EXPECT().GetAll().Return([]category.Category{
+		{ID: 9, Slug: "electronics", NameRu: "Электроника", NameEn: "Electronics"},
+		{ID: 1, ParentID: &parent, Slug: "gadgets", NameRu: "Смартфоны и гаджеты", NameEn: "Phones and gadgets"},
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/categories", nil)
+	w := httptest.NewRecorder()
+	h.GetTree(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var got []category.Category
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	if assert.Len(t, got, 1) {
+		assert.Equal(t, "electronics", got[0].Slug)
+		assert.Len(t, got[0].Children, 1)
+	}
+}
+
+func TestGetTree_RepoError(t *testing.T) {
+	t.Parallel()
+	h, mockRepo, teardown := setupHandler(t)
+	defer teardown()
+
+	mockRepo.EXPECT().GetAll().Return(nil, errors.New("db error"))
+
+	req := httptest.NewRequest(http.MethodGet, "/categories", nil)
+	w := httptest.NewRecorder()
+	h.GetTree(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestGetAttributes_UnknownCategory(t *testing.T) {
+	t.Parallel()
+	h, mockRepo, teardown := setupHandler(t)
+	defer teardown()
+
+	mockRepo.EXPECT().GetByID(42).Return(nil, myErr.ErrNotFound)
+
+	req := httptest.NewRequest(http.MethodGet, "/categories/42/attributes", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "42"})
+	w := httptest.NewRecorder()
+	h.GetAttributes(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGetAttributes_Success(t *testing.T) {
+	t.Parallel()
+	h, mockRepo, teardown := setupHandler(t)
+	defer teardown()
+
+	mockRepo.EXPECT().GetByID(1).Return(&category.Category{ID: 1}, nil)
+	mockRepo.EXPECT().GetAttributes(1).Return([]category.Attribute{
+		{ID: 1, CategoryID: 9, Code: "brand", Type: category.AttributeString},
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/categories/1/attributes", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+	w := httptest.NewRecorder()
+	h.GetAttributes(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var got []category.Attribute
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Len(t, got, 1)
+}
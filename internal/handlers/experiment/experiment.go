@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"gafroshka-main/internal/experiment"
+
+	"go.uber.org/zap"
+)
+
+// ExperimentHandler отдает клиенту варианты экспериментов, чтобы он мог показать нужный UI
+type ExperimentHandler struct {
+	Logger *zap.SugaredLogger
+}
+
+func NewExperimentHandler(l *zap.SugaredLogger) *ExperimentHandler {
+	return &ExperimentHandler{
+		Logger: l,
+	}
+}
+
+// Assignments handles GET /experiments
+func (h *ExperimentHandler) Assignments(w http.ResponseWriter, r *http.Request) {
+	assignments := experiment.FromContext(r.Context())
+	if assignments == nil {
+		assignments = map[string]string{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"experiments": assignments,
+	}); err != nil {
+		h.Logger.Warnw("error writing response", "err", err)
+	}
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gafroshka-main/internal/experiment"
+	"gafroshka-main/internal/middleware"
+	"gafroshka-main/internal/session"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestAssignments(t *testing.T) {
+	t.Parallel()
+	assigner, err := experiment.NewAssigner([]experiment.Experiment{{
+		Key:     "layout",
+		Enabled: true,
+		Variants: []experiment.Variant{
+			{Name: "control", Weight: 1},
+			{Name: "compact", Weight: 1},
+		},
+	}})
+	assert.NoError(t, err)
+
+	h := NewExperimentHandler(zaptest.NewLogger(t).Sugar())
+	handler := middleware.Experiments(assigner)(http.HandlerFunc(h.Assignments))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/experiments", nil)
+	req = req.WithContext(middleware.ContextWithSession(req.Context(), &session.Session{UserID: "u-1"}))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Experiments map[string]string `json:"experiments"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, assigner.Assign("u-1"), resp.Experiments)
+}
+
+func TestAssignments_NoUser(t *testing.T) {
+	t.Parallel()
+	h := NewExperimentHandler(zaptest.NewLogger(t).Sugar())
+
+	w := httptest.NewRecorder()
+	h.Assignments(w, httptest.NewRequest(http.MethodGet, "/api/experiments", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"experiments":{}}`, w.Body.String())
+}
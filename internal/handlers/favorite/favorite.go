@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"gafroshka-main/internal/announcement"
+	"gafroshka-main/internal/contextutil"
+	"gafroshka-main/internal/favorite"
+	"gafroshka-main/internal/kafka"
+	typesAnn "gafroshka-main/internal/types/announcement"
+	myErr "gafroshka-main/internal/types/errors"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// FavoriteHandler ручки избранного текущего пользователя
+type FavoriteHandler struct {
+	Logger           *zap.SugaredLogger
+	FavoriteRepo     favorite.FavoriteRepo
+	AnnouncementRepo announcement.AnnouncementRepo
+	EventProducer    kafka.EventProducer
+}
+
+func NewFavoriteHandler(
+	l *zap.SugaredLogger,
+	fr favorite.FavoriteRepo,
+	ar announcement.AnnouncementRepo,
+	ep kafka.EventProducer,
+) *FavoriteHandler {
+	return &FavoriteHandler{
+		Logger:           l,
+		FavoriteRepo:     fr,
+		AnnouncementRepo: ar,
+		EventProducer:    ep,
+	}
+}
+
+// favoriteItem - объявление в списке избранного
+type favoriteItem struct {
+	Announcement typesAnn.InfoForSC `json:"announcement"`
+	AddedAt      time.Time          `json:"added_at"`
+}
+
+// Add handles POST /favorites/{id}
+func (h *FavoriteHandler) Add(w http.ResponseWriter, r *http.Request) {
+	userID, ok := contextutil.GetUserIDFromContext(r.Context())
+	if !ok {
+		myErr.SendErrorTo(w, myErr.ErrNoAuth, http.StatusUnauthorized, h.Logger)
+		return
+	}
+	id := mux.Vars(r)["id"]
+
+	ann, err := h.AnnouncementRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, myErr.ErrNotFound) {
+			myErr.SendErrorTo(w, err, http.StatusNotFound, h.Logger)
+			return
+		}
+		myErr.SendErrorTo(w, err, http.StatusInternalServerError, h.Logger)
+		return
+	}
+	if ann.IsDraft {
+		myErr.SendErrorTo(w, myErr.ErrNotFound, http.StatusNotFound, h.Logger)
+		return
+	}
+
+	added, err := h.FavoriteRepo.Add(userID, id)
+	if err != nil {
+		myErr.SendErrorTo(w, err, http.StatusInternalServerError, h.Logger)
+		return
+	}
+	if !added {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	event := kafka.Event{
+		UserID:         userID,
+		Type:           kafka.EventTypeFavorite,
+		Categories:     []int{ann.Category},
+		AnnouncementID: ann.ID,
+		SellerID:       ann.UserSellerID,
+		Timestamp:      time.Now(),
+	}
+	if err := h.EventProducer.SendEvent(r.Context(), event); err != nil {
+		h.Logger.Warnf("failed to send favorite event: %v", err)
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	h.Logger.Infof("user %s added announcement %s to favorites", userID, id)
+}
+
+// Remove handles DELETE /favorites/{id}
+func (h *FavoriteHandler) Remove(w http.ResponseWriter, r *http.Request) {
+	userID, ok := contextutil.GetUserIDFromContext(r.Context())
+	if !ok {
+		myErr.SendErrorTo(w, myErr.ErrNoAuth, http.StatusUnauthorized, h.Logger)
+		return
+	}
+	id := mux.Vars(r)["id"]
+
+	if err := h.FavoriteRepo.Remove(userID, id); err != nil {
+		if errors.Is(err, myErr.ErrNotFound) {
+			myErr.SendErrorTo(w, err, http.StatusNotFound, h.Logger)
+			return
+		}
+		myErr.SendErrorTo(w, err, http.StatusInternalServerError, h.Logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// List handles GET /favorites
+// Возвращает избранное с краткой информацией об объявлениях, недавно добавленные первыми
+func (h *FavoriteHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := contextutil.GetUserIDFromContext(r.Context())
+	if !ok {
+		myErr.SendErrorTo(w, myErr.ErrNoAuth, http.StatusUnauthorized, h.Logger)
+		return
+	}
+
+	favorites, err := h.FavoriteRepo.GetByUserID(userID)
+	if err != nil {
+		myErr.SendErrorTo(w, err, http.StatusInternalServerError, h.Logger)
+		return
+	}
+
+	items := make([]favoriteItem, 0, len(favorites))
+	if len(favorites) > 0 {
+		ids := make([]string, len(favorites))
+		for i, f := range favorites {
+			ids[i] = f.AnnouncementID
+		}
+
+		infos, err := h.AnnouncementRepo.GetInfoForShoppingCart(ids)
+		if err != nil {
+			myErr.SendErrorTo(w, err, http.StatusInternalServerError, h.Logger)
+			return
+		}
+		infoByID := make(map[string]typesAnn.InfoForSC, len(infos))
+		for _, info := range infos {
+			infoByID[info.ID] = info
+		}
+
+		for _, f := range favorites {
+			if info, ok := infoByID[f.AnnouncementID]; ok {
+				items = append(items, favoriteItem{Announcement: info, AddedAt: f.CreatedAt})
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(items); err != nil {
+		h.Logger.Warnw("error writing response", "err", err)
+		return
+	}
+}
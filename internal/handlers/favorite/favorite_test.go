@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gafroshka-main/internal/announcement"
+	"gafroshka-main/internal/favorite"
+	"gafroshka-main/internal/kafka"
+	"gafroshka-main/internal/middleware"
+	"gafroshka-main/internal/mocks"
+	"gafroshka-main/internal/session"
+	typesAnn "gafroshka-main/internal/types/announcement"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+)
+
+type fakeProducer struct {
+	events []kafka.Event
+}
+
+func (f *fakeProducer) SendEvent(ctx context.Context, event kafka.Event) error {
+	f.events = append(f.events, event)
+	return nil
+}
+
+func (f *fakeProducer) Close() error { return nil }
+
+type testDeps struct {
+	favorites *mocks.MockFavoriteRepo
+	ann       *mocks.MockAnnouncementRepo
+	producer  *fakeProducer
+}
+
+func setupHandler(t *testing.T) (*FavoriteHandler, testDeps) {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	deps := testDeps{
+		favorites: mocks.NewMockFavoriteRepo(ctrl),
+		ann:       mocks.NewMockAnnouncementRepo(ctrl),
+		producer:  &fakeProducer{},
+	}
+	return NewFavoriteHandler(zaptest.NewLogger(t).Sugar(), deps.favorites, deps.ann, deps.producer), deps
+}
+
+func request(method, target, userID string) *http.Request {
+	req := httptest.NewRequest(method, target, nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "a1"})
+	if userID != "" {
+		req = req.WithContext(middleware.ContextWithSession(req.Context(), &session.Session{UserID: userID}))
+	}
+	return req
+}
+
+func TestAdd_SendsFavoriteEvent(t *testing.T) {
+	t.Parallel()
+	h, deps := setupHandler(t)
+
+	deps.ann.EXPECT().GetByID("a1").Return(&announcement.Announcement{ID: "a1", Category: 4}, nil)
+	deps.favorites.EXPECT().Add("u1", "a1").Return(true, nil)
+
+	w := httptest.NewRecorder()
+	h.Add(w, request(http.MethodPost, "/api/favorites/a1", "u1"))
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Len(t, deps.producer.events, 1)
+	assert.Equal(t, kafka.EventTypeFavorite, deps.producer.events[0].Type)
+	assert.Equal(t, []int{4}, deps.producer.events[0].Categories)
+}
+
+func TestAdd_AlreadyFavorite(t *testing.T) {
+	t.Parallel()
+	h, deps := setupHandler(t)
+
+	deps.ann.EXPECT().GetByID("a1").Return(&announcement.Announcement{ID: "a1", Category: 4}, nil)
+	deps.favorites.EXPECT().Add("u1", "a1").Return(false, nil)
+
+	w := httptest.NewRecorder()
+	h.Add(w, request(http.MethodPost, "/api/favorites/a1", "u1"))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, deps.producer.events)
+}
+
+func TestAdd_NoAuth(t *testing.T) {
+	t.Parallel()
+	h, _ := setupHandler(t)
+
+	w := httptest.NewRecorder()
+	h.Add(w, request(http.MethodPost, "/api/favorites/a1", ""))
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestList_KeepsOrder(t *testing.T) {
+	t.Parallel()
+	h, deps := setupHandler(t)
+
+	now := time.Now()
+	deps.favorites.EXPECT().GetByUserID("u1").Return([]favorite.Favorite{
+		{AnnouncementID: "a2", CreatedAt: now},
+		{AnnouncementID: "a1", CreatedAt: now.Add(-time.Hour)},
+		// снятый черновиком или удаленный товар в выдачу не попадает
+		{AnnouncementID: "a3", CreatedAt: now.Add(-2 * time.Hour)},
+	}, nil)
+	deps.ann.EXPECT().GetInfoForShoppingCart([]string{"a2", "a1", "a3"}).Return([]typesAnn.InfoForSC{
+		{ID: "a1", Name: "Диван"},
+		{ID: "a2", Name: "Велосипед"},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	h.List(w, request(http.MethodGet, "/api/favorites", "u1"))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var got []favoriteItem
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Len(t, got, 2)
+	assert.Equal(t, "a2", got[0].Announcement.ID)
+	assert.Equal(t, "a1", got[1].Announcement.ID)
+}
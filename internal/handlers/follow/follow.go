@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gafroshka-main/internal/announcement"
+	"gafroshka-main/internal/contextutil"
+	"gafroshka-main/internal/follow"
+	typesAnn "gafroshka-main/internal/types/announcement"
+	myErr "gafroshka-main/internal/types/errors"
+	"gafroshka-main/internal/user"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultFeedLimit = 20
+	maxFeedLimit     = 100
+)
+
+// FollowHandler ручки подписок на продавцов и ленты их объявлений
+type FollowHandler struct {
+	Logger           *zap.SugaredLogger
+	FollowRepo       follow.FollowRepo
+	UserRepo         user.UserRepo
+	AnnouncementRepo announcement.AnnouncementRepo
+}
+
+func NewFollowHandler(
+	l *zap.SugaredLogger,
+	fr follow.FollowRepo,
+	ur user.UserRepo,
+	ar announcement.AnnouncementRepo,
+) *FollowHandler {
+	return &FollowHandler{
+		Logger:           l,
+		FollowRepo:       fr,
+		UserRepo:         ur,
+		AnnouncementRepo: ar,
+	}
+}
+
+// feedItem - объявление в ленте подписок
+type feedItem struct {
+	Announcement typesAnn.InfoForSC `json:"announcement"`
+	UpdatedAt    time.Time          `json:"updated_at"`
+}
+
+// feedPage - страница ленты, next_cursor пуст на последней странице
+type feedPage struct {
+	Items      []feedItem `json:"items"`
+	NextCursor string     `json:"next_cursor,omitempty"`
+}
+
+// Follow handles POST /user/{id}/follow
+func (h *FollowHandler) Follow(w http.ResponseWriter, r *http.Request) {
+	userID, ok := contextutil.GetUserIDFromContext(r.Context())
+	if !ok {
+		myErr.SendErrorTo(w, myErr.ErrNoAuth, http.StatusUnauthorized, h.Logger)
+		return
+	}
+	sellerID := mux.Vars(r)["id"]
+	if _, err := uuid.Parse(sellerID); err != nil {
+		myErr.SendErrorTo(w, myErr.ErrBadID, http.StatusBadRequest, h.Logger)
+		return
+	}
+	if sellerID == userID {
+		myErr.SendErrorTo(w, myErr.ErrFollowSelf, http.StatusBadRequest, h.Logger)
+		return
+	}
+
+	if _, err := h.UserRepo.Info(sellerID); err != nil {
+		if errors.Is(err, myErr.ErrNotFound) {
+			myErr.SendErrorTo(w, err, http.StatusNotFound, h.Logger)
+			return
+		}
+		myErr.SendErrorTo(w, err, http.StatusInternalServerError, h.Logger)
+		return
+	}
+
+	added, err := h.FollowRepo.Follow(userID, sellerID)
+	if err != nil {
+		myErr.SendErrorTo(w, err, http.StatusInternalServerError, h.Logger)
+		return
+	}
+	if !added {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	h.Logger.Infof("user %s followed seller %s", userID, sellerID)
+}
+
+// Unfollow handles DELETE /user/{id}/follow
+func (h *FollowHandler) Unfollow(w http.ResponseWriter, r *http.Request) {
+	userID, ok := contextutil.GetUserIDFromContext(r.Context())
+	if !ok {
+		myErr.SendErrorTo(w, myErr.ErrNoAuth, http.StatusUnauthorized, h.Logger)
+		return
+	}
+	sellerID := mux.Vars(r)["id"]
+
+	if err := h.FollowRepo.Unfollow(userID, sellerID); err != nil {
+		if errors.Is(err, myErr.ErrNotFound) {
+			myErr.SendErrorTo(w, err, http.StatusNotFound, h.Logger)
+			return
+		}
+		myErr.SendErrorTo(w, err, http.StatusInternalServerError, h.Logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Feed handles GET /feed?cursor=&limit=N
+// Возвращает активные объявления продавцов из подписок, недавно опубликованные и измененные первыми
+func (h *FollowHandler) Feed(w http.ResponseWriter, r *http.Request) {
+	userID, ok := contextutil.GetUserIDFromContext(r.Context())
+	if !ok {
+		myErr.SendErrorTo(w, myErr.ErrNoAuth, http.StatusUnauthorized, h.Logger)
+		return
+	}
+
+	limit := defaultFeedLimit
+	if l := r.URL.Query().Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n <= 0 {
+			myErr.SendErrorTo(w, errors.New("limit must be positive number"), http.StatusBadRequest, h.Logger)
+			return
+		}
+		limit = min(n, maxFeedLimit)
+	}
+
+	var after *follow.Cursor
+	if c := r.URL.Query().Get("cursor"); c != "" {
+		cursor, err := follow.ParseCursor(c)
+		if err != nil {
+			myErr.SendErrorTo(w, err, http.StatusBadRequest, h.Logger)
+			return
+		}
+		after = &cursor
+	}
+
+	feed, err := h.FollowRepo.GetFeed(userID, after, limit)
+	if err != nil {
+		myErr.SendErrorTo(w, err, http.StatusInternalServerError, h.Logger)
+		return
+	}
+
+	page := feedPage{Items: make([]feedItem, 0, len(feed))}
+	if len(feed) > 0 {
+		ids := make([]string, len(feed))
+		for i, f := range feed {
+			ids[i] = f.AnnouncementID
+		}
+
+		infos, err := h.AnnouncementRepo.GetInfoForShoppingCart(ids)
+		if err != nil {
+			myErr.SendErrorTo(w, err, http.StatusInternalServerError, h.Logger)
+			return
+		}
+		infoByID := make(map[string]typesAnn.InfoForSC, len(infos))
+		for _, info := range infos {
+			infoByID[info.ID] = info
+		}
+
+		for _, f := range feed {
+			if info, ok := infoByID[f.AnnouncementID]; ok {
+				page.Items = append(page.Items, feedItem{Announcement: info, UpdatedAt: f.UpdatedAt})
+			}
+		}
+
+		// курсор берется из выборки, а не из ответа, чтобы не терять страницу,
+		// если объявление сняли с продажи между запросами
+		if len(feed) == limit {
+			last := feed[len(feed)-1]
+			page.NextCursor = follow.Cursor{UpdatedAt: last.UpdatedAt, AnnouncementID: last.AnnouncementID}.Encode()
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(page); err != nil {
+		h.Logger.Warnw("error writing response", "err", err)
+		return
+	}
+}
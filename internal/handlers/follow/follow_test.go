@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gafroshka-main/internal/follow"
+	"gafroshka-main/internal/middleware"
+	"gafroshka-main/internal/mocks"
+	"gafroshka-main/internal/session"
+	typesAnn "gafroshka-main/internal/types/announcement"
+	myErr "gafroshka-main/internal/types/errors"
+	"gafroshka-main/internal/user"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+)
+
+const (
+	testUserID   = "11111111-1111-1111-1111-111111111111"
+	testSellerID = "22222222-2222-2222-2222-222222222222"
+)
+
+type testDeps struct {
+	follows *mocks.MockFollowRepo
+	users   *mocks.MockUserRepo
+	ann     *mocks.MockAnnouncementRepo
+}
+
+func setupHandler(t *testing.T) (*FollowHandler, testDeps) {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	deps := testDeps{
+		follows: mocks.NewMockFollowRepo(ctrl),
+		users:   mocks.NewMockUserRepo(ctrl),
+		ann:     mocks.NewMockAnnouncementRepo(ctrl),
+	}
+	return NewFollowHandler(zaptest.NewLogger(t).Sugar(), deps.follows, deps.users, deps.ann), deps
+}
+
+func request(method, target, sellerID string) *http.Request {
+	req := httptest.NewRequest(method, target, nil)
+	req = mux.SetURLVars(req, map[string]string{"id": sellerID})
+	return req.WithContext(middleware.ContextWithSession(req.Context(), &session.Session{UserID: testUserID}))
+}
+
+func TestFollow(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name         string
+		sellerID     string
+		mockBehavior func(deps testDeps)
+		expectedCode int
+	}{
+		{
+			name:     "новая подписка",
+			sellerID: testSellerID,
+			mockBehavior: func(deps testDeps) {
+				deps.users.EXPECT().Info(testSellerID).Return(&user.User{ID: testSellerID}, nil)
+				deps.follows.EXPECT().Follow(testUserID, testSellerID).Return(true, nil)
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:     "уже подписан",
+			sellerID: testSellerID,
+			mockBehavior: func(deps testDeps) {
+				deps.users.EXPECT().Info(testSellerID).Return(&user.User{ID: testSellerID}, nil)
+				deps.follows.EXPECT().Follow(testUserID, testSellerID).Return(false, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:     "продавец не найден",
+			sellerID: testSellerID,
+			mockBehavior: func(deps testDeps) {
+				deps.users.EXPECT().Info(testSellerID).Return(nil, myErr.ErrNotFound)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "подписка на себя",
+			sellerID:     testUserID,
+			mockBehavior: func(deps testDeps) {},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, deps := setupHandler(t)
+			tt.mockBehavior(deps)
+
+			w := httptest.NewRecorder()
+			h.Follow(w, request(http.MethodPost, "/api/user/"+tt.sellerID+"/follow", tt.sellerID))
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+		})
+	}
+}
+
+func TestFeed_Pagination(t *testing.T) {
+	t.Parallel()
+	h, deps := setupHandler(t)
+
+	after := follow.Cursor{UpdatedAt: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), AnnouncementID: "a9"}
+	updatedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// a1 сняли с продажи после выборки: в ответ не попадает, но курсор строится по нему
+	deps.follows.EXPECT().GetFeed(testUserID, &after, 2).Return([]follow.FeedItem{
+		{AnnouncementID: "a2", UpdatedAt: updatedAt},
+		{AnnouncementID: "a1", UpdatedAt: updatedAt},
+	}, nil)
+	deps.ann.EXPECT().GetInfoForShoppingCart([]string{"a2", "a1"}).
+		Return([]typesAnn.InfoForSC{{ID: "a2"}}, nil)
+
+	w := httptest.NewRecorder()
+	h.Feed(w, request(http.MethodGet, "/api/feed?limit=2&cursor="+after.Encode(), ""))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var page feedPage
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Len(t, page.Items, 1)
+	assert.Equal(t, "a2", page.Items[0].Announcement.ID)
+
+	next, err := follow.ParseCursor(page.NextCursor)
+	assert.NoError(t, err)
+	assert.Equal(t, follow.Cursor{UpdatedAt: updatedAt, AnnouncementID: "a1"}, next)
+}
+
+func TestFeed_LastPage(t *testing.T) {
+	t.Parallel()
+	h, deps := setupHandler(t)
+
+	deps.follows.EXPECT().GetFeed(testUserID, nil, defaultFeedLimit).Return([]follow.FeedItem{}, nil)
+
+	w := httptest.NewRecorder()
+	h.Feed(w, request(http.MethodGet, "/api/feed", ""))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var page feedPage
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Empty(t, page.Items)
+	assert.Empty(t, page.NextCursor)
+}
+
+func TestFeed_BadCursor(t *testing.T) {
+	t.Parallel()
+	h, _ := setupHandler(t)
+
+	w := httptest.NewRecorder()
+	h.Feed(w, request(http.MethodGet, "/api/feed?cursor=garbage", ""))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"gafroshka-main/internal/contextutil"
+	"gafroshka-main/internal/notification"
+	myErr "gafroshka-main/internal/types/errors"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultNotificationsLimit = 50
+	maxNotificationsLimit     = 200
+)
+
+// NotificationHandler ручки для уведомлений текущего пользователя
+type NotificationHandler struct {
+	Logger           *zap.SugaredLogger
+	NotificationRepo notification.NotificationRepo
+}
+
+func NewNotificationHandler(l *zap.SugaredLogger, nr notification.NotificationRepo) *NotificationHandler {
+	return &NotificationHandler{
+		Logger:           l,
+		NotificationRepo: nr,
+	}
+}
+
+// List handles GET /notifications?limit=N
+func (h *NotificationHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := contextutil.GetUserIDFromContext(r.Context())
+	if !ok {
+		myErr.SendErrorTo(w, myErr.ErrNoAuth, http.StatusUnauthorized, h.Logger)
+		return
+	}
+
+	limit := defaultNotificationsLimit
+	if l := r.URL.Query().Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n <= 0 {
+			myErr.SendErrorTo(w, myErr.ErrInvalidAmount, http.StatusBadRequest, h.Logger)
+			return
+		}
+		limit = min(n, maxNotificationsLimit)
+	}
+
+	notifications, err := h.NotificationRepo.GetByUserID(userID, limit)
+	if err != nil {
+		myErr.SendErrorTo(w, err, http.StatusInternalServerError, h.Logger)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(notifications); err != nil {
+		h.Logger.Warnw("error writing response", "err", err)
+		return
+	}
+}
+
+// MarkRead handles POST /notifications/read
+// Принимает JSON-массив id уведомлений
+func (h *NotificationHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	userID, ok := contextutil.GetUserIDFromContext(r.Context())
+	if !ok {
+		myErr.SendErrorTo(w, myErr.ErrNoAuth, http.StatusUnauthorized, h.Logger)
+		return
+	}
+
+	var ids []string
+	if err := json.NewDecoder(r.Body).Decode(&ids); err != nil {
+		myErr.SendErrorTo(w, myErr.ErrInvalidJSONPayload, http.StatusBadRequest, h.Logger)
+		return
+	}
+
+	if err := h.NotificationRepo.MarkRead(userID, ids); err != nil {
+		myErr.SendErrorTo(w, err, http.StatusInternalServerError, h.Logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gafroshka-main/internal/middleware"
+	"gafroshka-main/internal/mocks"
+	"gafroshka-main/internal/notification"
+	"gafroshka-main/internal/session"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+)
+
+func setupHandler(t *testing.T) (*NotificationHandler, *mocks.MockNotificationRepo) {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	mockRepo := mocks.NewMockNotificationRepo(ctrl)
+	return NewNotificationHandler(zaptest.NewLogger(t).Sugar(), mockRepo), mockRepo
+}
+
+func withUser(req *http.Request, userID string) *http.Request {
+	return req.WithContext(middleware.ContextWithSession(req.Context(), &session.Session{UserID: userID}))
+}
+
+func TestList(t *testing.T) {
+	t.Parallel()
+	h, mockRepo := setupHandler(t)
+
+	mockRepo.EXPECT().GetByUserID("u1", 10).Return([]notification.Notification{
+		{ID: "n1", UserID: "u1", Type: notification.TypeExpirySoon, Message: "скоро истекает"},
+	}, nil)
+
+	req := withUser(httptest.NewRequest(http.MethodGet, "/api/notifications?limit=10", nil), "u1")
+	w := httptest.NewRecorder()
+	h.List(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var got []notification.Notification
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Len(t, got, 1)
+}
+
+func TestList_NoAuth(t *testing.T) {
+	t.Parallel()
+	h, _ := setupHandler(t)
+
+	w := httptest.NewRecorder()
+	h.List(w, httptest.NewRequest(http.MethodGet, "/api/notifications", nil))
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestMarkRead(t *testing.T) {
+	t.Parallel()
+	h, mockRepo := setupHandler(t)
+
+	mockRepo.EXPECT().MarkRead("u1", []string{"n1", "n2"}).Return(nil)
+
+	req := withUser(httptest.NewRequest(http.MethodPost, "/api/notifications/read", strings.NewReader(`["n1","n2"]`)), "u1")
+	w := httptest.NewRecorder()
+	h.MarkRead(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
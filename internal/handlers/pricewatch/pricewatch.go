@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"gafroshka-main/internal/announcement"
+	"gafroshka-main/internal/contextutil"
+	"gafroshka-main/internal/pricewatch"
+	myErr "gafroshka-main/internal/types/errors"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultHistoryLimit = 100
+	maxHistoryLimit     = 1000
+)
+
+// PriceWatchHandler ручки истории цен и подписок на снижение цены
+type PriceWatchHandler struct {
+	Logger           *zap.SugaredLogger
+	PriceWatchRepo   pricewatch.PriceWatchRepo
+	AnnouncementRepo announcement.AnnouncementRepo
+}
+
+func NewPriceWatchHandler(
+	l *zap.SugaredLogger,
+	pr pricewatch.PriceWatchRepo,
+	ar announcement.AnnouncementRepo,
+) *PriceWatchHandler {
+	return &PriceWatchHandler{
+		Logger:           l,
+		PriceWatchRepo:   pr,
+		AnnouncementRepo: ar,
+	}
+}
+
+// History handles GET /announcement/{id}/price-history?limit=N
+func (h *PriceWatchHandler) History(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	limit := defaultHistoryLimit
+	if l := r.URL.Query().Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n <= 0 {
+			myErr.SendErrorTo(w, myErr.ErrInvalidAmount, http.StatusBadRequest, h.Logger)
+			return
+		}
+		limit = min(n, maxHistoryLimit)
+	}
+
+	if !h.checkVisible(w, id) {
+		return
+	}
+
+	history, err := h.PriceWatchRepo.GetHistory(id, limit)
+	if err != nil {
+		myErr.SendErrorTo(w, err, http.StatusInternalServerError, h.Logger)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(history); err != nil {
+		h.Logger.Warnw("error writing response", "err", err)
+		return
+	}
+}
+
+// Watch handles PUT /announcement/{id}/price-watch
+// Принимает {"threshold": N} - уведомить, когда цена со скидкой станет ниже N
+func (h *PriceWatchHandler) Watch(w http.ResponseWriter, r *http.Request) {
+	userID, ok := contextutil.GetUserIDFromContext(r.Context())
+	if !ok {
+		myErr.SendErrorTo(w, myErr.ErrNoAuth, http.StatusUnauthorized, h.Logger)
+		return
+	}
+	id := mux.Vars(r)["id"]
+
+	var input struct {
+		Threshold int64 `json:"threshold"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		myErr.SendErrorTo(w, myErr.ErrInvalidJSONPayload, http.StatusBadRequest, h.Logger)
+		return
+	}
+	if input.Threshold <= 0 {
+		myErr.SendErrorTo(w, myErr.ErrInvalidAmount, http.StatusBadRequest, h.Logger)
+		return
+	}
+
+	if !h.checkVisible(w, id) {
+		return
+	}
+
+	if err := h.PriceWatchRepo.Watch(userID, id, input.Threshold); err != nil {
+		myErr.SendErrorTo(w, err, http.StatusInternalServerError, h.Logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	h.Logger.Infof("user %s watches price of %s below %d", userID, id, input.Threshold)
+}
+
+// Unwatch handles DELETE /announcement/{id}/price-watch
+func (h *PriceWatchHandler) Unwatch(w http.ResponseWriter, r *http.Request) {
+	userID, ok := contextutil.GetUserIDFromContext(r.Context())
+	if !ok {
+		myErr.SendErrorTo(w, myErr.ErrNoAuth, http.StatusUnauthorized, h.Logger)
+		return
+	}
+	id := mux.Vars(r)["id"]
+
+	if err := h.PriceWatchRepo.Unwatch(userID, id); err != nil {
+		if errors.Is(err, myErr.ErrNotFound) {
+			myErr.SendErrorTo(w, err, http.StatusNotFound, h.Logger)
+			return
+		}
+		myErr.SendErrorTo(w, err, http.StatusInternalServerError, h.Logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// checkVisible проверяет, что объявление существует и не является черновиком
+// При ошибке сам отправляет ответ и возвращает false
+func (h *PriceWatchHandler) checkVisible(w http.ResponseWriter, id string) bool {
+	ann, err := h.AnnouncementRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, myErr.ErrNotFound) {
+			myErr.SendErrorTo(w, err, http.StatusNotFound, h.Logger)
+			return false
+		}
+		myErr.SendErrorTo(w, err, http.StatusInternalServerError, h.Logger)
+		return false
+	}
+	if ann.IsDraft {
+		myErr.SendErrorTo(w, myErr.ErrNotFound, http.StatusNotFound, h.Logger)
+		return false
+	}
+
+	return true
+}
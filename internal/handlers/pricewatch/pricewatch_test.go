@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gafroshka-main/internal/announcement"
+	"gafroshka-main/internal/middleware"
+	"gafroshka-main/internal/mocks"
+	"gafroshka-main/internal/pricewatch"
+	"gafroshka-main/internal/session"
+	myErr "gafroshka-main/internal/types/errors"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+)
+
+type testDeps struct {
+	prices *mocks.MockPriceWatchRepo
+	ann    *mocks.MockAnnouncementRepo
+}
+
+func setupHandler(t *testing.T) (*PriceWatchHandler, testDeps) {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	deps := testDeps{
+		prices: mocks.NewMockPriceWatchRepo(ctrl),
+		ann:    mocks.NewMockAnnouncementRepo(ctrl),
+	}
+	return NewPriceWatchHandler(zaptest.NewLogger(t).Sugar(), deps.prices, deps.ann), deps
+}
+
+func request(method, target, userID, body string) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req = mux.SetURLVars(req, map[string]string{"id": "a1"})
+	if userID != "" {
+		req = req.WithContext(middleware.ContextWithSession(req.Context(), &session.Session{UserID: userID}))
+	}
+	return req
+}
+
+func TestHistory(t *testing.T) {
+	t.Parallel()
+	h, deps := setupHandler(t)
+
+	deps.ann.EXPECT().GetByID("a1").Return(&announcement.Announcement{ID: "a1"}, nil)
+	deps.prices.EXPECT().GetHistory("a1", defaultHistoryLimit).Return([]pricewatch.PricePoint{
+		{Price: 1000, Discount: 0, EffectivePrice: 1000, ChangedAt: time.Now().Add(-time.Hour)},
+		{Price: 1000, Discount: 10, EffectivePrice: 900, ChangedAt: time.Now()},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	h.History(w, request(http.MethodGet, "/api/announcement/a1/price-history", "", ""))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var got []pricewatch.PricePoint
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Len(t, got, 2)
+	assert.Equal(t, int64(900), got[1].EffectivePrice)
+}
+
+func TestHistory_Draft(t *testing.T) {
+	t.Parallel()
+	h, deps := setupHandler(t)
+
+	deps.ann.EXPECT().GetByID("a1").Return(&announcement.Announcement{ID: "a1", IsDraft: true}, nil)
+
+	w := httptest.NewRecorder()
+	h.History(w, request(http.MethodGet, "/api/announcement/a1/price-history", "", ""))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestWatch(t *testing.T) {
+	t.Parallel()
+	h, deps := setupHandler(t)
+
+	deps.ann.EXPECT().GetByID("a1").Return(&announcement.Announcement{ID: "a1"}, nil)
+	deps.prices.EXPECT().Watch("u1", "a1", int64(5000)).Return(nil)
+
+	w := httptest.NewRecorder()
+	h.Watch(w, request(http.MethodPut, "/api/announcement/a1/price-watch", "u1", `{"threshold":5000}`))
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestWatch_InvalidThreshold(t *testing.T) {
+	t.Parallel()
+	h, _ := setupHandler(t)
+
+	w := httptest.NewRecorder()
+	h.Watch(w, request(http.MethodPut, "/api/announcement/a1/price-watch", "u1", `{"threshold":0}`))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestUnwatch_NotFound(t *testing.T) {
+	t.Parallel()
+	h, deps := setupHandler(t)
+
+	deps.prices.EXPECT().Unwatch("u1", "a1").Return(myErr.ErrNotFound)
+
+	w := httptest.NewRecorder()
+	h.Unwatch(w, request(http.MethodDelete, "/api/announcement/a1/price-watch", "u1", ""))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
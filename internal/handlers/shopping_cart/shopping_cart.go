@@ -3,10 +3,10 @@ package handlers
 import (
 	"encoding/json"
 	"errors"
+	"gafroshka-main/internal/inventory"
 	"gafroshka-main/internal/kafka"
-	"gafroshka-main/internal/user"
-	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
@@ -24,8 +24,10 @@ type ShoppingCartHandler struct {
 	Logger           *zap.SugaredLogger
 	CartRepo         shopping_cart.ShoppingCartRepo
 	AnnouncementRepo announcement.AnnouncementRepo
-	UserRepo         user.UserRepo
+	InventoryRepo    inventory.InventoryRepo
 	EventProducer    kafka.EventProducer
+	// ReservationTTL - на сколько резервируется товар при оформлении покупки
+	ReservationTTL time.Duration
 }
 
 // NewShoppingCartHandler конструктор
@@ -33,19 +35,22 @@ func NewShoppingCartHandler(
 	log *zap.SugaredLogger,
 	cr shopping_cart.ShoppingCartRepo,
 	ar announcement.AnnouncementRepo,
-	ur user.UserRepo,
+	ir inventory.InventoryRepo,
 	ep kafka.EventProducer,
+	reservationTTL time.Duration,
 ) *ShoppingCartHandler {
 	return &ShoppingCartHandler{
 		Logger:           log,
 		CartRepo:         cr,
 		AnnouncementRepo: ar,
-		UserRepo:         ur,
+		InventoryRepo:    ir,
 		EventProducer:    ep,
+		ReservationTTL:   reservationTTL,
 	}
 }
 
-// AddToShoppingCart - POST /cart/{userID}/item/{annID}
+// AddToShoppingCart - POST /cart/{userID}/item/{annID}?quantity=N
+// Без quantity в корзину кладется один товар, повторный вызов заменяет количество
 func (h *ShoppingCartHandler) AddToShoppingCart(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	userID := vars["userID"]
@@ -60,26 +65,48 @@ func (h *ShoppingCartHandler) AddToShoppingCart(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	err := h.CartRepo.AddAnnouncement(userID, annID)
+	quantity := 1
+	if q := r.URL.Query().Get("quantity"); q != "" {
+		n, err := strconv.Atoi(q)
+		if err != nil || n <= 0 {
+			myErr.SendErrorTo(w, myErr.ErrInvalidQuantity, http.StatusBadRequest, h.Logger)
+			return
+		}
+		quantity = n
+	}
+
+	ann, err := h.AnnouncementRepo.GetByID(annID)
 	if err != nil {
+		if errors.Is(err, myErr.ErrNotFound) {
+			myErr.SendErrorTo(w, err, http.StatusNotFound, h.Logger)
+			return
+		}
 		myErr.SendErrorTo(w, err, http.StatusInternalServerError, h.Logger)
 		return
 	}
+	// черновик нельзя купить, пока продавец его не опубликует
+	if ann.IsDraft {
+		myErr.SendErrorTo(w, myErr.ErrDraft, http.StatusConflict, h.Logger)
+		return
+	}
 
-	// После успешного добавления — отправляем событие "view" (просмотр карточки объявления) в Kafka
-	ann, err := h.AnnouncementRepo.GetByID(annID)
+	err = h.CartRepo.AddAnnouncement(userID, annID, quantity)
 	if err != nil {
-		h.Logger.Warnf("failed to fetch announcement %s for analytics: %v", annID, err)
-	} else {
-		event := kafka.Event{
-			UserID:     userID,
-			Type:       kafka.EventTypeView,
-			Categories: []int{ann.Category},
-			Timestamp:  time.Now(),
-		}
-		if err := h.EventProducer.SendEvent(r.Context(), event); err != nil {
-			h.Logger.Warnf("failed to send view event on AddToShoppingCart: %v", err)
-		}
+		myErr.SendErrorTo(w, err, http.StatusInternalServerError, h.Logger)
+		return
+	}
+
+	// После успешного добавления — отправляем событие "add_to_cart" в Kafka
+	event := kafka.Event{
+		UserID:         userID,
+		Type:           kafka.EventTypeAddToCart,
+		Categories:     []int{ann.Category},
+		AnnouncementID: ann.ID,
+		SellerID:       ann.UserSellerID,
+		Timestamp:      time.Now(),
+	}
+	if err := h.EventProducer.SendEvent(r.Context(), event); err != nil {
+		h.Logger.Warnf("failed to send add_to_cart event: %v", err)
 	}
 
 	w.WriteHeader(http.StatusCreated)
@@ -111,6 +138,17 @@ func (h *ShoppingCartHandler) DeleteFromShoppingCart(w http.ResponseWriter, r *h
 		return
 	}
 
+	// продавца аналитика знает по событию добавления, поэтому объявление не перечитываем
+	event := kafka.Event{
+		UserID:         userID,
+		Type:           kafka.EventTypeRemoveFromCart,
+		AnnouncementID: annID,
+		Timestamp:      time.Now(),
+	}
+	if err := h.EventProducer.SendEvent(r.Context(), event); err != nil {
+		h.Logger.Warnf("failed to send remove_from_cart event: %v", err)
+	}
+
 	w.WriteHeader(http.StatusOK)
 	h.Logger.Infof("deleted announcement %s from user %s shopping cart", annID, userID)
 }
@@ -125,7 +163,7 @@ func (h *ShoppingCartHandler) GetCart(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ids, err := h.CartRepo.GetByUserID(userID)
+	items, err := h.CartRepo.GetByUserID(userID)
 	if err != nil {
 		if errors.Is(err, myErr.ErrNotFound) {
 			// Если пусто, то возвращаем no content
@@ -143,11 +181,21 @@ func (h *ShoppingCartHandler) GetCart(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	quantities := make(map[string]int, len(items))
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		quantities[item.AnnouncementID] = item.Quantity
+		ids = append(ids, item.AnnouncementID)
+	}
+
 	infos, err := h.AnnouncementRepo.GetInfoForShoppingCart(ids)
 	if err != nil {
 		myErr.SendErrorTo(w, err, http.StatusInternalServerError, h.Logger)
 		return
 	}
+	for i := range infos {
+		infos[i].CartQuantity = quantities[infos[i].ID]
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -166,6 +214,8 @@ func (h *ShoppingCartHandler) GetCart(w http.ResponseWriter, r *http.Request) {
 //	"id2"
 //
 // ]
+// Количество каждого товара берется из корзины. На время оплаты товары резервируются,
+// после оплаты остаток на складе атомарно уменьшается
 // После успешной оплаты возвращаем {"status": "success", "total": <сумма>}
 func (h *ShoppingCartHandler) PurchaseFromCart(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -196,7 +246,7 @@ func (h *ShoppingCartHandler) PurchaseFromCart(w http.ResponseWriter, r *http.Re
 	}
 
 	// Получаем текущую корзину пользователя
-	cartIDs, err := h.CartRepo.GetByUserID(userID)
+	cartItems, err := h.CartRepo.GetByUserID(userID)
 	if err != nil {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusInternalServerError)
@@ -207,12 +257,13 @@ func (h *ShoppingCartHandler) PurchaseFromCart(w http.ResponseWriter, r *http.Re
 	}
 
 	// Проверяем, что все переданные товары действительно есть в корзине
-	validItems := map[string]bool{}
-	for _, id := range cartIDs {
-		validItems[id] = true
+	validItems := map[string]int{}
+	for _, item := range cartItems {
+		validItems[item.AnnouncementID] = item.Quantity
 	}
+	items := make(map[string]int, len(requestedIDs))
 	for _, reqID := range requestedIDs {
-		if !validItems[reqID] {
+		if validItems[reqID] == 0 {
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusBadRequest)
 			_ = json.NewEncoder(w).Encode(map[string]interface{}{
@@ -220,11 +271,23 @@ func (h *ShoppingCartHandler) PurchaseFromCart(w http.ResponseWriter, r *http.Re
 			})
 			return
 		}
+		items[reqID] = validItems[reqID]
+	}
+
+	// Резервируем товары, чтобы параллельные покупатели не купили последний экземпляр
+	if _, err = h.InventoryRepo.Reserve(userID, items, h.ReservationTTL); err != nil {
+		if errors.Is(err, myErr.ErrOutOfStock) {
+			myErr.SendErrorTo(w, err, http.StatusConflict, h.Logger)
+			return
+		}
+		myErr.SendErrorTo(w, err, http.StatusInternalServerError, h.Logger)
+		return
 	}
 
 	// Получаем информацию о товарах для расчета суммы
 	infos, err := h.AnnouncementRepo.GetInfoForShoppingCart(requestedIDs)
 	if err != nil {
+		h.releaseReservation(userID, requestedIDs)
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusInternalServerError)
 		_ = json.NewEncoder(w).Encode(map[string]interface{}{
@@ -235,38 +298,24 @@ func (h *ShoppingCartHandler) PurchaseFromCart(w http.ResponseWriter, r *http.Re
 
 	var total int64 = 0
 	for _, item := range infos {
-		discountedPrice := float64(item.Price*(int64(100)-int64(item.Discount))) / 100.0
-		total += int64(math.Ceil(discountedPrice))
+		total += typesAnn.EffectivePrice(item.Price, item.Discount) * int64(items[item.ID])
 	}
 
-	// Получаем баланс пользователя
-	balance, err := h.UserRepo.GetBalanceByUserID(userID)
-	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		_ = json.NewEncoder(w).Encode(map[string]interface{}{
-			"error": "failed to get user balance",
-		})
-		return
-	}
-
-	if balance < total {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusPaymentRequired)
-		_ = json.NewEncoder(w).Encode(map[string]interface{}{
-			"error": "insufficient funds",
-		})
-		return
-	}
-
-	// Списываем деньги у пользователя
-	_, err = h.UserRepo.TopUpBalance(userID, -total)
-	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		_ = json.NewEncoder(w).Encode(map[string]interface{}{
-			"error": "failed to charge user",
-		})
+	// Списываем деньги и товары одной транзакцией: при нехватке денег или товара ничего не меняется
+	if err = h.InventoryRepo.Purchase(userID, items, total); err != nil {
+		h.releaseReservation(userID, requestedIDs)
+		switch {
+		case errors.Is(err, myErr.ErrNoFunds):
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusPaymentRequired)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"error": "insufficient funds",
+			})
+		case errors.Is(err, myErr.ErrOutOfStock):
+			myErr.SendErrorTo(w, err, http.StatusConflict, h.Logger)
+		default:
+			myErr.SendErrorTo(w, err, http.StatusInternalServerError, h.Logger)
+		}
 		return
 	}
 
@@ -295,10 +344,11 @@ func (h *ShoppingCartHandler) PurchaseFromCart(w http.ResponseWriter, r *http.Re
 	}
 	if len(categories) > 0 {
 		event := kafka.Event{
-			UserID:     userID,
-			Type:       kafka.EventTypePurchase,
-			Categories: categories,
-			Timestamp:  time.Now(),
+			UserID:          userID,
+			Type:            kafka.EventTypePurchase,
+			Categories:      categories,
+			AnnouncementIDs: requestedIDs,
+			Timestamp:       time.Now(),
 		}
 		if err := h.EventProducer.SendEvent(r.Context(), event); err != nil {
 			h.Logger.Warnf("failed to send purchase event: %v", err)
@@ -316,3 +366,63 @@ func (h *ShoppingCartHandler) PurchaseFromCart(w http.ResponseWriter, r *http.Re
 		"total":  total,
 	})
 }
+
+// ReserveCart - POST /cart/{userID}/reserve
+// Резервирует товары корзины на время оформления заказа, тело - массив id как в PurchaseFromCart
+// Возвращает {"expires_at": <время окончания резерва>}
+func (h *ShoppingCartHandler) ReserveCart(w http.ResponseWriter, r *http.Request) {
+	userID := mux.Vars(r)["userID"]
+	if _, err := uuid.Parse(userID); err != nil {
+		myErr.SendErrorTo(w, myErr.ErrBadID, http.StatusBadRequest, h.Logger)
+		return
+	}
+
+	var requestedIDs []string
+	if err := json.NewDecoder(r.Body).Decode(&requestedIDs); err != nil || len(requestedIDs) == 0 {
+		myErr.SendErrorTo(w, myErr.ErrInvalidJSONPayload, http.StatusBadRequest, h.Logger)
+		return
+	}
+
+	cartItems, err := h.CartRepo.GetByUserID(userID)
+	if err != nil {
+		myErr.SendErrorTo(w, err, http.StatusInternalServerError, h.Logger)
+		return
+	}
+
+	inCart := make(map[string]int, len(cartItems))
+	for _, item := range cartItems {
+		inCart[item.AnnouncementID] = item.Quantity
+	}
+	items := make(map[string]int, len(requestedIDs))
+	for _, id := range requestedIDs {
+		if inCart[id] == 0 {
+			myErr.SendErrorTo(w, myErr.ErrNotFound, http.StatusBadRequest, h.Logger)
+			return
+		}
+		items[id] = inCart[id]
+	}
+
+	expiresAt, err := h.InventoryRepo.Reserve(userID, items, h.ReservationTTL)
+	if err != nil {
+		if errors.Is(err, myErr.ErrOutOfStock) {
+			myErr.SendErrorTo(w, err, http.StatusConflict, h.Logger)
+			return
+		}
+		myErr.SendErrorTo(w, err, http.StatusInternalServerError, h.Logger)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err = json.NewEncoder(w).Encode(map[string]interface{}{"expires_at": expiresAt}); err != nil {
+		h.Logger.Warnw("error writing response", "err", err)
+	}
+}
+
+// releaseReservation снимает резерв после неудачной покупки
+// Ошибку только логируем: резерв все равно истечет сам
+func (h *ShoppingCartHandler) releaseReservation(userID string, ids []string) {
+	if err := h.InventoryRepo.Release(userID, ids); err != nil {
+		h.Logger.Warnw("failed to release reservation", "userID", userID, "err", err)
+	}
+}
@@ -1 +1,215 @@
 package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gafroshka-main/internal/announcement"
+	"gafroshka-main/internal/kafka"
+	"gafroshka-main/internal/mocks"
+	"gafroshka-main/internal/shopping_cart"
+	typesAnn "gafroshka-main/internal/types/announcement"
+	myErr "gafroshka-main/internal/types/errors"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+)
+
+const (
+	testUserID = "11111111-1111-1111-1111-111111111111"
+	testAnnID  = "22222222-2222-2222-2222-222222222222"
+	reserveTTL = 10 * time.Minute
+)
+
+type fakeProducer struct {
+	events []kafka.Event
+}
+
+func (f *fakeProducer) SendEvent(ctx context.Context, event kafka.Event) error {
+	f.events = append(f.events, event)
+	return nil
+}
+
+func (f *fakeProducer) Close() error { return nil }
+
+type testDeps struct {
+	cart      *mocks.MockShoppingCartRepo
+	ann       *mocks.MockAnnouncementRepo
+	inventory *mocks.MockInventoryRepo
+	producer  *fakeProducer
+}
+
+func setupHandler(t *testing.T) (*ShoppingCartHandler, testDeps) {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	deps := testDeps{
+		cart:      mocks.NewMockShoppingCartRepo(ctrl),
+		ann:       mocks.NewMockAnnouncementRepo(ctrl),
+		inventory: mocks.NewMockInventoryRepo(ctrl),
+		producer:  &fakeProducer{},
+	}
+	h := NewShoppingCartHandler(
+		zaptest.NewLogger(t).Sugar(), deps.cart, deps.ann, deps.inventory, deps.producer, reserveTTL,
+	)
+	return h, deps
+}
+
+func purchaseRequest(ids ...string) *http.Request {
+	body := `["` + strings.Join(ids, `","`) + `"]`
+	req := httptest.NewRequest(http.MethodPost, "/api/cart/"+testUserID+"/purchase", strings.NewReader(body))
+	return mux.SetURLVars(req, map[string]string{"userID": testUserID})
+}
+
+func TestPurchaseFromCart_Success(t *testing.T) {
+	t.Parallel()
+	h, deps := setupHandler(t)
+	items := map[string]int{testAnnID: 3}
+
+	deps.cart.EXPECT().GetByUserID(testUserID).
+		Return([]shopping_cart.CartItem{{AnnouncementID: testAnnID, Quantity: 3}}, nil)
+	deps.inventory.EXPECT().Reserve(testUserID, items, reserveTTL).Return(time.Now().Add(reserveTTL), nil)
+	deps.ann.EXPECT().GetInfoForShoppingCart([]string{testAnnID}).
+		Return([]typesAnn.InfoForSC{{ID: testAnnID, Price: 1000, Discount: 10}}, nil)
+	deps.inventory.EXPECT().Purchase(testUserID, items, int64(2700)).Return(nil)
+	deps.cart.EXPECT().DeleteAnnouncement(testUserID, testAnnID).Return(nil)
+	deps.ann.EXPECT().GetByID(testAnnID).Return(&announcement.Announcement{ID: testAnnID, Category: 1}, nil)
+
+	w := httptest.NewRecorder()
+	h.PurchaseFromCart(w, purchaseRequest(testAnnID))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, float64(2700), resp["total"])
+	assert.Len(t, deps.producer.events, 1)
+	assert.Equal(t, []string{testAnnID}, deps.producer.events[0].AnnouncementIDs)
+}
+
+func TestPurchaseFromCart_OutOfStock(t *testing.T) {
+	t.Parallel()
+	h, deps := setupHandler(t)
+
+	deps.cart.EXPECT().GetByUserID(testUserID).
+		Return([]shopping_cart.CartItem{{AnnouncementID: testAnnID, Quantity: 1}}, nil)
+	deps.inventory.EXPECT().Reserve(testUserID, map[string]int{testAnnID: 1}, reserveTTL).
+		Return(time.Time{}, fmt.Errorf("%w: %s", myErr.ErrOutOfStock, testAnnID))
+
+	w := httptest.NewRecorder()
+	h.PurchaseFromCart(w, purchaseRequest(testAnnID))
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestPurchaseFromCart_InsufficientFundsReleasesReservation(t *testing.T) {
+	t.Parallel()
+	h, deps := setupHandler(t)
+
+	deps.cart.EXPECT().GetByUserID(testUserID).
+		Return([]shopping_cart.CartItem{{AnnouncementID: testAnnID, Quantity: 1}}, nil)
+	deps.inventory.EXPECT().Reserve(testUserID, map[string]int{testAnnID: 1}, reserveTTL).
+		Return(time.Now().Add(reserveTTL), nil)
+	deps.ann.EXPECT().GetInfoForShoppingCart([]string{testAnnID}).
+		Return([]typesAnn.InfoForSC{{ID: testAnnID, Price: 1000}}, nil)
+	deps.inventory.EXPECT().Purchase(testUserID, map[string]int{testAnnID: 1}, int64(1000)).Return(myErr.ErrNoFunds)
+	deps.inventory.EXPECT().Release(testUserID, []string{testAnnID}).Return(nil)
+
+	w := httptest.NewRecorder()
+	h.PurchaseFromCart(w, purchaseRequest(testAnnID))
+
+	assert.Equal(t, http.StatusPaymentRequired, w.Code)
+}
+
+func TestPurchaseFromCart_PurchaseOutOfStockReleasesReservation(t *testing.T) {
+	t.Parallel()
+	h, deps := setupHandler(t)
+	items := map[string]int{testAnnID: 1}
+
+	deps.cart.EXPECT().GetByUserID(testUserID).
+		Return([]shopping_cart.CartItem{{AnnouncementID: testAnnID, Quantity: 1}}, nil)
+	deps.inventory.EXPECT().Reserve(testUserID, items, reserveTTL).Return(time.Now().Add(reserveTTL), nil)
+	deps.ann.EXPECT().GetInfoForShoppingCart([]string{testAnnID}).
+		Return([]typesAnn.InfoForSC{{ID: testAnnID, Price: 1000}}, nil)
+	// резерв успел истечь и товар раскупили: транзакция откатилась вместе со списанием денег
+	deps.inventory.EXPECT().Purchase(testUserID, items, int64(1000)).Return(myErr.ErrOutOfStock)
+	deps.inventory.EXPECT().Release(testUserID, []string{testAnnID}).Return(nil)
+
+	w := httptest.NewRecorder()
+	h.PurchaseFromCart(w, purchaseRequest(testAnnID))
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestAddToShoppingCart_InvalidQuantity(t *testing.T) {
+	t.Parallel()
+	h, _ := setupHandler(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/cart/"+testUserID+"/item/"+testAnnID+"?quantity=0", nil)
+	req = mux.SetURLVars(req, map[string]string{"userID": testUserID, "annID": testAnnID})
+
+	w := httptest.NewRecorder()
+	h.AddToShoppingCart(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAddToShoppingCart_Draft(t *testing.T) {
+	t.Parallel()
+	h, deps := setupHandler(t)
+
+	deps.ann.EXPECT().GetByID(testAnnID).Return(&announcement.Announcement{ID: testAnnID, IsDraft: true}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/cart/"+testUserID+"/item/"+testAnnID, nil)
+	req = mux.SetURLVars(req, map[string]string{"userID": testUserID, "annID": testAnnID})
+
+	w := httptest.NewRecorder()
+	h.AddToShoppingCart(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestAddToShoppingCart_SendsAddToCartEvent(t *testing.T) {
+	t.Parallel()
+	h, deps := setupHandler(t)
+
+	deps.ann.EXPECT().GetByID(testAnnID).
+		Return(&announcement.Announcement{ID: testAnnID, UserSellerID: "seller-1", Category: 3}, nil)
+	deps.cart.EXPECT().AddAnnouncement(testUserID, testAnnID, 1).Return(nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/cart/"+testUserID+"/item/"+testAnnID, nil)
+	req = mux.SetURLVars(req, map[string]string{"userID": testUserID, "annID": testAnnID})
+
+	w := httptest.NewRecorder()
+	h.AddToShoppingCart(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Len(t, deps.producer.events, 1)
+	assert.Equal(t, kafka.EventTypeAddToCart, deps.producer.events[0].Type)
+	assert.Equal(t, testAnnID, deps.producer.events[0].AnnouncementID)
+	assert.Equal(t, "seller-1", deps.producer.events[0].SellerID)
+}
+
+func TestDeleteFromShoppingCart_SendsRemoveFromCartEvent(t *testing.T) {
+	t.Parallel()
+	h, deps := setupHandler(t)
+
+	deps.cart.EXPECT().DeleteAnnouncement(testUserID, testAnnID).Return(nil)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/cart/"+testUserID+"/item/"+testAnnID, nil)
+	req = mux.SetURLVars(req, map[string]string{"userID": testUserID, "annID": testAnnID})
+
+	w := httptest.NewRecorder()
+	h.DeleteFromShoppingCart(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, deps.producer.events, 1)
+	assert.Equal(t, kafka.EventTypeRemoveFromCart, deps.producer.events[0].Type)
+	assert.Equal(t, testAnnID, deps.producer.events[0].AnnouncementID)
+}
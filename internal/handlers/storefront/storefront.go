@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gafroshka-main/internal/announcement"
+	typesAnn "gafroshka-main/internal/types/announcement"
+	myErr "gafroshka-main/internal/types/errors"
+	"gafroshka-main/internal/user"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultStorefrontLimit = 20
+	maxStorefrontLimit     = 100
+)
+
+// StorefrontHandler публичная витрина продавца: сводка и список его объявлений
+type StorefrontHandler struct {
+	Logger           *zap.SugaredLogger
+	UserRepo         user.UserRepo
+	AnnouncementRepo announcement.AnnouncementRepo
+}
+
+func NewStorefrontHandler(
+	l *zap.SugaredLogger,
+	ur user.UserRepo,
+	ar announcement.AnnouncementRepo,
+) *StorefrontHandler {
+	return &StorefrontHandler{
+		Logger:           l,
+		UserRepo:         ur,
+		AnnouncementRepo: ar,
+	}
+}
+
+// storefront - сводка по продавцу
+type storefront struct {
+	SellerID    string  `json:"seller_id"`
+	Name        string  `json:"name"`
+	Surname     string  `json:"surname"`
+	Rating      float64 `json:"rating"`
+	RatingCount int     `json:"rating_count"`
+	DealsCount  int     `json:"deals_count"`
+	typesAnn.SellerStats
+	MemberSince time.Time `json:"member_since"`
+	// MemberDays - сколько полных дней продавец зарегистрирован
+	MemberDays int `json:"member_days"`
+}
+
+// sellerAnnouncements - страница объявлений продавца
+type sellerAnnouncements struct {
+	Announcements []announcement.Announcement `json:"announcements"`
+	Total         int                         `json:"total"`
+	Limit         int                         `json:"limit"`
+	Offset        int                         `json:"offset"`
+}
+
+// seller проверяет id продавца и возвращает его, отвечая ошибкой сам
+func (h *StorefrontHandler) seller(w http.ResponseWriter, r *http.Request) (*user.User, bool) {
+	id := mux.Vars(r)["id"]
+	if _, err := uuid.Parse(id); err != nil {
+		myErr.SendErrorTo(w, myErr.ErrBadID, http.StatusBadRequest, h.Logger)
+		return nil, false
+	}
+
+	u, err := h.UserRepo.Info(id)
+	if err != nil {
+		if errors.Is(err, myErr.ErrNotFound) {
+			myErr.SendErrorTo(w, err, http.StatusNotFound, h.Logger)
+			return nil, false
+		}
+		myErr.SendErrorTo(w, err, http.StatusInternalServerError, h.Logger)
+		return nil, false
+	}
+
+	return u, true
+}
+
+// Storefront handles GET /user/{id}/storefront
+func (h *StorefrontHandler) Storefront(w http.ResponseWriter, r *http.Request) {
+	u, ok := h.seller(w, r)
+	if !ok {
+		return
+	}
+
+	stats, err := h.AnnouncementRepo.GetSellerStats(u.ID)
+	if err != nil {
+		myErr.SendErrorTo(w, err, http.StatusInternalServerError, h.Logger)
+		return
+	}
+
+	resp := storefront{
+		SellerID:    u.ID,
+		Name:        u.Name,
+		Surname:     u.Surname,
+		Rating:      u.Rating,
+		RatingCount: u.RatingCount,
+		DealsCount:  u.DealsCount,
+		SellerStats: stats,
+		MemberSince: u.RegistrationDate,
+		MemberDays:  int(time.Since(u.RegistrationDate).Hours() / 24),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.Logger.Warnw("error writing response", "err", err)
+	}
+}
+
+// Announcements handles GET /user/{id}/announcements?status=&sort=&limit=&offset=
+func (h *StorefrontHandler) Announcements(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseSellerFilter(r)
+	if err != nil {
+		myErr.SendErrorTo(w, err, http.StatusBadRequest, h.Logger)
+		return
+	}
+
+	u, ok := h.seller(w, r)
+	if !ok {
+		return
+	}
+
+	announcements, total, err := h.AnnouncementRepo.GetBySeller(u.ID, filter)
+	if err != nil {
+		myErr.SendErrorTo(w, err, http.StatusInternalServerError, h.Logger)
+		return
+	}
+
+	resp := sellerAnnouncements{
+		Announcements: announcements,
+		Total:         total,
+		Limit:         filter.Limit,
+		Offset:        filter.Offset,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.Logger.Warnw("error writing response", "err", err)
+	}
+}
+
+// parseSellerFilter читает фильтр из query, подставляя значения по умолчанию
+func parseSellerFilter(r *http.Request) (typesAnn.SellerFilter, error) {
+	q := r.URL.Query()
+	filter := typesAnn.SellerFilter{
+		Status: typesAnn.SellerStatusActive,
+		Sort:   typesAnn.SellerSortNewest,
+		Limit:  defaultStorefrontLimit,
+	}
+
+	if s := q.Get("status"); s != "" {
+		switch s {
+		case typesAnn.SellerStatusAll, typesAnn.SellerStatusActive, typesAnn.SellerStatusSold:
+			filter.Status = s
+		default:
+			return filter, errors.New("status must be one of: all, active, sold")
+		}
+	}
+
+	if s := q.Get("sort"); s != "" {
+		switch s {
+		case typesAnn.SellerSortNewest, typesAnn.SellerSortOldest,
+			typesAnn.SellerSortPriceAsc, typesAnn.SellerSortPriceDesc, typesAnn.SellerSortRating:
+			filter.Sort = s
+		default:
+			return filter, errors.New("sort must be one of: newest, oldest, price_asc, price_desc, rating")
+		}
+	}
+
+	if l := q.Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n <= 0 {
+			return filter, errors.New("limit must be positive number")
+		}
+		filter.Limit = min(n, maxStorefrontLimit)
+	}
+
+	if o := q.Get("offset"); o != "" {
+		n, err := strconv.Atoi(o)
+		if err != nil || n < 0 {
+			return filter, errors.New("offset must be non-negative number")
+		}
+		filter.Offset = n
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gafroshka-main/internal/announcement"
+	"gafroshka-main/internal/mocks"
+	typesAnn "gafroshka-main/internal/types/announcement"
+	myErr "gafroshka-main/internal/types/errors"
+	"gafroshka-main/internal/user"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+)
+
+const testSellerID = "11111111-1111-1111-1111-111111111111"
+
+func setupHandler(t *testing.T) (*StorefrontHandler, *mocks.MockUserRepo, *mocks.MockAnnouncementRepo) {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	ur := mocks.NewMockUserRepo(ctrl)
+	ar := mocks.NewMockAnnouncementRepo(ctrl)
+	return NewStorefrontHandler(zaptest.NewLogger(t).Sugar(), ur, ar), ur, ar
+}
+
+func sellerRequest(path, id string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	return mux.SetURLVars(req, map[string]string{"id": id})
+}
+
+func TestStorefront(t *testing.T) {
+	t.Parallel()
+	h, ur, ar := setupHandler(t)
+
+	ur.EXPECT().Info(testSellerID).Return(&user.User{
+		ID:               testSellerID,
+		Name:             "Иван",
+		Rating:           4.5,
+		RatingCount:      10,
+		DealsCount:       7,
+		RegistrationDate: time.Now().AddDate(0, 0, -30),
+	}, nil)
+	ar.EXPECT().GetSellerStats(testSellerID).
+		Return(typesAnn.SellerStats{TotalCount: 5, ActiveCount: 3, SoldCount: 1, AverageRating: 4.2}, nil)
+
+	w := httptest.NewRecorder()
+	h.Storefront(w, sellerRequest("/api/user/"+testSellerID+"/storefront", testSellerID))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, float64(3), resp["active_count"])
+	assert.Equal(t, float64(4.2), resp["average_rating"])
+	assert.Equal(t, float64(10), resp["rating_count"])
+	assert.Equal(t, float64(7), resp["deals_count"])
+	assert.Equal(t, float64(30), resp["member_days"])
+}
+
+func TestStorefront_NotFound(t *testing.T) {
+	t.Parallel()
+	h, ur, _ := setupHandler(t)
+
+	ur.EXPECT().Info(testSellerID).Return(nil, myErr.ErrNotFound)
+
+	w := httptest.NewRecorder()
+	h.Storefront(w, sellerRequest("/api/user/"+testSellerID+"/storefront", testSellerID))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestAnnouncements(t *testing.T) {
+	t.Parallel()
+	h, ur, ar := setupHandler(t)
+
+	ur.EXPECT().Info(testSellerID).Return(&user.User{ID: testSellerID}, nil)
+	ar.EXPECT().GetBySeller(testSellerID, typesAnn.SellerFilter{
+		Status: typesAnn.SellerStatusSold,
+		Sort:   typesAnn.SellerSortPriceAsc,
+		Limit:  maxStorefrontLimit,
+		Offset: 10,
+	}).Return([]announcement.Announcement{{ID: "a1"}}, 11, nil)
+
+	path := "/api/user/" + testSellerID + "/announcements?status=sold&sort=price_asc&limit=500&offset=10"
+	w := httptest.NewRecorder()
+	h.Announcements(w, sellerRequest(path, testSellerID))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp sellerAnnouncements
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, 11, resp.Total)
+	assert.Len(t, resp.Announcements, 1)
+}
+
+func TestAnnouncements_BadRequest(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name  string
+		id    string
+		query string
+	}{
+		{name: "неизвестный статус", id: testSellerID, query: "?status=deleted"},
+		{name: "неизвестная сортировка", id: testSellerID, query: "?sort=name"},
+		{name: "отрицательный offset", id: testSellerID, query: "?offset=-1"},
+		{name: "некорректный id", id: "seller", query: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, _, _ := setupHandler(t)
+
+			w := httptest.NewRecorder()
+			h.Announcements(w, sellerRequest("/api/user/"+tt.id+"/announcements"+tt.query, tt.id))
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
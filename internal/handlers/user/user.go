@@ -4,12 +4,16 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"gafroshka-main/internal/kafka"
+	recentlyviewed "gafroshka-main/internal/recently_viewed"
 	"gafroshka-main/internal/session"
 	myErr "gafroshka-main/internal/types/errors"
 	types "gafroshka-main/internal/types/user"
 	"gafroshka-main/internal/user"
+	"gafroshka-main/internal/visitor"
 	"net/http"
 	"net/mail"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -18,16 +22,50 @@ import (
 )
 
 type UserHandler struct {
-	Logger         *zap.SugaredLogger
-	UserRepository user.UserRepo
-	SessionManger  session.SessionRepo
+	Logger             *zap.SugaredLogger
+	UserRepository     user.UserRepo
+	SessionManger      session.SessionRepo
+	EventProducer      kafka.EventProducer
+	RecentlyViewedRepo recentlyviewed.RecentlyViewedRepo
 }
 
-func NewUserHandler(l *zap.SugaredLogger, ur user.UserRepo, sr session.SessionRepo) *UserHandler {
+func NewUserHandler(
+	l *zap.SugaredLogger,
+	ur user.UserRepo,
+	sr session.SessionRepo,
+	producer kafka.EventProducer,
+	rv recentlyviewed.RecentlyViewedRepo,
+) *UserHandler {
 	return &UserHandler{
-		Logger:         l,
-		UserRepository: ur,
-		SessionManger:  sr,
+		Logger:             l,
+		UserRepository:     ur,
+		SessionManger:      sr,
+		EventProducer:      producer,
+		RecentlyViewedRepo: rv,
+	}
+}
+
+// stitchVisitor переносит историю анонимного посетителя из контекста в аккаунт userID:
+// недавно просмотренные - сразу в Redis, предпочтения и события - через событие identify в аналитике.
+// Ошибки не прерывают вход, история посетителя просто останется несвязанной
+func (h *UserHandler) stitchVisitor(ctx context.Context, userID string) {
+	visitorID, ok := visitor.FromContext(ctx)
+	if !ok {
+		return
+	}
+
+	if err := h.RecentlyViewedRepo.Merge(ctx, visitor.Key(visitorID), userID); err != nil {
+		h.Logger.Warnf("failed to merge recently viewed of visitor %s: %v", visitorID, err)
+	}
+
+	event := kafka.Event{
+		UserID:    userID,
+		VisitorID: visitorID,
+		Type:      kafka.EventTypeIdentify,
+		Timestamp: time.Now(),
+	}
+	if err := h.EventProducer.SendEvent(ctx, event); err != nil {
+		h.Logger.Warnf("failed to send identify event: %v", err)
 	}
 }
 
@@ -55,6 +93,17 @@ func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Регистрация начинает когорту пользователя в аналитике
+	event := kafka.Event{
+		UserID:    u.ID,
+		Type:      kafka.EventTypeSignup,
+		Timestamp: time.Now(),
+	}
+	if err := h.EventProducer.SendEvent(r.Context(), event); err != nil {
+		h.Logger.Warnf("failed to send signup event: %v", err)
+	}
+	h.stitchVisitor(r.Context(), u.ID)
+
 	// Создаем для него сессию
 	sess, err := h.SessionManger.CreateSession(context.Background(), w, u.ID, u.Email)
 	if err != nil {
@@ -98,6 +147,8 @@ func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.stitchVisitor(r.Context(), u.ID)
+
 	// Создаем для него сессию
 	sess, err := h.SessionManger.CreateSession(context.Background(), w, u.ID, u.Email)
 	if err != nil {
@@ -2,13 +2,16 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"gafroshka-main/internal/kafka"
 	"gafroshka-main/internal/mocks"
 	"gafroshka-main/internal/session"
 	myErr "gafroshka-main/internal/types/errors"
 	types "gafroshka-main/internal/types/user"
 	"gafroshka-main/internal/user"
+	"gafroshka-main/internal/visitor"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -25,6 +28,13 @@ const (
 	invalidJSON = "Invalid JSON"
 )
 
+// nopProducer отбрасывает события, подтесты регистрации идут параллельно
+type nopProducer struct{}
+
+func (nopProducer) SendEvent(ctx context.Context, event kafka.Event) error { return nil }
+
+func (nopProducer) Close() error { return nil }
+
 func TestUserHandler_Login(t *testing.T) {
 	t.Parallel()
 	ctrl := gomock.NewController(t)
@@ -37,6 +47,7 @@ func TestUserHandler_Login(t *testing.T) {
 		Logger:         logger,
 		UserRepository: mockUserRepo,
 		SessionManger:  mockSessionRepo,
+		EventProducer:  nopProducer{},
 	}
 
 	tests := []struct {
@@ -146,6 +157,7 @@ func TestUserHandler_Register(t *testing.T) {
 		Logger:         logger,
 		UserRepository: mockUserRepo,
 		SessionManger:  mockSessionRepo,
+		EventProducer:  nopProducer{},
 	}
 
 	tests := []struct {
@@ -238,7 +250,7 @@ func TestUserHandler_Info(t *testing.T) {
 	logger := zap.NewNop().Sugar()
 	mockeSessionRepo := mocks.NewMockSessionRepo(ctrl)
 
-	handler := NewUserHandler(logger, mockRepo, mockeSessionRepo)
+	handler := NewUserHandler(logger, mockRepo, mockeSessionRepo, nopProducer{}, mocks.NewMockRecentlyViewedRepo(ctrl))
 
 	tests := []struct {
 		name           string
@@ -310,7 +322,7 @@ func TestUserHandler_ChangeProfile(t *testing.T) {
 	logger := zap.NewNop().Sugar()
 	mockeSessionRepo := mocks.NewMockSessionRepo(ctrl)
 
-	handler := NewUserHandler(logger, mockRepo, mockeSessionRepo)
+	handler := NewUserHandler(logger, mockRepo, mockeSessionRepo, nopProducer{}, mocks.NewMockRecentlyViewedRepo(ctrl))
 
 	tests := []struct {
 		name           string
@@ -394,7 +406,7 @@ func TestUserHandler_GetBalance(t *testing.T) {
 	mockRepo := mocks.NewMockUserRepo(ctrl)
 	mockSessionRepo := mocks.NewMockSessionRepo(ctrl)
 	logger := zap.NewNop().Sugar()
-	handler := NewUserHandler(logger, mockRepo, mockSessionRepo)
+	handler := NewUserHandler(logger, mockRepo, mockSessionRepo, nopProducer{}, mocks.NewMockRecentlyViewedRepo(ctrl))
 
 	tests := []struct {
 		name           string
@@ -465,7 +477,7 @@ func TestUserHandler_TopUpBalance(t *testing.T) {
 	mockRepo := mocks.NewMockUserRepo(ctrl)
 	mockSessionRepo := mocks.NewMockSessionRepo(ctrl)
 	logger := zap.NewNop().Sugar()
-	handler := NewUserHandler(logger, mockRepo, mockSessionRepo)
+	handler := NewUserHandler(logger, mockRepo, mockSessionRepo, nopProducer{}, mocks.NewMockRecentlyViewedRepo(ctrl))
 
 	tests := []struct {
 		name           string
@@ -559,3 +571,98 @@ func TestUserHandler_TopUpBalance(t *testing.T) {
 		})
 	}
 }
+
+type recordingProducer struct {
+	events []kafka.Event
+}
+
+func (p *recordingProducer) SendEvent(ctx context.Context, event kafka.Event) error {
+	p.events = append(p.events, event)
+	return nil
+}
+
+func (p *recordingProducer) Close() error { return nil }
+
+func TestUserHandler_Register_SendsSignupEvent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserRepo := mocks.NewMockUserRepo(ctrl)
+	mockSessionRepo := mocks.NewMockSessionRepo(ctrl)
+	producer := &recordingProducer{}
+	handler := NewUserHandler(zap.NewNop().Sugar(), mockUserRepo, mockSessionRepo, producer, mocks.NewMockRecentlyViewedRepo(ctrl))
+
+	form := types.CreateUser{Email: "new@example.com", Password: "123456"}
+	mockUserRepo.EXPECT().CreateUser(form).Return(&user.User{ID: "u-1", Email: form.Email}, nil)
+	mockSessionRepo.EXPECT().
+		CreateSession(gomock.Any(), gomock.Any(), "u-1", form.Email).
+		Return(&session.Session{ID: "sess-1"}, nil)
+
+	bodyBytes, _ := json.Marshal(form) // nolint:errcheck
+	req := httptest.NewRequest(http.MethodPost, "/register", bytes.NewReader(bodyBytes))
+	rr := httptest.NewRecorder()
+
+	handler.Register(rr, req)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.Equal(t, 1, len(producer.events))
+	assert.Equal(t, kafka.EventTypeSignup, producer.events[0].Type)
+	assert.Equal(t, "u-1", producer.events[0].UserID)
+}
+
+func TestUserHandler_Login_StitchesVisitor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserRepo := mocks.NewMockUserRepo(ctrl)
+	mockSessionRepo := mocks.NewMockSessionRepo(ctrl)
+	mockRecentlyViewed := mocks.NewMockRecentlyViewedRepo(ctrl)
+	producer := &recordingProducer{}
+	handler := NewUserHandler(zap.NewNop().Sugar(), mockUserRepo, mockSessionRepo, producer, mockRecentlyViewed)
+
+	form := RequestRegisterForm{Email: "test@example.com", Password: "123456"}
+	mockUserRepo.EXPECT().CheckUser(form.Email, form.Password).Return(&user.User{ID: "u-1", Email: form.Email}, nil)
+	mockRecentlyViewed.EXPECT().Merge(gomock.Any(), "visitor:v-1", "u-1").Return(nil)
+	mockSessionRepo.EXPECT().
+		CreateSession(gomock.Any(), gomock.Any(), "u-1", form.Email).
+		Return(&session.Session{ID: "sess-1"}, nil)
+
+	bodyBytes, _ := json.Marshal(form) // nolint:errcheck
+	req := httptest.NewRequest(http.MethodPost, "/login", bytes.NewReader(bodyBytes))
+	req = req.WithContext(visitor.WithID(req.Context(), "v-1"))
+	rr := httptest.NewRecorder()
+
+	handler.Login(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, 1, len(producer.events))
+	assert.Equal(t, kafka.EventTypeIdentify, producer.events[0].Type)
+	assert.Equal(t, "u-1", producer.events[0].UserID)
+	assert.Equal(t, "v-1", producer.events[0].VisitorID)
+}
+
+func TestUserHandler_Login_WithoutVisitor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserRepo := mocks.NewMockUserRepo(ctrl)
+	mockSessionRepo := mocks.NewMockSessionRepo(ctrl)
+	producer := &recordingProducer{}
+	handler := NewUserHandler(zap.NewNop().Sugar(), mockUserRepo, mockSessionRepo, producer, mocks.NewMockRecentlyViewedRepo(ctrl))
+
+	form := RequestRegisterForm{Email: "test@example.com", Password: "123456"}
+	mockUserRepo.EXPECT().CheckUser(form.Email, form.Password).Return(&user.User{ID: "u-1", Email: form.Email}, nil)
+	mockSessionRepo.EXPECT().
+		CreateSession(gomock.Any(), gomock.Any(), "u-1", form.Email).
+		Return(&session.Session{ID: "sess-1"}, nil)
+
+	bodyBytes, _ := json.Marshal(form) // nolint:errcheck
+	req := httptest.NewRequest(http.MethodPost, "/login", bytes.NewReader(bodyBytes))
+	rr := httptest.NewRecorder()
+
+	handler.Login(rr, req)
+
+	// вход без посетителя ничего не связывает и не начинает когорту заново
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, 0, len(producer.events))
+}
@@ -0,0 +1,19 @@
+package inventory
+
+import "time"
+
+// InventoryRepo интерфейс для работы с остатками товара и резервами при оформлении покупки
+//
+//go:generate mockgen -source=inventory.go -destination=../mocks/mock_inventory_repo.go -package=mocks
+type InventoryRepo interface {
+	// Reserve резервирует товары для пользователя на ttl: id объявления -> количество
+	// Повторный вызов продлевает резерв. Возвращает время окончания резерва
+	// Если товара не хватает с учетом чужих резервов, возвращает ErrOutOfStock
+	Reserve(userID string, items map[string]int, ttl time.Duration) (time.Time, error)
+	// Release снимает резервы пользователя с переданных объявлений
+	Release(userID string, announcementIDs []string) error
+	// Purchase в одной транзакции списывает с баланса пользователя total, товары со склада
+	// и снимает резервы пользователя. Объявление с нулевым остатком становится неактивным
+	// Если не хватает денег, возвращает ErrNoFunds, товара - ErrOutOfStock, и ничего не меняет
+	Purchase(userID string, items map[string]int, total int64) error
+}
@@ -0,0 +1,183 @@
+package inventory
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+
+	myErr "gafroshka-main/internal/types/errors"
+)
+
+func setup(t *testing.T) (*InventoryDBRepository, sqlmock.Sqlmock, func()) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка при создании mock db: %s", err)
+	}
+
+	repo := NewInventoryDBRepository(db, zaptest.NewLogger(t).Sugar())
+
+	return repo, mock, func() { db.Close() }
+}
+
+func expectLock(mock sqlmock.Sqlmock, ids []string, rows *sqlmock.Rows) {
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM stock_reservation WHERE expires_at <= NOW()")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, quantity, is_active FROM announcement WHERE id = ANY($1) ORDER BY id FOR UPDATE")).
+		WithArgs(pq.Array(ids)).
+		WillReturnRows(rows)
+}
+
+func TestReserve(t *testing.T) {
+	t.Parallel()
+	expiresAt := time.Date(2025, 1, 1, 12, 10, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		items         map[string]int
+		mockBehavior  func(mock sqlmock.Sqlmock)
+		expectedError error
+	}{
+		{
+			name:  "хватает с учетом чужих резервов",
+			items: map[string]int{"a1": 2},
+			mockBehavior: func(mock sqlmock.Sqlmock) {
+				expectLock(mock, []string{"a1"}, sqlmock.NewRows([]string{"id", "quantity", "is_active"}).AddRow("a1", 5, true))
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT announcement_id, SUM(quantity) FROM stock_reservation")).
+					WithArgs(pq.Array([]string{"a1"}), "u1").
+					WillReturnRows(sqlmock.NewRows([]string{"announcement_id", "sum"}).AddRow("a1", 3))
+				mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO stock_reservation")).
+					WithArgs("u1", "a1", 2, int64(600)).
+					WillReturnRows(sqlmock.NewRows([]string{"expires_at"}).AddRow(expiresAt))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:  "последний экземпляр зарезервирован другим покупателем",
+			items: map[string]int{"a1": 1},
+			mockBehavior: func(mock sqlmock.Sqlmock) {
+				expectLock(mock, []string{"a1"}, sqlmock.NewRows([]string{"id", "quantity", "is_active"}).AddRow("a1", 1, true))
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT announcement_id, SUM(quantity) FROM stock_reservation")).
+					WithArgs(pq.Array([]string{"a1"}), "u1").
+					WillReturnRows(sqlmock.NewRows([]string{"announcement_id", "sum"}).AddRow("a1", 1))
+				mock.ExpectRollback()
+			},
+			expectedError: myErr.ErrOutOfStock,
+		},
+		{
+			name:  "объявление снято с продажи",
+			items: map[string]int{"a1": 1},
+			mockBehavior: func(mock sqlmock.Sqlmock) {
+				expectLock(mock, []string{"a1"}, sqlmock.NewRows([]string{"id", "quantity", "is_active"}).AddRow("a1", 0, false))
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT announcement_id, SUM(quantity) FROM stock_reservation")).
+					WithArgs(pq.Array([]string{"a1"}), "u1").
+					WillReturnRows(sqlmock.NewRows([]string{"announcement_id", "sum"}))
+				mock.ExpectRollback()
+			},
+			expectedError: myErr.ErrOutOfStock,
+		},
+		{
+			name:          "неположительное количество",
+			items:         map[string]int{"a1": 0},
+			mockBehavior:  func(mock sqlmock.Sqlmock) {},
+			expectedError: myErr.ErrInvalidQuantity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock, cleanup := setup(t)
+			defer cleanup()
+
+			tt.mockBehavior(mock)
+
+			got, err := repo.Reserve("u1", tt.items, 10*time.Minute)
+			if tt.expectedError != nil {
+				assert.True(t, errors.Is(err, tt.expectedError))
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, expiresAt, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPurchase(t *testing.T) {
+	t.Parallel()
+	expectDebit := func(mock sqlmock.Sqlmock, affected int64) {
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE users SET balance = balance - $1 WHERE id = $2 AND balance >= $1")).
+			WithArgs(int64(500), "u1").
+			WillReturnResult(sqlmock.NewResult(0, affected))
+	}
+
+	tests := []struct {
+		name          string
+		mockBehavior  func(mock sqlmock.Sqlmock)
+		expectedError error
+	}{
+		{
+			name: "списание денег и товара",
+			mockBehavior: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectDebit(mock, 1)
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE announcement SET quantity = quantity - $1, is_active = quantity - $1 > 0")).
+					WithArgs(1, "a1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE announcement SET quantity = quantity - $1, is_active = quantity - $1 > 0")).
+					WithArgs(2, "a2").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta("DELETE FROM stock_reservation WHERE user_id = $1 AND announcement_id = ANY($2)")).
+					WithArgs("u1", pq.Array([]string{"a1", "a2"})).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "не хватает денег",
+			mockBehavior: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectDebit(mock, 0)
+				mock.ExpectRollback()
+			},
+			expectedError: myErr.ErrNoFunds,
+		},
+		{
+			// откат транзакции возвращает и деньги
+			name: "товар закончился",
+			mockBehavior: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectDebit(mock, 1)
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE announcement SET quantity = quantity - $1")).
+					WithArgs(1, "a1").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			expectedError: myErr.ErrOutOfStock,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock, cleanup := setup(t)
+			defer cleanup()
+
+			tt.mockBehavior(mock)
+
+			err := repo.Purchase("u1", map[string]int{"a1": 1, "a2": 2}, 500)
+			if tt.expectedError != nil {
+				assert.True(t, errors.Is(err, tt.expectedError))
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
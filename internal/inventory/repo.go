@@ -0,0 +1,254 @@
+package inventory
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	myErr "gafroshka-main/internal/types/errors"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+type InventoryDBRepository struct {
+	DB     *sql.DB
+	Logger *zap.SugaredLogger
+}
+
+func NewInventoryDBRepository(db *sql.DB, l *zap.SugaredLogger) *InventoryDBRepository {
+	return &InventoryDBRepository{
+		DB:     db,
+		Logger: l,
+	}
+}
+
+// sortedIDs возвращает id объявлений в стабильном порядке,
+// чтобы параллельные транзакции блокировали строки в одном порядке и не ловили deadlock
+func sortedIDs(items map[string]int) ([]string, error) {
+	ids := make([]string, 0, len(items))
+	for id, qty := range items {
+		if qty <= 0 {
+			return nil, myErr.ErrInvalidQuantity
+		}
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	return ids, nil
+}
+
+// Reserve резервирует товары для пользователя на ttl
+func (ir *InventoryDBRepository) Reserve(userID string, items map[string]int, ttl time.Duration) (time.Time, error) {
+	ids, err := sortedIDs(items)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	tx, err := ir.DB.Begin()
+	if err != nil {
+		ir.Logger.Errorf("Error starting transaction: %v", err)
+		return time.Time{}, myErr.ErrDBInternal
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec(`DELETE FROM stock_reservation WHERE expires_at <= NOW()`); err != nil {
+		ir.Logger.Errorf("Error deleting expired reservations: %v", err)
+		return time.Time{}, myErr.ErrDBInternal
+	}
+
+	// блокируем строки объявлений, пока считаем остатки
+	rows, err := tx.Query(`
+	SELECT id, quantity, is_active
+	FROM announcement
+	WHERE id = ANY($1)
+	ORDER BY id
+	FOR UPDATE
+	`, pq.Array(ids))
+	if err != nil {
+		ir.Logger.Errorf("Error locking announcements %v: %v", ids, err)
+		return time.Time{}, myErr.ErrDBInternal
+	}
+
+	available := make(map[string]int, len(ids))
+	for rows.Next() {
+		var (
+			id       string
+			quantity int
+			isActive bool
+		)
+		if err := rows.Scan(&id, &quantity, &isActive); err != nil {
+			rows.Close()
+			ir.Logger.Errorf("Error scanning announcement stock: %v", err)
+			return time.Time{}, myErr.ErrDBInternal
+		}
+		if isActive {
+			available[id] = quantity
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		ir.Logger.Errorf("Rows iteration error: %v", err)
+		return time.Time{}, myErr.ErrDBInternal
+	}
+
+	// вычитаем действующие резервы других покупателей
+	rows, err = tx.Query(`
+	SELECT announcement_id, SUM(quantity)
+	FROM stock_reservation
+	WHERE announcement_id = ANY($1) AND user_id <> $2
+	GROUP BY announcement_id
+	`, pq.Array(ids), userID)
+	if err != nil {
+		ir.Logger.Errorf("Error getting reservations of %v: %v", ids, err)
+		return time.Time{}, myErr.ErrDBInternal
+	}
+	for rows.Next() {
+		var (
+			id       string
+			reserved int
+		)
+		if err := rows.Scan(&id, &reserved); err != nil {
+			rows.Close()
+			ir.Logger.Errorf("Error scanning reservation: %v", err)
+			return time.Time{}, myErr.ErrDBInternal
+		}
+		available[id] -= reserved
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		ir.Logger.Errorf("Rows iteration error: %v", err)
+		return time.Time{}, myErr.ErrDBInternal
+	}
+
+	for _, id := range ids {
+		if available[id] < items[id] {
+			return time.Time{}, fmt.Errorf("%w: %s", myErr.ErrOutOfStock, id)
+		}
+	}
+
+	var expiresAt time.Time
+	for _, id := range ids {
+		err = tx.QueryRow(`
+		INSERT INTO stock_reservation (user_id, announcement_id, quantity, expires_at)
+		VALUES ($1, $2, $3, NOW() + $4 * INTERVAL '1 second')
+		ON CONFLICT (user_id, announcement_id)
+		DO UPDATE SET quantity = EXCLUDED.quantity, expires_at = EXCLUDED.expires_at
+		RETURNING expires_at
+		`, userID, id, items[id], int64(ttl.Seconds())).Scan(&expiresAt)
+		if err != nil {
+			ir.Logger.Errorf("Error reserving announcement %s: %v", id, err)
+			return time.Time{}, myErr.ErrDBInternal
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		ir.Logger.Errorf("Error committing reservation: %v", err)
+		return time.Time{}, myErr.ErrDBInternal
+	}
+
+	return expiresAt, nil
+}
+
+// Release снимает резервы пользователя с переданных объявлений
+func (ir *InventoryDBRepository) Release(userID string, announcementIDs []string) error {
+	query := `DELETE FROM stock_reservation WHERE user_id = $1 AND announcement_id = ANY($2)`
+
+	if _, err := ir.DB.Exec(query, userID, pq.Array(announcementIDs)); err != nil {
+		ir.Logger.Errorf("Error releasing reservations of user %s: %v", userID, err)
+		return myErr.ErrDBInternal
+	}
+
+	return nil
+}
+
+// Purchase списывает деньги и товары в одной транзакции: либо покупка прошла целиком, либо ничего не изменилось
+func (ir *InventoryDBRepository) Purchase(userID string, items map[string]int, total int64) error {
+	ids, err := sortedIDs(items)
+	if err != nil {
+		return err
+	}
+
+	tx, err := ir.DB.Begin()
+	if err != nil {
+		ir.Logger.Errorf("Error starting transaction: %v", err)
+		return myErr.ErrDBInternal
+	}
+	defer tx.Rollback()
+
+	if err = ir.debit(tx, userID, total); err != nil {
+		return err
+	}
+	if err = ir.commitStock(tx, userID, ids, items); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		ir.Logger.Errorf("Error committing purchase: %v", err)
+		return myErr.ErrDBInternal
+	}
+
+	return nil
+}
+
+// debit списывает amount с баланса пользователя
+// Проверка баланса в том же UPDATE не дает двум параллельным покупкам уйти в минус
+func (ir *InventoryDBRepository) debit(tx *sql.Tx, userID string, amount int64) error {
+	res, err := tx.Exec(`
+	UPDATE users
+	SET balance = balance - $1
+	WHERE id = $2 AND balance >= $1
+	`, amount, userID)
+	if err != nil {
+		ir.Logger.Errorf("Error charging user %s: %v", userID, err)
+		return myErr.ErrDBInternal
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		ir.Logger.Errorf("Error getting affected rows: %v", err)
+		return myErr.ErrDBInternal
+	}
+	if affected == 0 {
+		return myErr.ErrNoFunds
+	}
+
+	return nil
+}
+
+// commitStock списывает товары со склада и снимает резервы пользователя
+func (ir *InventoryDBRepository) commitStock(tx *sql.Tx, userID string, ids []string, items map[string]int) error {
+	for _, id := range ids {
+		// условие quantity >= $1 не дает уйти в минус даже без резерва
+		res, err := tx.Exec(`
+		UPDATE announcement
+		SET quantity = quantity - $1, is_active = quantity - $1 > 0
+		WHERE id = $2 AND is_active = TRUE AND quantity >= $1
+		`, items[id], id)
+		if err != nil {
+			ir.Logger.Errorf("Error decrementing stock of %s: %v", id, err)
+			return myErr.ErrDBInternal
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			ir.Logger.Errorf("Error getting affected rows: %v", err)
+			return myErr.ErrDBInternal
+		}
+		if affected == 0 {
+			return fmt.Errorf("%w: %s", myErr.ErrOutOfStock, id)
+		}
+	}
+
+	_, err := tx.Exec(
+		`DELETE FROM stock_reservation WHERE user_id = $1 AND announcement_id = ANY($2)`,
+		userID, pq.Array(ids),
+	)
+	if err != nil {
+		ir.Logger.Errorf("Error deleting reservations of user %s: %v", userID, err)
+		return myErr.ErrDBInternal
+	}
+
+	return nil
+}
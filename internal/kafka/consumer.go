@@ -4,19 +4,57 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"strconv"
+	"sync"
+	"time"
 
 	kgo "github.com/segmentio/kafka-go"
 	"go.uber.org/zap"
 )
 
+const (
+	defaultRetryBackoff = 200 * time.Millisecond
+	defaultMaxBackoff   = 10 * time.Second
+	defaultWorkers      = 1
+	defaultQueueSize    = 100
+	// commitTimeout - сколько ждать фиксации смещений, в том числе при остановке
+	commitTimeout = 10 * time.Second
+)
+
+// ConsumerConfig - политика повторов и DLQ для Consumer
+type ConsumerConfig struct {
+	// Retries - сколько раз повторить обработку события после первой неудачи
+	Retries int `yaml:"retries"`
+	// RetryBackoff - пауза перед первым повтором, дальше она удваивается до MaxBackoff
+	RetryBackoff time.Duration `yaml:"retry_backoff"`
+	MaxBackoff   time.Duration `yaml:"max_backoff"`
+	// DLQTopic - топик для сообщений, которые не удалось обработать, пустой - такие сообщения только логируются
+	DLQTopic string `yaml:"dlq_topic"`
+	// Workers - сколько событий обрабатывается одновременно, события одного ключа - по порядку
+	Workers int `yaml:"workers"`
+	// QueueSize - сколько прочитанных сообщений может ждать каждого обработчика,
+	// когда очередь заполнена, чтение из Kafka приостанавливается
+	QueueSize int `yaml:"queue_size"`
+}
+
 // Consumer реализует EventConsumer.
+// Сообщения распределяются между Workers обработчиками по ключу (пользователю), так что
+// события одного пользователя обрабатываются по порядку, а разных - параллельно.
+// Смещение фиксируется после обработки сообщения или его отправки в DLQ, поэтому
+// при падении сервиса необработанные сообщения будут прочитаны снова.
 type Consumer struct {
 	Reader ReaderInterface
+	// DLQ - writer топика ConsumerConfig.DLQTopic, nil если DLQ не настроен
+	DLQ    WriterInterface
 	Logger *zap.SugaredLogger
+	Config ConsumerConfig
 }
 
-func NewConsumer(brokers, topic, groupID string, logger *zap.SugaredLogger) EventConsumer {
-	return &Consumer{
+func NewConsumer(brokers, topic, groupID string, cfg ConsumerConfig, logger *zap.SugaredLogger) EventConsumer {
+	c := &Consumer{
 		Reader: &kafkaReaderWrapper{
 			Reader: kgo.NewReader(kgo.ReaderConfig{
 				Brokers:  []string{brokers},
@@ -27,15 +65,32 @@ func NewConsumer(brokers, topic, groupID string, logger *zap.SugaredLogger) Even
 			}),
 		},
 		Logger: logger,
+		Config: cfg,
 	}
+
+	if cfg.DLQTopic != "" {
+		c.DLQ = &kafkaWriterWrapper{
+			Writer: &kgo.Writer{
+				Addr:     kgo.TCP(brokers),
+				Topic:    cfg.DLQTopic,
+				Balancer: &kgo.Hash{},
+			},
+		}
+	}
+
+	return c
 }
 
 type kafkaReaderWrapper struct {
 	Reader *kgo.Reader
 }
 
-func (w *kafkaReaderWrapper) ReadMessage(ctx context.Context) (kgo.Message, error) {
-	return w.Reader.ReadMessage(ctx)
+func (w *kafkaReaderWrapper) FetchMessage(ctx context.Context) (kgo.Message, error) {
+	return w.Reader.FetchMessage(ctx)
+}
+
+func (w *kafkaReaderWrapper) CommitMessages(ctx context.Context, msgs ...kgo.Message) error {
+	return w.Reader.CommitMessages(ctx, msgs...)
 }
 
 func (w *kafkaReaderWrapper) Close() error {
@@ -43,28 +98,252 @@ func (w *kafkaReaderWrapper) Close() error {
 }
 
 func (c *Consumer) Consume(ctx context.Context, handler func(context.Context, Event) error) {
+	workers := c.Config.Workers
+	if workers <= 0 {
+		workers = defaultWorkers
+	}
+	queueSize := c.Config.QueueSize
+	if queueSize <= 0 {
+		queueSize = defaultQueueSize
+	}
+
+	tracker := newOffsetTracker()
+	done := make(chan kgo.Message, workers*queueSize)
+	committed := make(chan struct{})
+	go func() {
+		defer close(committed)
+		c.commitLoop(ctx, done, tracker)
+	}()
+
+	var wg sync.WaitGroup
+	queues := make([]chan kgo.Message, workers)
+	for i := range queues {
+		queues[i] = make(chan kgo.Message, queueSize)
+		wg.Add(1)
+		go func(queue <-chan kgo.Message) {
+			defer wg.Done()
+			c.work(ctx, queue, done, handler)
+		}(queues[i])
+	}
+
+	c.fetchLoop(ctx, queues, tracker)
+
+	// обработанное до остановки успевает зафиксироваться
+	for _, queue := range queues {
+		close(queue)
+	}
+	wg.Wait()
+	close(done)
+	<-committed
+}
+
+// fetchLoop читает сообщения и раздает их обработчикам, пока чтение не прервется.
+// Заполненная очередь обработчика останавливает чтение
+func (c *Consumer) fetchLoop(ctx context.Context, queues []chan kgo.Message, tracker *offsetTracker) {
+	backoff := c.retryBackoff()
 	for {
-		msg, err := c.Reader.ReadMessage(ctx)
+		msg, err := c.Reader.FetchMessage(ctx)
 		if err != nil {
-			if errors.Is(err, context.Canceled) {
+			// io.EOF - reader закрыт
+			if errors.Is(err, context.Canceled) || errors.Is(err, io.EOF) {
 				return
 			}
-			c.Logger.Errorf("Failed to read message: %v", err)
+			// пока брокер недоступен, чтение повторяется с растущей паузой, а не в холостом цикле
+			c.Logger.Errorf("Failed to read message, retrying in %s: %v", backoff, err)
+			if sleep(ctx, backoff) != nil {
+				return
+			}
+			backoff = c.nextBackoff(backoff)
+			continue
+		}
+		backoff = c.retryBackoff()
+
+		tracker.track(msg)
+		select {
+		case queues[workerIndex(msg, len(queues))] <- msg:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// work обрабатывает сообщения своей очереди по порядку и передает обработанные на фиксацию
+func (c *Consumer) work(ctx context.Context, queue <-chan kgo.Message, done chan<- kgo.Message, handler func(context.Context, Event) error) {
+	for msg := range queue {
+		// после отмены сообщения не обрабатываются и не фиксируются: их прочитают после перезапуска
+		if ctx.Err() != nil {
+			continue
+		}
+		if err := c.process(ctx, msg, handler); err != nil {
 			continue
 		}
+		done <- msg
+	}
+}
+
+// commitLoop фиксирует смещения обработанных сообщений. Фиксирует одна горутина,
+// поэтому смещение партиции не откатывается назад, а накопившиеся сообщения фиксируются разом
+func (c *Consumer) commitLoop(ctx context.Context, done <-chan kgo.Message, tracker *offsetTracker) {
+	// фиксация обработанного нужна и после отмены ctx
+	commitCtx := context.WithoutCancel(ctx)
+
+	for msg := range done {
+		batch := make(map[topicPartition]kgo.Message)
+		add := func(msg kgo.Message) {
+			if commit, ok := tracker.complete(msg); ok {
+				batch[topicPartition{topic: commit.Topic, partition: commit.Partition}] = commit
+			}
+		}
 
-		var event Event
-		if err := json.Unmarshal(msg.Value, &event); err != nil {
-			c.Logger.Errorf("Failed to unmarshal event: %v", err)
+		add(msg)
+	drain:
+		for {
+			select {
+			case msg, ok := <-done:
+				if !ok {
+					break drain
+				}
+				add(msg)
+			default:
+				break drain
+			}
+		}
+
+		if len(batch) == 0 {
 			continue
 		}
+		msgs := make([]kgo.Message, 0, len(batch))
+		for _, commit := range batch {
+			msgs = append(msgs, commit)
+		}
+		c.commit(commitCtx, msgs)
+	}
+}
+
+func (c *Consumer) commit(ctx context.Context, msgs []kgo.Message) {
+	ctx, cancel := context.WithTimeout(ctx, commitTimeout)
+	defer cancel()
+
+	if err := c.Reader.CommitMessages(ctx, msgs...); err != nil {
+		c.Logger.Errorf("Failed to commit offsets of %d partitions: %v", len(msgs), err)
+	}
+}
 
-		if err := handler(ctx, event); err != nil {
-			c.Logger.Errorf("Failed to process event: %v", err)
+// workerIndex выбирает обработчика по ключу сообщения, сообщения без ключа -
+// по партиции, чтобы сохранить хотя бы порядок внутри нее
+func workerIndex(msg kgo.Message, workers int) int {
+	key := msg.Key
+	if len(key) == 0 {
+		key = []byte(strconv.Itoa(msg.Partition))
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write(key)
+	return int(h.Sum32() % uint32(workers))
+}
+
+// process обрабатывает сообщение с повторами, а исчерпав их, отправляет в DLQ.
+// Ошибка возвращается только при отмене ctx - сообщение не обработано и не должно фиксироваться
+func (c *Consumer) process(ctx context.Context, msg kgo.Message, handler func(context.Context, Event) error) error {
+	var event Event
+	if err := json.Unmarshal(msg.Value, &event); err != nil {
+		// битое сообщение повтор не исправит
+		c.Logger.Errorf("Failed to unmarshal event at %s/%d/%d: %v", msg.Topic, msg.Partition, msg.Offset, err)
+		return c.deadLetter(ctx, msg, fmt.Errorf("failed to unmarshal event: %w", err), 1)
+	}
+	// у событий старых продюсеров нет id, повторная доставка узнается хотя бы по положению в топике
+	if event.ID == "" {
+		event.ID = fmt.Sprintf("%s/%d/%d", msg.Topic, msg.Partition, msg.Offset)
+	}
+
+	backoff := c.retryBackoff()
+	attempts := 0
+	for {
+		attempts++
+		err := handler(ctx, event)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if attempts > c.Config.Retries {
+			c.Logger.Errorf("Failed to process event at %s/%d/%d after %d attempts: %v",
+				msg.Topic, msg.Partition, msg.Offset, attempts, err)
+			return c.deadLetter(ctx, msg, err, attempts)
+		}
+
+		c.Logger.Warnf("Failed to process event at %s/%d/%d, attempt %d of %d: %v",
+			msg.Topic, msg.Partition, msg.Offset, attempts, c.Config.Retries+1, err)
+		if err := sleep(ctx, backoff); err != nil {
+			return err
 		}
+		backoff = c.nextBackoff(backoff)
+	}
+}
+
+// deadLetter отправляет сообщение в DLQ. Запись повторяется, пока не удастся:
+// зафиксировать смещение, не сохранив сообщение, значит потерять его
+func (c *Consumer) deadLetter(ctx context.Context, msg kgo.Message, cause error, attempts int) error {
+	if c.DLQ == nil {
+		c.Logger.Errorf("DLQ is not configured, dropping message at %s/%d/%d", msg.Topic, msg.Partition, msg.Offset)
+		return nil
+	}
+
+	dead := deadLetterMessage(msg, cause, attempts, time.Now())
+	backoff := c.retryBackoff()
+	for {
+		err := c.DLQ.WriteMessages(ctx, dead)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		c.Logger.Errorf("Failed to write message at %s/%d/%d to DLQ: %v", msg.Topic, msg.Partition, msg.Offset, err)
+		if err := sleep(ctx, backoff); err != nil {
+			return err
+		}
+		backoff = c.nextBackoff(backoff)
+	}
+}
+
+func (c *Consumer) retryBackoff() time.Duration {
+	if c.Config.RetryBackoff <= 0 {
+		return defaultRetryBackoff
+	}
+	return c.Config.RetryBackoff
+}
+
+// nextBackoff удваивает паузу, не превышая MaxBackoff
+func (c *Consumer) nextBackoff(backoff time.Duration) time.Duration {
+	maxBackoff := c.Config.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = defaultMaxBackoff
+	}
+	return min(backoff*2, maxBackoff)
+}
+
+// sleep ждет d или отмены ctx
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
 	}
 }
 
 func (c *Consumer) Close() error {
+	if c.DLQ != nil {
+		if err := c.DLQ.Close(); err != nil {
+			c.Logger.Errorf("Failed to close DLQ writer: %v", err)
+		}
+	}
 	return c.Reader.Close()
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"sync"
 	"testing"
 	"time"
 
@@ -18,11 +19,13 @@ type fakeReader struct {
 	// Количество ошибок может быть меньше, чем количество циклов чтения; тогда после исчерпания всех
 	// сообщений и всех ошибок вернётся context.Canceled.
 	errors []error
-	// idx указывает, сколько раз уже вызывался ReadMessage.
+	// idx указывает, сколько раз уже вызывался FetchMessage.
 	idx int
+	// committed — сообщения, переданные в CommitMessages.
+	committed []kafka.Message
 }
 
-func (f *fakeReader) ReadMessage(ctx context.Context) (kafka.Message, error) {
+func (f *fakeReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
 	// Если ещё есть необработанные сообщения — возвращаем текущее
 	if f.idx < len(f.messages) {
 		msg := f.messages[f.idx]
@@ -40,6 +43,11 @@ func (f *fakeReader) ReadMessage(ctx context.Context) (kafka.Message, error) {
 	return kafka.Message{}, context.Canceled
 }
 
+func (f *fakeReader) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	f.committed = append(f.committed, msgs...)
+	return nil
+}
+
 func (f *fakeReader) Close() error {
 	return nil
 }
@@ -122,6 +130,10 @@ func TestConsumer_Consume_InvalidJSON(t *testing.T) {
 	if called {
 		t.Error("ожидали, что handler НЕ будет вызван при некорректном JSON")
 	}
+	// Без DLQ сообщение всё равно фиксируется, иначе чтение застрянет на нём
+	if len(fr.committed) != 1 {
+		t.Errorf("ожидали одну фиксацию смещения, получили %d", len(fr.committed))
+	}
 }
 
 func TestConsumer_Consume_HandlerError(t *testing.T) {
@@ -161,3 +173,286 @@ func TestConsumer_Consume_HandlerError(t *testing.T) {
 		t.Error("ожидали, что handler всё же будет вызван, даже если он вернул ошибку")
 	}
 }
+
+// eventMessage сериализует событие в сообщение топика user-events.
+func eventMessage(t *testing.T, evt Event, offset int64) kafka.Message {
+	t.Helper()
+	payload, err := json.Marshal(evt)
+	if err != nil {
+		t.Fatalf("не удалось сериализовать событие: %v", err)
+	}
+	return kafka.Message{Topic: "user-events", Partition: 2, Offset: offset, Value: payload}
+}
+
+func TestConsumer_Consume_RetrySucceeds(t *testing.T) {
+	msg := eventMessage(t, Event{UserID: "user-retry", Type: EventTypeView}, 5)
+	fr := &fakeReader{messages: []kafka.Message{msg}}
+	fw := &fakeWriter{}
+
+	consumer := &Consumer{
+		Reader: fr,
+		DLQ:    fw,
+		Logger: zapTestLogger(t),
+		Config: ConsumerConfig{Retries: 2, RetryBackoff: time.Millisecond},
+	}
+
+	calls := 0
+	consumer.Consume(context.Background(), func(ctx context.Context, e Event) error {
+		calls++
+		if calls < 3 {
+			return errors.New("temporary failure")
+		}
+		return nil
+	})
+
+	if calls != 3 {
+		t.Errorf("ожидали 3 вызова handler, получили %d", calls)
+	}
+	if len(fw.lastMessages) != 0 {
+		t.Errorf("ожидали пустую DLQ, получили %d сообщений", len(fw.lastMessages))
+	}
+	if len(fr.committed) != 1 || fr.committed[0].Offset != 5 {
+		t.Errorf("ожидали фиксацию смещения 5, получили %+v", fr.committed)
+	}
+}
+
+func TestConsumer_Consume_EventID(t *testing.T) {
+	withID := eventMessage(t, Event{ID: "e-1", UserID: "user-a", Type: EventTypeView}, 7)
+	legacy := eventMessage(t, Event{UserID: "user-b", Type: EventTypeView}, 8)
+	fr := &fakeReader{messages: []kafka.Message{withID, legacy}}
+
+	consumer := &Consumer{Reader: fr, Logger: zapTestLogger(t)}
+
+	var ids []string
+	consumer.Consume(context.Background(), func(ctx context.Context, e Event) error {
+		ids = append(ids, e.ID)
+		return nil
+	})
+
+	// у события без id старого продюсера id - положение сообщения в топике
+	if len(ids) != 2 || ids[0] != "e-1" || ids[1] != "user-events/2/8" {
+		t.Errorf("ожидали id [e-1 user-events/2/8], получили %v", ids)
+	}
+}
+
+func TestConsumer_Consume_RetriesExhausted(t *testing.T) {
+	msg := eventMessage(t, Event{UserID: "user-dead", Type: EventTypeView}, 7)
+	msg.Key = []byte("user-dead")
+	fr := &fakeReader{messages: []kafka.Message{msg}}
+	fw := &fakeWriter{}
+
+	consumer := &Consumer{
+		Reader: fr,
+		DLQ:    fw,
+		Logger: zapTestLogger(t),
+		Config: ConsumerConfig{Retries: 2, RetryBackoff: time.Millisecond},
+	}
+
+	calls := 0
+	consumer.Consume(context.Background(), func(ctx context.Context, e Event) error {
+		calls++
+		return errors.New("db is down")
+	})
+
+	if calls != 3 {
+		t.Errorf("ожидали 3 вызова handler, получили %d", calls)
+	}
+	if len(fw.lastMessages) != 1 {
+		t.Fatalf("ожидали одно сообщение в DLQ, получили %d", len(fw.lastMessages))
+	}
+	dead := fw.lastMessages[0]
+	if string(dead.Value) != string(msg.Value) || string(dead.Key) != "user-dead" {
+		t.Errorf("ожидали исходные ключ и тело в DLQ, получили key=%q value=%q", dead.Key, dead.Value)
+	}
+
+	dl := ParseDeadLetter(dead)
+	if dl.Error != "db is down" || dl.Attempts != 3 {
+		t.Errorf("ожидали ошибку %q и 3 попытки, получили %q и %d", "db is down", dl.Error, dl.Attempts)
+	}
+	if dl.Topic != "user-events" || dl.Partition != 2 || dl.Offset != 7 {
+		t.Errorf("ожидали user-events/2/7, получили %s/%d/%d", dl.Topic, dl.Partition, dl.Offset)
+	}
+	if dl.FailedAt.IsZero() {
+		t.Error("ожидали время попадания в DLQ")
+	}
+	if len(fr.committed) != 1 {
+		t.Errorf("ожидали фиксацию после отправки в DLQ, получили %d", len(fr.committed))
+	}
+}
+
+func TestConsumer_Consume_InvalidJSONToDLQ(t *testing.T) {
+	badMsg := kafka.Message{Topic: "user-events", Value: []byte(`{bad json`)}
+	fr := &fakeReader{messages: []kafka.Message{badMsg}}
+	fw := &fakeWriter{}
+
+	consumer := &Consumer{
+		Reader: fr,
+		DLQ:    fw,
+		Logger: zapTestLogger(t),
+		Config: ConsumerConfig{Retries: 3, RetryBackoff: time.Millisecond},
+	}
+
+	consumer.Consume(context.Background(), func(ctx context.Context, e Event) error {
+		t.Error("ожидали, что handler НЕ будет вызван при некорректном JSON")
+		return nil
+	})
+
+	if len(fw.lastMessages) != 1 {
+		t.Fatalf("ожидали одно сообщение в DLQ, получили %d", len(fw.lastMessages))
+	}
+	// битое сообщение не повторяется
+	if dl := ParseDeadLetter(fw.lastMessages[0]); dl.Attempts != 1 {
+		t.Errorf("ожидали 1 попытку, получили %d", dl.Attempts)
+	}
+}
+
+func TestConsumer_Consume_CanceledDuringRetry(t *testing.T) {
+	msg := eventMessage(t, Event{UserID: "user-cancel", Type: EventTypeView}, 1)
+	fr := &fakeReader{messages: []kafka.Message{msg}}
+	fw := &fakeWriter{}
+
+	consumer := &Consumer{
+		Reader: fr,
+		DLQ:    fw,
+		Logger: zapTestLogger(t),
+		Config: ConsumerConfig{Retries: 5, RetryBackoff: time.Hour},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	consumer.Consume(ctx, func(ctx context.Context, e Event) error {
+		cancel()
+		return errors.New("failure")
+	})
+
+	// необработанное сообщение не фиксируется и не уходит в DLQ: его прочитают после перезапуска
+	if len(fr.committed) != 0 {
+		t.Errorf("ожидали, что смещение не зафиксировано, получили %+v", fr.committed)
+	}
+	if len(fw.lastMessages) != 0 {
+		t.Errorf("ожидали пустую DLQ, получили %d сообщений", len(fw.lastMessages))
+	}
+}
+
+func TestConsumer_Consume_OrderPerUser(t *testing.T) {
+	users := []string{"user-a", "user-b", "user-c"}
+	var messages []kafka.Message
+	for i := 0; i < 30; i++ {
+		userID := users[i%len(users)]
+		msg := eventMessage(t, Event{UserID: userID, Type: EventTypeView, Categories: []int{i}}, int64(i))
+		msg.Key = []byte(userID)
+		messages = append(messages, msg)
+	}
+	fr := &fakeReader{messages: messages}
+
+	consumer := &Consumer{
+		Reader: fr,
+		Logger: zapTestLogger(t),
+		Config: ConsumerConfig{Workers: 4, QueueSize: 2},
+	}
+
+	var mu sync.Mutex
+	seen := make(map[string][]int)
+	consumer.Consume(context.Background(), func(ctx context.Context, e Event) error {
+		mu.Lock()
+		defer mu.Unlock()
+		seen[e.UserID] = append(seen[e.UserID], e.Categories[0])
+		return nil
+	})
+
+	for _, userID := range users {
+		got := seen[userID]
+		if len(got) != 10 {
+			t.Fatalf("ожидали 10 событий %s, получили %d", userID, len(got))
+		}
+		for i := 1; i < len(got); i++ {
+			if got[i] < got[i-1] {
+				t.Errorf("события %s обработаны не по порядку: %v", userID, got)
+				break
+			}
+		}
+	}
+
+	// последнее зафиксированное смещение - последнее прочитанное
+	if len(fr.committed) == 0 || fr.committed[len(fr.committed)-1].Offset != 29 {
+		t.Errorf("ожидали фиксацию смещения 29, получили %+v", fr.committed)
+	}
+}
+
+func TestOffsetTracker_Complete(t *testing.T) {
+	tracker := newOffsetTracker()
+	msg := func(partition int, offset int64) kafka.Message {
+		return kafka.Message{Topic: "user-events", Partition: partition, Offset: offset}
+	}
+	for offset := int64(10); offset < 13; offset++ {
+		tracker.track(msg(0, offset))
+	}
+	tracker.track(msg(1, 3))
+
+	// 11 обработано раньше 10 - фиксировать нельзя, иначе 10 потеряется при падении
+	if _, ok := tracker.complete(msg(0, 11)); ok {
+		t.Error("ожидали, что смещение 11 не фиксируется до обработки 10")
+	}
+	if commit, ok := tracker.complete(msg(0, 10)); !ok || commit.Offset != 11 {
+		t.Errorf("ожидали фиксацию до 11, получили %d, %v", commit.Offset, ok)
+	}
+	if commit, ok := tracker.complete(msg(1, 3)); !ok || commit.Partition != 1 || commit.Offset != 3 {
+		t.Errorf("ожидали фиксацию партиции 1 до 3, получили %+v, %v", commit, ok)
+	}
+	if commit, ok := tracker.complete(msg(0, 12)); !ok || commit.Offset != 12 {
+		t.Errorf("ожидали фиксацию до 12, получили %d, %v", commit.Offset, ok)
+	}
+
+	// после ребалансировки партиция читается заново с зафиксированного смещения
+	tracker.track(msg(0, 11))
+	if commit, ok := tracker.complete(msg(0, 11)); !ok || commit.Offset != 11 {
+		t.Errorf("ожидали фиксацию до 11 после ребалансировки, получили %d, %v", commit.Offset, ok)
+	}
+}
+
+func TestConsumer_Consume_FetchErrorBackoff(t *testing.T) {
+	fetchErr := errors.New("broker unavailable")
+	fr := &fakeReader{errors: []error{fetchErr, fetchErr, fetchErr}}
+
+	consumer := &Consumer{
+		Reader: fr,
+		DLQ:    &fakeWriter{},
+		Logger: zapTestLogger(t),
+		Config: ConsumerConfig{RetryBackoff: 10 * time.Millisecond, MaxBackoff: 20 * time.Millisecond},
+	}
+
+	// паузы 10, 20 и 20 мс между неудачными чтениями
+	start := time.Now()
+	consumer.Consume(context.Background(), func(ctx context.Context, e Event) error { return nil })
+
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("ожидали паузы между неудачными чтениями, цикл занял %s", elapsed)
+	}
+	if fr.idx != 3 {
+		t.Errorf("ожидали 3 неудачных чтения, получили %d", fr.idx)
+	}
+}
+
+func TestConsumer_Consume_CanceledDuringFetchBackoff(t *testing.T) {
+	fetchErr := errors.New("broker unavailable")
+	fr := &fakeReader{errors: []error{fetchErr}}
+
+	consumer := &Consumer{
+		Reader: fr,
+		DLQ:    &fakeWriter{},
+		Logger: zapTestLogger(t),
+		Config: ConsumerConfig{RetryBackoff: time.Hour},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	consumer.Consume(ctx, func(ctx context.Context, e Event) error { return nil })
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("ожидали выход по отмене контекста, цикл занял %s", elapsed)
+	}
+	if fr.idx != 1 {
+		t.Errorf("ожидали одно чтение до отмены, получили %d", fr.idx)
+	}
+}
@@ -0,0 +1,113 @@
+package kafka
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	kgo "github.com/segmentio/kafka-go"
+)
+
+// Заголовки сообщения в DLQ: почему и откуда оно туда попало
+const (
+	dlqHeaderPrefix = "dlq-"
+
+	HeaderDLQError     = "dlq-error"
+	HeaderDLQAttempts  = "dlq-attempts"
+	HeaderDLQTopic     = "dlq-original-topic"
+	HeaderDLQPartition = "dlq-original-partition"
+	HeaderDLQOffset    = "dlq-original-offset"
+	HeaderDLQFailedAt  = "dlq-failed-at"
+	// HeaderReplayCount - сколько раз сообщение уже возвращали из DLQ в исходный топик
+	HeaderReplayCount = "replay-count"
+)
+
+// DeadLetter - метаданные сообщения из DLQ
+type DeadLetter struct {
+	Error     string
+	Attempts  int
+	Topic     string
+	Partition int
+	Offset    int64
+	FailedAt  time.Time
+	Replays   int
+}
+
+// deadLetterMessage копирует сообщение для DLQ: ключ и тело без изменений,
+// в заголовках - ошибка, число попыток и исходное положение в топике
+func deadLetterMessage(msg kgo.Message, cause error, attempts int, failedAt time.Time) kgo.Message {
+	headers := withoutDLQHeaders(msg.Headers)
+	headers = append(headers,
+		kgo.Header{Key: HeaderDLQError, Value: []byte(cause.Error())},
+		kgo.Header{Key: HeaderDLQAttempts, Value: []byte(strconv.Itoa(attempts))},
+		kgo.Header{Key: HeaderDLQTopic, Value: []byte(msg.Topic)},
+		kgo.Header{Key: HeaderDLQPartition, Value: []byte(strconv.Itoa(msg.Partition))},
+		kgo.Header{Key: HeaderDLQOffset, Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+		kgo.Header{Key: HeaderDLQFailedAt, Value: []byte(failedAt.UTC().Format(time.RFC3339Nano))},
+	)
+
+	return kgo.Message{
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: headers,
+	}
+}
+
+// ParseDeadLetter читает метаданные из заголовков сообщения DLQ,
+// отсутствующие или битые заголовки оставляют нулевые значения
+func ParseDeadLetter(msg kgo.Message) DeadLetter {
+	var dl DeadLetter
+	for _, h := range msg.Headers {
+		value := string(h.Value)
+		switch h.Key {
+		case HeaderDLQError:
+			dl.Error = value
+		case HeaderDLQAttempts:
+			dl.Attempts, _ = strconv.Atoi(value)
+		case HeaderDLQTopic:
+			dl.Topic = value
+		case HeaderDLQPartition:
+			dl.Partition, _ = strconv.Atoi(value)
+		case HeaderDLQOffset:
+			dl.Offset, _ = strconv.ParseInt(value, 10, 64)
+		case HeaderDLQFailedAt:
+			dl.FailedAt, _ = time.Parse(time.RFC3339Nano, value)
+		case HeaderReplayCount:
+			dl.Replays, _ = strconv.Atoi(value)
+		}
+	}
+
+	return dl
+}
+
+// ReplayMessage готовит сообщение DLQ к повторной отправке в исходный топик:
+// заголовки DLQ убираются, счетчик повторов увеличивается
+func ReplayMessage(msg kgo.Message) kgo.Message {
+	replays := ParseDeadLetter(msg).Replays + 1
+
+	headers := make([]kgo.Header, 0, len(msg.Headers)+1)
+	for _, h := range withoutDLQHeaders(msg.Headers) {
+		if h.Key != HeaderReplayCount {
+			headers = append(headers, h)
+		}
+	}
+	headers = append(headers, kgo.Header{Key: HeaderReplayCount, Value: []byte(strconv.Itoa(replays))})
+
+	return kgo.Message{
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: headers,
+	}
+}
+
+// withoutDLQHeaders возвращает копию заголовков без заголовков DLQ,
+// чтобы повторно упавшее сообщение не копило метаданные прошлых попаданий
+func withoutDLQHeaders(headers []kgo.Header) []kgo.Header {
+	result := make([]kgo.Header, 0, len(headers))
+	for _, h := range headers {
+		if !strings.HasPrefix(h.Key, dlqHeaderPrefix) {
+			result = append(result, h)
+		}
+	}
+	return result
+}
@@ -0,0 +1,45 @@
+package kafka
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+func TestReplayMessage(t *testing.T) {
+	orig := kafka.Message{
+		Topic:     "user-events",
+		Partition: 1,
+		Offset:    42,
+		Key:       []byte("user1"),
+		Value:     []byte(`{"user_id":"user1"}`),
+		Headers:   []kafka.Header{{Key: "trace-id", Value: []byte("abc")}},
+	}
+	failedAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	dead := deadLetterMessage(orig, errors.New("boom"), 4, failedAt)
+	dl := ParseDeadLetter(dead)
+	want := DeadLetter{Error: "boom", Attempts: 4, Topic: "user-events", Partition: 1, Offset: 42, FailedAt: failedAt}
+	if dl != want {
+		t.Errorf("ожидали %+v, получили %+v", want, dl)
+	}
+
+	replayed := ReplayMessage(dead)
+	if string(replayed.Key) != "user1" || string(replayed.Value) != string(orig.Value) {
+		t.Errorf("ожидали исходные ключ и тело, получили key=%q value=%q", replayed.Key, replayed.Value)
+	}
+	if len(replayed.Headers) != 2 || replayed.Headers[0].Key != "trace-id" {
+		t.Fatalf("ожидали trace-id и replay-count, получили %+v", replayed.Headers)
+	}
+	if got := ParseDeadLetter(replayed); got.Replays != 1 || got.Error != "" {
+		t.Errorf("ожидали 1 повтор без заголовков DLQ, получили %+v", got)
+	}
+
+	// повторно упавшее сообщение снова попадает в DLQ, счетчик повторов сохраняется
+	again := ParseDeadLetter(ReplayMessage(deadLetterMessage(replayed, errors.New("boom"), 1, failedAt)))
+	if again.Replays != 2 {
+		t.Errorf("ожидали 2 повтора, получили %d", again.Replays)
+	}
+}
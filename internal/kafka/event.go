@@ -8,11 +8,32 @@ const (
 	EventTypeSearch   EventType = "search"
 	EventTypeView     EventType = "view"
 	EventTypePurchase EventType = "purchase"
+	EventTypeFavorite EventType = "favorite"
+	// EventTypeAddToCart и EventTypeRemoveFromCart - изменения корзины, нужны для воронки объявления
+	EventTypeAddToCart      EventType = "add_to_cart"
+	EventTypeRemoveFromCart EventType = "remove_from_cart"
+	// EventTypeSignup - регистрация пользователя, по ней строятся когорты удержания
+	EventTypeSignup EventType = "signup"
+	// EventTypeIdentify - вход или регистрация анонимного посетителя VisitorID как пользователя UserID,
+	// аналитика переносит историю посетителя в аккаунт
+	EventTypeIdentify EventType = "identify"
 )
 
 type Event struct {
+	// ID - уникальный id события, проставляется продюсером. По нему аналитика
+	// не учитывает повторно доставленное или возвращенное из DLQ событие дважды
+	ID         string    `json:"id,omitempty"`
 	UserID     string    `json:"user_id"`
 	Type       EventType `json:"type"`
 	Categories []int     `json:"categories,omitempty"`
-	Timestamp  time.Time `json:"timestamp"`
+	// VisitorID - анонимный id браузера или приложения, проставляется продюсером из контекста
+	VisitorID string `json:"visitor_id,omitempty"`
+	// AnnouncementID и SellerID - объявление события и его продавец, у покупки пустые
+	AnnouncementID string `json:"announcement_id,omitempty"`
+	SellerID       string `json:"seller_id,omitempty"`
+	// AnnouncementIDs - объявления события, у покупки - все купленные вместе
+	AnnouncementIDs []string `json:"announcement_ids,omitempty"`
+	// Experiments - варианты пользователя в A/B экспериментах, проставляются продюсером из контекста
+	Experiments map[string]string `json:"experiments,omitempty"`
+	Timestamp   time.Time         `json:"timestamp"`
 }
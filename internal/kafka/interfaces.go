@@ -6,8 +6,10 @@ import (
 )
 
 // ReaderInterface — интерфейс для Kafka Reader.
+// Смещение сообщения фиксируется только явным CommitMessages после обработки.
 type ReaderInterface interface {
-	ReadMessage(ctx context.Context) (kafka.Message, error)
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
 	Close() error
 }
 
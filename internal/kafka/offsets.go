@@ -0,0 +1,70 @@
+package kafka
+
+import (
+	"sync"
+
+	kgo "github.com/segmentio/kafka-go"
+)
+
+type topicPartition struct {
+	topic     string
+	partition int
+}
+
+// offsetTracker помнит прочитанные, но еще не зафиксированные смещения каждой партиции.
+// Обработчики завершают сообщения в любом порядке, а зафиксировать можно только смещение,
+// все сообщения партиции до которого уже обработаны
+type offsetTracker struct {
+	mu         sync.Mutex
+	partitions map[topicPartition]*pendingOffsets
+}
+
+type pendingOffsets struct {
+	// offsets - прочитанные необработанные смещения по возрастанию
+	offsets []int64
+	done    map[int64]struct{}
+}
+
+func newOffsetTracker() *offsetTracker {
+	return &offsetTracker{partitions: make(map[topicPartition]*pendingOffsets)}
+}
+
+// track запоминает прочитанное сообщение
+func (t *offsetTracker) track(msg kgo.Message) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := topicPartition{topic: msg.Topic, partition: msg.Partition}
+	p, ok := t.partitions[key]
+	// смещение не растет после ребалансировки: партиция читается заново с зафиксированного
+	if !ok || (len(p.offsets) > 0 && msg.Offset <= p.offsets[len(p.offsets)-1]) {
+		p = &pendingOffsets{done: make(map[int64]struct{})}
+		t.partitions[key] = p
+	}
+	p.offsets = append(p.offsets, msg.Offset)
+}
+
+// complete отмечает сообщение обработанным и возвращает сообщение партиции,
+// до которого включительно можно фиксировать смещение. false - фиксировать пока нечего
+func (t *offsetTracker) complete(msg kgo.Message) (kgo.Message, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	p, ok := t.partitions[topicPartition{topic: msg.Topic, partition: msg.Partition}]
+	if !ok || len(p.offsets) == 0 || msg.Offset < p.offsets[0] {
+		return kgo.Message{}, false
+	}
+	p.done[msg.Offset] = struct{}{}
+
+	commit := kgo.Message{Topic: msg.Topic, Partition: msg.Partition, Offset: -1}
+	for len(p.offsets) > 0 {
+		if _, ok := p.done[p.offsets[0]]; !ok {
+			break
+		}
+		commit.Offset = p.offsets[0]
+		delete(p.done, p.offsets[0])
+		p.offsets = p.offsets[1:]
+	}
+
+	return commit, commit.Offset >= 0
+}
@@ -5,6 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"gafroshka-main/internal/experiment"
+	"gafroshka-main/internal/visitor"
+
+	"github.com/google/uuid"
 	"github.com/segmentio/kafka-go"
 	"go.uber.org/zap"
 )
@@ -21,7 +25,7 @@ func NewProducer(brokers []string, topic string, logger *zap.SugaredLogger) Even
 			Writer: &kafka.Writer{
 				Addr:     kafka.TCP(brokers...),
 				Topic:    topic,
-				Balancer: &kafka.LeastBytes{},
+				Balancer: &kafka.Hash{}, // партиция по ключу: события пользователя попадают в одну партицию
 			},
 		},
 		Logger: logger,
@@ -42,12 +46,25 @@ func (w *kafkaWriterWrapper) Close() error {
 }
 
 func (p *Producer) SendEvent(ctx context.Context, event Event) error {
+	if event.ID == "" {
+		event.ID = uuid.New().String()
+	}
+	// варианты экспериментов нужны аналитике для сравнения конверсий
+	if event.Experiments == nil {
+		event.Experiments = experiment.FromContext(ctx)
+	}
+	// по посетителю аналитика связывает анонимные события с аккаунтом после входа
+	if event.VisitorID == "" {
+		event.VisitorID, _ = visitor.FromContext(ctx)
+	}
+
 	value, err := json.Marshal(event)
 	if err != nil {
 		return fmt.Errorf("failed to marshal event: %w", err)
 	}
 
 	err = p.Writer.WriteMessages(ctx, kafka.Message{
+		Key:   eventKey(event),
 		Value: value,
 	})
 
@@ -59,6 +76,22 @@ func (p *Producer) SendEvent(ctx context.Context, event Event) error {
 	return nil
 }
 
+// eventKey - ключ сообщения: пользователь, а у анонимного события - посетитель.
+// Consumer обрабатывает сообщения с одним ключом по порядку. identify идет с ключом посетителя:
+// перенос его истории в аккаунт должен случиться после всех его анонимных событий
+func eventKey(event Event) []byte {
+	if event.Type == EventTypeIdentify && event.VisitorID != "" {
+		return []byte(event.VisitorID)
+	}
+	if event.UserID != "" {
+		return []byte(event.UserID)
+	}
+	if event.VisitorID != "" {
+		return []byte(event.VisitorID)
+	}
+	return nil
+}
+
 func (p *Producer) Close() error {
 	return p.Writer.Close()
 }
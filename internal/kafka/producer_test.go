@@ -7,6 +7,9 @@ import (
 	"testing"
 	"time"
 
+	"gafroshka-main/internal/experiment"
+	"gafroshka-main/internal/visitor"
+
 	"github.com/segmentio/kafka-go"
 	"go.uber.org/zap"
 )
@@ -74,6 +77,10 @@ func TestProducer_SendEvent_Success(t *testing.T) {
 	if decoded.UserID != evt.UserID {
 		t.Errorf("разобранный UserID не совпал: ожидали %q, получили %q", evt.UserID, decoded.UserID)
 	}
+	// ключ по пользователю сохраняет порядок его событий в одной партиции
+	if string(fw.lastMessages[0].Key) != evt.UserID {
+		t.Errorf("ожидали ключ %q, получили %q", evt.UserID, fw.lastMessages[0].Key)
+	}
 	if decoded.Type != evt.Type {
 		t.Errorf("разобранный EventType не совпал: ожидали %q, получили %q", evt.Type, decoded.Type)
 	}
@@ -107,3 +114,105 @@ func TestProducer_SendEvent_WriteError(t *testing.T) {
 		t.Fatalf("ожидали ошибку от SendEvent, но получили nil")
 	}
 }
+
+func TestProducer_SendEvent_StampsExperiments(t *testing.T) {
+	logger := zapTestLogger(t)
+	defer func() { _ = logger.Sync() }()
+
+	fw := &fakeWriter{}
+	p := &Producer{Writer: fw, Logger: logger}
+
+	ctx := experiment.WithAssignments(context.Background(), map[string]string{"layout": "compact"})
+	if err := p.SendEvent(ctx, Event{UserID: "user1", Type: EventTypeView}); err != nil {
+		t.Fatalf("ожидали, что SendEvent не вернёт ошибку, но получили: %v", err)
+	}
+
+	var decoded Event
+	if err := json.Unmarshal(fw.lastMessages[0].Value, &decoded); err != nil {
+		t.Fatalf("не удалось разобрать записанное сообщение как JSON: %v", err)
+	}
+	if decoded.Experiments["layout"] != "compact" {
+		t.Errorf("ожидали вариант compact в событии, получили %v", decoded.Experiments)
+	}
+}
+
+func TestProducer_SendEvent_StampsVisitor(t *testing.T) {
+	logger := zapTestLogger(t)
+	defer func() { _ = logger.Sync() }()
+
+	fw := &fakeWriter{}
+	p := &Producer{Writer: fw, Logger: logger}
+
+	ctx := visitor.WithID(context.Background(), "visitor1")
+	if err := p.SendEvent(ctx, Event{Type: EventTypeView}); err != nil {
+		t.Fatalf("ожидали, что SendEvent не вернёт ошибку, но получили: %v", err)
+	}
+
+	var decoded Event
+	if err := json.Unmarshal(fw.lastMessages[0].Value, &decoded); err != nil {
+		t.Fatalf("не удалось разобрать записанное сообщение как JSON: %v", err)
+	}
+	if decoded.VisitorID != "visitor1" {
+		t.Errorf("ожидали посетителя visitor1 в событии, получили %q", decoded.VisitorID)
+	}
+	// без пользователя ключом сообщения становится посетитель
+	if string(fw.lastMessages[0].Key) != "visitor1" {
+		t.Errorf("ожидали ключ visitor1, получили %q", fw.lastMessages[0].Key)
+	}
+}
+
+func TestProducer_SendEvent_StampsID(t *testing.T) {
+	logger := zapTestLogger(t)
+	defer func() { _ = logger.Sync() }()
+
+	fw := &fakeWriter{}
+	p := &Producer{Writer: fw, Logger: logger}
+
+	for i := 0; i < 2; i++ {
+		if err := p.SendEvent(context.Background(), Event{UserID: "user1", Type: EventTypeView}); err != nil {
+			t.Fatalf("ожидали, что SendEvent не вернёт ошибку, но получили: %v", err)
+		}
+	}
+
+	ids := make(map[string]struct{})
+	for _, msg := range fw.lastMessages {
+		var decoded Event
+		if err := json.Unmarshal(msg.Value, &decoded); err != nil {
+			t.Fatalf("не удалось разобрать записанное сообщение как JSON: %v", err)
+		}
+		if decoded.ID == "" {
+			t.Fatalf("ожидали id события, получили пустой")
+		}
+		ids[decoded.ID] = struct{}{}
+	}
+	// по id аналитика отличает повторную доставку от нового события
+	if len(ids) != 2 {
+		t.Errorf("ожидали разные id у двух событий, получили %v", ids)
+	}
+}
+
+func TestProducer_SendEvent_IdentifyKeyedByVisitor(t *testing.T) {
+	logger := zapTestLogger(t)
+	defer func() { _ = logger.Sync() }()
+
+	fw := &fakeWriter{}
+	p := &Producer{Writer: fw, Logger: logger}
+
+	ctx := visitor.WithID(context.Background(), "visitor1")
+	if err := p.SendEvent(ctx, Event{Type: EventTypeView}); err != nil {
+		t.Fatalf("ожидали, что SendEvent не вернёт ошибку, но получили: %v", err)
+	}
+	if err := p.SendEvent(ctx, Event{UserID: "user1", Type: EventTypeIdentify}); err != nil {
+		t.Fatalf("ожидали, что SendEvent не вернёт ошибку, но получили: %v", err)
+	}
+
+	// identify попадает в партицию посетителя и обрабатывается после его анонимных событий
+	if len(fw.lastMessages) != 2 {
+		t.Fatalf("ожидали 2 сообщения, получили %d", len(fw.lastMessages))
+	}
+	for _, msg := range fw.lastMessages {
+		if string(msg.Key) != "visitor1" {
+			t.Errorf("ожидали ключ visitor1, получили %q", msg.Key)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package lifecycle
+
+import (
+	"context"
+	"time"
+)
+
+// Expiring объявление, у которого скоро закончится срок публикации
+type Expiring struct {
+	ID           string
+	Name         string
+	UserSellerID string
+	ExpiresAt    time.Time
+}
+
+// LifecycleRepo интерфейс для смены состояний объявлений по времени
+//
+//go:generate mockgen -source=lifecycle.go -destination=../mocks/mock_lifecycle_repo.go -package=mocks
+type LifecycleRepo interface {
+	// PublishScheduled активирует отложенные объявления, у которых наступил publish_at
+	PublishScheduled() (int64, error)
+	// ExpireOverdue снимает с публикации объявления с истекшим expires_at
+	ExpireOverdue() (int64, error)
+	// GetInactiveIndexed возвращает id неактивных объявлений, которые еще лежат в поиске
+	GetInactiveIndexed(limit int) ([]string, error)
+	// MarkUnindexed снимает отметку об индексации после удаления документов из поиска
+	MarkUnindexed(ids []string) error
+	// GetExpiringSoon возвращает активные объявления, срок которых истекает в пределах within,
+	// и о которых продавец еще не уведомлен
+	GetExpiringSoon(within time.Duration, limit int) ([]Expiring, error)
+	// MarkExpiryNotified отмечает, что продавец уведомлен о скором окончании срока
+	MarkExpiryNotified(ids []string) error
+}
+
+// SearchIndex - то, что планировщику нужно от поискового индекса
+type SearchIndex interface {
+	BulkDelete(ctx context.Context, ids []string) error
+}
@@ -0,0 +1,50 @@
+package lifecycle
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestGetExpiringSoon(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка при создании mock db: %s", err)
+	}
+	defer db.Close()
+	repo := NewLifecycleDBRepository(db, zaptest.NewLogger(t).Sugar())
+
+	expiresAt := time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, name, user_seller_id, expires_at FROM announcement WHERE is_active = TRUE")).
+		WithArgs(int64(259200), 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "user_seller_id", "expires_at"}).
+			AddRow("a1", "Велосипед", "u1", expiresAt))
+
+	got, err := repo.GetExpiringSoon(72*time.Hour, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []Expiring{{ID: "a1", Name: "Велосипед", UserSellerID: "u1", ExpiresAt: expiresAt}}, got)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMarkUnindexed(t *testing.T) {
+	t.Parallel()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка при создании mock db: %s", err)
+	}
+	defer db.Close()
+	repo := NewLifecycleDBRepository(db, zaptest.NewLogger(t).Sugar())
+
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE announcement SET searching = FALSE WHERE id = ANY($1) AND is_active = FALSE")).
+		WithArgs(pq.Array([]string{"a1"})).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, repo.MarkUnindexed([]string{"a1"}))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
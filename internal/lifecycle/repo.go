@@ -0,0 +1,153 @@
+package lifecycle
+
+import (
+	"database/sql"
+	"time"
+
+	myErr "gafroshka-main/internal/types/errors"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+type LifecycleDBRepository struct {
+	DB     *sql.DB
+	Logger *zap.SugaredLogger
+}
+
+func NewLifecycleDBRepository(db *sql.DB, l *zap.SugaredLogger) *LifecycleDBRepository {
+	return &LifecycleDBRepository{
+		DB:     db,
+		Logger: l,
+	}
+}
+
+// PublishScheduled активирует отложенные объявления, у которых наступил publish_at
+// Черновики не трогаются, их публикует только продавец
+// Объявление, срок которого истек еще до публикации, публикуется неактивным и в поиск не попадает
+// searching = FALSE, чтобы ETL проиндексировал их заново
+func (lr *LifecycleDBRepository) PublishScheduled() (int64, error) {
+	query := `
+	UPDATE announcement
+	SET published = TRUE, is_active = quantity > 0 AND expires_at > NOW(), searching = FALSE, updated_at = NOW()
+	WHERE published = FALSE AND is_draft = FALSE AND publish_at <= NOW()
+	`
+	res, err := lr.DB.Exec(query)
+	if err != nil {
+		lr.Logger.Errorf("Error publishing scheduled announcements: %v", err)
+		return 0, myErr.ErrDBInternal
+	}
+
+	return res.RowsAffected()
+}
+
+// ExpireOverdue снимает с публикации объявления с истекшим expires_at
+// Отметка searching остается, по ней объявление затем удаляется из поиска
+func (lr *LifecycleDBRepository) ExpireOverdue() (int64, error) {
+	query := `
+	UPDATE announcement
+	SET is_active = FALSE
+	WHERE is_active = TRUE AND expires_at <= NOW()
+	`
+	res, err := lr.DB.Exec(query)
+	if err != nil {
+		lr.Logger.Errorf("Error expiring announcements: %v", err)
+		return 0, myErr.ErrDBInternal
+	}
+
+	return res.RowsAffected()
+}
+
+// GetInactiveIndexed возвращает id неактивных объявлений, которые еще лежат в поиске
+// Сюда попадают и истекшие, и распроданные объявления
+func (lr *LifecycleDBRepository) GetInactiveIndexed(limit int) ([]string, error) {
+	query := `
+	SELECT id
+	FROM announcement
+	WHERE is_active = FALSE AND searching = TRUE
+	LIMIT $1
+	`
+	rows, err := lr.DB.Query(query, limit)
+	if err != nil {
+		lr.Logger.Errorf("Error getting inactive indexed announcements: %v", err)
+		return nil, myErr.ErrDBInternal
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			lr.Logger.Errorf("Error scanning announcement id: %v", err)
+			return nil, myErr.ErrDBInternal
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		lr.Logger.Errorf("Rows iteration error: %v", err)
+		return nil, myErr.ErrDBInternal
+	}
+
+	return ids, nil
+}
+
+// MarkUnindexed снимает отметку об индексации после удаления документов из поиска
+func (lr *LifecycleDBRepository) MarkUnindexed(ids []string) error {
+	query := `UPDATE announcement SET searching = FALSE WHERE id = ANY($1) AND is_active = FALSE`
+
+	if _, err := lr.DB.Exec(query, pq.Array(ids)); err != nil {
+		lr.Logger.Errorf("Error resetting search flag: %v", err)
+		return myErr.ErrDBInternal
+	}
+
+	return nil
+}
+
+// GetExpiringSoon возвращает активные объявления, срок которых истекает в пределах within
+func (lr *LifecycleDBRepository) GetExpiringSoon(within time.Duration, limit int) ([]Expiring, error) {
+	query := `
+	SELECT id, name, user_seller_id, expires_at
+	FROM announcement
+	WHERE is_active = TRUE
+		AND expiry_notified = FALSE
+		AND expires_at <= NOW() + $1 * INTERVAL '1 second'
+	ORDER BY expires_at
+	LIMIT $2
+	`
+	rows, err := lr.DB.Query(query, int64(within.Seconds()), limit)
+	if err != nil {
+		lr.Logger.Errorf("Error getting expiring announcements: %v", err)
+		return nil, myErr.ErrDBInternal
+	}
+	defer rows.Close()
+
+	var result []Expiring
+	for rows.Next() {
+		var e Expiring
+		if err := rows.Scan(&e.ID, &e.Name, &e.UserSellerID, &e.ExpiresAt); err != nil {
+			lr.Logger.Errorf("Error scanning expiring announcement: %v", err)
+			return nil, myErr.ErrDBInternal
+		}
+		result = append(result, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		lr.Logger.Errorf("Rows iteration error: %v", err)
+		return nil, myErr.ErrDBInternal
+	}
+
+	return result, nil
+}
+
+// MarkExpiryNotified отмечает, что продавец уведомлен о скором окончании срока
+func (lr *LifecycleDBRepository) MarkExpiryNotified(ids []string) error {
+	query := `UPDATE announcement SET expiry_notified = TRUE WHERE id = ANY($1)`
+
+	if _, err := lr.DB.Exec(query, pq.Array(ids)); err != nil {
+		lr.Logger.Errorf("Error marking expiry notified: %v", err)
+		return myErr.ErrDBInternal
+	}
+
+	return nil
+}
@@ -0,0 +1,142 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gafroshka-main/internal/notification"
+
+	"go.uber.org/zap"
+)
+
+// batchSize - сколько объявлений обрабатывается за одну итерацию каждого шага
+const batchSize = 500
+
+type Scheduler struct {
+	repo          LifecycleRepo
+	index         SearchIndex
+	notifications notification.NotificationRepo
+	logger        *zap.SugaredLogger
+	interval      time.Duration
+	// expiryNotice - за сколько до окончания срока уведомлять продавца, 0 - не уведомлять
+	expiryNotice time.Duration
+}
+
+func NewScheduler(
+	repo LifecycleRepo,
+	index SearchIndex,
+	notifications notification.NotificationRepo,
+	logger *zap.SugaredLogger,
+	interval time.Duration,
+	expiryNotice time.Duration,
+) *Scheduler {
+	return &Scheduler{
+		repo:          repo,
+		index:         index,
+		notifications: notifications,
+		logger:        logger,
+		interval:      interval,
+		expiryNotice:  expiryNotice,
+	}
+}
+
+// Run - публикует отложенные объявления, снимает истекшие и убирает их из поиска,
+// запускается через определенные промежутки времени
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	s.logger.Infow("Lifecycle scheduler started")
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce - одна итерация планировщика. Шаги независимы: ошибка одного не мешает остальным
+func (s *Scheduler) RunOnce(ctx context.Context) {
+	published, err := s.repo.PublishScheduled()
+	if err != nil {
+		s.logger.Errorw("Publishing scheduled announcements failed", zap.Error(err))
+	} else if published > 0 {
+		s.logger.Infof("Published %d scheduled announcements", published)
+	}
+
+	expired, err := s.repo.ExpireOverdue()
+	if err != nil {
+		s.logger.Errorw("Expiring announcements failed", zap.Error(err))
+	} else if expired > 0 {
+		s.logger.Infof("Expired %d announcements", expired)
+	}
+
+	s.removeFromIndex(ctx)
+
+	if s.expiryNotice > 0 {
+		s.notifyExpiring()
+	}
+}
+
+// removeFromIndex удаляет неактивные объявления из ES
+// Отметка searching снимается только после успешного удаления, так что неудача повторится на следующей итерации
+func (s *Scheduler) removeFromIndex(ctx context.Context) {
+	ids, err := s.repo.GetInactiveIndexed(batchSize)
+	if err != nil {
+		s.logger.Errorw("Getting inactive indexed announcements failed", zap.Error(err))
+		return
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	if err = s.index.BulkDelete(ctx, ids); err != nil {
+		s.logger.Errorw("Removing inactive announcements from ES failed", zap.Error(err))
+		return
+	}
+
+	if err = s.repo.MarkUnindexed(ids); err != nil {
+		s.logger.Errorw("Resetting search flag failed", zap.Error(err))
+		return
+	}
+
+	s.logger.Infof("Removed %d inactive announcements from search", len(ids))
+}
+
+// notifyExpiring уведомляет продавцов об объявлениях, срок которых скоро закончится
+func (s *Scheduler) notifyExpiring() {
+	expiring, err := s.repo.GetExpiringSoon(s.expiryNotice, batchSize)
+	if err != nil {
+		s.logger.Errorw("Getting expiring announcements failed", zap.Error(err))
+		return
+	}
+
+	notified := make([]string, 0, len(expiring))
+	for _, e := range expiring {
+		_, err := s.notifications.Create(notification.Notification{
+			UserID:         e.UserSellerID,
+			Type:           notification.TypeExpirySoon,
+			AnnouncementID: e.ID,
+			Message: fmt.Sprintf(
+				"Срок публикации объявления «%s» закончится %s. Продлите его, чтобы оно осталось в поиске",
+				e.Name, e.ExpiresAt.Format("02.01.2006 15:04"),
+			),
+		})
+		if err != nil {
+			s.logger.Warnw("Failed to notify seller about expiry", "announcement", e.ID, zap.Error(err))
+			continue
+		}
+		notified = append(notified, e.ID)
+	}
+
+	if len(notified) == 0 {
+		return
+	}
+	if err = s.repo.MarkExpiryNotified(notified); err != nil {
+		s.logger.Errorw("Marking expiry notified failed", zap.Error(err))
+	}
+}
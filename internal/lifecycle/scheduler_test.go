@@ -0,0 +1,75 @@
+package lifecycle_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gafroshka-main/internal/lifecycle"
+	"gafroshka-main/internal/mocks"
+	"gafroshka-main/internal/notification"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+)
+
+type schedulerDeps struct {
+	repo          *mocks.MockLifecycleRepo
+	index         *mocks.MockSearchIndex
+	notifications *mocks.MockNotificationRepo
+}
+
+func setupScheduler(t *testing.T, expiryNotice time.Duration) (*lifecycle.Scheduler, schedulerDeps) {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	deps := schedulerDeps{
+		repo:          mocks.NewMockLifecycleRepo(ctrl),
+		index:         mocks.NewMockSearchIndex(ctrl),
+		notifications: mocks.NewMockNotificationRepo(ctrl),
+	}
+	s := lifecycle.NewScheduler(deps.repo, deps.index, deps.notifications, zaptest.NewLogger(t).Sugar(), time.Minute, expiryNotice)
+	return s, deps
+}
+
+func TestRunOnce_RemovesInactiveAndNotifies(t *testing.T) {
+	t.Parallel()
+	s, deps := setupScheduler(t, 72*time.Hour)
+	ctx := context.Background()
+
+	deps.repo.EXPECT().PublishScheduled().Return(int64(1), nil)
+	deps.repo.EXPECT().ExpireOverdue().Return(int64(2), nil)
+	deps.repo.EXPECT().GetInactiveIndexed(gomock.Any()).Return([]string{"a1", "a2"}, nil)
+	deps.index.EXPECT().BulkDelete(ctx, []string{"a1", "a2"}).Return(nil)
+	deps.repo.EXPECT().MarkUnindexed([]string{"a1", "a2"}).Return(nil)
+	deps.repo.EXPECT().GetExpiringSoon(72*time.Hour, gomock.Any()).Return([]lifecycle.Expiring{
+		{ID: "a3", Name: "Велосипед", UserSellerID: "u1", ExpiresAt: time.Now().Add(time.Hour)},
+		{ID: "a4", Name: "Диван", UserSellerID: "u2", ExpiresAt: time.Now().Add(time.Hour)},
+	}, nil)
+	deps.notifications.EXPECT().Create(gomock.Any()).DoAndReturn(func(n notification.Notification) (*notification.Notification, error) {
+		assert.Equal(t, notification.TypeExpirySoon, n.Type)
+		assert.Equal(t, "a3", n.AnnouncementID)
+		assert.Equal(t, "u1", n.UserID)
+		return &n, nil
+	})
+	deps.notifications.EXPECT().Create(gomock.Any()).Return(nil, errors.New("db error"))
+	// уведомление, которое не удалось сохранить, будет отправлено на следующей итерации
+	deps.repo.EXPECT().MarkExpiryNotified([]string{"a3"}).Return(nil)
+
+	s.RunOnce(ctx)
+}
+
+func TestRunOnce_IndexFailureKeepsFlag(t *testing.T) {
+	t.Parallel()
+	s, deps := setupScheduler(t, 0)
+	ctx := context.Background()
+
+	deps.repo.EXPECT().PublishScheduled().Return(int64(0), nil)
+	deps.repo.EXPECT().ExpireOverdue().Return(int64(0), errors.New("db error"))
+	deps.repo.EXPECT().GetInactiveIndexed(gomock.Any()).Return([]string{"a1"}, nil)
+	deps.index.EXPECT().BulkDelete(ctx, []string{"a1"}).Return(errors.New("es down"))
+	// MarkUnindexed и уведомления не вызываются
+
+	s.RunOnce(ctx)
+}
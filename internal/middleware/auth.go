@@ -10,7 +10,7 @@ type SessKey string
 
 var sessKey SessKey = "sessionKey"
 
-func Auth(sm *session.SessionRepository) func(http.Handler) http.Handler {
+func Auth(sm session.SessionRepo) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Проверка сессии пользователя
@@ -27,6 +27,19 @@ func Auth(sm *session.SessionRepository) func(http.Handler) http.Handler {
 	}
 }
 
+// OptionalAuth кладет сессию в контекст, если запрос пришел с действующим токеном.
+// Без токена или с недействительным токеном запрос обрабатывается как анонимный
+func OptionalAuth(sm session.SessionRepo) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if sess, err := sm.CheckSession(r); err == nil && sess != nil {
+				r = r.WithContext(ContextWithSession(r.Context(), sess))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 func ContextWithSession(ctx context.Context, s *session.Session) context.Context {
 	// создаем новый контекст с нашим ключом и сессией
 	return context.WithValue(ctx, sessKey, s)
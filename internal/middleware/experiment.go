@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"net/http"
+
+	"gafroshka-main/internal/experiment"
+	"gafroshka-main/internal/visitor"
+
+	"github.com/gorilla/mux"
+)
+
+// Experiments распределяет пользователя по вариантам экспериментов и кладет их в контекст.
+// Пользователь берется из сессии, а на ручках без авторизации - из параметра пути user_id.
+// Анонимный посетитель распределяется по своему id, поэтому middleware ставится после Visitor
+func Experiments(a *experiment.Assigner) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID := mux.Vars(r)["user_id"]
+			if sess, ok := GetSessionFromContext(r.Context()); ok && sess != nil {
+				userID = sess.UserID
+			}
+			if userID == "" {
+				userID, _ = visitor.FromContext(r.Context())
+			}
+
+			if assignments := a.Assign(userID); len(assignments) > 0 {
+				r = r.WithContext(experiment.WithAssignments(r.Context(), assignments))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
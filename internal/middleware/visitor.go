@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"gafroshka-main/internal/visitor"
+
+	"github.com/google/uuid"
+)
+
+// visitorCookieTTL - сколько браузер хранит id посетителя
+const visitorCookieTTL = 365 * 24 * time.Hour
+
+// Visitor кладет в контекст id анонимного посетителя: из заголовка X-Visitor-ID, иначе из cookie.
+// Если id нет или он не uuid, выдается новый и сохраняется в cookie
+func Visitor(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(visitor.HeaderName)
+		if _, err := uuid.Parse(id); err != nil {
+			id = ""
+			if c, err := r.Cookie(visitor.CookieName); err == nil {
+				if _, err := uuid.Parse(c.Value); err == nil {
+					id = c.Value
+				}
+			}
+		}
+
+		if id == "" {
+			id = uuid.New().String()
+			http.SetCookie(w, &http.Cookie{
+				Name:     visitor.CookieName,
+				Value:    id,
+				Path:     "/",
+				Expires:  time.Now().Add(visitorCookieTTL),
+				HttpOnly: true,
+				SameSite: http.SameSiteLaxMode,
+			})
+		}
+
+		next.ServeHTTP(w, r.WithContext(visitor.WithID(r.Context(), id)))
+	})
+}
@@ -7,7 +7,9 @@ package mocks
 import (
 	announcement "gafroshka-main/internal/announcement"
 	announcement0 "gafroshka-main/internal/types/announcement"
+	elastic "gafroshka-main/internal/types/elastic"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -50,6 +52,21 @@ func (mr *MockAnnouncementRepoMockRecorder) Create(a interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAnnouncementRepo)(nil).Create), a)
 }
 
+// Facets mocks base method.
+func (m *MockAnnouncementRepo) Facets(filter announcement0.SearchFilter) (elastic.Facets, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Facets", filter)
+	ret0, _ := ret[0].(elastic.Facets)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Facets indicates an expected call of Facets.
+func (mr *MockAnnouncementRepoMockRecorder) Facets(filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Facets", reflect.TypeOf((*MockAnnouncementRepo)(nil).Facets), filter)
+}
+
 // GetByID mocks base method.
 func (m *MockAnnouncementRepo) GetByID(id string) (*announcement.Announcement, error) {
 	m.ctrl.T.Helper()
@@ -65,6 +82,37 @@ func (mr *MockAnnouncementRepoMockRecorder) GetByID(id interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockAnnouncementRepo)(nil).GetByID), id)
 }
 
+// GetBySeller mocks base method.
+func (m *MockAnnouncementRepo) GetBySeller(sellerID string, filter announcement0.SellerFilter) ([]announcement.Announcement, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBySeller", sellerID, filter)
+	ret0, _ := ret[0].([]announcement.Announcement)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetBySeller indicates an expected call of GetBySeller.
+func (mr *MockAnnouncementRepoMockRecorder) GetBySeller(sellerID, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBySeller", reflect.TypeOf((*MockAnnouncementRepo)(nil).GetBySeller), sellerID, filter)
+}
+
+// GetForCompare mocks base method.
+func (m *MockAnnouncementRepo) GetForCompare(ids []string) ([]announcement0.CompareItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetForCompare", ids)
+	ret0, _ := ret[0].([]announcement0.CompareItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetForCompare indicates an expected call of GetForCompare.
+func (mr *MockAnnouncementRepoMockRecorder) GetForCompare(ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetForCompare", reflect.TypeOf((*MockAnnouncementRepo)(nil).GetForCompare), ids)
+}
+
 // GetInfoForShoppingCart mocks base method.
 func (m *MockAnnouncementRepo) GetInfoForShoppingCart(ids []string) ([]announcement0.InfoForSC, error) {
 	m.ctrl.T.Helper()
@@ -80,47 +128,122 @@ func (mr *MockAnnouncementRepoMockRecorder) GetInfoForShoppingCart(ids interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInfoForShoppingCart", reflect.TypeOf((*MockAnnouncementRepo)(nil).GetInfoForShoppingCart), ids)
 }
 
+// GetSellerStats mocks base method.
+func (m *MockAnnouncementRepo) GetSellerStats(sellerID string) (announcement0.SellerStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSellerStats", sellerID)
+	ret0, _ := ret[0].(announcement0.SellerStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSellerStats indicates an expected call of GetSellerStats.
+func (mr *MockAnnouncementRepoMockRecorder) GetSellerStats(sellerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSellerStats", reflect.TypeOf((*MockAnnouncementRepo)(nil).GetSellerStats), sellerID)
+}
+
+// GetSimilar mocks base method.
+func (m *MockAnnouncementRepo) GetSimilar(id string, limit int) ([]announcement.SimilarAnnouncement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSimilar", id, limit)
+	ret0, _ := ret[0].([]announcement.SimilarAnnouncement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSimilar indicates an expected call of GetSimilar.
+func (mr *MockAnnouncementRepoMockRecorder) GetSimilar(id, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSimilar", reflect.TypeOf((*MockAnnouncementRepo)(nil).GetSimilar), id, limit)
+}
+
 // GetTopN mocks base method.
-func (m *MockAnnouncementRepo) GetTopN(limit int, categories []int) ([]announcement.Announcement, error) {
+func (m *MockAnnouncementRepo) GetTopN(limit int, prefs map[int]float64) ([]announcement.Announcement, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetTopN", limit, categories)
+	ret := m.ctrl.Call(m, "GetTopN", limit, prefs)
 	ret0, _ := ret[0].([]announcement.Announcement)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetTopN indicates an expected call of GetTopN.
-func (mr *MockAnnouncementRepoMockRecorder) GetTopN(limit, categories interface{}) *gomock.Call {
+func (mr *MockAnnouncementRepoMockRecorder) GetTopN(limit, prefs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTopN", reflect.TypeOf((*MockAnnouncementRepo)(nil).GetTopN), limit, prefs)
+}
+
+// Publish mocks base method.
+func (m *MockAnnouncementRepo) Publish(id string) (*announcement.Announcement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Publish", id)
+	ret0, _ := ret[0].(*announcement.Announcement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockAnnouncementRepoMockRecorder) Publish(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockAnnouncementRepo)(nil).Publish), id)
+}
+
+// Renew mocks base method.
+func (m *MockAnnouncementRepo) Renew(id string, period time.Duration) (*announcement.Announcement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Renew", id, period)
+	ret0, _ := ret[0].(*announcement.Announcement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Renew indicates an expected call of Renew.
+func (mr *MockAnnouncementRepoMockRecorder) Renew(id, period interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTopN", reflect.TypeOf((*MockAnnouncementRepo)(nil).GetTopN), limit, categories, )
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Renew", reflect.TypeOf((*MockAnnouncementRepo)(nil).Renew), id, period)
 }
 
 // Search mocks base method.
-func (m *MockAnnouncementRepo) Search(query string) ([]announcement.Announcement, error) {
+func (m *MockAnnouncementRepo) Search(filter announcement0.SearchFilter) ([]announcement.Announcement, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Search", query)
+	ret := m.ctrl.Call(m, "Search", filter)
 	ret0, _ := ret[0].([]announcement.Announcement)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Search indicates an expected call of Search.
-func (mr *MockAnnouncementRepoMockRecorder) Search(query interface{}) *gomock.Call {
+func (mr *MockAnnouncementRepoMockRecorder) Search(filter interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockAnnouncementRepo)(nil).Search), query)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockAnnouncementRepo)(nil).Search), filter)
 }
 
-// UpdateRating mocks base method.
-func (m *MockAnnouncementRepo) UpdateRating(id string, rate int) (*announcement.Announcement, error) {
+// Update mocks base method.
+func (m *MockAnnouncementRepo) Update(id string, u announcement0.UpdateAnnouncement) (*announcement.Announcement, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateRating", id, rate)
+	ret := m.ctrl.Call(m, "Update", id, u)
 	ret0, _ := ret[0].(*announcement.Announcement)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// UpdateRating indicates an expected call of UpdateRating.
-func (mr *MockAnnouncementRepoMockRecorder) UpdateRating(id, rate interface{}) *gomock.Call {
+// Update indicates an expected call of Update.
+func (mr *MockAnnouncementRepoMockRecorder) Update(id, u interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockAnnouncementRepo)(nil).Update), id, u)
+}
+
+// UpdateAttributes mocks base method.
+func (m *MockAnnouncementRepo) UpdateAttributes(id string, attributes map[string]string) (map[string]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAttributes", id, attributes)
+	ret0, _ := ret[0].(map[string]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateAttributes indicates an expected call of UpdateAttributes.
+func (mr *MockAnnouncementRepoMockRecorder) UpdateAttributes(id, attributes interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRating", reflect.TypeOf((*MockAnnouncementRepo)(nil).UpdateRating), id, rate)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAttributes", reflect.TypeOf((*MockAnnouncementRepo)(nil).UpdateAttributes), id, attributes)
 }
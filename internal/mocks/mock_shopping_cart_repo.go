@@ -5,6 +5,7 @@
 package mocks
 
 import (
+	shopping_cart "gafroshka-main/internal/shopping_cart"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -34,17 +35,17 @@ func (m *MockShoppingCartRepo) EXPECT() *MockShoppingCartRepoMockRecorder {
 }
 
 // AddAnnouncement mocks base method.
-func (m *MockShoppingCartRepo) AddAnnouncement(userID, announcementID string) error {
+func (m *MockShoppingCartRepo) AddAnnouncement(userID, announcementID string, quantity int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AddAnnouncement", userID, announcementID)
+	ret := m.ctrl.Call(m, "AddAnnouncement", userID, announcementID, quantity)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // AddAnnouncement indicates an expected call of AddAnnouncement.
-func (mr *MockShoppingCartRepoMockRecorder) AddAnnouncement(userID, announcementID interface{}) *gomock.Call {
+func (mr *MockShoppingCartRepoMockRecorder) AddAnnouncement(userID, announcementID, quantity interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAnnouncement", reflect.TypeOf((*MockShoppingCartRepo)(nil).AddAnnouncement), userID, announcementID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAnnouncement", reflect.TypeOf((*MockShoppingCartRepo)(nil).AddAnnouncement), userID, announcementID, quantity)
 }
 
 // DeleteAnnouncement mocks base method.
@@ -62,10 +63,10 @@ func (mr *MockShoppingCartRepoMockRecorder) DeleteAnnouncement(userID, announcem
 }
 
 // GetByUserID mocks base method.
-func (m *MockShoppingCartRepo) GetByUserID(userID string) ([]string, error) {
+func (m *MockShoppingCartRepo) GetByUserID(userID string) ([]shopping_cart.CartItem, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetByUserID", userID)
-	ret0, _ := ret[0].([]string)
+	ret0, _ := ret[0].([]shopping_cart.CartItem)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
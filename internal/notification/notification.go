@@ -0,0 +1,38 @@
+package notification
+
+import "time"
+
+type Type string
+
+const (
+	// TypeExpirySoon - объявление скоро будет снято с публикации
+	TypeExpirySoon Type = "expiry_soon"
+	// TypePriceDrop - цена объявления, за которым следит пользователь, опустилась ниже порога
+	TypePriceDrop Type = "price_drop"
+)
+
+// Notification уведомление пользователя
+type Notification struct {
+	ID             string `json:"id"`
+	UserID         string `json:"user_id"`
+	Type           Type   `json:"type"`
+	AnnouncementID string `json:"announcement_id,omitempty"`
+	// PriceHistoryID - изменение цены, о котором уведомляет TypePriceDrop
+	PriceHistoryID int64     `json:"-"`
+	Message        string    `json:"message"`
+	IsRead         bool      `json:"is_read"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// NotificationRepo интерфейс для работы с уведомлениями пользователей
+//
+//go:generate mockgen -source=notification.go -destination=../mocks/mock_notification_repo.go -package=mocks
+type NotificationRepo interface {
+	// Create сохраняет уведомление для пользователя
+	// Повторное уведомление о том же изменении цены не сохраняется, возвращается ErrAlreadyExists
+	Create(n Notification) (*Notification, error)
+	// GetByUserID возвращает последние limit уведомлений пользователя, новые первыми
+	GetByUserID(userID string, limit int) ([]Notification, error)
+	// MarkRead отмечает уведомления пользователя прочитанными
+	MarkRead(userID string, ids []string) error
+}
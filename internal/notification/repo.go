@@ -0,0 +1,105 @@
+package notification
+
+import (
+	"database/sql"
+	"errors"
+
+	myErr "gafroshka-main/internal/types/errors"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+type NotificationDBRepository struct {
+	DB     *sql.DB
+	Logger *zap.SugaredLogger
+}
+
+func NewNotificationDBRepository(db *sql.DB, l *zap.SugaredLogger) *NotificationDBRepository {
+	return &NotificationDBRepository{
+		DB:     db,
+		Logger: l,
+	}
+}
+
+// Create сохраняет уведомление для пользователя
+func (nr *NotificationDBRepository) Create(n Notification) (*Notification, error) {
+	query := `
+	INSERT INTO notification (user_id, type, announcement_id, price_history_id, message)
+	VALUES ($1, $2, $3, $4, $5)
+	ON CONFLICT (user_id, price_history_id) DO NOTHING
+	RETURNING id, is_read, created_at
+	`
+	var announcementID sql.NullString
+	if n.AnnouncementID != "" {
+		announcementID = sql.NullString{String: n.AnnouncementID, Valid: true}
+	}
+	var priceHistoryID sql.NullInt64
+	if n.PriceHistoryID != 0 {
+		priceHistoryID = sql.NullInt64{Int64: n.PriceHistoryID, Valid: true}
+	}
+
+	created := n
+	err := nr.DB.QueryRow(query, n.UserID, n.Type, announcementID, priceHistoryID, n.Message).
+		Scan(&created.ID, &created.IsRead, &created.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		// пользователь уже уведомлен об этом изменении цены
+		return nil, myErr.ErrAlreadyExists
+	}
+	if err != nil {
+		nr.Logger.Errorf("Error creating notification for user %s: %v", n.UserID, err)
+		return nil, myErr.ErrDBInternal
+	}
+
+	return &created, nil
+}
+
+// GetByUserID возвращает последние limit уведомлений пользователя, новые первыми
+func (nr *NotificationDBRepository) GetByUserID(userID string, limit int) ([]Notification, error) {
+	query := `
+	SELECT id, user_id, type, announcement_id, message, is_read, created_at
+	FROM notification
+	WHERE user_id = $1
+	ORDER BY created_at DESC
+	LIMIT $2
+	`
+	rows, err := nr.DB.Query(query, userID, limit)
+	if err != nil {
+		nr.Logger.Errorf("Error getting notifications of user %s: %v", userID, err)
+		return nil, myErr.ErrDBInternal
+	}
+	defer rows.Close()
+
+	notifications := make([]Notification, 0)
+	for rows.Next() {
+		var (
+			n              Notification
+			announcementID sql.NullString
+		)
+		if err := rows.Scan(&n.ID, &n.UserID, &n.Type, &announcementID, &n.Message, &n.IsRead, &n.CreatedAt); err != nil {
+			nr.Logger.Errorf("Error scanning notification: %v", err)
+			return nil, myErr.ErrDBInternal
+		}
+		n.AnnouncementID = announcementID.String
+		notifications = append(notifications, n)
+	}
+
+	if err := rows.Err(); err != nil {
+		nr.Logger.Errorf("Rows iteration error: %v", err)
+		return nil, myErr.ErrDBInternal
+	}
+
+	return notifications, nil
+}
+
+// MarkRead отмечает уведомления пользователя прочитанными
+func (nr *NotificationDBRepository) MarkRead(userID string, ids []string) error {
+	query := `UPDATE notification SET is_read = TRUE WHERE user_id = $1 AND id = ANY($2)`
+
+	if _, err := nr.DB.Exec(query, userID, pq.Array(ids)); err != nil {
+		nr.Logger.Errorf("Error marking notifications of user %s: %v", userID, err)
+		return myErr.ErrDBInternal
+	}
+
+	return nil
+}
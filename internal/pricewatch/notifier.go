@@ -0,0 +1,112 @@
+package pricewatch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gafroshka-main/internal/notification"
+	myErr "gafroshka-main/internal/types/errors"
+
+	"go.uber.org/zap"
+)
+
+// batchSize - сколько изменений цены разбирается за одну итерацию
+const batchSize = 500
+
+// Notifier разбирает новые записи истории цен и уведомляет подписчиков о снижении цены
+type Notifier struct {
+	repo          PriceWatchRepo
+	notifications notification.NotificationRepo
+	logger        *zap.SugaredLogger
+	interval      time.Duration
+}
+
+func NewNotifier(
+	repo PriceWatchRepo,
+	notifications notification.NotificationRepo,
+	logger *zap.SugaredLogger,
+	interval time.Duration,
+) *Notifier {
+	return &Notifier{
+		repo:          repo,
+		notifications: notifications,
+		logger:        logger,
+		interval:      interval,
+	}
+}
+
+// Run - запускает разбор изменений цен через определенные промежутки времени
+func (n *Notifier) Run(ctx context.Context) {
+	ticker := time.NewTicker(n.interval)
+	defer ticker.Stop()
+
+	n.logger.Infow("Price watch notifier started")
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n.RunOnce()
+		}
+	}
+}
+
+// RunOnce - одна итерация: уведомляет о снижениях цены и отмечает изменения обработанными
+// Изменение, по которому не удалось отправить все уведомления, повторится на следующей итерации,
+// а уже уведомленные подписчики повторно его не получат
+func (n *Notifier) RunOnce() {
+	changes, err := n.repo.GetUnprocessed(batchSize)
+	if err != nil {
+		n.logger.Errorw("Getting price changes failed", zap.Error(err))
+		return
+	}
+
+	processed := make([]int64, 0, len(changes))
+	for _, c := range changes {
+		// повышение цены и изменения неактивных объявлений никого не интересуют
+		if c.IsDrop() && c.IsActive && !n.notifyWatchers(c) {
+			continue
+		}
+		processed = append(processed, c.ID)
+	}
+
+	if len(processed) == 0 {
+		return
+	}
+	if err = n.repo.MarkProcessed(processed); err != nil {
+		n.logger.Errorw("Marking price changes processed failed", zap.Error(err))
+	}
+}
+
+// notifyWatchers уведомляет подписчиков об одном снижении цены, false - если не получилось
+func (n *Notifier) notifyWatchers(c PriceChange) bool {
+	users, err := n.repo.GetWatchers(c.AnnouncementID, c.OldPrice, c.NewPrice)
+	if err != nil {
+		n.logger.Errorw("Getting price watchers failed", "announcement", c.AnnouncementID, zap.Error(err))
+		return false
+	}
+
+	ok := true
+	for _, userID := range users {
+		_, err := n.notifications.Create(notification.Notification{
+			UserID:         userID,
+			Type:           notification.TypePriceDrop,
+			AnnouncementID: c.AnnouncementID,
+			PriceHistoryID: c.ID,
+			Message:        fmt.Sprintf("Цена на «%s» снизилась с %d до %d", c.Name, c.OldPrice, c.NewPrice),
+		})
+		// уведомление отправлено на прошлой итерации, которая не смогла уведомить остальных
+		if errors.Is(err, myErr.ErrAlreadyExists) {
+			continue
+		}
+		if err != nil {
+			n.logger.Warnw("Failed to notify user about price drop", "user", userID, "announcement", c.AnnouncementID, zap.Error(err))
+			ok = false
+		}
+	}
+
+	return ok
+}
@@ -0,0 +1,85 @@
+package pricewatch_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gafroshka-main/internal/mocks"
+	"gafroshka-main/internal/notification"
+	"gafroshka-main/internal/pricewatch"
+	myErr "gafroshka-main/internal/types/errors"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+)
+
+func setupNotifier(t *testing.T) (*pricewatch.Notifier, *mocks.MockPriceWatchRepo, *mocks.MockNotificationRepo) {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockPriceWatchRepo(ctrl)
+	notifications := mocks.NewMockNotificationRepo(ctrl)
+	n := pricewatch.NewNotifier(repo, notifications, zaptest.NewLogger(t).Sugar(), time.Minute)
+	return n, repo, notifications
+}
+
+func TestRunOnce_NotifiesOnDrop(t *testing.T) {
+	t.Parallel()
+	n, repo, notifications := setupNotifier(t)
+
+	repo.EXPECT().GetUnprocessed(gomock.Any()).Return([]pricewatch.PriceChange{
+		{ID: 1, AnnouncementID: "a1", Name: "Велосипед", IsActive: true, OldPrice: 1000, NewPrice: 800},
+		// повышение цены - только отмечается обработанным
+		{ID: 2, AnnouncementID: "a2", Name: "Диван", IsActive: true, OldPrice: 1000, NewPrice: 1200},
+		// снижение у снятого с продажи объявления никого не уведомляет
+		{ID: 3, AnnouncementID: "a3", Name: "Стол", IsActive: false, OldPrice: 1000, NewPrice: 500},
+	}, nil)
+	repo.EXPECT().GetWatchers("a1", int64(1000), int64(800)).Return([]string{"u1", "u2"}, nil)
+	notifications.EXPECT().Create(gomock.Any()).DoAndReturn(func(nt notification.Notification) (*notification.Notification, error) {
+		assert.Equal(t, notification.TypePriceDrop, nt.Type)
+		assert.Equal(t, "a1", nt.AnnouncementID)
+		return &nt, nil
+	}).Times(2)
+	repo.EXPECT().MarkProcessed([]int64{1, 2, 3}).Return(nil)
+
+	n.RunOnce()
+}
+
+func TestRunOnce_RetriesFailedNotification(t *testing.T) {
+	t.Parallel()
+	n, repo, notifications := setupNotifier(t)
+
+	repo.EXPECT().GetUnprocessed(gomock.Any()).Return([]pricewatch.PriceChange{
+		{ID: 1, AnnouncementID: "a1", Name: "Велосипед", IsActive: true, OldPrice: 1000, NewPrice: 800},
+		{ID: 2, AnnouncementID: "a2", Name: "Диван", IsActive: true, OldPrice: 1000, NewPrice: 900},
+	}, nil)
+	repo.EXPECT().GetWatchers("a1", int64(1000), int64(800)).Return([]string{"u1"}, nil)
+	notifications.EXPECT().Create(gomock.Any()).Return(nil, errors.New("db error"))
+	repo.EXPECT().GetWatchers("a2", int64(1000), int64(900)).Return(nil, nil)
+	// изменение a1 останется необработанным и повторится на следующей итерации
+	repo.EXPECT().MarkProcessed([]int64{2}).Return(nil)
+
+	n.RunOnce()
+}
+
+func TestRunOnce_SkipsAlreadyNotifiedOnRetry(t *testing.T) {
+	t.Parallel()
+	n, repo, notifications := setupNotifier(t)
+
+	repo.EXPECT().GetUnprocessed(gomock.Any()).Return([]pricewatch.PriceChange{
+		{ID: 1, AnnouncementID: "a1", Name: "Велосипед", IsActive: true, OldPrice: 1000, NewPrice: 800},
+	}, nil)
+	repo.EXPECT().GetWatchers("a1", int64(1000), int64(800)).Return([]string{"u1", "u2"}, nil)
+	// u1 уведомлен на прошлой итерации, уведомление u2 тогда не сохранилось
+	notifications.EXPECT().Create(gomock.Any()).DoAndReturn(func(nt notification.Notification) (*notification.Notification, error) {
+		assert.Equal(t, int64(1), nt.PriceHistoryID)
+		if nt.UserID == "u1" {
+			return nil, myErr.ErrAlreadyExists
+		}
+		return &nt, nil
+	}).Times(2)
+	repo.EXPECT().MarkProcessed([]int64{1}).Return(nil)
+
+	n.RunOnce()
+}
@@ -0,0 +1,46 @@
+package pricewatch
+
+import "time"
+
+// PricePoint - точка истории цены объявления
+type PricePoint struct {
+	Price          int64     `json:"price"`
+	Discount       int       `json:"discount"`
+	EffectivePrice int64     `json:"effective_price"`
+	ChangedAt      time.Time `json:"changed_at"`
+}
+
+// PriceChange - необработанное изменение цены вместе с предыдущей ценой со скидкой
+type PriceChange struct {
+	ID             int64
+	AnnouncementID string
+	Name           string
+	IsActive       bool
+	OldPrice       int64
+	NewPrice       int64
+}
+
+// IsDrop - цена со скидкой снизилась
+func (c PriceChange) IsDrop() bool {
+	return c.NewPrice < c.OldPrice
+}
+
+// PriceWatchRepo интерфейс для истории цен и подписок на снижение цены
+//
+//go:generate mockgen -source=pricewatch.go -destination=../mocks/mock_pricewatch_repo.go -package=mocks
+type PriceWatchRepo interface {
+	// GetHistory возвращает историю цены объявления от старых записей к новым
+	GetHistory(announcementID string, limit int) ([]PricePoint, error)
+	// Watch подписывает пользователя на снижение цены ниже threshold, повторный вызов меняет порог
+	Watch(userID, announcementID string, threshold int64) error
+	// Unwatch отменяет подписку пользователя
+	Unwatch(userID, announcementID string) error
+	// GetUnprocessed возвращает изменения цены, о которых подписчики еще не уведомлены
+	GetUnprocessed(limit int) ([]PriceChange, error)
+	// GetWatchers возвращает пользователей, для которых цена опустилась ниже порога:
+	// подписчиков, чей порог пересечен, и добавивших объявление в корзину или избранное без подписки -
+	// для них порог равен старой цене
+	GetWatchers(announcementID string, oldPrice, newPrice int64) ([]string, error)
+	// MarkProcessed отмечает изменения цены обработанными
+	MarkProcessed(ids []int64) error
+}
@@ -0,0 +1,197 @@
+package pricewatch
+
+import (
+	"database/sql"
+
+	myErr "gafroshka-main/internal/types/errors"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+type PriceWatchDBRepository struct {
+	DB     *sql.DB
+	Logger *zap.SugaredLogger
+}
+
+func NewPriceWatchDBRepository(db *sql.DB, l *zap.SugaredLogger) *PriceWatchDBRepository {
+	return &PriceWatchDBRepository{
+		DB:     db,
+		Logger: l,
+	}
+}
+
+// GetHistory возвращает последние limit изменений цены объявления от старых к новым
+func (pr *PriceWatchDBRepository) GetHistory(announcementID string, limit int) ([]PricePoint, error) {
+	query := `
+	SELECT price, discount, effective_price, changed_at
+	FROM (
+		SELECT id, price, discount, effective_price, changed_at
+		FROM price_history
+		WHERE announcement_id = $1
+		ORDER BY id DESC
+		LIMIT $2
+	) h
+	ORDER BY id
+	`
+	rows, err := pr.DB.Query(query, announcementID, limit)
+	if err != nil {
+		pr.Logger.Errorf("Error getting price history of announcement %s: %v", announcementID, err)
+		return nil, myErr.ErrDBInternal
+	}
+	defer rows.Close()
+
+	history := make([]PricePoint, 0)
+	for rows.Next() {
+		var p PricePoint
+		if err := rows.Scan(&p.Price, &p.Discount, &p.EffectivePrice, &p.ChangedAt); err != nil {
+			pr.Logger.Errorf("Error scanning price point: %v", err)
+			return nil, myErr.ErrDBInternal
+		}
+		history = append(history, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		pr.Logger.Errorf("Rows iteration error: %v", err)
+		return nil, myErr.ErrDBInternal
+	}
+
+	return history, nil
+}
+
+// Watch подписывает пользователя на снижение цены объявления ниже threshold
+func (pr *PriceWatchDBRepository) Watch(userID, announcementID string, threshold int64) error {
+	query := `
+	INSERT INTO price_watch (user_id, announcement_id, threshold)
+	VALUES ($1, $2, $3)
+	ON CONFLICT (user_id, announcement_id) DO UPDATE SET threshold = EXCLUDED.threshold
+	`
+	if _, err := pr.DB.Exec(query, userID, announcementID, threshold); err != nil {
+		pr.Logger.Errorf("Error watching price of %s by user %s: %v", announcementID, userID, err)
+		return myErr.ErrDBInternal
+	}
+
+	return nil
+}
+
+// Unwatch отменяет подписку пользователя на цену объявления
+func (pr *PriceWatchDBRepository) Unwatch(userID, announcementID string) error {
+	query := `DELETE FROM price_watch WHERE user_id = $1 AND announcement_id = $2`
+
+	res, err := pr.DB.Exec(query, userID, announcementID)
+	if err != nil {
+		pr.Logger.Errorf("Error unwatching price of %s by user %s: %v", announcementID, userID, err)
+		return myErr.ErrDBInternal
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		pr.Logger.Errorf("Error getting affected rows: %v", err)
+		return myErr.ErrDBInternal
+	}
+	if affected == 0 {
+		return myErr.ErrNotFound
+	}
+
+	return nil
+}
+
+// GetUnprocessed возвращает необработанные изменения цены в порядке записи
+// Старая цена - предыдущая запись истории, у первой записи она совпадает с новой
+func (pr *PriceWatchDBRepository) GetUnprocessed(limit int) ([]PriceChange, error) {
+	query := `
+	SELECT ph.id, ph.announcement_id, a.name, a.is_active,
+		COALESCE(prev.effective_price, ph.effective_price), ph.effective_price
+	FROM price_history ph
+	JOIN announcement a ON a.id = ph.announcement_id
+	LEFT JOIN LATERAL (
+		SELECT p.effective_price
+		FROM price_history p
+		WHERE p.announcement_id = ph.announcement_id AND p.id < ph.id
+		ORDER BY p.id DESC
+		LIMIT 1
+	) prev ON TRUE
+	WHERE ph.processed = FALSE
+	ORDER BY ph.id
+	LIMIT $1
+	`
+	rows, err := pr.DB.Query(query, limit)
+	if err != nil {
+		pr.Logger.Errorf("Error getting unprocessed price changes: %v", err)
+		return nil, myErr.ErrDBInternal
+	}
+	defer rows.Close()
+
+	var changes []PriceChange
+	for rows.Next() {
+		var c PriceChange
+		if err := rows.Scan(&c.ID, &c.AnnouncementID, &c.Name, &c.IsActive, &c.OldPrice, &c.NewPrice); err != nil {
+			pr.Logger.Errorf("Error scanning price change: %v", err)
+			return nil, myErr.ErrDBInternal
+		}
+		changes = append(changes, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		pr.Logger.Errorf("Rows iteration error: %v", err)
+		return nil, myErr.ErrDBInternal
+	}
+
+	return changes, nil
+}
+
+// GetWatchers возвращает пользователей, которых нужно уведомить о снижении цены с oldPrice до newPrice
+// Уведомление приходит один раз - в момент, когда цена пересекает порог
+func (pr *PriceWatchDBRepository) GetWatchers(announcementID string, oldPrice, newPrice int64) ([]string, error) {
+	query := `
+	SELECT user_id
+	FROM price_watch
+	WHERE announcement_id = $1 AND threshold > $3 AND threshold <= $2
+	UNION
+	SELECT t.user_id
+	FROM (
+		SELECT user_id FROM shopping_cart WHERE announcement_id = $1
+		UNION
+		SELECT user_id FROM favorite WHERE announcement_id = $1
+	) t
+	WHERE NOT EXISTS (
+		SELECT 1 FROM price_watch pw
+		WHERE pw.user_id = t.user_id AND pw.announcement_id = $1
+	)
+	`
+	rows, err := pr.DB.Query(query, announcementID, oldPrice, newPrice)
+	if err != nil {
+		pr.Logger.Errorf("Error getting price watchers of announcement %s: %v", announcementID, err)
+		return nil, myErr.ErrDBInternal
+	}
+	defer rows.Close()
+
+	var users []string
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			pr.Logger.Errorf("Error scanning watcher: %v", err)
+			return nil, myErr.ErrDBInternal
+		}
+		users = append(users, userID)
+	}
+
+	if err := rows.Err(); err != nil {
+		pr.Logger.Errorf("Rows iteration error: %v", err)
+		return nil, myErr.ErrDBInternal
+	}
+
+	return users, nil
+}
+
+// MarkProcessed отмечает изменения цены обработанными
+func (pr *PriceWatchDBRepository) MarkProcessed(ids []int64) error {
+	query := `UPDATE price_history SET processed = TRUE WHERE id = ANY($1)`
+
+	if _, err := pr.DB.Exec(query, pq.Array(ids)); err != nil {
+		pr.Logger.Errorf("Error marking price changes processed: %v", err)
+		return myErr.ErrDBInternal
+	}
+
+	return nil
+}
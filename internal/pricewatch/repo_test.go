@@ -0,0 +1,85 @@
+package pricewatch
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+
+	myErr "gafroshka-main/internal/types/errors"
+)
+
+func setup(t *testing.T) (*PriceWatchDBRepository, sqlmock.Sqlmock, func()) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка при создании mock db: %s", err)
+	}
+
+	repo := NewPriceWatchDBRepository(db, zaptest.NewLogger(t).Sugar())
+
+	return repo, mock, func() { db.Close() }
+}
+
+func TestGetUnprocessed(t *testing.T) {
+	t.Parallel()
+	repo, mock, cleanup := setup(t)
+	defer cleanup()
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM price_history ph")).
+		WithArgs(100).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "announcement_id", "name", "is_active", "old", "new"}).
+			AddRow(int64(7), "a1", "Велосипед", true, int64(1000), int64(900)))
+
+	got, err := repo.GetUnprocessed(100)
+	assert.NoError(t, err)
+	assert.Equal(t, []PriceChange{
+		{ID: 7, AnnouncementID: "a1", Name: "Велосипед", IsActive: true, OldPrice: 1000, NewPrice: 900},
+	}, got)
+	assert.True(t, got[0].IsDrop())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetWatchers(t *testing.T) {
+	t.Parallel()
+	repo, mock, cleanup := setup(t)
+	defer cleanup()
+
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE announcement_id = $1 AND threshold > $3 AND threshold <= $2")).
+		WithArgs("a1", int64(1000), int64(900)).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow("u1").AddRow("u2"))
+
+	got, err := repo.GetWatchers("a1", 1000, 900)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"u1", "u2"}, got)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUnwatch_NotFound(t *testing.T) {
+	t.Parallel()
+	repo, mock, cleanup := setup(t)
+	defer cleanup()
+
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM price_watch")).
+		WithArgs("u1", "a1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.ErrorIs(t, repo.Unwatch("u1", "a1"), myErr.ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMarkProcessed(t *testing.T) {
+	t.Parallel()
+	repo, mock, cleanup := setup(t)
+	defer cleanup()
+
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE price_history SET processed = TRUE WHERE id = ANY($1)")).
+		WithArgs(pq.Array([]int64{1, 2})).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	assert.NoError(t, repo.MarkProcessed([]int64{1, 2}))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,15 @@
+package recentlyviewed
+
+import "context"
+
+// RecentlyViewedRepo интерфейс списка недавно просмотренных объявлений пользователя
+//
+//go:generate mockgen -source=recently_viewed.go -destination=../mocks/mock_recently_viewed_repo.go -package=mocks
+type RecentlyViewedRepo interface {
+	// Add отмечает просмотр объявления: повторный просмотр поднимает его в начало списка
+	Add(ctx context.Context, userID, announcementID string) error
+	// Get возвращает id последних просмотренных объявлений, новые первыми
+	Get(ctx context.Context, userID string, limit int) ([]string, error)
+	// Merge переносит просмотры fromID в список toID и удаляет список fromID
+	Merge(ctx context.Context, fromID, toID string) error
+}
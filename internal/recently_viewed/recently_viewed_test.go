@@ -0,0 +1,112 @@
+package recentlyviewed
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+)
+
+func setupTestRepo(t *testing.T, maxItems int) (*RecentlyViewedRedisRepository, *miniredis.Miniredis) {
+	t.Helper()
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+
+	rdb := redis.NewClient(&redis.Options{
+		Addr: mr.Addr(),
+	})
+	repo := NewRecentlyViewedRedisRepository(rdb, zaptest.NewLogger(t).Sugar(), maxItems, time.Hour)
+
+	return repo, mr
+}
+
+func TestAdd_DeduplicatesAndOrders(t *testing.T) {
+	t.Parallel()
+	repo, mr := setupTestRepo(t, 10)
+	defer mr.Close()
+	ctx := context.Background()
+
+	for _, id := range []string{"a1", "a2", "a3", "a1"} {
+		assert.NoError(t, repo.Add(ctx, "u1", id))
+	}
+
+	got, err := repo.Get(ctx, "u1", 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a1", "a3", "a2"}, got)
+}
+
+func TestAdd_CapsAndSetsTTL(t *testing.T) {
+	t.Parallel()
+	repo, mr := setupTestRepo(t, 2)
+	defer mr.Close()
+	ctx := context.Background()
+
+	for _, id := range []string{"a1", "a2", "a3"} {
+		assert.NoError(t, repo.Add(ctx, "u1", id))
+	}
+
+	got, err := repo.Get(ctx, "u1", 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a3", "a2"}, got)
+	assert.Equal(t, time.Hour, mr.TTL(key("u1")))
+
+	mr.FastForward(2 * time.Hour)
+	got, err = repo.Get(ctx, "u1", 10)
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func TestGet_Limit(t *testing.T) {
+	t.Parallel()
+	repo, mr := setupTestRepo(t, 10)
+	defer mr.Close()
+	ctx := context.Background()
+
+	for _, id := range []string{"a1", "a2", "a3"} {
+		assert.NoError(t, repo.Add(ctx, "u1", id))
+	}
+
+	got, err := repo.Get(ctx, "u1", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a3"}, got)
+}
+
+func TestMerge(t *testing.T) {
+	t.Parallel()
+	repo, mr := setupTestRepo(t, 3)
+	defer mr.Close()
+	ctx := context.Background()
+
+	assert.NoError(t, repo.Add(ctx, "u1", "a1"))
+	assert.NoError(t, repo.Add(ctx, "u1", "a2"))
+	assert.NoError(t, repo.Add(ctx, "visitor:v1", "a3"))
+	assert.NoError(t, repo.Add(ctx, "visitor:v1", "a4"))
+	// повторный просмотр посетителем поднимает объявление пользователя
+	assert.NoError(t, repo.Add(ctx, "visitor:v1", "a1"))
+
+	assert.NoError(t, repo.Merge(ctx, "visitor:v1", "u1"))
+
+	got, err := repo.Get(ctx, "u1", 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a1", "a4", "a3"}, got)
+	assert.Equal(t, time.Hour, mr.TTL(key("u1")))
+	assert.False(t, mr.Exists(key("visitor:v1")))
+}
+
+func TestMerge_EmptyVisitor(t *testing.T) {
+	t.Parallel()
+	repo, mr := setupTestRepo(t, 10)
+	defer mr.Close()
+	ctx := context.Background()
+
+	assert.NoError(t, repo.Add(ctx, "u1", "a1"))
+	assert.NoError(t, repo.Merge(ctx, "visitor:v1", "u1"))
+
+	got, err := repo.Get(ctx, "u1", 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a1"}, got)
+}
@@ -0,0 +1,99 @@
+package recentlyviewed
+
+import (
+	"context"
+	"time"
+
+	myErr "gafroshka-main/internal/types/errors"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+// keyPrefix - префикс ключа sorted set с просмотрами пользователя
+const keyPrefix = "recently_viewed:"
+
+// RecentlyViewedRedisRepository хранит просмотры в sorted set: member - id объявления, score - время просмотра
+// Повторный просмотр перезаписывает score, так что дубликатов нет, а порядок - по времени
+type RecentlyViewedRedisRepository struct {
+	RedisClient *redis.Client
+	Logger      *zap.SugaredLogger
+	// maxItems - сколько последних просмотров хранится на пользователя
+	maxItems int
+	// ttl - сколько список живет после последнего просмотра
+	ttl time.Duration
+}
+
+func NewRecentlyViewedRedisRepository(
+	redisClient *redis.Client,
+	logger *zap.SugaredLogger,
+	maxItems int,
+	ttl time.Duration,
+) *RecentlyViewedRedisRepository {
+	return &RecentlyViewedRedisRepository{
+		RedisClient: redisClient,
+		Logger:      logger,
+		maxItems:    maxItems,
+		ttl:         ttl,
+	}
+}
+
+func key(userID string) string {
+	return keyPrefix + userID
+}
+
+// Add отмечает просмотр, обрезает список до maxItems и продлевает TTL одной транзакцией
+func (rr *RecentlyViewedRedisRepository) Add(ctx context.Context, userID, announcementID string) error {
+	k := key(userID)
+
+	_, err := rr.RedisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZAdd(ctx, k, &redis.Z{
+			Score:  float64(time.Now().UnixMicro()),
+			Member: announcementID,
+		})
+		// удаляем все, кроме maxItems самых свежих
+		pipe.ZRemRangeByRank(ctx, k, 0, int64(-rr.maxItems-1))
+		pipe.Expire(ctx, k, rr.ttl)
+		return nil
+	})
+	if err != nil {
+		rr.Logger.Errorw("Failed to save recently viewed", "user", userID, "announcement", announcementID, zap.Error(err))
+		return myErr.ErrDBInternal
+	}
+
+	return nil
+}
+
+// Get возвращает до limit последних просмотренных объявлений, новые первыми
+func (rr *RecentlyViewedRedisRepository) Get(ctx context.Context, userID string, limit int) ([]string, error) {
+	ids, err := rr.RedisClient.ZRevRange(ctx, key(userID), 0, int64(limit-1)).Result()
+	if err != nil {
+		rr.Logger.Errorw("Failed to get recently viewed", "user", userID, zap.Error(err))
+		return nil, myErr.ErrDBInternal
+	}
+
+	return ids, nil
+}
+
+// Merge объединяет списки по времени просмотра: у объявления из обоих списков остается
+// более поздний просмотр, затем список обрезается до maxItems. Все одной транзакцией
+func (rr *RecentlyViewedRedisRepository) Merge(ctx context.Context, fromID, toID string) error {
+	from, to := key(fromID), key(toID)
+
+	_, err := rr.RedisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZUnionStore(ctx, to, &redis.ZStore{
+			Keys:      []string{to, from},
+			Aggregate: "MAX",
+		})
+		pipe.ZRemRangeByRank(ctx, to, 0, int64(-rr.maxItems-1))
+		pipe.Expire(ctx, to, rr.ttl)
+		pipe.Del(ctx, from)
+		return nil
+	})
+	if err != nil {
+		rr.Logger.Errorw("Failed to merge recently viewed", "from", fromID, "to", toID, zap.Error(err))
+		return myErr.ErrDBInternal
+	}
+
+	return nil
+}
@@ -21,14 +21,18 @@ func NewShoppingCartRepository(db *sql.DB, logger *zap.SugaredLogger) *ShoppingC
 	}
 }
 
-// AddAnnouncement добавляет пользователю в корзину товар
-func (scr *ShoppingCartRepository) AddAnnouncement(userID string, announcementID string) error {
+// AddAnnouncement добавляет пользователю в корзину товар в количестве quantity
+func (scr *ShoppingCartRepository) AddAnnouncement(userID string, announcementID string, quantity int) error {
+	if quantity <= 0 {
+		return myErr.ErrInvalidQuantity
+	}
+
 	query := `
-	INSERT INTO shopping_cart(user_id, announcement_id) 
-	VALUES ($1, $2) ON CONFLICT (user_id, announcement_id)
-	DO NOTHING
+	INSERT INTO shopping_cart(user_id, announcement_id, quantity) 
+	VALUES ($1, $2, $3) ON CONFLICT (user_id, announcement_id)
+	DO UPDATE SET quantity = EXCLUDED.quantity
 `
-	_, err := scr.DB.Exec(query, userID, announcementID)
+	_, err := scr.DB.Exec(query, userID, announcementID, quantity)
 	if err != nil {
 		scr.Logger.Errorf("Ошибка при добавлении объявления: %v", err)
 		return myErr.ErrDBInternal
@@ -56,10 +60,10 @@ func (scr *ShoppingCartRepository) DeleteAnnouncement(userID string, announcemen
 	return nil
 }
 
-// GetByUserID получает корзину пользователя (список id объявлений)
-func (scr *ShoppingCartRepository) GetByUserID(userID string) ([]string, error) {
+// GetByUserID получает корзину пользователя (позиции с количеством)
+func (scr *ShoppingCartRepository) GetByUserID(userID string) ([]CartItem, error) {
 	query := `
-	SELECT announcement_id FROM shopping_cart
+	SELECT announcement_id, quantity FROM shopping_cart
 	WHERE user_id = $1
 `
 	rows, err := scr.DB.Query(query, userID)
@@ -73,15 +77,15 @@ func (scr *ShoppingCartRepository) GetByUserID(userID string) ([]string, error)
 	}
 	defer rows.Close()
 
-	var announcementIDs []string
+	var items []CartItem
 	for rows.Next() {
-		var announcementID string
-		if err := rows.Scan(&announcementID); err != nil {
+		var item CartItem
+		if err := rows.Scan(&item.AnnouncementID, &item.Quantity); err != nil {
 			return nil, myErr.ErrDBInternal
 		}
 
-		announcementIDs = append(announcementIDs, announcementID)
+		items = append(items, item)
 	}
 
-	return announcementIDs, nil
+	return items, nil
 }
@@ -4,16 +4,24 @@ package shopping_cart
 type ShoppingCart struct {
 	UserID         string `json:"user_id"`
 	AnnouncementID string `json:"announcement_id"`
+	Quantity       int    `json:"quantity"`
+}
+
+// CartItem позиция корзины: объявление и количество товара
+type CartItem struct {
+	AnnouncementID string `json:"announcement_id"`
+	Quantity       int    `json:"quantity"`
 }
 
 // ShoppingCartRepo интерфейс для работы репозитория корзины покупок
 //
 //go:generate mockgen -source=shopping_cart.go -destination=../mocks/mock_shopping_cart_repo.go -package=mocks
 type ShoppingCartRepo interface {
-	// AddAnnouncement добавляет пользователю в корзину товар
-	AddAnnouncement(userID string, announcementID string) error
+	// AddAnnouncement добавляет пользователю в корзину товар в количестве quantity
+	// Если товар уже в корзине, количество заменяется
+	AddAnnouncement(userID string, announcementID string, quantity int) error
 	// DeleteAnnouncement удаляет из корзины покупки
 	DeleteAnnouncement(userID string, announcementID string) error
-	// GetByUserID получает корзину пользователя (список id объявлений)
-	GetByUserID(userID string) ([]string, error)
+	// GetByUserID получает корзину пользователя (позиции с количеством)
+	GetByUserID(userID string) ([]CartItem, error)
 }
@@ -42,8 +42,8 @@ func TestAddAnnouncement(t *testing.T) {
 		{
 			name: "успешное добавление",
 			mockBehavior: func(mock sqlmock.Sqlmock) {
-				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO shopping_cart(user_id, announcement_id) VALUES ($1, $2)")).
-					WithArgs("user123", "ann456").
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO shopping_cart(user_id, announcement_id, quantity) VALUES ($1, $2, $3)")).
+					WithArgs("user123", "ann456", 2).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 			},
 			expectedError: nil,
@@ -51,8 +51,8 @@ func TestAddAnnouncement(t *testing.T) {
 		{
 			name: "ошибка БД",
 			mockBehavior: func(mock sqlmock.Sqlmock) {
-				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO shopping_cart(user_id, announcement_id) VALUES ($1, $2)")).
-					WithArgs("user123", "ann456").
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO shopping_cart(user_id, announcement_id, quantity) VALUES ($1, $2, $3)")).
+					WithArgs("user123", "ann456", 2).
 					WillReturnError(errors.New("db error"))
 			},
 			expectedError: myErr.ErrDBInternal, // сравним через errors.Is
@@ -66,7 +66,7 @@ func TestAddAnnouncement(t *testing.T) {
 
 			tt.mockBehavior(mock)
 
-			err := repo.AddAnnouncement("user123", "ann456")
+			err := repo.AddAnnouncement("user123", "ann456", 2)
 			if tt.expectedError != nil {
 				assert.Error(t, err)
 				assert.True(t, errors.Is(err, tt.expectedError))
@@ -129,26 +129,26 @@ func TestGetByUserID(t *testing.T) {
 	tests := []struct {
 		name           string
 		mockBehavior   func(mock sqlmock.Sqlmock)
-		expectedResult []string
+		expectedResult []CartItem
 		expectedError  error
 	}{
 		{
 			name: "успешный возврат",
 			mockBehavior: func(mock sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"announcement_id"}).
-					AddRow("ann1").
-					AddRow("ann2")
-				mock.ExpectQuery(regexp.QuoteMeta("SELECT announcement_id FROM shopping_cart WHERE user_id = $1")).
+				rows := sqlmock.NewRows([]string{"announcement_id", "quantity"}).
+					AddRow("ann1", 1).
+					AddRow("ann2", 3)
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT announcement_id, quantity FROM shopping_cart WHERE user_id = $1")).
 					WithArgs("user123").
 					WillReturnRows(rows)
 			},
-			expectedResult: []string{"ann1", "ann2"},
+			expectedResult: []CartItem{{AnnouncementID: "ann1", Quantity: 1}, {AnnouncementID: "ann2", Quantity: 3}},
 			expectedError:  nil,
 		},
 		{
 			name: "ошибка БД",
 			mockBehavior: func(mock sqlmock.Sqlmock) {
-				mock.ExpectQuery(regexp.QuoteMeta("SELECT announcement_id FROM shopping_cart WHERE user_id = $1")).
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT announcement_id, quantity FROM shopping_cart WHERE user_id = $1")).
 					WithArgs("user123").
 					WillReturnError(errors.New("db failure"))
 			},
@@ -1,5 +1,7 @@
 package announcement
 
+import "time"
+
 // CreateAnnouncement - форма для создания объявления
 type CreateAnnouncement struct {
 	Name         string `json:"name"`
@@ -8,6 +10,28 @@ type CreateAnnouncement struct {
 	Price        int64  `json:"price"`
 	Category     int    `json:"category"`
 	Discount     int    `json:"discount"`
+	// Quantity - количество товара в наличии, по умолчанию 1
+	Quantity int `json:"quantity,omitempty"`
+	// PublishAt - отложенная публикация, пусто - публикуется сразу
+	PublishAt *time.Time `json:"publish_at,omitempty"`
+	// Attributes - значения атрибутов по схеме категории: код атрибута -> значение
+	Attributes map[string]string `json:"attributes,omitempty"`
+	// Draft - сохранить черновиком: поля можно заполнить позже, публикация отдельным запросом
+	Draft bool `json:"draft,omitempty"`
+}
+
+// UpdateAnnouncement - частичное изменение объявления, nil-поля не меняются
+type UpdateAnnouncement struct {
+	Name        *string `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+	Price       *int64  `json:"price,omitempty"`
+	Category    *int    `json:"category,omitempty"`
+	Discount    *int    `json:"discount,omitempty"`
+	Quantity    *int    `json:"quantity,omitempty"`
+	// PublishAt меняется только у черновика
+	PublishAt *time.Time `json:"publish_at,omitempty"`
+	// Attributes заменяет все значения атрибутов целиком
+	Attributes map[string]string `json:"attributes,omitempty"`
 }
 
 // InfoForSC - форма для получения информации для вывода в корзине
@@ -16,6 +40,83 @@ type InfoForSC struct {
 	Name     string  `json:"name"`
 	Price    int64   `json:"price"`
 	Discount int     `json:"discount"`
+	Quantity int     `json:"quantity"`
 	IsActive bool    `json:"is_active"`
 	Rating   float64 `json:"rating"`
+	Images   []Image `json:"images,omitempty"`
+	// CartQuantity - количество товара в корзине пользователя
+	CartQuantity int `json:"cart_quantity,omitempty"`
+}
+
+// SearchFilter - параметры поиска объявлений
+type SearchFilter struct {
+	Query      string            `json:"query"`
+	CategoryID int               `json:"category_id"`
+	Attributes map[string]string `json:"attributes"`
+}
+
+// Image - фотография объявления с URL оригинала и превью разных размеров
+type Image struct {
+	ID         string            `json:"id"`
+	Position   int               `json:"position"`
+	URL        string            `json:"url"`
+	Thumbnails map[string]string `json:"thumbnails"`
+}
+
+// EffectivePrice - цена с учетом скидки, округленная вверх, как при оплате корзины
+func EffectivePrice(price int64, discount int) int64 {
+	return (price*int64(100-discount) + 99) / 100
+}
+
+// Статусы объявлений в витрине продавца
+const (
+	SellerStatusAll    = "all"
+	SellerStatusActive = "active"
+	SellerStatusSold   = "sold"
+)
+
+// Сортировки объявлений в витрине продавца
+const (
+	SellerSortNewest    = "newest"
+	SellerSortOldest    = "oldest"
+	SellerSortPriceAsc  = "price_asc"
+	SellerSortPriceDesc = "price_desc"
+	SellerSortRating    = "rating"
+)
+
+// SellerFilter - параметры выборки объявлений продавца
+type SellerFilter struct {
+	// Status - all, active или sold (товар закончился)
+	Status string `json:"status"`
+	// Sort - newest, oldest, price_asc, price_desc или rating
+	Sort   string `json:"sort"`
+	Limit  int    `json:"limit"`
+	Offset int    `json:"offset"`
+}
+
+// SellerStats - агрегаты по объявлениям продавца для витрины
+type SellerStats struct {
+	TotalCount  int `json:"total_count"`
+	ActiveCount int `json:"active_count"`
+	SoldCount   int `json:"sold_count"`
+	// AverageRating - средний рейтинг объявлений, у которых есть оценки
+	AverageRating float64 `json:"average_rating"`
+}
+
+// CompareItem - объявление в сравнении вместе с рейтингом продавца и атрибутами
+type CompareItem struct {
+	ID             string  `json:"id"`
+	Name           string  `json:"name"`
+	SellerID       string  `json:"user_seller_id"`
+	Category       int     `json:"category"`
+	Price          int64   `json:"price"`
+	Discount       int     `json:"discount"`
+	EffectivePrice int64   `json:"effective_price"`
+	IsActive       bool    `json:"is_active"`
+	Rating         float64 `json:"rating"`
+	RatingCount    int     `json:"rating_count"`
+	// SellerRating и SellerRatingCount - рейтинг продавца из users
+	SellerRating      float64           `json:"seller_rating"`
+	SellerRatingCount int               `json:"seller_rating_count"`
+	Attributes        map[string]string `json:"attributes"`
 }
@@ -6,4 +6,46 @@ type ElasticDoc struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
 	Category    int    `json:"category,omitempty"`
+	SellerID    string `json:"seller_id,omitempty"`
+	// Price - цена с учетом скидки, по ней подбираются похожие объявления
+	Price      int64             `json:"price,omitempty"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// SearchFilter - фильтры поиска: категории (уже с потомками) и точные значения атрибутов
+type SearchFilter struct {
+	Categories []int
+	Attributes map[string]string
+}
+
+// FacetBucket - значение атрибута и количество документов с ним
+type FacetBucket struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// Facets - фасеты по кодам атрибутов
+type Facets map[string][]FacetBucket
+
+// Причины, по которым объявление попало в похожие: имена именованных запросов ES
+const (
+	SimilarReasonText     = "similar_text"
+	SimilarReasonCategory = "same_category"
+	SimilarReasonPrice    = "similar_price"
+)
+
+// SimilarFilter - параметры подбора похожих объявлений
+type SimilarFilter struct {
+	// ExcludeSellerID - продавец, чьи объявления не предлагаются
+	ExcludeSellerID string
+	Category        int
+	// PriceFrom и PriceTo - ценовой коридор, в котором объявления поднимаются выше
+	PriceFrom int64
+	PriceTo   int64
+}
+
+// SimilarHit - найденное похожее объявление и причины, по которым оно подошло
+type SimilarHit struct {
+	ID      string
+	Reasons []string
 }
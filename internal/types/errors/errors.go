@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"sort"
+	"strings"
 
 	"go.uber.org/zap"
 )
@@ -33,8 +35,65 @@ var (
 	ErrSearch   = errors.New("search error")
 
 	ErrAlreadyLeftFeedback = errors.New("user has already left feedback for this announcement")
+
+	ErrUnknownCategory  = errors.New("unknown category")
+	ErrInvalidAttribute = errors.New("invalid attribute")
+
+	ErrForbidden = errors.New("access denied")
+
+	ErrUnsupportedImage  = errors.New("unsupported image format")
+	ErrImageTooLarge     = errors.New("image is too large")
+	ErrTooManyImages     = errors.New("too many images for one announcement")
+	ErrInvalidImageOrder = errors.New("image order must contain every image of the announcement exactly once")
+	ErrBadBlobKey        = errors.New("bad blob key")
+
+	ErrInvalidQuantity = errors.New("quantity must be positive")
+	ErrOutOfStock      = errors.New("not enough items in stock")
+	ErrNoFunds         = errors.New("insufficient funds")
+
+	ErrValidation = errors.New("validation failed")
+	ErrDraft      = errors.New("announcement is a draft")
+	ErrNotDraft   = errors.New("announcement is already published")
+
+	ErrFollowSelf = errors.New("can't follow yourself")
+	ErrBadCursor  = errors.New("bad cursor")
+
+	ErrAnalyticsUnavailable = errors.New("analytics service unavailable")
 )
 
+// FieldErrors - ошибки валидации по полям формы: имя поля -> описание проблемы
+// errors.Is(fe, ErrValidation) == true
+type FieldErrors map[string]string
+
+func (fe FieldErrors) Error() string {
+	fields := make([]string, 0, len(fe))
+	for field, msg := range fe {
+		fields = append(fields, field+": "+msg)
+	}
+	sort.Strings(fields)
+
+	return ErrValidation.Error() + ": " + strings.Join(fields, "; ")
+}
+
+func (fe FieldErrors) Unwrap() error {
+	return ErrValidation
+}
+
+// Add запоминает первую ошибку по полю
+func (fe FieldErrors) Add(field, msg string) {
+	if _, ok := fe[field]; !ok {
+		fe[field] = msg
+	}
+}
+
+// OrNil возвращает nil, если ошибок нет, иначе сами ошибки
+func (fe FieldErrors) OrNil() error {
+	if len(fe) == 0 {
+		return nil
+	}
+	return fe
+}
+
 type ErrorServer struct {
 	Message string `json:"message"`
 }
@@ -68,3 +127,18 @@ func SendErrorTo(w http.ResponseWriter, err error, statusCode int, logger *zap.S
 		logger.Error(errEncode)
 	}
 }
+
+type validationErrorServer struct {
+	Message string      `json:"message"`
+	Fields  FieldErrors `json:"fields"`
+}
+
+// SendFieldErrorsTo отвечает 422 со списком ошибок по полям
+func SendFieldErrorsTo(w http.ResponseWriter, fe FieldErrors, logger *zap.SugaredLogger) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	resp := validationErrorServer{Message: ErrValidation.Error(), Fields: fe}
+	if errEncode := json.NewEncoder(w).Encode(resp); errEncode != nil {
+		logger.Error(errEncode)
+	}
+}
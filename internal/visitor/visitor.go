@@ -0,0 +1,31 @@
+package visitor
+
+import "context"
+
+const (
+	// CookieName - cookie браузера с id посетителя
+	CookieName = "visitor_id"
+	// HeaderName - заголовок с id посетителя для клиентов без cookie (мобильные приложения)
+	HeaderName = "X-Visitor-ID"
+	// keyPrefix отличает посетителей от пользователей в хранилищах, общих для обоих
+	keyPrefix = "visitor:"
+)
+
+type ctxKey struct{}
+
+// Key - ключ посетителя в хранилищах, где лежат и данные пользователей:
+// недавно просмотренные в Redis и предпочтения в аналитике
+func Key(id string) string {
+	return keyPrefix + id
+}
+
+// WithID кладет id анонимного посетителя в контекст запроса
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+// FromContext возвращает id посетителя из контекста
+func FromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(ctxKey{}).(string)
+	return id, ok && id != ""
+}
@@ -0,0 +1,28 @@
+package visitor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromContext(t *testing.T) {
+	t.Parallel()
+
+	_, ok := FromContext(context.Background())
+	assert.False(t, ok)
+
+	id, ok := FromContext(WithID(context.Background(), "v1"))
+	assert.True(t, ok)
+	assert.Equal(t, "v1", id)
+
+	// пустой id считается отсутствующим
+	_, ok = FromContext(WithID(context.Background(), ""))
+	assert.False(t, ok)
+}
+
+func TestKey(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "visitor:v1", Key("v1"))
+}